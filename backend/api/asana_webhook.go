@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const AsanaResourceTypeTask = "task"
+const AsanaResourceTypeStory = "story"
+const AsanaActionAdded = "added"
+const AsanaActionChanged = "changed"
+const AsanaActionDeleted = "deleted"
+
+type AsanaWebhookResource struct {
+	GID             string `json:"gid"`
+	ResourceType    string `json:"resource_type"`
+	ResourceSubtype string `json:"resource_subtype"`
+}
+
+type AsanaWebhookEvent struct {
+	Action   string                `json:"action"`
+	Resource AsanaWebhookResource  `json:"resource"`
+	Parent   *AsanaWebhookResource `json:"parent"`
+}
+
+type AsanaWebhookPayload struct {
+	Events []AsanaWebhookEvent `json:"events"`
+}
+
+func (api *API) AsanaWebhook(c *gin.Context) {
+	webhookID, err := primitive.ObjectIDFromHex(c.Query("webhook_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "unrecognized asana webhook"})
+		return
+	}
+	webhookCollection := database.GetAsanaWebhookCollection(api.DB)
+
+	// the handshake is sent once when the webhook is registered, and carries the secret for later deliveries.
+	// only Asana knows the nonce from the target URL, so no one else can set the secret first.
+	handshakeSecret := c.Request.Header.Get("X-Hook-Secret")
+	if handshakeSecret != "" {
+		handshakeNonce := c.Query("nonce")
+		if handshakeNonce == "" {
+			c.JSON(400, gin.H{"detail": "unrecognized asana webhook"})
+			return
+		}
+		result, err := webhookCollection.UpdateOne(
+			context.Background(),
+			bson.M{"$and": []bson.M{{"_id": webhookID}, {"handshake_nonce": handshakeNonce}, {"secret": ""}}},
+			bson.M{"$set": bson.M{"secret": handshakeSecret}},
+		)
+		if err != nil || result.MatchedCount == 0 {
+			c.JSON(400, gin.H{"detail": "unrecognized asana webhook"})
+			return
+		}
+		c.Header("X-Hook-Secret", handshakeSecret)
+		c.JSON(200, gin.H{})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to read asana webhook request body")
+		c.JSON(400, gin.H{"detail": "unable to read request body"})
+		return
+	}
+
+	// verification for security
+	var webhook database.AsanaWebhook
+	err = webhookCollection.FindOne(context.Background(), bson.M{"_id": webhookID}).Decode(&webhook)
+	if err != nil || webhook.Secret == "" {
+		c.JSON(400, gin.H{"detail": "signature invalid"})
+		return
+	}
+	err = authenticateAsanaRequest(webhook.Secret, c.Request.Header.Get("X-Hook-Signature"), body)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "signature invalid"})
+		return
+	}
+
+	var webhookPayload AsanaWebhookPayload
+	err = json.Unmarshal(body, &webhookPayload)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "unable to process asana webhook payload"})
+		return
+	}
+
+	for _, event := range webhookPayload.Events {
+		err = api.processAsanaWebhookEvent(event)
+		if err != nil {
+			api.Logger.Error().Err(err).Str("action", event.Action).Str("resourceType", event.Resource.ResourceType).Msg("unable to process asana webhook")
+			c.JSON(400, gin.H{"detail": "unable to process asana webhook"})
+			return
+		}
+	}
+	c.JSON(200, gin.H{})
+}
+
+func authenticateAsanaRequest(secret string, signature string, body []byte) error {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write(body)
+	computed := []byte(hex.EncodeToString(hash.Sum(nil)))
+	if !hmac.Equal(computed, []byte(signature)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// events only reference the changed resource, so the details are fetched for every user who has the task
+func (api *API) processAsanaWebhookEvent(event AsanaWebhookEvent) error {
+	taskID := event.Resource.GID
+	isComment := event.Resource.ResourceType == AsanaResourceTypeStory && event.Resource.ResourceSubtype == external.AsanaCommentSubtype
+	if isComment {
+		if event.Action != AsanaActionAdded || event.Parent == nil {
+			return nil
+		}
+		taskID = event.Parent.GID
+	} else if event.Resource.ResourceType != AsanaResourceTypeTask {
+		return nil
+	}
+
+	// tasks we don't have yet are picked up by the next refresh
+	tasks, err := database.GetTasksByExternalIDWithoutUser(api.DB, taskID, external.TASK_SOURCE_ID_ASANA)
+	if err != nil {
+		return err
+	}
+	if len(*tasks) == 0 {
+		return nil
+	}
+	asanaTaskSource, err := api.getAsanaTaskSource()
+	if err != nil {
+		return err
+	}
+	for _, task := range *tasks {
+		task := task
+		if isComment {
+			var comment *database.Comment
+			comment, err = asanaTaskSource.GetComment(api.DB, task.UserID, task.SourceAccountID, event.Resource.GID)
+			if err == nil {
+				err = api.addExternalComment(&task, *comment)
+			}
+		} else if event.Action == AsanaActionDeleted {
+			err = api.removeTaskFromAsanaEvent(&task)
+		} else if event.Action == AsanaActionAdded || event.Action == AsanaActionChanged {
+			_, err = asanaTaskSource.RefreshTask(api.DB, task.UserID, task.SourceAccountID, taskID)
+		}
+		if err != nil {
+			// one user's expired token shouldn't stop the others from being updated
+			api.Logger.Error().Err(err).Msg("failed to apply asana webhook event")
+		}
+	}
+	return nil
+}
+
+func (api *API) removeTaskFromAsanaEvent(task *database.Task) error {
+	deletionState := true
+	updateTask := database.Task{
+		IsDeleted: &deletionState,
+		DeletedAt: primitive.NewDateTimeFromTime(api.GetCurrentTime()),
+	}
+	return api.UpdateTaskInDBWithError(task, task.UserID, &updateTask)
+}
+
+func (api *API) getAsanaTaskSource() (external.AsanaTaskSource, error) {
+	taskSource, err := api.ExternalConfig.GetSourceResult(external.TASK_SOURCE_ID_ASANA)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to get task source result for asana")
+		return external.AsanaTaskSource{}, err
+	}
+	return taskSource.Source.(external.AsanaTaskSource), nil
+}
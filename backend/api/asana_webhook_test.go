@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func signAsanaWebhookBody(secret string, body string) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(body))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func TestAsanaWebhook(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	router := GetRouter(api)
+
+	authToken := login("test_asana_webhook@resonant-kelpie-404a42.netlify.app", "")
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	webhookResult, err := database.GetAsanaWebhookCollection(api.DB).InsertOne(context.Background(), database.AsanaWebhook{
+		ResourceID:     "asana_webhook_project",
+		HandshakeNonce: "handshake_nonce",
+		UserID:         userID,
+	})
+	assert.NoError(t, err)
+	webhookID := webhookResult.InsertedID.(primitive.ObjectID).Hex()
+	isDeleted := false
+	insertResult, err := database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:          userID,
+		IDExternal:      "asana_webhook_task",
+		SourceID:        external.TASK_SOURCE_ID_ASANA,
+		SourceAccountID: "sample_account@email.com",
+		IsDeleted:       &isDeleted,
+	})
+	assert.NoError(t, err)
+	taskID := insertResult.InsertedID.(primitive.ObjectID)
+
+	serveAsanaWebhook := func(webhookID string, nonce string, payload string, headers map[string]string) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest("POST", "/asana/webhook/?webhook_id="+webhookID+"&nonce="+nonce, bytes.NewBuffer([]byte(payload)))
+		for key, value := range headers {
+			request.Header.Add(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	readBody := func(recorder *httptest.ResponseRecorder) string {
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("HandshakeUnknownWebhook", func(t *testing.T) {
+		recorder := serveAsanaWebhook(primitive.NewObjectID().Hex(), "handshake_nonce", "", map[string]string{"X-Hook-Secret": "secret"})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, `{"detail":"unrecognized asana webhook"}`, readBody(recorder))
+	})
+	t.Run("HandshakeWrongNonce", func(t *testing.T) {
+		recorder := serveAsanaWebhook(webhookID, "guessed_nonce", "", map[string]string{"X-Hook-Secret": "secret"})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		recorder = serveAsanaWebhook(webhookID, "", "", map[string]string{"X-Hook-Secret": "secret"})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("Handshake", func(t *testing.T) {
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", "", map[string]string{"X-Hook-Secret": "secret"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "secret", recorder.Header().Get("X-Hook-Secret"))
+
+		// the secret can't be replaced once the handshake is done
+		recorder = serveAsanaWebhook(webhookID, "handshake_nonce", "", map[string]string{"X-Hook-Secret": "other_secret"})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("InvalidSignature", func(t *testing.T) {
+		payload := `{"events":[]}`
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", payload, map[string]string{"X-Hook-Signature": signAsanaWebhookBody("other_secret", payload)})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, `{"detail":"signature invalid"}`, readBody(recorder))
+	})
+	t.Run("InvalidFormat", func(t *testing.T) {
+		payload := `"uhoh"`
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", payload, map[string]string{"X-Hook-Signature": signAsanaWebhookBody("secret", payload)})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, `{"detail":"unable to process asana webhook payload"}`, readBody(recorder))
+	})
+	t.Run("Heartbeat", func(t *testing.T) {
+		payload := `{"events":[]}`
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", payload, map[string]string{"X-Hook-Signature": signAsanaWebhookBody("secret", payload)})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+	t.Run("UntrackedTask", func(t *testing.T) {
+		payload := `{"events":[{"action":"changed","resource":{"gid":"not_tracked","resource_type":"task"}}]}`
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", payload, map[string]string{"X-Hook-Signature": signAsanaWebhookBody("secret", payload)})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+	t.Run("TaskDeleted", func(t *testing.T) {
+		payload := `{"events":[{"action":"deleted","resource":{"gid":"asana_webhook_task","resource_type":"task"}}]}`
+		recorder := serveAsanaWebhook(webhookID, "handshake_nonce", payload, map[string]string{"X-Hook-Signature": signAsanaWebhookBody("secret", payload)})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var task database.Task
+		err := database.GetTaskCollection(api.DB).FindOne(context.Background(), bson.M{"_id": taskID}).Decode(&task)
+		assert.NoError(t, err)
+		assert.True(t, *task.IsDeleted)
+	})
+}
+
+func TestAddExternalComment(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := primitive.NewObjectID()
+
+	comments := []database.Comment{{ExternalID: "existing", Body: "first"}, {Body: "added from here"}}
+	insertResult, err := database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:   userID,
+		Comments: &comments,
+	})
+	assert.NoError(t, err)
+	taskID := insertResult.InsertedID.(primitive.ObjectID)
+
+	addComment := func(comment database.Comment) []database.Comment {
+		task, err := database.GetTask(api.DB, taskID, userID)
+		assert.NoError(t, err)
+		assert.NoError(t, api.addExternalComment(task, comment))
+		task, err = database.GetTask(api.DB, taskID, userID)
+		assert.NoError(t, err)
+		return *task.Comments
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		result := addComment(database.Comment{ExternalID: "existing", Body: "first"})
+		assert.Equal(t, 2, len(result))
+	})
+	t.Run("FillsExternalID", func(t *testing.T) {
+		result := addComment(database.Comment{ExternalID: "echoed", Body: "added from here"})
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "echoed", result[1].ExternalID)
+	})
+	t.Run("NewComment", func(t *testing.T) {
+		result := addComment(database.Comment{ExternalID: "new", Body: "brand new"})
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "new", result[2].ExternalID)
+	})
+}
@@ -128,6 +128,98 @@ func (api *API) LinkCallback(c *gin.Context) {
 	c.Status(200)
 }
 
+type LinkCredentialsParams struct {
+	ServerURL string `json:"server_url" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password"`
+}
+
+// LinkCredentials godoc
+// @Summary      Links an account to a service using a username and password
+// @Description  Used for services which don't support oauth, such as CalDAV servers
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        service_name   path      string  true  "Source ID"
+// @Param        payload  		body      LinkCredentialsParams  true  "Credentials"
+// @Success      201 {object} string "success"
+// @Failure      400 {object} string "invalid params"
+// @Failure      404 {object} string "service not found"
+// @Router       /link/{service_name}/credentials/ [post]
+func (api *API) LinkCredentials(c *gin.Context) {
+	taskServiceResult, err := api.ExternalConfig.GetTaskServiceResult(c.Param("service_name"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	if taskServiceResult.Details.AuthType != external.AuthTypeBasic {
+		c.JSON(400, gin.H{"detail": "service does not support credentials"})
+		return
+	}
+	var linkParams LinkCredentialsParams
+	err = c.BindJSON(&linkParams)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	err = taskServiceResult.Service.HandleLinkCallback(api.DB, external.CallbackParams{BasicAuth: &external.BasicAuthParams{
+		ServerURL: linkParams.ServerURL,
+		Username:  linkParams.Username,
+		Password:  linkParams.Password,
+	}}, userID)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
+type LinkFeedURLParams struct {
+	URL  string `json:"url" binding:"required"`
+	Name string `json:"name"`
+}
+
+// LinkFeedURL godoc
+// @Summary      Subscribes to a calendar feed
+// @Description  Used for read-only calendars which are published at a URL, such as ICS feeds
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        service_name   path      string  true  "Source ID"
+// @Param        payload  		body      LinkFeedURLParams  true  "Feed URL"
+// @Success      201 {object} string "success"
+// @Failure      400 {object} string "invalid params"
+// @Failure      404 {object} string "service not found"
+// @Router       /link/{service_name}/url/ [post]
+func (api *API) LinkFeedURL(c *gin.Context) {
+	taskServiceResult, err := api.ExternalConfig.GetTaskServiceResult(c.Param("service_name"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	if taskServiceResult.Details.AuthType != external.AuthTypeURL {
+		c.JSON(400, gin.H{"detail": "service does not support feed urls"})
+		return
+	}
+	var linkParams LinkFeedURLParams
+	err = c.BindJSON(&linkParams)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	err = taskServiceResult.Service.HandleLinkCallback(api.DB, external.CallbackParams{FeedURL: &external.FeedURLParams{
+		URL:  linkParams.URL,
+		Name: linkParams.Name,
+	}}, userID)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
 // LinkSlackApp godoc
 // @Summary      Links a Slack workspace to be able to use General Task
 // @Description  Used because we treat this access_token differently to the others
@@ -158,12 +250,19 @@ func (api *API) LinkSlackApp(c *gin.Context) {
 	}
 
 	slackService := taskService.Service.(external.SlackService)
-	_, err = slackService.Config.OauthConfig.Exchange(context.Background(), redirectParams.Code)
+	token, err := slackService.Config.OauthConfig.Exchange(context.Background(), redirectParams.Code)
 	if err != nil {
 		logger.Error().Err(err).Msg("unable to exchange Slack app oauth keys")
 		c.JSON(500, gin.H{"detail": err.Error()})
 		return
 	}
+	// the app token is used to send users direct messages, i.e. reminders
+	err = external.SaveSlackAppToken(api.DB, token)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to save Slack app token")
+		Handle500(c)
+		return
+	}
 
 	c.Redirect(302, config.GetConfigValue("HOME_URL"))
 }
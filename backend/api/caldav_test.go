@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkCalDAVCredentials(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	router := GetRouter(api)
+	authToken := login("test_link_caldav@resonant-kelpie-404a42.netlify.app", "")
+
+	serveLinkCredentials := func(serviceName string, payload string) (int, string) {
+		request, _ := http.NewRequest("POST", "/link/"+serviceName+"/credentials/", bytes.NewBuffer([]byte(payload)))
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return recorder.Code, string(body)
+	}
+
+	t.Run("UnknownService", func(t *testing.T) {
+		code, _ := serveLinkCredentials("unknown", `{"server_url":"https://example.com/","username":"user"}`)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+	t.Run("OauthService", func(t *testing.T) {
+		code, body := serveLinkCredentials("asana", `{"server_url":"https://example.com/","username":"user"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"service does not support credentials"}`, body)
+	})
+	t.Run("MissingParams", func(t *testing.T) {
+		code, body := serveLinkCredentials("caldav", `{"username":"user"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"invalid or missing parameter"}`, body)
+	})
+	t.Run("InvalidServerURL", func(t *testing.T) {
+		code, body := serveLinkCredentials("caldav", `{"server_url":"ftp://example.com/","username":"user"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"invalid caldav server url"}`, body)
+	})
+	t.Run("Unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		code, body := serveLinkCredentials("caldav", `{"server_url":"`+server.URL+`/","username":"user","password":"wrong"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unable to connect to caldav server"}`, body)
+	})
+	UnauthorizedTest(t, "POST", "/link/caldav/credentials/", nil)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CalendarFeedTaskFormatEvent = "vevent"
+	CalendarFeedTaskFormatTodo  = "vtodo"
+	calendarFeedName            = "General Task"
+	// calendar clients replace the whole feed on refresh, so recent events are kept to avoid them disappearing
+	calendarFeedPastDays   = 7
+	calendarFeedFutureDays = 90
+)
+
+type CalendarFeedParams struct {
+	TaskFormat string `form:"task_format"`
+}
+
+type CalendarFeedURLResult struct {
+	URL string `json:"url"`
+}
+
+// CalendarFeedURL godoc
+// @Summary      Returns the user's secret calendar feed URL, creating it if needed
+// @Tags         calendar
+// @Produce      json
+// @Success      200 {object} CalendarFeedURLResult
+// @Failure      500 {object} string "internal server error"
+// @Router       /calendar_feed/ [get]
+func (api *API) CalendarFeedURL(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	user, err := database.GetUser(api.DB, userID)
+	if err != nil {
+		Handle500(c)
+		return
+	}
+	feedToken := user.CalendarFeedToken
+	if feedToken == "" {
+		feedToken, err = api.updateCalendarFeedToken(userID)
+		if err != nil {
+			api.Logger.Error().Err(err).Msg("failed to create calendar feed token")
+			Handle500(c)
+			return
+		}
+	}
+	c.JSON(200, CalendarFeedURLResult{URL: getCalendarFeedURL(feedToken)})
+}
+
+// CalendarFeedURLReset godoc
+// @Summary      Replaces the user's calendar feed URL, so that the previous URL stops working
+// @Tags         calendar
+// @Produce      json
+// @Success      200 {object} CalendarFeedURLResult
+// @Failure      500 {object} string "internal server error"
+// @Router       /calendar_feed/reset/ [post]
+func (api *API) CalendarFeedURLReset(c *gin.Context) {
+	feedToken, err := api.updateCalendarFeedToken(getUserIDFromContext(c))
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to reset calendar feed token")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, CalendarFeedURLResult{URL: getCalendarFeedURL(feedToken)})
+}
+
+// CalendarFeed godoc
+// @Summary      Publishes events created or linked in the app, and tasks with due dates, as an ICS feed
+// @Description  Unauthenticated, the secret token in the URL identifies the user
+// @Tags         calendar
+// @Produce      text/calendar
+// @Param        feed_token   path      string  true  "Calendar feed token"
+// @Param        task_format  query     string  false "vevent (default) for all day events, or vtodo"
+// @Success      200 {object} string "ics feed"
+// @Failure      400 {object} string "invalid params"
+// @Failure      404 {object} string "feed not found"
+// @Router       /calendar_feed/ics/{feed_token}/ [get]
+func (api *API) CalendarFeed(c *gin.Context) {
+	var params CalendarFeedParams
+	err := c.BindQuery(&params)
+	if err != nil || (params.TaskFormat != "" && params.TaskFormat != CalendarFeedTaskFormatEvent && params.TaskFormat != CalendarFeedTaskFormatTodo) {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	feedToken := c.Param("feed_token")
+	var user database.User
+	err = database.GetUserCollection(api.DB).FindOne(context.Background(), bson.M{"calendar_feed_token": feedToken}).Decode(&user)
+	if feedToken == "" || err != nil {
+		Handle404(c)
+		return
+	}
+	location, err := database.GetUserLocation(api.DB, user.ID)
+	if err != nil || location == nil {
+		location = time.UTC
+	}
+
+	timeNow := api.GetCurrentTime()
+	events, err := database.GetCalendarEvents(api.DB, user.ID, &[]bson.M{
+		{"datetime_end": bson.M{"$gte": timeNow.AddDate(0, 0, -calendarFeedPastDays)}},
+		{"datetime_start": bson.M{"$lte": timeNow.AddDate(0, 0, calendarFeedFutureDays)}},
+	})
+	if err != nil {
+		Handle500(c)
+		return
+	}
+	tasks, err := database.GetActiveTasks(api.DB, user.ID)
+	if err != nil {
+		Handle500(c)
+		return
+	}
+
+	calendar := getCalendarFeed(*events, *tasks, params.TaskFormat == CalendarFeedTaskFormatTodo, location, timeNow)
+	c.Data(200, "text/calendar; charset=utf-8", []byte(calendar.Serialize()))
+}
+
+func (api *API) updateCalendarFeedToken(userID primitive.ObjectID) (string, error) {
+	randomBytes := make([]byte, 32)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+	feedToken := hex.EncodeToString(randomBytes)
+	_, err = database.GetUserCollection(api.DB).UpdateOne(
+		context.Background(),
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"calendar_feed_token": feedToken}},
+	)
+	return feedToken, err
+}
+
+func getCalendarFeedURL(feedToken string) string {
+	return config.GetConfigValue("SERVER_URL") + "calendar_feed/ics/" + feedToken + "/"
+}
+
+func getCalendarFeed(events []database.CalendarEvent, tasks []database.Task, tasksAsTodos bool, location *time.Location, timeNow time.Time) *external.ICalComponent {
+	components := []*external.ICalComponent{}
+	for _, event := range events {
+		if isCalendarFeedEvent(event) {
+			components = append(components, calendarEventToICal(event, timeNow))
+		}
+	}
+	for _, task := range tasks {
+		// due dates before 1971 are treated as empty
+		if task.DueDate == nil || task.DueDate.Time().UTC().Year() <= 1971 {
+			continue
+		}
+		if tasksAsTodos {
+			components = append(components, taskToICalTodo(task, timeNow))
+		} else {
+			components = append(components, taskToICalEvent(task, location, timeNow))
+		}
+	}
+	calendar := external.NewICalCalendar(components...)
+	calendar.SetProperty(external.ICalPropertyMethod, external.ICalMethodPublish, nil)
+	calendar.SetText(external.ICalPropertyCalName, calendarFeedName)
+	return calendar
+}
+
+// isCalendarFeedEvent returns whether the event was created in the app, which stores our ID as its external ID, or linked to a task, view or pull request
+func isCalendarFeedEvent(event database.CalendarEvent) bool {
+	// subscribed feeds are read only, so none of their events could have been created here
+	if event.SourceID == external.TASK_SOURCE_ID_ICS {
+		return false
+	}
+	if event.LinkedTaskID != primitive.NilObjectID || event.LinkedViewID != primitive.NilObjectID || event.LinkedPullRequestID != primitive.NilObjectID {
+		return true
+	}
+	// instances of a series created here are identified by the series ID
+	return primitive.IsValidObjectID(event.IDExternal) || primitive.IsValidObjectID(event.RecurringEventID)
+}
+
+func calendarEventToICal(event database.CalendarEvent, timeNow time.Time) *external.ICalComponent {
+	vevent := &external.ICalComponent{Name: external.ICalComponentEvent}
+	vevent.SetProperty(external.ICalPropertyUID, event.ID.Hex(), nil)
+	vevent.SetProperty(external.ICalPropertyTimestamp, external.FormatICalDateTime(timeNow), nil)
+	vevent.SetProperty(external.ICalPropertyStart, external.FormatICalDateTime(event.DatetimeStart.Time()), nil)
+	vevent.SetProperty(external.ICalPropertyEnd, external.FormatICalDateTime(event.DatetimeEnd.Time()), nil)
+	vevent.SetText(external.ICalPropertySummary, event.Title)
+	if event.Body != "" {
+		vevent.SetText(external.ICalPropertyDesc, event.Body)
+	}
+	if event.Location != "" {
+		vevent.SetText(external.ICalPropertyLocation, event.Location)
+	}
+	if event.Deeplink != "" {
+		vevent.SetProperty(external.ICalPropertyURL, event.Deeplink, nil)
+	}
+	return vevent
+}
+
+// taskToICalEvent shows the task as an all day event on its due date in the user's timezone.
+// Due dates without a time are stored at midnight UTC, so keep their date as is.
+func taskToICalEvent(task database.Task, location *time.Location, timeNow time.Time) *external.ICalComponent {
+	dueDate := task.DueDate.Time().UTC()
+	if !isDateOnlyDueDate(dueDate) {
+		dueDate = dueDate.In(location)
+	}
+	dueDay := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	vevent := &external.ICalComponent{Name: external.ICalComponentEvent}
+	vevent.SetProperty(external.ICalPropertyUID, task.ID.Hex(), nil)
+	vevent.SetProperty(external.ICalPropertyTimestamp, external.FormatICalDateTime(timeNow), nil)
+	startValue, startParams := external.FormatICalDate(dueDay)
+	vevent.SetProperty(external.ICalPropertyStart, startValue, startParams)
+	endValue, endParams := external.FormatICalDate(dueDay.AddDate(0, 0, 1))
+	vevent.SetProperty(external.ICalPropertyEnd, endValue, endParams)
+	setCalendarFeedTaskFields(vevent, task)
+	return vevent
+}
+
+func taskToICalTodo(task database.Task, timeNow time.Time) *external.ICalComponent {
+	dueDate := task.DueDate.Time().UTC()
+	vtodo := &external.ICalComponent{Name: external.ICalComponentTodo}
+	vtodo.SetProperty(external.ICalPropertyUID, task.ID.Hex(), nil)
+	vtodo.SetProperty(external.ICalPropertyTimestamp, external.FormatICalDateTime(timeNow), nil)
+	if isDateOnlyDueDate(dueDate) {
+		value, params := external.FormatICalDate(dueDate)
+		vtodo.SetProperty(external.ICalPropertyDue, value, params)
+	} else {
+		vtodo.SetProperty(external.ICalPropertyDue, external.FormatICalDateTime(dueDate), nil)
+	}
+	vtodo.SetProperty(external.ICalPropertyStatus, external.CalDAVStatusNeedsAction, nil)
+	setCalendarFeedTaskFields(vtodo, task)
+	return vtodo
+}
+
+func setCalendarFeedTaskFields(component *external.ICalComponent, task database.Task) {
+	if task.Title != nil {
+		component.SetText(external.ICalPropertySummary, *task.Title)
+	}
+	if task.Body != nil && *task.Body != "" {
+		component.SetText(external.ICalPropertyDesc, *task.Body)
+	}
+	if task.Deeplink != "" {
+		component.SetProperty(external.ICalPropertyURL, task.Deeplink, nil)
+	}
+}
+
+func isDateOnlyDueDate(dueDate time.Time) bool {
+	return dueDate.Hour() == 0 && dueDate.Minute() == 0 && dueDate.Second() == 0
+}
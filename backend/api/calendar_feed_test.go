@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetCalendarFeed(t *testing.T) {
+	timeNow := time.Date(2023, time.March, 6, 12, 0, 0, 0, time.UTC)
+	location := time.FixedZone("", -8*60*60)
+	title := "write report"
+	body := "for the quarter"
+	dateOnlyDueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.March, 8, 0, 0, 0, 0, time.UTC))
+	// 2am UTC is still the previous day in UTC-8
+	timedDueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.March, 9, 2, 0, 0, 0, time.UTC))
+	emptyDueDate := primitive.NewDateTimeFromTime(time.Unix(0, 0))
+	dateOnlyTask := database.Task{ID: primitive.NewObjectID(), Title: &title, Body: &body, DueDate: &dateOnlyDueDate, Deeplink: "https://example.com/task"}
+	timedTask := database.Task{ID: primitive.NewObjectID(), Title: &title, DueDate: &timedDueDate}
+	tasks := []database.Task{dateOnlyTask, timedTask, {ID: primitive.NewObjectID(), Title: &title}, {ID: primitive.NewObjectID(), Title: &title, DueDate: &emptyDueDate}}
+
+	createdEvent := database.CalendarEvent{
+		ID:            primitive.NewObjectID(),
+		IDExternal:    primitive.NewObjectID().Hex(),
+		Title:         "focus time",
+		Location:      "home",
+		DatetimeStart: primitive.NewDateTimeFromTime(timeNow),
+		DatetimeEnd:   primitive.NewDateTimeFromTime(timeNow.Add(time.Hour)),
+	}
+	linkedEvent := database.CalendarEvent{ID: primitive.NewObjectID(), IDExternal: "gcal_event", LinkedTaskID: dateOnlyTask.ID}
+	externalEvent := database.CalendarEvent{ID: primitive.NewObjectID(), IDExternal: "gcal_event"}
+	subscribedEvent := database.CalendarEvent{ID: primitive.NewObjectID(), IDExternal: primitive.NewObjectID().Hex(), SourceID: external.TASK_SOURCE_ID_ICS}
+	events := []database.CalendarEvent{createdEvent, linkedEvent, externalEvent, subscribedEvent}
+
+	t.Run("TasksAsEvents", func(t *testing.T) {
+		calendar := getCalendarFeed(events, tasks, false, location, timeNow)
+		assert.Equal(t, "General Task", calendar.GetText(external.ICalPropertyCalName))
+		vevents := calendar.GetComponents(external.ICalComponentEvent)
+		assert.Equal(t, 4, len(vevents))
+		assert.Equal(t, 0, len(calendar.GetComponents(external.ICalComponentTodo)))
+
+		assert.Equal(t, createdEvent.ID.Hex(), vevents[0].GetText(external.ICalPropertyUID))
+		assert.Equal(t, "20230306T120000Z", vevents[0].GetText(external.ICalPropertyStart))
+		assert.Equal(t, "20230306T130000Z", vevents[0].GetText(external.ICalPropertyEnd))
+		assert.Equal(t, "home", vevents[0].GetText(external.ICalPropertyLocation))
+		assert.Equal(t, linkedEvent.ID.Hex(), vevents[1].GetText(external.ICalPropertyUID))
+
+		assert.Equal(t, dateOnlyTask.ID.Hex(), vevents[2].GetText(external.ICalPropertyUID))
+		assert.Equal(t, &external.ICalProperty{Name: external.ICalPropertyStart, Value: "20230308", Params: map[string]string{external.ICalParamValue: external.ICalValueDate}}, vevents[2].GetProperty(external.ICalPropertyStart))
+		assert.Equal(t, "20230309", vevents[2].GetText(external.ICalPropertyEnd))
+		assert.Equal(t, "write report", vevents[2].GetText(external.ICalPropertySummary))
+		assert.Equal(t, "for the quarter", vevents[2].GetText(external.ICalPropertyDesc))
+		assert.Equal(t, "https://example.com/task", vevents[2].GetText(external.ICalPropertyURL))
+		assert.Equal(t, "20230308", vevents[3].GetText(external.ICalPropertyStart))
+	})
+	t.Run("TasksAsTodos", func(t *testing.T) {
+		calendar := getCalendarFeed(events, tasks, true, location, timeNow)
+		assert.Equal(t, 2, len(calendar.GetComponents(external.ICalComponentEvent)))
+		vtodos := calendar.GetComponents(external.ICalComponentTodo)
+		assert.Equal(t, 2, len(vtodos))
+		assert.Equal(t, &external.ICalProperty{Name: external.ICalPropertyDue, Value: "20230308", Params: map[string]string{external.ICalParamValue: external.ICalValueDate}}, vtodos[0].GetProperty(external.ICalPropertyDue))
+		assert.Equal(t, "20230309T020000Z", vtodos[1].GetText(external.ICalPropertyDue))
+		assert.Equal(t, external.CalDAVStatusNeedsAction, vtodos[1].GetText(external.ICalPropertyStatus))
+	})
+}
+
+func TestCalendarFeed(t *testing.T) {
+	authToken := login("test_calendar_feed@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+	router := GetRouter(api)
+
+	getFeedURL := func(method string, path string) string {
+		request, _ := http.NewRequest(method, path, nil)
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var result CalendarFeedURLResult
+		err := json.NewDecoder(recorder.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result.URL, "http://localhost:8080/calendar_feed/ics/"))
+		return strings.TrimPrefix(result.URL, "http://localhost:8080")
+	}
+	getFeed := func(path string) (int, string) {
+		request, _ := http.NewRequest("GET", path, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return recorder.Code, string(body)
+	}
+
+	title := "feed task"
+	dueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.March, 8, 0, 0, 0, 0, time.UTC))
+	taskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &title, DueDate: &dueDate, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	eventStart := time.Now().Add(time.Hour)
+	_, err := database.GetCalendarEventCollection(api.DB).InsertOne(context.Background(), database.CalendarEvent{
+		UserID:        userID,
+		IDExternal:    primitive.NewObjectID().Hex(),
+		Title:         "feed event",
+		DatetimeStart: primitive.NewDateTimeFromTime(eventStart),
+		DatetimeEnd:   primitive.NewDateTimeFromTime(eventStart.Add(time.Hour)),
+	})
+	assert.NoError(t, err)
+
+	UnauthorizedTest(t, "GET", "/calendar_feed/", nil)
+	UnauthorizedTest(t, "POST", "/calendar_feed/reset/", nil)
+	feedPath := getFeedURL("GET", "/calendar_feed/")
+	t.Run("SameURL", func(t *testing.T) {
+		assert.Equal(t, feedPath, getFeedURL("GET", "/calendar_feed/"))
+	})
+	t.Run("Success", func(t *testing.T) {
+		code, body := getFeed(feedPath)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+		assert.Contains(t, body, "SUMMARY:feed event\r\n")
+		assert.Contains(t, body, "UID:"+taskID+"\r\n")
+		assert.Contains(t, body, "DTSTART;VALUE=DATE:20230308\r\n")
+	})
+	t.Run("Todos", func(t *testing.T) {
+		code, body := getFeed(feedPath + "?task_format=vtodo")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "BEGIN:VTODO\r\n")
+		assert.Contains(t, body, "DUE;VALUE=DATE:20230308\r\n")
+	})
+	t.Run("InvalidTaskFormat", func(t *testing.T) {
+		code, _ := getFeed(feedPath + "?task_format=csv")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+	t.Run("UnknownToken", func(t *testing.T) {
+		code, _ := getFeed("/calendar_feed/ics/unknown/")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+	t.Run("Reset", func(t *testing.T) {
+		newFeedPath := getFeedURL("POST", "/calendar_feed/reset/")
+		assert.NotEqual(t, feedPath, newFeedPath)
+		code, _ := getFeed(feedPath)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = getFeed(newFeedPath)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestLinkICSFeed(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	router := GetRouter(api)
+	authToken := login("test_link_ics@resonant-kelpie-404a42.netlify.app", "")
+
+	serveLinkFeedURL := func(serviceName string, payload string) (int, string) {
+		request, _ := http.NewRequest("POST", "/link/"+serviceName+"/url/", strings.NewReader(payload))
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return recorder.Code, string(body)
+	}
+
+	t.Run("CredentialsService", func(t *testing.T) {
+		code, body := serveLinkFeedURL("caldav", `{"url":"https://example.com/feed.ics"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"service does not support feed urls"}`, body)
+	})
+	t.Run("MissingParams", func(t *testing.T) {
+		code, body := serveLinkFeedURL("ics", `{"name":"holidays"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"invalid or missing parameter"}`, body)
+	})
+	t.Run("InvalidURL", func(t *testing.T) {
+		code, body := serveLinkFeedURL("ics", `{"url":"ftp://example.com/feed.ics"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"invalid ics feed url"}`, body)
+	})
+	t.Run("InternalAddress", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n"))
+		}))
+		defer server.Close()
+		code, body := serveLinkFeedURL("ics", `{"url":"`+server.URL+`/feed.ics","name":"holidays"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unable to load ics feed"}`, body)
+	})
+	UnauthorizedTest(t, "POST", "/link/ics/url/", nil)
+}
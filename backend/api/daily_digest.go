@@ -0,0 +1,102 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/templating"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetDailyDigest summarizes the user's day in their timezone, and is used by the daily digest job
+func (api *API) GetDailyDigest(userID primitive.ObjectID, location *time.Location) (*templating.DailyDigest, error) {
+	timeNow := api.GetCurrentTime().In(location)
+	timeStartOfDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, location)
+	digest := templating.DailyDigest{
+		Date:               timeNow.Format("Monday, January 2"),
+		Events:             []templating.DailyDigestItem{},
+		DueTasks:           []templating.DailyDigestItem{},
+		PullRequests:       []templating.DailyDigestItem{},
+		CompletedYesterday: []templating.DailyDigestCompletion{},
+		HomeURL:            config.GetConfigValue("HOME_URL"),
+	}
+
+	events, err := database.GetEventsUntilEndOfDay(api.DB, userID, timeStartOfDay)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(*events, func(i, j int) bool {
+		return (*events)[i].DatetimeStart < (*events)[j].DatetimeStart
+	})
+	for _, event := range *events {
+		digest.Events = append(digest.Events, templating.DailyDigestItem{
+			Title:    event.Title,
+			Subtitle: event.DatetimeStart.Time().In(location).Format("3:04 PM"),
+			Link:     event.Deeplink,
+		})
+	}
+
+	// the due today view expects an offset in the same format as the Timezone-Offset header
+	_, zoneOffsetSeconds := timeNow.Zone()
+	dueToday, err := api.GetDueTodayOverviewResult(database.View{UserID: userID}, userID, time.Duration(-zoneOffsetSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range dueToday.ViewItems {
+		link := task.Deeplink
+		if link == "" {
+			link = config.GetConfigValue("HOME_URL") + "overview"
+		}
+		digest.DueTasks = append(digest.DueTasks, templating.DailyDigestItem{
+			Title: task.Title,
+			Link:  link,
+		})
+	}
+
+	pullRequests, err := database.GetPullRequests(api.DB, userID, &[]bson.M{
+		{"is_completed": false},
+		{"required_action": external.ActionReviewPR},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, pullRequest := range *pullRequests {
+		digest.PullRequests = append(digest.PullRequests, templating.DailyDigestItem{
+			Title:    pullRequest.Title,
+			Subtitle: pullRequest.RepositoryName,
+			Link:     pullRequest.Deeplink,
+		})
+	}
+
+	// completions are grouped by UTC date, so yesterday in the user's timezone can span two groups
+	completions, err := api.GetDailyTaskCompletionList(userID, timeStartOfDay.AddDate(0, 0, -1), timeStartOfDay.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+	countBySource := map[string]int{}
+	sourceIDs := []string{}
+	for _, completion := range *completions {
+		for _, source := range completion.Sources {
+			if _, exists := countBySource[source.SourceID]; !exists {
+				sourceIDs = append(sourceIDs, source.SourceID)
+			}
+			countBySource[source.SourceID] += source.Count
+		}
+	}
+	sort.Strings(sourceIDs)
+	for _, sourceID := range sourceIDs {
+		sourceName := sourceID
+		if taskSourceResult, err := api.ExternalConfig.GetSourceResult(sourceID); err == nil {
+			sourceName = taskSourceResult.Details.Name
+		}
+		digest.CompletedYesterday = append(digest.CompletedYesterday, templating.DailyDigestCompletion{
+			Source: sourceName,
+			Count:  countBySource[sourceID],
+		})
+	}
+	return &digest, nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetDailyDigest(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	// 9am on April 20th in Los Angeles
+	testTime := time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC)
+	api.OverrideTime = &testTime
+
+	completedTrue := true
+	completedFalse := false
+	userID := primitive.NewObjectID()
+
+	dueTitle := "write report"
+	dueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.April, 20, 0, 0, 0, 0, time.UTC))
+	_, err = database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:      userID,
+		Title:       &dueTitle,
+		IsCompleted: &completedFalse,
+		DueDate:     &dueDate,
+		SourceID:    external.TASK_SOURCE_ID_GT_TASK,
+	})
+	assert.NoError(t, err)
+	// completed yesterday afternoon in Los Angeles, which is April 20th in UTC
+	_, err = database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:      userID,
+		IsCompleted: &completedTrue,
+		CompletedAt: primitive.NewDateTimeFromTime(time.Date(2023, time.April, 20, 2, 0, 0, 0, time.UTC)),
+		SourceID:    external.TASK_SOURCE_ID_GT_TASK,
+	})
+	assert.NoError(t, err)
+	// completed today, so not included
+	_, err = database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:      userID,
+		IsCompleted: &completedTrue,
+		CompletedAt: primitive.NewDateTimeFromTime(time.Date(2023, time.April, 20, 15, 0, 0, 0, time.UTC)),
+		SourceID:    external.TASK_SOURCE_ID_GT_TASK,
+	})
+	assert.NoError(t, err)
+
+	_, err = database.GetPullRequestCollection(api.DB).InsertOne(context.Background(), database.PullRequest{
+		UserID:         userID,
+		IsCompleted:    &completedFalse,
+		Title:          "fix login",
+		RepositoryName: "frontend",
+		Deeplink:       "https://github.com/pr/1",
+		RequiredAction: external.ActionReviewPR,
+	})
+	assert.NoError(t, err)
+	_, err = database.GetPullRequestCollection(api.DB).InsertOne(context.Background(), database.PullRequest{
+		UserID:         userID,
+		IsCompleted:    &completedFalse,
+		Title:          "my own pr",
+		RequiredAction: external.ActionWaitingOnReview,
+	})
+	assert.NoError(t, err)
+
+	digest, err := api.GetDailyDigest(userID, losAngeles)
+	assert.NoError(t, err)
+	assert.Equal(t, "Thursday, April 20", digest.Date)
+	assert.Equal(t, 1, len(digest.DueTasks))
+	assert.Equal(t, dueTitle, digest.DueTasks[0].Title)
+	assert.Equal(t, 1, len(digest.PullRequests))
+	assert.Equal(t, "fix login", digest.PullRequests[0].Title)
+	assert.Equal(t, "frontend", digest.PullRequests[0].Subtitle)
+	assert.Equal(t, 1, digest.CompletedYesterdayCount())
+	assert.Equal(t, "General Task", digest.CompletedYesterday[0].Source)
+}
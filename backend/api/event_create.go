@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,17 @@ func (api *API) EventCreate(c *gin.Context) {
 		return
 	}
 
+	if len(eventCreateObject.Recurrence) > 0 {
+		if sourceID != external.TASK_SOURCE_ID_GCAL {
+			c.JSON(400, gin.H{"detail": "recurring events are not supported for this source"})
+			return
+		}
+		if !isValidEventRecurrence(eventCreateObject.Recurrence) {
+			c.JSON(400, gin.H{"detail": "invalid recurrence"})
+			return
+		}
+	}
+
 	userID := getUserIDFromContext(c)
 
 	linkedSourceID := ""
@@ -85,11 +98,17 @@ func (api *API) EventCreate(c *gin.Context) {
 		LinkedPullRequestID: eventCreateObject.LinkedPullRequestID,
 		LinkedSourceID:      linkedSourceID,
 	}
+	if len(eventCreateObject.Recurrence) > 0 {
+		// stands in for the first instance, which google identifies by the series ID and its start
+		event.IDExternal = externalEventID.Hex() + "_" + external.FormatICalDateTime(*eventCreateObject.DatetimeStart)
+		event.RecurringEventID = externalEventID.Hex()
+		event.OriginalStartTime = event.DatetimeStart
+	}
 
 	insertedEvent, err := database.UpdateOrCreateCalendarEvent(
 		api.DB,
 		userID,
-		externalEventID.Hex(),
+		event.IDExternal,
 		sourceID,
 		event,
 		nil,
@@ -102,3 +121,17 @@ func (api *API) EventCreate(c *gin.Context) {
 	}
 	c.JSON(201, gin.H{"id": insertedEvent.ID.Hex()})
 }
+
+func isValidEventRecurrence(recurrence []string) bool {
+	for _, line := range recurrence {
+		if strings.HasPrefix(line, external.ICalPropertyRecurrence+":") {
+			_, err := external.ParseRRule(line)
+			if err != nil {
+				return false
+			}
+		} else if !strings.HasPrefix(line, "EXDATE") && !strings.HasPrefix(line, "RDATE") {
+			return false
+		}
+	}
+	return true
+}
@@ -172,6 +172,21 @@ func TestEventCreate(t *testing.T) {
 		eventCreateObject.LinkedPullRequestID = prID
 		makeCreateRequest(t, &eventCreateObject, http.StatusBadRequest, fmt.Sprintf(`{"detail":"linked PR not found: %s"}`, prID.Hex()), url, authToken, api)
 	})
+	t.Run("SuccessRecurring", func(t *testing.T) {
+		eventCreateObj := defaultEventCreateObject
+		eventCreateObj.TimeZone = "America/Los_Angeles"
+		eventCreateObj.Recurrence = []string{"RRULE:FREQ=WEEKLY;COUNT=4", "EXDATE;TZID=America/Los_Angeles:20220828T123000"}
+		eventID := makeCreateRequest(t, &eventCreateObj, http.StatusCreated, "", url, authToken, api)
+		dbEvent, err := database.GetCalendarEvent(api.DB, eventID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, dbEvent.RecurringEventID+"_20220821T193000Z", dbEvent.IDExternal)
+		assert.Equal(t, dbEvent.DatetimeStart, dbEvent.OriginalStartTime)
+	})
+	t.Run("InvalidRecurrence", func(t *testing.T) {
+		eventCreateObj := defaultEventCreateObject
+		eventCreateObj.Recurrence = []string{"RRULE:FREQ=HOURLY"}
+		makeCreateRequest(t, &eventCreateObj, http.StatusBadRequest, `{"detail":"invalid recurrence"}`, url, authToken, api)
+	})
 	t.Run("UnsupportedService", func(t *testing.T) {
 		body, err := json.Marshal(defaultEventCreateObject)
 		assert.NoError(t, err)
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,6 +20,11 @@ func (api *API) EventDelete(c *gin.Context) {
 		Handle404(c)
 		return
 	}
+	scope := c.Query("scope")
+	if !external.IsValidEventScope(scope) {
+		c.JSON(400, gin.H{"detail": "invalid scope"})
+		return
+	}
 	userID := getUserIDFromContext(c)
 
 	event, err := database.GetCalendarEvent(api.DB, eventID, userID)
@@ -26,6 +32,10 @@ func (api *API) EventDelete(c *gin.Context) {
 		c.JSON(404, gin.H{"detail": "event not found", "eventID": eventID})
 		return
 	}
+	if !external.IsSingleEventScope(scope) && event.SourceID != external.TASK_SOURCE_ID_GCAL {
+		c.JSON(400, gin.H{"detail": "scope is not supported for this source"})
+		return
+	}
 
 	taskSourceResult, err := api.ExternalConfig.GetSourceResult(event.SourceID)
 	if err != nil {
@@ -34,7 +44,7 @@ func (api *API) EventDelete(c *gin.Context) {
 		return
 	}
 
-	err = taskSourceResult.Source.DeleteEvent(api.DB, userID, event.SourceAccountID, event.IDExternal, event.CalendarID)
+	err = taskSourceResult.Source.DeleteEvent(api.DB, userID, event.SourceAccountID, event.IDExternal, event.CalendarID, scope)
 	if err != nil {
 		api.Logger.Error().Err(err).Msg("failed to update external task source")
 		Handle500(c)
@@ -42,6 +52,26 @@ func (api *API) EventDelete(c *gin.Context) {
 	}
 
 	eventCollection := database.GetCalendarEventCollection(api.DB)
+	if !external.IsSingleEventScope(scope) && event.RecurringEventID != "" {
+		// the rest of the series is removed from the DB too, and the next full sync restores anything removed by mistake
+		seriesFilter := []bson.M{
+			{"user_id": userID},
+			{"source_id": event.SourceID},
+			{"source_account_id": event.SourceAccountID},
+			{"recurring_event_id": event.RecurringEventID},
+		}
+		if scope == external.EventScopeThisAndFollowing {
+			seriesFilter = append(seriesFilter, bson.M{"original_start_time": bson.M{"$gte": event.OriginalStartTime}})
+		}
+		_, err = eventCollection.DeleteMany(context.Background(), bson.M{"$and": seriesFilter})
+		if err != nil {
+			api.Logger.Error().Err(err).Msg("failed to update internal DB")
+			Handle500(c)
+			return
+		}
+		c.JSON(200, gin.H{})
+		return
+	}
 	res, err := eventCollection.DeleteOne(
 		context.Background(),
 		bson.M{"$and": []bson.M{
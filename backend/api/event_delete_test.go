@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jjPlusPlus/task-manager/backend/testutils"
 	"go.mongodb.org/mongo-driver/bson"
@@ -47,7 +48,8 @@ func TestEventDelete(t *testing.T) {
 	calendarTaskID2 := insertResult2.InsertedID.(primitive.ObjectID)
 	calendarTaskIDHex2 := calendarTaskID2.Hex()
 
-	calendarDeleteServer := testutils.GetMockAPIServer(t, 200, "[]")
+	// recurring events are fetched before they're deleted, and this one has no series
+	calendarDeleteServer := testutils.GetMockAPIServer(t, 200, "{}")
 	api, dbCleanup := GetAPIWithDBCleanup()
 	defer dbCleanup()
 	api.ExternalConfig.GoogleOverrideURLs.CalendarDeleteURL = &calendarDeleteServer.URL
@@ -98,4 +100,35 @@ func TestEventDelete(t *testing.T) {
 		count, _ := eventCollection.CountDocuments(context.Background(), bson.M{"_id": calendarTaskID2})
 		assert.Equal(t, int64(0), count)
 	})
+
+	t.Run("InvalidScope", func(t *testing.T) {
+		ServeRequest(t, authToken, "DELETE", "/events/delete/"+calendarTaskIDHex+"/?scope=some", nil, http.StatusBadRequest, api)
+	})
+
+	t.Run("SuccessThisAndFollowing", func(t *testing.T) {
+		seriesStart := time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)
+		instanceIDs := []primitive.ObjectID{}
+		for week := 0; week < 3; week++ {
+			originalStart := primitive.NewDateTimeFromTime(seriesStart.AddDate(0, 0, 7*week))
+			insertResult, err := eventCollection.InsertOne(context.Background(), database.CalendarEvent{
+				UserID:            userID,
+				SourceAccountID:   "account_id",
+				CalendarID:        "cal_1",
+				IDExternal:        "series_" + external.FormatICalDateTime(originalStart.Time()),
+				SourceID:          external.TASK_SOURCE_ID_GCAL,
+				RecurringEventID:  "series",
+				OriginalStartTime: originalStart,
+				DatetimeStart:     originalStart,
+			})
+			assert.NoError(t, err)
+			instanceIDs = append(instanceIDs, insertResult.InsertedID.(primitive.ObjectID))
+		}
+
+		ServeRequest(t, authToken, "DELETE", "/events/delete/"+instanceIDs[1].Hex()+"/?scope=this_and_following", nil, http.StatusOK, api)
+
+		count, _ := eventCollection.CountDocuments(context.Background(), bson.M{"_id": instanceIDs[0]})
+		assert.Equal(t, int64(1), count)
+		count, _ = eventCollection.CountDocuments(context.Background(), bson.M{"_id": bson.M{"$in": instanceIDs[1:]}})
+		assert.Equal(t, int64(0), count)
+	})
 }
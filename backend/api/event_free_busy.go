@@ -0,0 +1,177 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// google limits free/busy queries to 50 calendars, which includes the user's own
+	EVENT_FREE_BUSY_MAX_ATTENDEES   = 49
+	EVENT_FREE_BUSY_MAX_SUGGESTIONS = 10
+)
+
+type EventFreeBusyParams struct {
+	AccountID       string     `json:"account_id" binding:"required"`
+	AttendeeEmails  []string   `json:"attendee_emails" binding:"required"`
+	DatetimeStart   *time.Time `json:"datetime_start" binding:"required"`
+	DatetimeEnd     *time.Time `json:"datetime_end" binding:"required"`
+	DurationMinutes int        `json:"duration_minutes" binding:"required"`
+}
+
+type EventFreeBusyAttendee struct {
+	Email string `json:"email"`
+	// false when the attendee's calendar isn't shared with the account, in which case their busy times are unknown
+	CanView bool                         `json:"can_view"`
+	Busy    []external.CalendarBusyRange `json:"busy"`
+}
+
+type EventFreeBusySuggestion struct {
+	DatetimeStart string `json:"datetime_start"`
+	DatetimeEnd   string `json:"datetime_end"`
+}
+
+type EventFreeBusyResult struct {
+	Attendees   []EventFreeBusyAttendee   `json:"attendees"`
+	Suggestions []EventFreeBusySuggestion `json:"suggestions"`
+}
+
+// EventFreeBusy looks up when the attendees are busy in google calendar, and suggests times within the user's
+// working hours when the user and every attendee whose calendar could be read are free
+func (api *API) EventFreeBusy(c *gin.Context) {
+	var params EventFreeBusyParams
+	err := c.BindJSON(&params)
+	if err != nil || len(params.AttendeeEmails) == 0 {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	if len(params.AttendeeEmails) > EVENT_FREE_BUSY_MAX_ATTENDEES {
+		c.JSON(400, gin.H{"detail": fmt.Sprintf("at most %d attendees are supported", EVENT_FREE_BUSY_MAX_ATTENDEES)})
+		return
+	}
+	if params.DurationMinutes <= 0 {
+		c.JSON(400, gin.H{"detail": "'duration_minutes' must be positive"})
+		return
+	}
+	timezoneOffset, err := GetTimezoneOffsetFromHeader(c)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	timeNow := api.GetCurrentTime().In(api.getPlannerLocation(userID, timezoneOffset))
+	datetimeStart := params.DatetimeStart.In(timeNow.Location())
+	if datetimeStart.Before(timeNow) {
+		datetimeStart = timeNow
+	}
+	datetimeEnd := params.DatetimeEnd.In(timeNow.Location())
+	if !datetimeEnd.After(datetimeStart) || datetimeEnd.After(datetimeStart.AddDate(0, 0, PLANNER_MAX_DAYS)) {
+		c.JSON(400, gin.H{"detail": fmt.Sprintf("'datetime_end' must be in the future, after 'datetime_start' and within %d days", PLANNER_MAX_DAYS)})
+		return
+	}
+
+	tokens, err := database.GetExternalTokens(api.DB, userID, external.TASK_SERVICE_ID_GOOGLE)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load external tokens")
+		Handle500(c)
+		return
+	}
+	isLinkedAccount := false
+	for _, token := range *tokens {
+		if token.AccountID == params.AccountID {
+			isLinkedAccount = true
+		}
+	}
+	if !isLinkedAccount {
+		c.JSON(404, gin.H{"detail": "account not found"})
+		return
+	}
+	taskSourceResult, err := api.ExternalConfig.GetSourceResult(external.TASK_SOURCE_ID_GCAL)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load google calendar source")
+		Handle500(c)
+		return
+	}
+	googleCalendar := taskSourceResult.Source.(external.GoogleCalendarSource)
+
+	attendeeEmails := getFreeBusyAttendeeEmails(params.AttendeeEmails, params.AccountID)
+	calendarIDToBusy, _, err := googleCalendar.GetFreeBusy(api.DB, userID, params.AccountID, append([]string{params.AccountID}, attendeeEmails...), datetimeStart, datetimeEnd)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to query free/busy")
+		Handle500(c)
+		return
+	}
+
+	// the user's events in their other accounts matter too, which the stored events cover
+	busyRanges, err := api.getPlannerBusyRanges(userID, datetimeStart, datetimeEnd)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load events")
+		Handle500(c)
+		return
+	}
+	for _, busy := range calendarIDToBusy[params.AccountID] {
+		busyRanges = append(busyRanges, plannerRange{start: busy.DatetimeStart.In(timeNow.Location()), end: busy.DatetimeEnd.In(timeNow.Location())})
+	}
+	result := EventFreeBusyResult{Attendees: []EventFreeBusyAttendee{}, Suggestions: []EventFreeBusySuggestion{}}
+	for _, email := range attendeeEmails {
+		attendeeBusy, canView := calendarIDToBusy[email]
+		if attendeeBusy == nil {
+			attendeeBusy = []external.CalendarBusyRange{}
+		}
+		result.Attendees = append(result.Attendees, EventFreeBusyAttendee{Email: email, CanView: canView, Busy: attendeeBusy})
+		for _, busy := range attendeeBusy {
+			busyRanges = append(busyRanges, plannerRange{start: busy.DatetimeStart.In(timeNow.Location()), end: busy.DatetimeEnd.In(timeNow.Location())})
+		}
+	}
+
+	var userSettings []database.UserSetting
+	err = database.FindWithCollection(database.GetUserSettingsCollection(api.DB), userID, nil, &userSettings, nil)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load settings")
+		Handle500(c)
+		return
+	}
+	workingRanges := getPlannerWorkingRanges(datetimeStart, datetimeEnd, getPlannerWorkingHours(userSettings))
+	suggestions := getFreeBusySuggestions(getPlannerFreeRanges(workingRanges, busyRanges), time.Duration(params.DurationMinutes)*time.Minute)
+	for _, suggestion := range suggestions {
+		result.Suggestions = append(result.Suggestions, EventFreeBusySuggestion{
+			DatetimeStart: suggestion.start.In(timeNow.Location()).Format(time.RFC3339),
+			DatetimeEnd:   suggestion.end.In(timeNow.Location()).Format(time.RFC3339),
+		})
+	}
+	c.JSON(200, result)
+}
+
+// getFreeBusyAttendeeEmails removes duplicates and the user's own account, which is always looked up
+func getFreeBusyAttendeeEmails(emails []string, accountID string) []string {
+	seenEmails := map[string]bool{strings.ToLower(accountID): true}
+	attendeeEmails := []string{}
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		if email == "" || seenEmails[strings.ToLower(email)] {
+			continue
+		}
+		seenEmails[strings.ToLower(email)] = true
+		attendeeEmails = append(attendeeEmails, email)
+	}
+	return attendeeEmails
+}
+
+// getFreeBusySuggestions returns the earliest meeting time in each free range long enough for the meeting
+func getFreeBusySuggestions(freeRanges []plannerRange, duration time.Duration) []plannerRange {
+	suggestions := []plannerRange{}
+	for _, freeRange := range freeRanges {
+		if len(suggestions) == EVENT_FREE_BUSY_MAX_SUGGESTIONS {
+			break
+		}
+		if freeRange.end.Sub(freeRange.start) >= duration {
+			suggestions = append(suggestions, plannerRange{start: freeRange.start, end: freeRange.start.Add(duration)})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestGetFreeBusyAttendeeEmails(t *testing.T) {
+	assert.Equal(t,
+		[]string{"teammate@example.com", "designer@example.com"},
+		getFreeBusyAttendeeEmails([]string{"teammate@example.com", " designer@example.com", "Teammate@example.com", "User@example.com", ""}, "user@example.com"),
+	)
+}
+
+func TestGetFreeBusySuggestions(t *testing.T) {
+	day := time.Date(2023, time.March, 6, 0, 0, 0, 0, time.UTC)
+	at := func(hour int, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	t.Run("SkipsShortRanges", func(t *testing.T) {
+		freeRanges := []plannerRange{{start: at(9, 0), end: at(9, 30)}, {start: at(10, 0), end: at(12, 0)}}
+		assert.Equal(t, []plannerRange{{start: at(10, 0), end: at(11, 0)}}, getFreeBusySuggestions(freeRanges, time.Hour))
+	})
+	t.Run("MaxSuggestions", func(t *testing.T) {
+		freeRanges := []plannerRange{}
+		for days := 0; days < EVENT_FREE_BUSY_MAX_SUGGESTIONS+2; days++ {
+			freeRanges = append(freeRanges, plannerRange{start: at(9, 0).AddDate(0, 0, days), end: at(17, 0).AddDate(0, 0, days)})
+		}
+		assert.Equal(t, EVENT_FREE_BUSY_MAX_SUGGESTIONS, len(getFreeBusySuggestions(freeRanges, time.Hour)))
+	})
+}
+
+func TestEventFreeBusy(t *testing.T) {
+	authToken := login("test_event_free_busy@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	accountID := getGoogleTokenFromAuthToken(t, api.DB, authToken).AccountID
+	// Monday at 10am in UTC-7
+	testTime := time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)
+	api.OverrideTime = &testTime
+
+	freeBusyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&calendar.FreeBusyResponse{Calendars: map[string]calendar.FreeBusyCalendar{
+			accountID:              {Busy: []*calendar.TimePeriod{{Start: "2023-03-06T17:00:00Z", End: "2023-03-06T18:00:00Z"}}},
+			"teammate@example.com": {Busy: []*calendar.TimePeriod{{Start: "2023-03-06T18:00:00Z", End: "2023-03-06T19:30:00Z"}}},
+			"outsider@example.com": {Errors: []*calendar.Error{{Domain: "global", Reason: "notFound"}}},
+		}})
+	}))
+	defer freeBusyServer.Close()
+	api.ExternalConfig.GoogleOverrideURLs.CalendarFetchURL = &freeBusyServer.URL
+	router := GetRouter(api)
+
+	freeBusy := func(body string, expectedResponseCode int) EventFreeBusyResult {
+		request, _ := http.NewRequest("POST", "/events/free_busy/", bytes.NewBuffer([]byte(body)))
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		request.Header.Set("Timezone-Offset", "420")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, expectedResponseCode, recorder.Code)
+		responseBody, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		var result EventFreeBusyResult
+		json.Unmarshal(responseBody, &result)
+		return result
+	}
+	getBody := func(accountID string, durationMinutes int) string {
+		return fmt.Sprintf(`{
+			"account_id": "%s",
+			"attendee_emails": ["teammate@example.com", "outsider@example.com"],
+			"datetime_start": "2023-03-06T10:00:00-07:00",
+			"datetime_end": "2023-03-06T17:00:00-07:00",
+			"duration_minutes": %d
+		}`, accountID, durationMinutes)
+	}
+
+	UnauthorizedTest(t, "POST", "/events/free_busy/", nil)
+	t.Run("MissingAttendees", func(t *testing.T) {
+		freeBusy(fmt.Sprintf(`{"account_id": "%s", "attendee_emails": [], "datetime_start": "2023-03-06T10:00:00-07:00", "datetime_end": "2023-03-06T17:00:00-07:00", "duration_minutes": 60}`, accountID), http.StatusBadRequest)
+	})
+	t.Run("InvalidDuration", func(t *testing.T) {
+		freeBusy(getBody(accountID, -30), http.StatusBadRequest)
+	})
+	t.Run("UnknownAccount", func(t *testing.T) {
+		freeBusy(getBody("unknown@example.com", 60), http.StatusNotFound)
+	})
+	t.Run("Success", func(t *testing.T) {
+		result := freeBusy(getBody(accountID, 60), http.StatusOK)
+		assert.Equal(t, 2, len(result.Attendees))
+		assert.Equal(t, "teammate@example.com", result.Attendees[0].Email)
+		assert.True(t, result.Attendees[0].CanView)
+		assert.Equal(t, 1, len(result.Attendees[0].Busy))
+		assert.Equal(t, "outsider@example.com", result.Attendees[1].Email)
+		assert.False(t, result.Attendees[1].CanView)
+		// the user is busy until 11am and the teammate until 12:30pm
+		assert.Equal(t, []EventFreeBusySuggestion{{DatetimeStart: "2023-03-06T12:30:00-07:00", DatetimeEnd: "2023-03-06T13:30:00-07:00"}}, result.Suggestions)
+	})
+}
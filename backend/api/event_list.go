@@ -23,25 +23,28 @@ type EventListParams struct {
 }
 
 type EventResult struct {
-	ID                  primitive.ObjectID   `json:"id"`
-	AccountID           string               `json:"account_id"`
-	CalendarID          string               `json:"calendar_id"`
-	ColorID             string               `json:"color_id"`
-	Deeplink            string               `json:"deeplink"`
-	Title               string               `json:"title"`
-	Body                string               `json:"body"`
-	Location            string               `json:"location"`
-	CanModify           bool                 `json:"can_modify"`
-	ConferenceCall      utils.ConferenceCall `json:"conference_call"`
-	DatetimeEnd         primitive.DateTime   `json:"datetime_end,omitempty"`
-	DatetimeStart       primitive.DateTime   `json:"datetime_start,omitempty"`
-	LinkedTaskID        string               `json:"linked_task_id"`
-	LinkedViewID        string               `json:"linked_view_id"`
-	LinkedPullRequestID string               `json:"linked_pull_request_id"`
-	LinkedNoteID        string               `json:"linked_note_id,omitempty"`
-	Logo                string               `json:"logo"`
-	ColorBackground     string               `json:"color_background,omitempty"`
-	ColorForeground     string               `json:"color_foreground,omitempty"`
+	ID                  primitive.ObjectID               `json:"id"`
+	AccountID           string                           `json:"account_id"`
+	CalendarID          string                           `json:"calendar_id"`
+	ColorID             string                           `json:"color_id"`
+	Deeplink            string                           `json:"deeplink"`
+	Title               string                           `json:"title"`
+	Body                string                           `json:"body"`
+	Location            string                           `json:"location"`
+	CanModify           bool                             `json:"can_modify"`
+	ConferenceCall      utils.ConferenceCall             `json:"conference_call"`
+	DatetimeEnd         primitive.DateTime               `json:"datetime_end,omitempty"`
+	DatetimeStart       primitive.DateTime               `json:"datetime_start,omitempty"`
+	LinkedTaskID        string                           `json:"linked_task_id"`
+	LinkedViewID        string                           `json:"linked_view_id"`
+	LinkedPullRequestID string                           `json:"linked_pull_request_id"`
+	LinkedNoteID        string                           `json:"linked_note_id,omitempty"`
+	Logo                string                           `json:"logo"`
+	ColorBackground     string                           `json:"color_background,omitempty"`
+	ColorForeground     string                           `json:"color_foreground,omitempty"`
+	RecurringEventID    string                           `json:"recurring_event_id,omitempty"`
+	OriginalStartTime   primitive.DateTime               `json:"original_start_time,omitempty"`
+	Attendees           []database.CalendarEventAttendee `json:"attendees"`
 }
 
 func (api *API) EventsList(c *gin.Context) {
@@ -162,6 +165,10 @@ func (api *API) calendarEventToResult(event *database.CalendarEvent, userID prim
 	if event.LinkedViewID != primitive.NilObjectID {
 		linkedViewID = event.LinkedViewID.Hex()
 	}
+	attendees := event.Attendees
+	if attendees == nil {
+		attendees = []database.CalendarEventAttendee{}
+	}
 	var linkedPRID string
 	if event.LinkedPullRequestID != primitive.NilObjectID {
 		linkedPRID = event.LinkedPullRequestID.Hex()
@@ -197,6 +204,9 @@ func (api *API) calendarEventToResult(event *database.CalendarEvent, userID prim
 		LinkedNoteID:        linkedNoteID,
 		ColorBackground:     event.ColorBackground,
 		ColorForeground:     event.ColorForeground,
+		RecurringEventID:    event.RecurringEventID,
+		OriginalStartTime:   event.OriginalStartTime,
+		Attendees:           attendees,
 	}, nil
 }
 
@@ -25,11 +25,15 @@ func (api *API) EventModify(c *gin.Context) {
 	}
 
 	// check that modifyParams isn't empty
-	emptyObj := external.EventModifyObject{AccountID: modifyParams.AccountID}
+	emptyObj := external.EventModifyObject{AccountID: modifyParams.AccountID, Scope: modifyParams.Scope}
 	if modifyParams == emptyObj {
 		c.JSON(400, gin.H{"detail": "parameter missing"})
 		return
 	}
+	if !external.IsValidEventScope(modifyParams.Scope) {
+		c.JSON(400, gin.H{"detail": "invalid scope"})
+		return
+	}
 
 	userID := getUserIDFromContext(c)
 
@@ -38,6 +42,10 @@ func (api *API) EventModify(c *gin.Context) {
 		c.JSON(404, gin.H{"detail": "event not found", "eventID": eventID})
 		return
 	}
+	if !external.IsSingleEventScope(modifyParams.Scope) && event.SourceID != external.TASK_SOURCE_ID_GCAL {
+		c.JSON(400, gin.H{"detail": "scope is not supported for this source"})
+		return
+	}
 
 	eventSourceResult, err := api.ExternalConfig.GetSourceResult(event.SourceID)
 	if err != nil {
@@ -177,6 +177,14 @@ func TestEventModify(t *testing.T) {
 		body := bytes.NewBuffer([]byte(`{"account_id": "duck@duck.com"}`))
 		ServeRequest(t, authToken, "PATCH", validUrl, body, http.StatusBadRequest, nil)
 	})
+	t.Run("MissingModifyParamsWithScope", func(t *testing.T) {
+		body := bytes.NewBuffer([]byte(`{"account_id": "duck@duck.com", "scope": "all"}`))
+		ServeRequest(t, authToken, "PATCH", validUrl, body, http.StatusBadRequest, nil)
+	})
+	t.Run("InvalidScope", func(t *testing.T) {
+		body := bytes.NewBuffer([]byte(`{"account_id": "duck@duck.com", "summary": "duck", "scope": "some"}`))
+		ServeRequest(t, authToken, "PATCH", validUrl, body, http.StatusBadRequest, nil)
+	})
 	t.Run("InvalidEventID", func(t *testing.T) {
 		body := bytes.NewBuffer([]byte(`{"account_id": "duck@duck.com", "summary": "duck"}`))
 		ServeRequest(t, authToken, "PATCH", "/events/modify/bad_id/", body, http.StatusBadRequest, nil)
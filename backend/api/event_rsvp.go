@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventRSVPParams struct {
+	Response string `json:"response" binding:"required"`
+}
+
+// EventRSVP accepts, declines or tentatively accepts an invitation to an event
+func (api *API) EventRSVP(c *gin.Context) {
+	eventIDHex := c.Param("event_id")
+	eventID, err := primitive.ObjectIDFromHex(eventIDHex)
+	if err != nil {
+		// This means the event ID is improperly formatted
+		c.JSON(400, gin.H{"detail": "event ID missing or malformed"})
+		return
+	}
+	var params EventRSVPParams
+	err = c.BindJSON(&params)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "parameter missing or malformed"})
+		return
+	}
+	if params.Response != constants.EventResponseAccepted && params.Response != constants.EventResponseDeclined && params.Response != constants.EventResponseTentative {
+		c.JSON(400, gin.H{"detail": "'response' must be one of accepted, declined or tentative"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+
+	event, err := database.GetCalendarEvent(api.DB, eventID, userID)
+	if err != nil {
+		c.JSON(404, gin.H{"detail": "event not found", "eventID": eventID})
+		return
+	}
+	selfIndex := -1
+	for index, attendee := range event.Attendees {
+		if attendee.IsSelf {
+			selfIndex = index
+		}
+	}
+	if selfIndex == -1 {
+		c.JSON(400, gin.H{"detail": "not an attendee of this event"})
+		return
+	}
+
+	eventSourceResult, err := api.ExternalConfig.GetSourceResult(event.SourceID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load external event source")
+		Handle500(c)
+		return
+	}
+	err = eventSourceResult.Source.RespondToEvent(api.DB, userID, event.SourceAccountID, event.IDExternal, event.CalendarID, params.Response)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to respond to event in external source")
+		Handle500(c)
+		return
+	}
+
+	eventCollection := database.GetCalendarEventCollection(api.DB)
+	if params.Response == constants.EventResponseDeclined {
+		// declined events aren't shown, the same as when they're synced
+		_, err = eventCollection.DeleteOne(context.Background(), bson.M{"$and": []bson.M{
+			{"_id": eventID},
+			{"user_id": userID},
+		}})
+	} else {
+		event.Attendees[selfIndex].ResponseStatus = params.Response
+		_, err = eventCollection.UpdateOne(
+			context.Background(),
+			bson.M{"$and": []bson.M{
+				{"_id": eventID},
+				{"user_id": userID},
+			}},
+			bson.M{"$set": bson.M{"attendees": event.Attendees}},
+		)
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to update internal DB")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, gin.H{})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/testutils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEventRSVP(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	authToken := login("test_event_rsvp@resonant-kelpie-404a42.netlify.app", "")
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	// the invitation is served for the lookup, and the patch accepts any body
+	calendarModifyServer := testutils.GetMockAPIServer(t, 200, `{"id": "invitation", "attendees": [{"email": "organizer@example.com", "organizer": true}, {"email": "user@example.com", "self": true}]}`)
+	api.ExternalConfig.GoogleOverrideURLs.CalendarModifyURL = &calendarModifyServer.URL
+
+	eventCollection := database.GetCalendarEventCollection(api.DB)
+	insertEvent := func(idExternal string, attendees []database.CalendarEventAttendee) primitive.ObjectID {
+		insertResult, err := eventCollection.InsertOne(context.Background(), database.CalendarEvent{
+			UserID:          userID,
+			SourceAccountID: "user@example.com",
+			IDExternal:      idExternal,
+			SourceID:        external.TASK_SOURCE_ID_GCAL,
+			Attendees:       attendees,
+		})
+		assert.NoError(t, err)
+		return insertResult.InsertedID.(primitive.ObjectID)
+	}
+	invitationAttendees := []database.CalendarEventAttendee{
+		{Email: "organizer@example.com", ResponseStatus: constants.EventResponseAccepted, IsOrganizer: true},
+		{Email: "user@example.com", ResponseStatus: constants.EventResponseNeedsAction, IsSelf: true},
+	}
+	invitationID := insertEvent("invitation", invitationAttendees)
+	ownEventID := insertEvent("own_event", []database.CalendarEventAttendee{})
+	rsvp := func(eventID primitive.ObjectID, response string, expectedResponseCode int) {
+		body := bytes.NewBuffer([]byte(fmt.Sprintf(`{"response": "%s"}`, response)))
+		ServeRequest(t, authToken, "POST", "/events/rsvp/"+eventID.Hex()+"/", body, expectedResponseCode, api)
+	}
+
+	UnauthorizedTest(t, "POST", "/events/rsvp/"+invitationID.Hex()+"/", nil)
+	t.Run("InvalidEventID", func(t *testing.T) {
+		body := bytes.NewBuffer([]byte(`{"response": "accepted"}`))
+		ServeRequest(t, authToken, "POST", "/events/rsvp/bad_id/", body, http.StatusBadRequest, api)
+	})
+	t.Run("InvalidResponse", func(t *testing.T) {
+		rsvp(invitationID, constants.EventResponseNeedsAction, http.StatusBadRequest)
+	})
+	t.Run("EventNotFound", func(t *testing.T) {
+		rsvp(primitive.NewObjectID(), constants.EventResponseAccepted, http.StatusNotFound)
+	})
+	t.Run("NotAttendee", func(t *testing.T) {
+		rsvp(ownEventID, constants.EventResponseAccepted, http.StatusBadRequest)
+	})
+	t.Run("Tentative", func(t *testing.T) {
+		rsvp(invitationID, constants.EventResponseTentative, http.StatusOK)
+		event, err := database.GetCalendarEvent(api.DB, invitationID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.EventResponseAccepted, event.Attendees[0].ResponseStatus)
+		assert.Equal(t, constants.EventResponseTentative, event.Attendees[1].ResponseStatus)
+	})
+	t.Run("Declined", func(t *testing.T) {
+		rsvp(invitationID, constants.EventResponseDeclined, http.StatusOK)
+		count, err := eventCollection.CountDocuments(context.Background(), bson.M{"_id": invitationID})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
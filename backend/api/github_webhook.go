@@ -0,0 +1,159 @@
+package api
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v45/github"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const GithubEventPullRequest = "pull_request"
+const GithubEventPullRequestReview = "pull_request_review"
+const GithubEventIssueComment = "issue_comment"
+const GithubEventCheckRun = "check_run"
+const GithubEventPing = "ping"
+
+type githubWebhookAccount struct {
+	UserID    primitive.ObjectID
+	AccountID string
+}
+
+// the pull requests touched by a webhook event, and the Github users known to be involved in them
+type githubWebhookTarget struct {
+	Repository *github.Repository
+	Numbers    []int
+	AccountIDs []string
+}
+
+func (api *API) GithubWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to read github webhook request body")
+		c.JSON(400, gin.H{"detail": "unable to read request body"})
+		return
+	}
+
+	// verification for security
+	githubWebhookSecret := config.GetConfigValue("GITHUB_WEBHOOK_SECRET")
+	err = github.ValidateSignature(c.Request.Header.Get("X-Hub-Signature-256"), body, []byte(githubWebhookSecret))
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "signature invalid"})
+		return
+	}
+
+	eventType := c.Request.Header.Get("X-GitHub-Event")
+	if eventType == GithubEventPing {
+		c.JSON(200, gin.H{})
+		return
+	}
+	if eventType != GithubEventPullRequest && eventType != GithubEventPullRequestReview && eventType != GithubEventIssueComment && eventType != GithubEventCheckRun {
+		c.JSON(400, gin.H{"detail": "unrecognized github event type"})
+		return
+	}
+	event, err := github.ParseWebHook(eventType, body)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to process github webhook payload")
+		c.JSON(400, gin.H{"detail": "unable to process github webhook payload"})
+		return
+	}
+
+	target := getGithubWebhookTarget(event)
+	if target.Repository == nil {
+		c.JSON(400, gin.H{"detail": "unable to process github webhook payload"})
+		return
+	}
+	for _, number := range target.Numbers {
+		err = api.refreshGithubPullRequest(target.Repository, number, target.AccountIDs)
+		if err != nil {
+			c.JSON(400, gin.H{"detail": "unable to process github webhook"})
+			return
+		}
+	}
+	c.JSON(200, gin.H{})
+}
+
+func getGithubWebhookTarget(event interface{}) githubWebhookTarget {
+	target := githubWebhookTarget{}
+	switch event := event.(type) {
+	case *github.PullRequestEvent:
+		target.Repository = event.GetRepo()
+		target.Numbers = []int{event.GetPullRequest().GetNumber()}
+		target.AccountIDs = getGithubPullRequestAccountIDs(event.GetPullRequest())
+		if event.RequestedReviewer != nil {
+			target.AccountIDs = append(target.AccountIDs, fmt.Sprint(event.RequestedReviewer.GetID()))
+		}
+	case *github.PullRequestReviewEvent:
+		target.Repository = event.GetRepo()
+		target.Numbers = []int{event.GetPullRequest().GetNumber()}
+		target.AccountIDs = append(getGithubPullRequestAccountIDs(event.GetPullRequest()), fmt.Sprint(event.GetReview().GetUser().GetID()))
+	case *github.IssueCommentEvent:
+		// issue comments are also sent for regular issues, which we don't track
+		if !event.GetIssue().IsPullRequest() {
+			return githubWebhookTarget{Repository: event.GetRepo()}
+		}
+		target.Repository = event.GetRepo()
+		target.Numbers = []int{event.GetIssue().GetNumber()}
+		target.AccountIDs = []string{fmt.Sprint(event.GetIssue().GetUser().GetID())}
+	case *github.CheckRunEvent:
+		// check runs don't say who is involved, so only users already tracking the PR are refreshed
+		target.Repository = event.GetRepo()
+		for _, pullRequest := range event.GetCheckRun().PullRequests {
+			target.Numbers = append(target.Numbers, pullRequest.GetNumber())
+		}
+	}
+	return target
+}
+
+func getGithubPullRequestAccountIDs(pullRequest *github.PullRequest) []string {
+	accountIDs := []string{fmt.Sprint(pullRequest.GetUser().GetID())}
+	for _, reviewer := range pullRequest.RequestedReviewers {
+		accountIDs = append(accountIDs, fmt.Sprint(reviewer.GetID()))
+	}
+	return accountIDs
+}
+
+// recomputes the required action for every user who has the PR or is involved in it
+// users only requested via a team are picked up by the next full refresh
+func (api *API) refreshGithubPullRequest(repository *github.Repository, number int, accountIDs []string) error {
+	accounts := []githubWebhookAccount{}
+	addAccount := func(account githubWebhookAccount) {
+		for _, existingAccount := range accounts {
+			if existingAccount == account {
+				return
+			}
+		}
+		accounts = append(accounts, account)
+	}
+
+	pullRequests, err := database.GetPullRequestsByRepositoryWithoutUser(api.DB, fmt.Sprint(repository.GetID()), number)
+	if err != nil {
+		return err
+	}
+	for _, pullRequest := range *pullRequests {
+		addAccount(githubWebhookAccount{UserID: pullRequest.UserID, AccountID: pullRequest.SourceAccountID})
+	}
+	if len(accountIDs) > 0 {
+		tokens, err := database.GetExternalTokensByAccountIDs(api.DB, accountIDs, external.TASK_SERVICE_ID_GITHUB)
+		if err != nil {
+			return err
+		}
+		for _, token := range *tokens {
+			addAccount(githubWebhookAccount{UserID: token.UserID, AccountID: token.AccountID})
+		}
+	}
+
+	githubPR := external.GithubPRSource{Github: external.GithubService{Config: api.ExternalConfig.Github}}
+	for _, account := range accounts {
+		_, err := githubPR.RefreshPullRequest(api.DB, account.UserID, account.AccountID, repository, number)
+		if err != nil {
+			// one user's expired token shouldn't stop the others from being updated
+			api.Logger.Error().Err(err).Msg("failed to refresh github pull request from webhook")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func signGithubWebhookBody(body string) string {
+	hash := hmac.New(sha256.New, []byte(config.GetConfigValue("GITHUB_WEBHOOK_SECRET")))
+	hash.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func TestGithubWebhook(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	router := GetRouter(api)
+
+	serveGithubWebhook := func(eventType string, body string, signature string) (int, string) {
+		request, _ := http.NewRequest("POST", "/github/webhook/", bytes.NewBuffer([]byte(body)))
+		request.Header.Add("X-GitHub-Event", eventType)
+		request.Header.Add("X-Hub-Signature-256", signature)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		responseBody, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return recorder.Code, string(responseBody)
+	}
+
+	t.Run("MissingSignature", func(t *testing.T) {
+		code, body := serveGithubWebhook(GithubEventPing, `{}`, "")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"signature invalid"}`, body)
+	})
+	t.Run("InvalidSignature", func(t *testing.T) {
+		code, body := serveGithubWebhook(GithubEventPing, `{}`, signGithubWebhookBody(`{"tampered":true}`))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"signature invalid"}`, body)
+	})
+	t.Run("Ping", func(t *testing.T) {
+		code, _ := serveGithubWebhook(GithubEventPing, `{}`, signGithubWebhookBody(`{}`))
+		assert.Equal(t, http.StatusOK, code)
+	})
+	t.Run("UnrecognizedEvent", func(t *testing.T) {
+		code, body := serveGithubWebhook("push", `{}`, signGithubWebhookBody(`{}`))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unrecognized github event type"}`, body)
+	})
+	t.Run("MissingRepository", func(t *testing.T) {
+		code, body := serveGithubWebhook(GithubEventPullRequest, `{"action":"opened"}`, signGithubWebhookBody(`{"action":"opened"}`))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unable to process github webhook payload"}`, body)
+	})
+	t.Run("IssueCommentOnIssueIgnored", func(t *testing.T) {
+		payload := `{"action":"created","issue":{"number":1,"user":{"id":1}},"repository":{"id":1234,"name":"ExampleRepository","owner":{"login":"dankmemes"}}}`
+		code, _ := serveGithubWebhook(GithubEventIssueComment, payload, signGithubWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+	})
+	t.Run("CheckRunForUntrackedPullRequest", func(t *testing.T) {
+		payload := `{"action":"completed","check_run":{"pull_requests":[{"number":4242}]},"repository":{"id":98765,"name":"ExampleRepository","owner":{"login":"dankmemes"}}}`
+		code, _ := serveGithubWebhook(GithubEventCheckRun, payload, signGithubWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestGetGithubWebhookTarget(t *testing.T) {
+	repository := &github.Repository{ID: github.Int64(1234)}
+	pullRequest := &github.PullRequest{
+		Number:             github.Int(7),
+		User:               &github.User{ID: github.Int64(1)},
+		RequestedReviewers: []*github.User{{ID: github.Int64(2)}},
+	}
+
+	t.Run("PullRequest", func(t *testing.T) {
+		target := getGithubWebhookTarget(&github.PullRequestEvent{
+			Repo:              repository,
+			PullRequest:       pullRequest,
+			RequestedReviewer: &github.User{ID: github.Int64(3)},
+		})
+		assert.Equal(t, repository, target.Repository)
+		assert.Equal(t, []int{7}, target.Numbers)
+		assert.Equal(t, []string{"1", "2", "3"}, target.AccountIDs)
+	})
+	t.Run("PullRequestReview", func(t *testing.T) {
+		target := getGithubWebhookTarget(&github.PullRequestReviewEvent{
+			Repo:        repository,
+			PullRequest: pullRequest,
+			Review:      &github.PullRequestReview{User: &github.User{ID: github.Int64(4)}},
+		})
+		assert.Equal(t, []int{7}, target.Numbers)
+		assert.Equal(t, []string{"1", "2", "4"}, target.AccountIDs)
+	})
+	t.Run("IssueComment", func(t *testing.T) {
+		target := getGithubWebhookTarget(&github.IssueCommentEvent{
+			Repo: repository,
+			Issue: &github.Issue{
+				Number:           github.Int(7),
+				User:             &github.User{ID: github.Int64(1)},
+				PullRequestLinks: &github.PullRequestLinks{},
+			},
+		})
+		assert.Equal(t, []int{7}, target.Numbers)
+		assert.Equal(t, []string{"1"}, target.AccountIDs)
+	})
+	t.Run("IssueCommentNotOnPullRequest", func(t *testing.T) {
+		target := getGithubWebhookTarget(&github.IssueCommentEvent{
+			Repo:  repository,
+			Issue: &github.Issue{Number: github.Int(7)},
+		})
+		assert.Equal(t, 0, len(target.Numbers))
+	})
+	t.Run("CheckRun", func(t *testing.T) {
+		target := getGithubWebhookTarget(&github.CheckRunEvent{
+			Repo: repository,
+			CheckRun: &github.CheckRun{
+				PullRequests: []*github.PullRequest{{Number: github.Int(7)}, {Number: github.Int(8)}},
+			},
+		})
+		assert.Equal(t, []int{7, 8}, target.Numbers)
+		assert.Equal(t, 0, len(target.AccountIDs))
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sent once when a channel is created, before any change to the calendar
+const GoogleCalendarResourceStateSync = "sync"
+
+func (api *API) GoogleCalendarWebhook(c *gin.Context) {
+	channelID := c.Request.Header.Get("X-Goog-Channel-ID")
+	if channelID == "" {
+		c.JSON(400, gin.H{"detail": "invalid request format"})
+		return
+	}
+	var calendarAccount database.CalendarAccount
+	err := database.GetCalendarAccountCollection(api.DB).FindOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"source_id": external.TASK_SOURCE_ID_GCAL},
+			{"calendars.watch_channel_id": channelID},
+		}},
+	).Decode(&calendarAccount)
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	var watchedCalendar *database.Calendar
+	for index := range calendarAccount.Calendars {
+		if calendarAccount.Calendars[index].WatchChannelID == channelID {
+			watchedCalendar = &calendarAccount.Calendars[index]
+		}
+	}
+	if watchedCalendar == nil ||
+		subtle.ConstantTimeCompare([]byte(c.Request.Header.Get("X-Goog-Channel-Token")), []byte(watchedCalendar.WatchToken)) != 1 ||
+		c.Request.Header.Get("X-Goog-Resource-ID") != watchedCalendar.WatchResourceID {
+		c.JSON(400, gin.H{"detail": "unrecognized google calendar channel"})
+		return
+	}
+	if c.Request.Header.Get("X-Goog-Resource-State") == GoogleCalendarResourceStateSync {
+		c.JSON(200, gin.H{})
+		return
+	}
+
+	var token database.ExternalAPIToken
+	err = database.GetExternalTokenCollection(api.DB).FindOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": calendarAccount.UserID},
+			{"account_id": calendarAccount.IDExternal},
+			{"service_id": external.TASK_SERVICE_ID_GOOGLE},
+		}},
+	).Decode(&token)
+	if err != nil {
+		// the account was unlinked, so the channel is left to expire
+		Handle404(c)
+		return
+	}
+	if token.IsBadToken {
+		c.JSON(200, gin.H{})
+		return
+	}
+	taskSourceResult, err := api.ExternalConfig.GetSourceResult(external.TASK_SOURCE_ID_GCAL)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load google calendar source")
+		Handle500(c)
+		return
+	}
+
+	// the synced range is covered by the sync token, so this only fetches the changes
+	calendarResult := make(chan external.CalendarResult)
+	go taskSourceResult.Source.GetEvents(api.DB, calendarAccount.UserID, calendarAccount.IDExternal, watchedCalendar.SyncedFrom.Time(), watchedCalendar.SyncedUntil.Time(), token.Scopes, calendarResult)
+	result := <-calendarResult
+	if result.Error != nil {
+		api.Logger.Error().Err(result.Error).Msg("failed to sync google calendar from push notification")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, gin.H{})
+}
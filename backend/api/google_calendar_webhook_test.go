@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/testutils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestGoogleCalendarWebhook(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	authToken := login("test_gcal_webhook@resonant-kelpie-404a42.netlify.app", "")
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+	googleToken := getGoogleTokenFromAuthToken(t, api.DB, authToken)
+
+	syncedFrom, _ := time.Parse(time.RFC3339, "2021-03-01T00:00:00-05:00")
+	_, err := database.GetCalendarAccountCollection(api.DB).InsertOne(context.Background(), database.CalendarAccount{
+		UserID:     userID,
+		IDExternal: googleToken.AccountID,
+		SourceID:   external.TASK_SOURCE_ID_GCAL,
+		Calendars: []database.Calendar{{
+			CalendarID:      googleToken.AccountID,
+			AccessRole:      constants.AccessControlOwner,
+			SyncToken:       "sync_token",
+			SyncedFrom:      primitive.NewDateTimeFromTime(syncedFrom),
+			SyncedUntil:     primitive.NewDateTimeFromTime(syncedFrom.AddDate(0, 0, 14)),
+			WatchChannelID:  "channel_id",
+			WatchResourceID: "resource_id",
+			WatchToken:      "channel_token",
+		}},
+	})
+	assert.NoError(t, err)
+
+	server := testutils.GetGcalFetchServer([]*calendar.Event{{
+		Id:      "pushed_event",
+		Summary: "Pushed Event",
+		Start:   &calendar.EventDateTime{DateTime: "2021-03-06T15:00:00-05:00"},
+		End:     &calendar.EventDateTime{DateTime: "2021-03-06T15:30:00-05:00"},
+	}})
+	defer server.Close()
+	api.ExternalConfig.GoogleOverrideURLs.CalendarFetchURL = &server.URL
+	router := GetRouter(api)
+
+	serveWebhook := func(headers map[string]string) int {
+		request, _ := http.NewRequest("POST", "/google/calendar/webhook/", nil)
+		for key, value := range headers {
+			request.Header.Add(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+	validHeaders := func(resourceState string) map[string]string {
+		return map[string]string{
+			"X-Goog-Channel-ID":     "channel_id",
+			"X-Goog-Channel-Token":  "channel_token",
+			"X-Goog-Resource-ID":    "resource_id",
+			"X-Goog-Resource-State": resourceState,
+		}
+	}
+
+	t.Run("MissingChannel", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serveWebhook(map[string]string{}))
+	})
+	t.Run("UnknownChannel", func(t *testing.T) {
+		headers := validHeaders("exists")
+		headers["X-Goog-Channel-ID"] = "unknown_channel_id"
+		assert.Equal(t, http.StatusNotFound, serveWebhook(headers))
+	})
+	t.Run("WrongToken", func(t *testing.T) {
+		headers := validHeaders("exists")
+		headers["X-Goog-Channel-Token"] = "wrong_token"
+		assert.Equal(t, http.StatusBadRequest, serveWebhook(headers))
+	})
+	t.Run("SyncMessage", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serveWebhook(validHeaders(GoogleCalendarResourceStateSync)))
+		_, err := database.GetCalendarEventByExternalId(api.DB, "pushed_event", userID)
+		assert.Error(t, err)
+	})
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serveWebhook(validHeaders("exists")))
+		event, err := database.GetCalendarEventByExternalId(api.DB, "pushed_event", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Pushed Event", event.Title)
+	})
+}
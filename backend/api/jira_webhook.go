@@ -0,0 +1,208 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const JIRAIssueCreatedEvent = "jira:issue_created"
+const JIRAIssueUpdatedEvent = "jira:issue_updated"
+const JIRAIssueDeletedEvent = "jira:issue_deleted"
+const JIRACommentCreatedEvent = "comment_created"
+
+type JIRAWebhookPayload struct {
+	WebhookEvent string                `json:"webhookEvent"`
+	Timestamp    int64                 `json:"timestamp"`
+	Issue        external.JIRATask     `json:"issue"`
+	Comment      *external.JIRAComment `json:"comment"`
+}
+
+func (api *API) JIRAWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to read jira webhook request body")
+		c.JSON(400, gin.H{"detail": "unable to read request body"})
+		return
+	}
+
+	// verification for security
+	jiraWebhookSecret := config.GetConfigValue("JIRA_WEBHOOK_SECRET")
+	err = authenticateJIRARequest(jiraWebhookSecret, c.Request.Header.Get("X-Hub-Signature"), body)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "signature invalid"})
+		return
+	}
+
+	var webhookPayload JIRAWebhookPayload
+	err = json.Unmarshal(body, &webhookPayload)
+	if err != nil || webhookPayload.Issue.ID == "" {
+		c.JSON(400, gin.H{"detail": "unable to process jira webhook payload"})
+		return
+	}
+
+	switch webhookPayload.WebhookEvent {
+	case JIRAIssueCreatedEvent, JIRAIssueUpdatedEvent, JIRAIssueDeletedEvent:
+	case JIRACommentCreatedEvent:
+		if webhookPayload.Comment == nil {
+			c.JSON(400, gin.H{"detail": "unable to process jira webhook payload"})
+			return
+		}
+	default:
+		c.JSON(400, gin.H{"detail": "unrecognized jira payload format"})
+		return
+	}
+
+	// issue IDs are only unique within a site, so tasks are matched on the site the issue is from as well
+	issueURL, err := url.Parse(webhookPayload.Issue.Self)
+	if err != nil || issueURL.Scheme == "" || issueURL.Host == "" {
+		c.JSON(400, gin.H{"detail": "unable to process jira webhook payload"})
+		return
+	}
+	siteDeeplinkPrefix := issueURL.Scheme + "://" + issueURL.Host + "/browse/"
+	// issues we don't have yet are picked up by the next poll, as the webhook doesn't tell us whose they are
+	tasks, err := database.GetTasksByExternalIDWithoutUser(api.DB, webhookPayload.Issue.ID, external.TASK_SOURCE_ID_JIRA)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("could not find matching jira issue")
+		c.JSON(400, gin.H{"detail": "unable to process jira webhook"})
+		return
+	}
+
+	// every user who has the issue gets the change
+	processingFailed := false
+	for _, task := range *tasks {
+		if !strings.HasPrefix(task.Deeplink, siteDeeplinkPrefix) {
+			continue
+		}
+		task := task
+		switch webhookPayload.WebhookEvent {
+		case JIRAIssueCreatedEvent, JIRAIssueUpdatedEvent:
+			err = api.updateTaskFromJIRAIssue(&task, webhookPayload.Issue)
+		case JIRAIssueDeletedEvent:
+			err = api.removeTaskFromJIRAIssue(&task, webhookPayload.Timestamp)
+		case JIRACommentCreatedEvent:
+			err = api.addCommentFromJIRAPayload(&task, *webhookPayload.Comment)
+		}
+		if err != nil {
+			api.Logger.Error().Err(err).Str("webhookEvent", webhookPayload.WebhookEvent).Str("taskID", task.ID.Hex()).Msg("unable to process jira webhook")
+			processingFailed = true
+		}
+	}
+	if processingFailed {
+		c.JSON(400, gin.H{"detail": "unable to process jira webhook"})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+func authenticateJIRARequest(secret string, signature string, body []byte) error {
+	// Jira signs webhooks with a secret in the same format as Github
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write(body)
+	computed := []byte("sha256=" + hex.EncodeToString(hash.Sum(nil)))
+	if !hmac.Equal(computed, []byte(signature)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (api *API) updateTaskFromJIRAIssue(task *database.Task, issue external.JIRATask) error {
+	title := issue.Fields.Summary
+	isCompleted := issue.Fields.Status.Category.Key == external.JIRADone
+	updateTask := database.Task{
+		Title:       &title,
+		IsCompleted: &isCompleted,
+		Status: &database.ExternalTaskStatus{
+			ExternalID:        issue.Fields.Status.ID,
+			State:             issue.Fields.Status.Name,
+			Type:              issue.Fields.Status.Category.Key,
+			IsCompletedStatus: isCompleted,
+		},
+	}
+	if isCompleted && (task.IsCompleted == nil || !*task.IsCompleted) {
+		updateTask.CompletedAt = primitive.NewDateTimeFromTime(api.GetCurrentTime())
+	}
+	dueDate, err := time.Parse(constants.YEAR_MONTH_DAY_FORMAT, issue.Fields.DueDate)
+	if err == nil {
+		primDueDate := primitive.NewDateTimeFromTime(dueDate)
+		updateTask.DueDate = &primDueDate
+	}
+	updatedAt, err := time.Parse("2006-01-02T15:04:05.999-0700", issue.Fields.UpdatedAt)
+	if err == nil {
+		updateTask.UpdatedAt = primitive.NewDateTimeFromTime(updatedAt)
+	}
+
+	if issue.Fields.Priority.ID != "" {
+		allPriorities := task.AllExternalPriorities
+		priority := external.GetJIRAExternalPriority(allPriorities, issue.Fields.Priority.ID)
+		if priority == nil {
+			// the priority may have been added since we last polled the site
+			jiraTaskSource, err := api.getJIRATaskSource()
+			if err != nil {
+				return err
+			}
+			allPriorities, err = jiraTaskSource.GetExternalPriorities(task.UserID, task.SourceAccountID)
+			if err != nil {
+				api.Logger.Error().Err(err).Msg("failed to fetch jira priorities")
+			}
+			priority = external.GetJIRAExternalPriority(allPriorities, issue.Fields.Priority.ID)
+			updateTask.AllExternalPriorities = allPriorities
+		}
+		if priority != nil {
+			updateTask.ExternalPriority = priority
+			updateTask.PriorityNormalized = &priority.PriorityNormalized
+		}
+	}
+
+	_, err = database.UpdateOrCreateTask(
+		api.DB,
+		task.UserID,
+		task.IDExternal,
+		task.SourceID,
+		nil,
+		updateTask,
+		// the same user can have issues with the same ID from different sites
+		&[]bson.M{{"_id": task.ID}},
+	)
+	return err
+}
+
+func (api *API) removeTaskFromJIRAIssue(task *database.Task, timestamp int64) error {
+	deletionState := true
+	deletedAt := api.GetCurrentTime()
+	if timestamp != 0 {
+		deletedAt = time.UnixMilli(timestamp)
+	}
+	updateTask := database.Task{
+		IsDeleted: &deletionState,
+		DeletedAt: primitive.NewDateTimeFromTime(deletedAt),
+	}
+	return api.UpdateTaskInDBWithError(task, task.UserID, &updateTask)
+}
+
+func (api *API) addCommentFromJIRAPayload(task *database.Task, jiraComment external.JIRAComment) error {
+	return api.addExternalComment(task, external.GetDatabaseCommentFromJIRAComment(jiraComment))
+}
+
+func (api *API) getJIRATaskSource() (external.JIRASource, error) {
+	taskSource, err := api.ExternalConfig.GetSourceResult(external.TASK_SOURCE_ID_JIRA)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("unable to get task source result for jira")
+		return external.JIRASource{}, err
+	}
+	return taskSource.Source.(external.JIRASource), nil
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func signJIRAWebhookBody(body string) string {
+	hash := hmac.New(sha256.New, []byte(config.GetConfigValue("JIRA_WEBHOOK_SECRET")))
+	hash.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func TestJIRAWebhook(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	router := GetRouter(api)
+
+	authToken := login("test_jira_webhook@resonant-kelpie-404a42.netlify.app", "")
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	isCompleted := false
+	insertResult, err := database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+		UserID:          userID,
+		IDExternal:      "jira_webhook_issue",
+		SourceID:        external.TASK_SOURCE_ID_JIRA,
+		SourceAccountID: "example cloud ID",
+		Deeplink:        "https://example.atlassian.net/browse/GT-1",
+		IsCompleted:     &isCompleted,
+		AllExternalPriorities: []*database.ExternalTaskPriority{
+			{ExternalID: "1", Name: "Highest", PriorityNormalized: 1.0},
+			{ExternalID: "2", Name: "Lowest", PriorityNormalized: 4.0},
+		},
+	})
+	assert.NoError(t, err)
+	taskID := insertResult.InsertedID.(primitive.ObjectID)
+	getTaskByID := func(taskID primitive.ObjectID) database.Task {
+		var task database.Task
+		err := database.GetTaskCollection(api.DB).FindOne(context.Background(), bson.M{"_id": taskID}).Decode(&task)
+		assert.NoError(t, err)
+		return task
+	}
+	getTask := func() database.Task {
+		return getTaskByID(taskID)
+	}
+	insertOtherUserTask := func(deeplink string) primitive.ObjectID {
+		insertResult, err := database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+			UserID:          primitive.NewObjectID(),
+			IDExternal:      "jira_webhook_issue",
+			SourceID:        external.TASK_SOURCE_ID_JIRA,
+			SourceAccountID: "other cloud ID",
+			Deeplink:        deeplink,
+			IsCompleted:     &isCompleted,
+		})
+		assert.NoError(t, err)
+		return insertResult.InsertedID.(primitive.ObjectID)
+	}
+	// another user of the same site has the same issue, while an issue on another site has the same ID
+	sameSiteTaskID := insertOtherUserTask("https://example.atlassian.net/browse/GT-1")
+	otherSiteTaskID := insertOtherUserTask("https://other.atlassian.net/browse/OT-7")
+
+	serveJIRAWebhook := func(payload string, signature string) (int, string) {
+		request, _ := http.NewRequest("POST", "/jira/webhook/", bytes.NewBuffer([]byte(payload)))
+		request.Header.Add("X-Hub-Signature", signature)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return recorder.Code, string(body)
+	}
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		code, body := serveJIRAWebhook(`{}`, "sha256=oopsie")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"signature invalid"}`, body)
+	})
+	t.Run("InvalidFormat", func(t *testing.T) {
+		code, body := serveJIRAWebhook(`"uhoh"`, signJIRAWebhookBody(`"uhoh"`))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unable to process jira webhook payload"}`, body)
+	})
+	t.Run("UntrackedIssue", func(t *testing.T) {
+		payload := `{"webhookEvent":"jira:issue_created","issue":{"id":"not_tracked","self":"https://example.atlassian.net/rest/api/2/issue/not_tracked","fields":{"summary":"new issue"}}}`
+		code, _ := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+	})
+	t.Run("UnrecognizedEvent", func(t *testing.T) {
+		payload := `{"webhookEvent":"worklog_created","issue":{"id":"jira_webhook_issue","self":"https://example.atlassian.net/rest/api/2/issue/jira_webhook_issue"}}`
+		code, body := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unrecognized jira payload format"}`, body)
+	})
+	t.Run("IssueUpdated", func(t *testing.T) {
+		payload := `{"webhookEvent":"jira:issue_updated","issue":{"id":"jira_webhook_issue","self":"https://example.atlassian.net/rest/api/2/issue/jira_webhook_issue","key":"GT-1","fields":{"summary":"updated title","duedate":"2023-04-20","updated":"2023-04-19T10:00:00.000+0000","status":{"id":"10001","name":"In Progress","statusCategory":{"key":"indeterminate"}},"priority":{"id":"2","name":"Lowest"}}}}`
+		code, _ := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+
+		task := getTask()
+		assert.Equal(t, "updated title", *task.Title)
+		assert.Equal(t, "In Progress", task.Status.State)
+		assert.False(t, *task.IsCompleted)
+		assert.Equal(t, "2", task.ExternalPriority.ExternalID)
+		assert.Equal(t, 4.0, *task.PriorityNormalized)
+		expectedDueDate, _ := time.Parse("2006-01-02", "2023-04-20")
+		assert.Equal(t, primitive.NewDateTimeFromTime(expectedDueDate), *task.DueDate)
+
+		assert.Equal(t, "updated title", *getTaskByID(sameSiteTaskID).Title)
+		assert.Nil(t, getTaskByID(otherSiteTaskID).Title)
+	})
+	t.Run("MissingSite", func(t *testing.T) {
+		payload := `{"webhookEvent":"jira:issue_updated","issue":{"id":"jira_webhook_issue","fields":{"summary":"forged title"}}}`
+		code, body := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"detail":"unable to process jira webhook payload"}`, body)
+	})
+	t.Run("IssueCompleted", func(t *testing.T) {
+		payload := `{"webhookEvent":"jira:issue_updated","issue":{"id":"jira_webhook_issue","self":"https://example.atlassian.net/rest/api/2/issue/jira_webhook_issue","key":"GT-1","fields":{"summary":"updated title","status":{"id":"10002","name":"Done","statusCategory":{"key":"done"}}}}}`
+		code, _ := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+
+		task := getTask()
+		assert.True(t, *task.IsCompleted)
+		assert.True(t, task.Status.IsCompletedStatus)
+		assert.NotEqual(t, primitive.DateTime(0), task.CompletedAt)
+	})
+	t.Run("CommentCreated", func(t *testing.T) {
+		payload := `{"webhookEvent":"comment_created","issue":{"id":"jira_webhook_issue","self":"https://example.atlassian.net/rest/api/2/issue/jira_webhook_issue"},"comment":{"id":"20001","body":"looks good","created":"2023-04-19T11:00:00.000+0000","author":{"accountId":"jira_user","displayName":"Jira User"}}}`
+		code, _ := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+		// retried deliveries don't duplicate the comment
+		code, _ = serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+
+		task := getTask()
+		assert.Equal(t, 1, len(*task.Comments))
+		comment := (*task.Comments)[0]
+		assert.Equal(t, "20001", comment.ExternalID)
+		assert.Equal(t, `"looks good"`, comment.Body)
+		assert.Equal(t, "Jira User", comment.User.DisplayName)
+	})
+	t.Run("IssueDeleted", func(t *testing.T) {
+		payload := `{"webhookEvent":"jira:issue_deleted","timestamp":1681902000000,"issue":{"id":"jira_webhook_issue","self":"https://example.atlassian.net/rest/api/2/issue/jira_webhook_issue"}}`
+		code, _ := serveJIRAWebhook(payload, signJIRAWebhookBody(payload))
+		assert.Equal(t, http.StatusOK, code)
+
+		task := getTask()
+		assert.True(t, *task.IsDeleted)
+		assert.Equal(t, primitive.DateTime(1681902000000), task.DeletedAt)
+	})
+}
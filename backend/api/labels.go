@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var labelColorRegex = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+type LabelCreateParams struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
+type LabelModifyParams struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TaskLabelAddParams struct {
+	LabelID string `json:"label_id" binding:"required"`
+}
+
+type LabelResult struct {
+	ID    primitive.ObjectID `json:"id"`
+	Name  string             `json:"name"`
+	Color string             `json:"color"`
+}
+
+func (api *API) LabelsList(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	labels, err := database.GetLabels(api.DB, userID)
+	if err != nil {
+		Handle500(c)
+		return
+	}
+	labelResults := []LabelResult{}
+	for _, label := range *labels {
+		labelResults = append(labelResults, LabelResult{
+			ID:    label.ID,
+			Name:  label.Name,
+			Color: label.Color,
+		})
+	}
+	c.JSON(200, labelResults)
+}
+
+func (api *API) LabelCreate(c *gin.Context) {
+	var params LabelCreateParams
+	err := c.BindJSON(&params)
+	params.Name = strings.TrimSpace(params.Name)
+	if err != nil || params.Name == "" {
+		c.JSON(400, gin.H{"detail": "invalid or missing 'name' parameter"})
+		return
+	}
+	if params.Color == "" {
+		params.Color = constants.DefaultLabelColor
+	}
+	if !labelColorRegex.MatchString(params.Color) {
+		c.JSON(400, gin.H{"detail": "'color' must be a hex color, i.e. #ff0000"})
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	timeNow := primitive.NewDateTimeFromTime(time.Now())
+	insertResult, err := database.GetLabelCollection(api.DB).InsertOne(context.Background(), database.Label{
+		UserID:    userID,
+		Name:      params.Name,
+		Color:     params.Color,
+		CreatedAt: timeNow,
+		UpdatedAt: timeNow,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(400, gin.H{"detail": "label already exists"})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to insert label")
+		Handle500(c)
+		return
+	}
+	c.JSON(201, gin.H{"id": insertResult.InsertedID.(primitive.ObjectID).Hex()})
+}
+
+func (api *API) LabelModify(c *gin.Context) {
+	labelID, err := primitive.ObjectIDFromHex(c.Param("label_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	var params LabelModifyParams
+	err = c.BindJSON(&params)
+	params.Name = strings.TrimSpace(params.Name)
+	if err != nil || (params.Name == "" && params.Color == "") {
+		c.JSON(400, gin.H{"detail": "invalid or missing label modify parameter"})
+		return
+	}
+	if params.Color != "" && !labelColorRegex.MatchString(params.Color) {
+		c.JSON(400, gin.H{"detail": "'color' must be a hex color, i.e. #ff0000"})
+		return
+	}
+
+	updateFields := bson.M{"updated_at": primitive.NewDateTimeFromTime(time.Now())}
+	if params.Name != "" {
+		updateFields["name"] = params.Name
+	}
+	if params.Color != "" {
+		updateFields["color"] = params.Color
+	}
+	userID := getUserIDFromContext(c)
+	res, err := database.GetLabelCollection(api.DB).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"_id": labelID},
+			{"user_id": userID},
+		}},
+		bson.M{"$set": updateFields},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(400, gin.H{"detail": "label already exists"})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to update label")
+		Handle500(c)
+		return
+	}
+	if res.MatchedCount != 1 {
+		Handle404(c)
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// LabelDelete also removes the label from tasks. Imported labels are recreated by the next sync if still set in the source.
+func (api *API) LabelDelete(c *gin.Context) {
+	labelID, err := primitive.ObjectIDFromHex(c.Param("label_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	res, err := database.GetLabelCollection(api.DB).DeleteOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"_id": labelID},
+			{"user_id": userID},
+		}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to delete label")
+		Handle500(c)
+		return
+	}
+	if res.DeletedCount != 1 {
+		Handle404(c)
+		return
+	}
+
+	_, err = database.GetTaskCollection(api.DB).UpdateMany(
+		context.Background(),
+		bson.M{"user_id": userID},
+		bson.M{"$pull": bson.M{"label_ids": labelID, "external_label_ids": labelID}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to remove label from tasks")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+func (api *API) TaskLabelAdd(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("task_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	var params TaskLabelAddParams
+	err = c.BindJSON(&params)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	labelID, err := primitive.ObjectIDFromHex(params.LabelID)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid label id"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	_, err = database.GetLabel(api.DB, labelID, userID)
+	if err != nil {
+		c.JSON(404, gin.H{"detail": "label not found"})
+		return
+	}
+	api.updateTaskLabels(c, taskID, userID, bson.M{"$addToSet": bson.M{"label_ids": labelID}})
+}
+
+// TaskLabelRemove only removes labels attached in General Task, as imported labels are managed in their source
+func (api *API) TaskLabelRemove(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("task_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	labelID, err := primitive.ObjectIDFromHex(c.Param("label_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	api.updateTaskLabels(c, taskID, getUserIDFromContext(c), bson.M{"$pull": bson.M{"label_ids": labelID}})
+}
+
+func (api *API) updateTaskLabels(c *gin.Context, taskID primitive.ObjectID, userID primitive.ObjectID, update bson.M) {
+	res, err := database.GetTaskCollection(api.DB).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"_id": taskID},
+			{"user_id": userID},
+		}},
+		update,
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to update task labels")
+		Handle500(c)
+		return
+	}
+	if res.MatchedCount != 1 {
+		Handle404(c)
+		return
+	}
+	database.PublishChange(api.DB, userID, database.ChangeTypeTask, taskID)
+	c.JSON(200, gin.H{})
+}
+
+// getTaskLabelIDs merges the labels attached in General Task with those imported from the task's source
+func getTaskLabelIDs(task *database.Task) []primitive.ObjectID {
+	labelIDs := []primitive.ObjectID{}
+	isAdded := map[primitive.ObjectID]bool{}
+	for _, taskLabelIDs := range []*[]primitive.ObjectID{task.LabelIDs, task.ExternalLabelIDs} {
+		if taskLabelIDs == nil {
+			continue
+		}
+		for _, labelID := range *taskLabelIDs {
+			if !isAdded[labelID] {
+				labelIDs = append(labelIDs, labelID)
+				isAdded[labelID] = true
+			}
+		}
+	}
+	return labelIDs
+}
+
+func getLabelTaskFilter(labelID primitive.ObjectID) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"label_ids": labelID},
+		{"external_label_ids": labelID},
+	}}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetTaskLabelIDs(t *testing.T) {
+	labelID1 := primitive.NewObjectID()
+	labelID2 := primitive.NewObjectID()
+	assert.Equal(t, []primitive.ObjectID{}, getTaskLabelIDs(&database.Task{}))
+	assert.Equal(t, []primitive.ObjectID{labelID1, labelID2}, getTaskLabelIDs(&database.Task{
+		LabelIDs:         &[]primitive.ObjectID{labelID1},
+		ExternalLabelIDs: &[]primitive.ObjectID{labelID2, labelID1},
+	}))
+}
+
+func TestLabels(t *testing.T) {
+	authToken := login("test_labels@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	notCompleted := false
+	taskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	otherTaskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+
+	createLabel := func(params LabelCreateParams, expectedStatus int) string {
+		bodyParams, err := json.Marshal(params)
+		assert.NoError(t, err)
+		response := ServeRequest(t, authToken, "POST", "/labels/create/", bytes.NewBuffer(bodyParams), expectedStatus, api)
+		var result map[string]string
+		json.Unmarshal(response, &result)
+		return result["id"]
+	}
+	listLabels := func() []LabelResult {
+		response := ServeRequest(t, authToken, "GET", "/labels/", nil, http.StatusOK, api)
+		var result []LabelResult
+		assert.NoError(t, json.Unmarshal(response, &result))
+		return result
+	}
+	listTaskIDs := func(labelID string) []string {
+		response := ServeRequest(t, authToken, "GET", "/tasks/v4/?label_id="+labelID, nil, http.StatusOK, api)
+		var result []TaskResultV4
+		assert.NoError(t, json.Unmarshal(response, &result))
+		taskIDs := []string{}
+		for _, task := range result {
+			taskIDs = append(taskIDs, task.ID.Hex())
+		}
+		return taskIDs
+	}
+
+	UnauthorizedTest(t, "GET", "/labels/", nil)
+	var labelID string
+	t.Run("Create", func(t *testing.T) {
+		createLabel(LabelCreateParams{Name: " "}, http.StatusBadRequest)
+		createLabel(LabelCreateParams{Name: "on-call", Color: "red"}, http.StatusBadRequest)
+		labelID = createLabel(LabelCreateParams{Name: " on-call "}, http.StatusCreated)
+		createLabel(LabelCreateParams{Name: "on-call"}, http.StatusBadRequest)
+
+		labels := listLabels()
+		assert.Equal(t, 1, len(labels))
+		assert.Equal(t, labelID, labels[0].ID.Hex())
+		assert.Equal(t, "on-call", labels[0].Name)
+		assert.Equal(t, constants.DefaultLabelColor, labels[0].Color)
+	})
+	t.Run("Modify", func(t *testing.T) {
+		ServeRequest(t, authToken, "PATCH", "/labels/modify/"+labelID+"/", bytes.NewBuffer([]byte(`{"color": "#ff0000"}`)), http.StatusOK, api)
+		ServeRequest(t, authToken, "PATCH", "/labels/modify/"+primitive.NewObjectID().Hex()+"/", bytes.NewBuffer([]byte(`{"color": "#ff0000"}`)), http.StatusNotFound, api)
+		assert.Equal(t, "#ff0000", listLabels()[0].Color)
+	})
+	t.Run("AttachToTask", func(t *testing.T) {
+		ServeRequest(t, authToken, "POST", "/tasks/"+taskID+"/labels/", bytes.NewBuffer([]byte(`{"label_id": "`+primitive.NewObjectID().Hex()+`"}`)), http.StatusNotFound, api)
+		ServeRequest(t, authToken, "POST", "/tasks/"+taskID+"/labels/", bytes.NewBuffer([]byte(`{"label_id": "`+labelID+`"}`)), http.StatusOK, api)
+		assert.Equal(t, []string{taskID}, listTaskIDs(labelID))
+		ServeRequest(t, authToken, "GET", "/tasks/v4/?label_id=invalid", nil, http.StatusBadRequest, api)
+	})
+	t.Run("ImportedLabels", func(t *testing.T) {
+		labelIDs, err := database.GetOrCreateLabels(api.DB, userID, []database.Label{{Name: "on-call"}, {Name: "customer-escalation"}}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(labelIDs))
+		assert.Equal(t, labelID, labelIDs[0].Hex())
+		assert.Equal(t, 2, len(listLabels()))
+
+		otherTaskObjectID, _ := primitive.ObjectIDFromHex(otherTaskID)
+		_, err = database.GetTaskCollection(api.DB).UpdateOne(context.Background(), bson.M{"_id": otherTaskObjectID}, bson.M{"$set": bson.M{"external_label_ids": labelIDs}})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{taskID, otherTaskID}, listTaskIDs(labelID))
+		// imported labels can't be removed here
+		ServeRequest(t, authToken, "DELETE", "/tasks/"+otherTaskID+"/labels/"+labelID+"/", nil, http.StatusOK, api)
+		assert.ElementsMatch(t, []string{taskID, otherTaskID}, listTaskIDs(labelID))
+	})
+	t.Run("OverviewView", func(t *testing.T) {
+		ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "label"}`)), http.StatusBadRequest, api)
+		ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "label", "label_id": "`+labelID+`"}`)), http.StatusOK, api)
+
+		labelObjectID, _ := primitive.ObjectIDFromHex(labelID)
+		var labelView database.View
+		err := database.GetViewCollection(api.DB).FindOne(context.Background(), bson.M{"user_id": userID, "label_id": labelObjectID}).Decode(&labelView)
+		assert.NoError(t, err)
+		result, err := api.GetLabelOverviewResult(labelView, userID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "on-call", result.Name)
+		assert.Equal(t, 2, len(result.ViewItems))
+	})
+	t.Run("Delete", func(t *testing.T) {
+		ServeRequest(t, authToken, "DELETE", "/labels/delete/"+labelID+"/", nil, http.StatusOK, api)
+		ServeRequest(t, authToken, "DELETE", "/labels/delete/"+labelID+"/", nil, http.StatusNotFound, api)
+		assert.Equal(t, []string{}, listTaskIDs(labelID))
+	})
+}
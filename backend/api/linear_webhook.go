@@ -419,7 +419,9 @@ func (api *API) removeTaskOwnerIfExists(issuePayload LinearIssuePayload) {
 	if err != nil {
 		logger := logging.GetSentryLogger()
 		logger.Error().Err(err).Msg("unable to delete task with owner not in GT")
+		return
 	}
+	database.PublishChange(api.DB, task.UserID, database.ChangeTypeTask, task.ID)
 }
 
 func (api *API) getTaskStatuses(userID primitive.ObjectID, accountID string, issuePayload LinearIssuePayload) ([]*database.ExternalTaskStatus, error) {
@@ -38,11 +38,18 @@ func (api *API) SupportedAccountTypesList(c *gin.Context) {
 		if !service.Details.IsLinkable || serviceName == external.TASK_SERVICE_ID_SLACK_APP {
 			continue
 		}
+		authorizationURL := serverURL + "link/" + service.Details.ID + "/"
+		// basic auth services take credentials from a form rather than redirecting to the service
+		if service.Details.AuthType == external.AuthTypeBasic {
+			authorizationURL += "credentials/"
+		} else if service.Details.AuthType == external.AuthTypeURL {
+			authorizationURL += "url/"
+		}
 		supportedAccountTypes = append(supportedAccountTypes, SupportedAccountType{
 			Name:             service.Details.Name,
 			Logo:             service.Details.Logo,
 			LogoV2:           service.Details.LogoV2,
-			AuthorizationURL: serverURL + "link/" + service.Details.ID + "/",
+			AuthorizationURL: authorizationURL,
 		})
 	}
 	c.JSON(200, supportedAccountTypes)
@@ -135,7 +142,7 @@ func (api *API) DeleteLinkedAccount(c *gin.Context) {
 			Handle500(c)
 			return
 		}
-	} else if accountToDelete.ServiceID == external.TASK_SERVICE_ID_GOOGLE {
+	} else if accountToDelete.ServiceID == external.TASK_SERVICE_ID_GOOGLE || accountToDelete.ServiceID == external.TASK_SERVICE_ID_MICROSOFT || accountToDelete.ServiceID == external.TASK_SERVICE_ID_ICS {
 		_, err := database.GetCalendarAccountCollection(api.DB).DeleteMany(
 			context.Background(),
 			bson.M{"$and": []bson.M{
@@ -32,6 +32,8 @@ func TestSupportedAccountTypesList(t *testing.T) {
 		assert.True(t, strings.Contains(string(body), "{\"name\":\"Google Calendar\",\"logo\":\"/images/gcal.png\",\"logo_v2\":\"gcal\",\"authorization_url\":\"http://localhost:8080/link/google/\"}"))
 		assert.Equal(t, 1, strings.Count(string(body), "{\"name\":\"Slack\",\"logo\":\"/images/slack.svg\",\"logo_v2\":\"slack\",\"authorization_url\":\"http://localhost:8080/link/slack/\"}"))
 		assert.Equal(t, 1, strings.Count(string(body), "{\"name\":\"Jira\",\"logo\":\"/images/jira.svg\",\"logo_v2\":\"jira\",\"authorization_url\":\"http://localhost:8080/link/atlassian/\"}"))
+		assert.Equal(t, 1, strings.Count(string(body), "{\"name\":\"CalDAV\",\"logo\":\"/images/caldav.svg\",\"logo_v2\":\"caldav\",\"authorization_url\":\"http://localhost:8080/link/caldav/credentials/\"}"))
+		assert.Equal(t, 1, strings.Count(string(body), "{\"name\":\"ICS Calendar\",\"logo\":\"/images/ics.svg\",\"logo_v2\":\"ics\",\"authorization_url\":\"http://localhost:8080/link/ics/url/\"}"))
 	})
 	UnauthorizedTest(t, "GET", "/linked_accounts/supported_types/", nil)
 }
@@ -1,12 +1,23 @@
 package api
 
 import (
-	"net/http"
+	"fmt"
+	"math"
+	"sort"
+	"time"
 
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/jjPlusPlus/task-manager/backend/utils"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const MEETING_BANNER_MAX_ACTIONS = 3
+
 type meetingBanner struct {
 	Title    string                `json:"title"`
 	Subtitle string                `json:"subtitle"`
@@ -26,33 +37,176 @@ type meetingBannerAction struct {
 }
 
 func (api *API) MeetingBanner(c *gin.Context) {
-	c.JSON(http.StatusOK, meetingBanner{
-		Title:    "Your next meeting is at 4:20pm",
-		Subtitle: "It looks like you've got a little time before your next meeting (6.9 min)",
-		Events: []meetingBannerEvent{{
-			Title: "Blast off",
-			ConferenceCall: utils.ConferenceCall{
-				Platform: "Google Meet",
-				Logo:     "/images/google-meet.svg",
-				URL:      "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
-			},
-		}},
-		Actions: []meetingBannerAction{
-			{
-				Logo:  "github",
-				Title: "Review PR: Email reply v0",
-				Link:  "https://github.com/franchizzle/task-manager/pull/1027",
-			},
-			{
-				Logo:  "gmail",
-				Title: "Unread email: Ramp Daily Digest for May 12",
-				Link:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
-			},
-			{
-				Logo:  "slack",
-				Title: "Unread messages from john",
-				Link:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
-			},
-		},
+	userID := getUserIDFromContext(c)
+	timezoneOffset, err := GetTimezoneOffsetFromHeader(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	banner, err := api.GetMeetingBanner(userID, timezoneOffset)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to build meeting banner")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, banner)
+}
+
+func (api *API) GetMeetingBanner(userID primitive.ObjectID, timezoneOffset time.Duration) (*meetingBanner, error) {
+	timeNow := api.GetCurrentLocalizedTime(timezoneOffset)
+	events, err := database.GetEventsUntilEndOfDay(api.DB, userID, timeNow)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	nextEvents := getNextMeetingBannerEvents(events)
+	banner := meetingBanner{
+		Title:    "No more meetings today",
+		Subtitle: "Your calendar is clear for the rest of the day",
+		Events:   []meetingBannerEvent{},
+	}
+	if len(nextEvents) > 0 {
+		eventStart := nextEvents[0].DatetimeStart.Time().In(timeNow.Location())
+		banner.Title = fmt.Sprintf("Your next meeting is at %s", eventStart.Format("3:04pm"))
+		banner.Subtitle = getMeetingBannerSubtitle(eventStart.Sub(timeNow))
+		for _, event := range nextEvents {
+			banner.Events = append(banner.Events, meetingBannerEvent{
+				Title: event.Title,
+				ConferenceCall: utils.ConferenceCall{
+					Platform: event.CallPlatform,
+					Logo:     event.CallLogo,
+					URL:      event.CallURL,
+				},
+			})
+		}
+	}
+
+	actions, err := api.getMeetingBannerActions(userID, timeNow)
+	if err != nil {
+		return nil, err
+	}
+	banner.Actions = actions
+	return &banner, nil
+}
+
+// returns the earliest upcoming event, along with any events starting at the same time
+func getNextMeetingBannerEvents(events *[]database.CalendarEvent) []database.CalendarEvent {
+	if events == nil || len(*events) == 0 {
+		return []database.CalendarEvent{}
+	}
+	sortedEvents := make([]database.CalendarEvent, len(*events))
+	copy(sortedEvents, *events)
+	sort.SliceStable(sortedEvents, func(i, j int) bool {
+		return sortedEvents[i].DatetimeStart < sortedEvents[j].DatetimeStart
 	})
+	nextEvents := []database.CalendarEvent{}
+	for _, event := range sortedEvents {
+		if event.DatetimeStart != sortedEvents[0].DatetimeStart {
+			break
+		}
+		nextEvents = append(nextEvents, event)
+	}
+	return nextEvents
+}
+
+func getMeetingBannerSubtitle(timeUntilMeeting time.Duration) string {
+	minutes := math.Round(timeUntilMeeting.Minutes()*10) / 10
+	if minutes < 1 {
+		return "Your next meeting is starting now"
+	}
+	return fmt.Sprintf("It looks like you've got a little time before your next meeting (%v min)", minutes)
+}
+
+func (api *API) getMeetingBannerActions(userID primitive.ObjectID, timeNow time.Time) ([]meetingBannerAction, error) {
+	actions := []meetingBannerAction{}
+
+	pullRequests, err := database.GetPullRequests(api.DB, userID, &[]bson.M{
+		{"is_completed": false},
+		{"required_action": external.ActionReviewPR},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(*pullRequests, func(i, j int) bool {
+		return (*pullRequests)[i].CreatedAtExternal < (*pullRequests)[j].CreatedAtExternal
+	})
+	for _, pullRequest := range *pullRequests {
+		actions = append(actions, meetingBannerAction{
+			Logo:  external.TaskSourceGithubPR.LogoV2,
+			Title: "Review PR: " + pullRequest.Title,
+			Link:  pullRequest.Deeplink,
+		})
+	}
+
+	// due dates are stored at midnight UTC of the day they're due, so the user's local day is compared in UTC
+	timeStartOfDueDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, time.FixedZone("", 0))
+	timeEndOfDueDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 23, 59, 59, 0, time.FixedZone("", 0))
+	dueTasks, err := database.GetTasks(api.DB, userID, &[]bson.M{
+		{"is_completed": false},
+		{"is_deleted": bson.M{"$ne": true}},
+		{"due_date": bson.M{"$lte": primitive.NewDateTimeFromTime(timeEndOfDueDay)}},
+		{"due_date": bson.M{"$gte": primitive.NewDateTimeFromTime(time.Unix(63090000, 0))}},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(*dueTasks, func(i, j int) bool {
+		return *(*dueTasks)[i].DueDate < *(*dueTasks)[j].DueDate
+	})
+	for _, task := range *dueTasks {
+		titlePrefix := "Due today: "
+		if task.DueDate.Time().Before(timeStartOfDueDay) {
+			titlePrefix = "Overdue: "
+		}
+		actions = append(actions, api.taskToMeetingBannerAction(task, titlePrefix))
+	}
+
+	// meetings start at real times, so unlike due dates they're compared against the user's local day
+	timeEndOfDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 23, 59, 59, 0, timeNow.Location())
+
+	meetingPrepTasks, err := database.GetTasks(api.DB, userID, &[]bson.M{
+		{"is_completed": false},
+		{"is_deleted": bson.M{"$ne": true}},
+		{"is_meeting_preparation_task": true},
+		{"meeting_preparation_params.event_moved_or_deleted": bson.M{"$ne": true}},
+		{"meeting_preparation_params.datetime_start": bson.M{"$gte": timeNow}},
+		{"meeting_preparation_params.datetime_start": bson.M{"$lte": timeEndOfDay}},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(*meetingPrepTasks, func(i, j int) bool {
+		return (*meetingPrepTasks)[i].MeetingPreparationParams.DatetimeStart < (*meetingPrepTasks)[j].MeetingPreparationParams.DatetimeStart
+	})
+	for _, task := range *meetingPrepTasks {
+		actions = append(actions, api.taskToMeetingBannerAction(task, "Prepare for: "))
+	}
+
+	if len(actions) > MEETING_BANNER_MAX_ACTIONS {
+		actions = actions[:MEETING_BANNER_MAX_ACTIONS]
+	}
+	return actions, nil
+}
+
+func (api *API) taskToMeetingBannerAction(task database.Task, titlePrefix string) meetingBannerAction {
+	title := ""
+	if task.Title != nil {
+		title = *task.Title
+	}
+	logo := external.TaskSourceGeneralTask.LogoV2
+	if task.IsMeetingPreparationTask {
+		logo = external.TaskSourceGoogleCalendar.LogoV2
+	} else if taskSourceResult, err := api.ExternalConfig.GetSourceResult(task.SourceID); err == nil {
+		logo = taskSourceResult.Details.LogoV2
+	}
+	link := task.Deeplink
+	if link == "" {
+		link = config.GetConfigValue("HOME_URL") + "tasks/" + task.IDTaskSection.Hex() + "/" + task.ID.Hex()
+	}
+	return meetingBannerAction{
+		Logo:  logo,
+		Title: titlePrefix + title,
+		Link:  link,
+	}
 }
@@ -1,18 +1,169 @@
 package api
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestMeetingBanner(t *testing.T) {
-	authToken := login("approved@resonant-kelpie-404a42.netlify.app", "")
+	authToken := login("test_meeting_banner@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+	testTime := time.Date(2022, time.January, 1, 12, 0, 0, 0, time.UTC)
+	api.OverrideTime = &testTime
+	router := GetRouter(api)
+
+	serveBannerRequest := func(t *testing.T, timezoneOffset string, expectedStatus int) string {
+		request, _ := http.NewRequest("GET", "/meeting_banner/", nil)
+		request.Header.Set("Authorization", "Bearer "+authToken)
+		if timezoneOffset != "" {
+			request.Header.Set("Timezone-Offset", timezoneOffset)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, expectedStatus, recorder.Code)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
 
 	UnauthorizedTest(t, "GET", "/meeting_banner/", nil)
+	t.Run("MissingTimezoneOffsetHeader", func(t *testing.T) {
+		body := serveBannerRequest(t, "", http.StatusBadRequest)
+		assert.Equal(t, `{"error":"Timezone-Offset header is required"}`, body)
+	})
+	t.Run("NoMeetings", func(t *testing.T) {
+		body := serveBannerRequest(t, "0", http.StatusOK)
+		assert.Equal(t, `{"title":"No more meetings today","subtitle":"Your calendar is clear for the rest of the day","events":[],"actions":[]}`, body)
+	})
 	t.Run("Success", func(t *testing.T) {
-		body := ServeRequest(t, authToken, "GET", "/meeting_banner/", nil, http.StatusOK, nil)
-		assert.Equal(t, "{\"title\":\"Your next meeting is at 4:20pm\",\"subtitle\":\"It looks like you've got a little time before your next meeting (6.9 min)\",\"events\":[{\"title\":\"Blast off\",\"conference_call\":{\"platform\":\"Google Meet\",\"logo\":\"/images/google-meet.svg\",\"url\":\"https://www.youtube.com/watch?v=dQw4w9WgXcQ\"}}],\"actions\":[{\"logo\":\"github\",\"title\":\"Review PR: Email reply v0\",\"link\":\"https://github.com/franchizzle/task-manager/pull/1027\"},{\"logo\":\"gmail\",\"title\":\"Unread email: Ramp Daily Digest for May 12\",\"link\":\"https://www.youtube.com/watch?v=dQw4w9WgXcQ\"},{\"logo\":\"slack\",\"title\":\"Unread messages from john\",\"link\":\"https://www.youtube.com/watch?v=dQw4w9WgXcQ\"}]}", string(body))
+		eventCollection := database.GetCalendarEventCollection(api.DB)
+		_, err := eventCollection.InsertOne(context.Background(), database.CalendarEvent{
+			UserID:        userID,
+			IDExternal:    "banner_event_later",
+			SourceID:      external.TASK_SOURCE_ID_GCAL,
+			Title:         "Later meeting",
+			DatetimeStart: primitive.NewDateTimeFromTime(testTime.Add(2 * time.Hour)),
+			DatetimeEnd:   primitive.NewDateTimeFromTime(testTime.Add(3 * time.Hour)),
+		})
+		assert.NoError(t, err)
+		_, err = eventCollection.InsertOne(context.Background(), database.CalendarEvent{
+			UserID:        userID,
+			IDExternal:    "banner_event_next",
+			SourceID:      external.TASK_SOURCE_ID_GCAL,
+			Title:         "Blast off",
+			CallPlatform:  "Google Meet",
+			CallLogo:      "/images/google-meet.svg",
+			CallURL:       "https://meet.google.com/abc-defg-hij",
+			DatetimeStart: primitive.NewDateTimeFromTime(testTime.Add(30 * time.Minute)),
+			DatetimeEnd:   primitive.NewDateTimeFromTime(testTime.Add(time.Hour)),
+		})
+		assert.NoError(t, err)
+		_, err = eventCollection.InsertOne(context.Background(), database.CalendarEvent{
+			UserID:        userID,
+			IDExternal:    "banner_event_task_block",
+			SourceID:      external.TASK_SOURCE_ID_GCAL,
+			Title:         "Focus block",
+			LinkedTaskID:  primitive.NewObjectID(),
+			DatetimeStart: primitive.NewDateTimeFromTime(testTime.Add(10 * time.Minute)),
+			DatetimeEnd:   primitive.NewDateTimeFromTime(testTime.Add(20 * time.Minute)),
+		})
+		assert.NoError(t, err)
+
+		notCompleted := false
+		_, err = database.GetPullRequestCollection(api.DB).InsertOne(context.Background(), database.PullRequest{
+			UserID:         userID,
+			IDExternal:     "banner_pr",
+			SourceID:       external.TASK_SOURCE_ID_GITHUB_PR,
+			IsCompleted:    &notCompleted,
+			Title:          "Email reply v0",
+			Deeplink:       "https://github.com/jjPlusPlus/task-manager/pull/1027",
+			RequiredAction: external.ActionReviewPR,
+		})
+		assert.NoError(t, err)
+		_, err = database.GetPullRequestCollection(api.DB).InsertOne(context.Background(), database.PullRequest{
+			UserID:         userID,
+			IDExternal:     "banner_pr_no_action",
+			SourceID:       external.TASK_SOURCE_ID_GITHUB_PR,
+			IsCompleted:    &notCompleted,
+			Title:          "Waiting on author",
+			RequiredAction: external.ActionWaitingOnAuthor,
+		})
+		assert.NoError(t, err)
+
+		taskTitle := "Send invoice"
+		dueDate := primitive.NewDateTimeFromTime(testTime)
+		_, err = database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+			UserID:      userID,
+			IDExternal:  "banner_task",
+			SourceID:    external.TASK_SOURCE_ID_LINEAR,
+			Title:       &taskTitle,
+			Deeplink:    "https://linear.app/issue/GT-1",
+			IsCompleted: &notCompleted,
+			DueDate:     &dueDate,
+		})
+		assert.NoError(t, err)
+
+		body := serveBannerRequest(t, "0", http.StatusOK)
+		assert.Equal(t, `{"title":"Your next meeting is at 12:30pm","subtitle":"It looks like you've got a little time before your next meeting (30 min)","events":[{"title":"Blast off","conference_call":{"platform":"Google Meet","logo":"/images/google-meet.svg","url":"https://meet.google.com/abc-defg-hij"}}],"actions":[{"logo":"github","title":"Review PR: Email reply v0","link":"https://github.com/jjPlusPlus/task-manager/pull/1027"},{"logo":"linear","title":"Due today: Send invoice","link":"https://linear.app/issue/GT-1"}]}`, body)
+	})
+	t.Run("SuccessWithTimezoneOffset", func(t *testing.T) {
+		// the Timezone-Offset header is in minutes behind UTC, so 60 means the user is at UTC-1
+		body := serveBannerRequest(t, "60", http.StatusOK)
+		assert.Contains(t, body, `"title":"Your next meeting is at 11:30am"`)
+	})
+	t.Run("OverdueTask", func(t *testing.T) {
+		notCompleted := false
+		taskTitle := "File expenses"
+		dueDate := primitive.NewDateTimeFromTime(testTime.AddDate(0, 0, -1))
+		_, err := database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+			UserID:      userID,
+			IDExternal:  "banner_overdue_task",
+			SourceID:    external.TASK_SOURCE_ID_LINEAR,
+			Title:       &taskTitle,
+			Deeplink:    "https://linear.app/issue/GT-2",
+			IsCompleted: &notCompleted,
+			DueDate:     &dueDate,
+		})
+		assert.NoError(t, err)
+
+		body := serveBannerRequest(t, "0", http.StatusOK)
+		assert.Contains(t, body, `{"logo":"linear","title":"Overdue: File expenses","link":"https://linear.app/issue/GT-2"},{"logo":"linear","title":"Due today: Send invoice","link":"https://linear.app/issue/GT-1"}`)
+	})
+	t.Run("MeetingPreparationWestOfUTC", func(t *testing.T) {
+		_, err := database.GetTaskCollection(api.DB).UpdateMany(context.Background(), bson.M{"user_id": userID}, bson.M{"$set": bson.M{"is_completed": true}})
+		assert.NoError(t, err)
+		_, err = database.GetPullRequestCollection(api.DB).UpdateMany(context.Background(), bson.M{"user_id": userID}, bson.M{"$set": bson.M{"is_completed": true}})
+		assert.NoError(t, err)
+		// 9pm at UTC-8 is already the next day in UTC, but it's still today for the user
+		notCompleted := false
+		taskTitle := "Late sync"
+		_, err = database.GetTaskCollection(api.DB).InsertOne(context.Background(), database.Task{
+			UserID:                   userID,
+			IDExternal:               "banner_meeting_prep_task",
+			SourceID:                 external.TASK_SOURCE_ID_GCAL,
+			Title:                    &taskTitle,
+			Deeplink:                 "https://calendar.google.com/event",
+			IsCompleted:              &notCompleted,
+			IsMeetingPreparationTask: true,
+			MeetingPreparationParams: &database.MeetingPreparationParams{
+				DatetimeStart: primitive.NewDateTimeFromTime(time.Date(2022, time.January, 2, 5, 0, 0, 0, time.UTC)),
+			},
+		})
+		assert.NoError(t, err)
+
+		body := serveBannerRequest(t, "480", http.StatusOK)
+		assert.Contains(t, body, `"actions":[{"logo":"gcal","title":"Prepare for: Late sync","link":"https://calendar.google.com/event"}]`)
 	})
 }
@@ -97,7 +97,19 @@ func (api *API) NoteModify(c *gin.Context) {
 			CreatedAt:    note.CreatedAt,
 		}
 
-		api.UpdateNoteInDB(c, note, userID, &updatedNote)
+		err = api.UpdateNoteInDBWithError(note, userID, &updatedNote)
+		if err != nil {
+			Handle500(c)
+			return
+		}
+		isSharingChanged := modifyParams.NoteChangeable.SharedUntil != nil || modifyParams.NoteChangeable.SharedAccess != nil
+		if isSharingChanged && sharedUntil.Time().After(api.GetCurrentTime()) {
+			if modifyParams.NoteChangeable.Title != nil {
+				note.Title = modifyParams.NoteChangeable.Title
+			}
+			note.SharedUntil = sharedUntil
+			api.queueWebhookEvent(userID, constants.WebhookEventNoteShared, getWebhookNoteData(note))
+		}
 	}
 
 	c.JSON(200, gin.H{})
@@ -51,6 +51,7 @@ type SupportedViewItem struct {
 	IsAdded       bool               `json:"is_added"`
 	TaskSectionID primitive.ObjectID `json:"task_section_id"`
 	GithubID      string             `json:"github_id"`
+	LabelID       string             `json:"label_id,omitempty"`
 	ViewID        primitive.ObjectID `json:"view_id"`
 }
 
@@ -163,6 +164,10 @@ func (api *API) GetOverviewResults(views []database.View, userID primitive.Objec
 			singleOverviewResult, err = api.GetMeetingPreparationOverviewResult(view, userID, timezoneOffset, showMovedOrDeleted, ignoreMeetingPreparation)
 		case string(constants.ViewDueToday):
 			singleOverviewResult, err = api.GetDueTodayOverviewResult(view, userID, timezoneOffset)
+		case string(constants.ViewLabel):
+			singleOverviewResult, err = api.GetLabelOverviewResult(view, userID, timezoneOffset)
+		case string(constants.ViewSavedQuery):
+			singleOverviewResult, err = api.GetSavedQueryOverviewResult(view, userID, timezoneOffset)
 		default:
 			err = errors.New("invalid view type")
 		}
@@ -254,6 +259,50 @@ func (api *API) GetTaskSectionOverviewResult(view database.View, userID primitiv
 	}, nil
 }
 
+// GetLabelOverviewResult lists the label's tasks from all sources
+func (api *API) GetLabelOverviewResult(view database.View, userID primitive.ObjectID, timezoneOffset time.Duration) (*OverviewResult[TaskResult], error) {
+	if view.UserID != userID {
+		return nil, errors.New("invalid user")
+	}
+	label, err := database.GetLabel(api.DB, view.LabelID, userID)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		_, err = database.GetViewCollection(api.DB).DeleteOne(context.Background(), bson.M{"_id": view.ID})
+		return nil, err
+	}
+
+	tasks, err := database.GetTasks(api.DB, userID, &[]bson.M{
+		{"is_completed": false},
+		{"is_deleted": bson.M{"$ne": true}},
+		getLabelTaskFilter(label.ID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	taskResults := api.taskListToTaskResultList(tasks, userID)
+
+	timeNow := api.GetCurrentLocalizedTime(timezoneOffset)
+	timeStartOfDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, time.FixedZone("", 0))
+	taskCompletedInLastDay := api.getCompletedInLastDay(database.GetTaskCollection(api.DB), userID, timeStartOfDay, &[]bson.M{getLabelTaskFilter(label.ID)})
+
+	return &OverviewResult[TaskResult]{
+		ID:                     view.ID,
+		Name:                   label.Name,
+		Logo:                   external.TaskServiceGeneralTask.LogoV2,
+		Type:                   constants.ViewLabel,
+		IsLinked:               view.IsLinked,
+		Sources:                []SourcesResult{},
+		TaskSectionID:          view.TaskSectionID,
+		IsReorderable:          view.IsReorderable,
+		IDOrdering:             view.IDOrdering,
+		ViewItems:              taskResults,
+		ViewItemIDs:            GetTaskSectionViewItemIDs(taskResults),
+		HasTasksCompletedToday: taskCompletedInLastDay,
+	}, nil
+}
+
 func getTaskCompletedInLastDayFromResults(taskResults []*TaskResult, timeStartOfDay time.Time) bool {
 	for _, task := range taskResults {
 		if task.CompletedAt.Time().Sub(timeStartOfDay) > 0 {
@@ -317,7 +366,7 @@ func (api *API) UpdateViewsLinkedStatus(views *[]database.View, userID primitive
 			return errors.New("invalid user")
 		}
 		var serviceID string
-		if view.Type == string(constants.ViewTaskSection) || view.Type == string(constants.ViewMeetingPreparation) || view.Type == string(constants.ViewDueToday) {
+		if view.Type == string(constants.ViewTaskSection) || view.Type == string(constants.ViewMeetingPreparation) || view.Type == string(constants.ViewDueToday) || view.Type == string(constants.ViewLabel) || view.Type == string(constants.ViewSavedQuery) {
 			serviceID = external.TaskServiceGeneralTask.ID
 		} else if view.Type == string(constants.ViewJira) {
 			serviceID = external.TaskServiceAtlassian.ID
@@ -929,6 +978,9 @@ type ViewCreateParams struct {
 	Type          string  `json:"type" binding:"required"`
 	TaskSectionID *string `json:"task_section_id"`
 	GithubID      *string `json:"github_id"`
+	LabelID       *string `json:"label_id"`
+	Name          *string `json:"name"`
+	Query         *string `json:"query"`
 }
 
 func (api *API) OverviewViewAdd(c *gin.Context) {
@@ -944,6 +996,12 @@ func (api *API) OverviewViewAdd(c *gin.Context) {
 	} else if viewCreateParams.Type == string(constants.ViewGithub) && viewCreateParams.GithubID == nil {
 		c.JSON(400, gin.H{"detail": "'id_github' is required for github type views"})
 		return
+	} else if viewCreateParams.Type == string(constants.ViewLabel) && viewCreateParams.LabelID == nil {
+		c.JSON(400, gin.H{"detail": "'label_id' is required for label type views"})
+		return
+	} else if viewCreateParams.Type == string(constants.ViewSavedQuery) && viewCreateParams.Query == nil {
+		c.JSON(400, gin.H{"detail": "'query' is required for saved query type views"})
+		return
 	}
 
 	userID := getUserIDFromContext(c)
@@ -960,6 +1018,9 @@ func (api *API) OverviewViewAdd(c *gin.Context) {
 	var serviceID string
 	taskSectionID := primitive.NilObjectID
 	var githubID string
+	labelID := primitive.NilObjectID
+	var name string
+	var query string
 	if viewCreateParams.Type == string(constants.ViewTaskSection) {
 		serviceID = external.TASK_SERVICE_ID_GT
 		taskSectionID, err = getValidTaskSection(*viewCreateParams.TaskSectionID, userID, api.DB)
@@ -984,6 +1045,25 @@ func (api *API) OverviewViewAdd(c *gin.Context) {
 			return
 		}
 		githubID = *viewCreateParams.GithubID
+	} else if viewCreateParams.Type == string(constants.ViewLabel) {
+		serviceID = external.TASK_SERVICE_ID_GT
+		labelID, _ = primitive.ObjectIDFromHex(*viewCreateParams.LabelID)
+		_, err = database.GetLabel(api.DB, labelID, userID)
+		if err != nil {
+			c.JSON(400, gin.H{"detail": "'label_id' is not a valid ID"})
+			return
+		}
+	} else if viewCreateParams.Type == string(constants.ViewSavedQuery) {
+		serviceID = external.TASK_SERVICE_ID_GT
+		query = *viewCreateParams.Query
+		_, err = parseSavedQuery(query, time.Now(), map[string]primitive.ObjectID{})
+		if err != nil {
+			c.JSON(400, gin.H{"detail": "invalid 'query': " + err.Error()})
+			return
+		}
+		if viewCreateParams.Name != nil {
+			name = *viewCreateParams.Name
+		}
 	} else if viewCreateParams.Type != string(constants.ViewJira) && viewCreateParams.Type != string(constants.ViewLinear) && viewCreateParams.Type != string(constants.ViewSlack) && viewCreateParams.Type != string(constants.ViewMeetingPreparation) && viewCreateParams.Type != string(constants.ViewDueToday) {
 		c.JSON(400, gin.H{"detail": "unsupported 'type'"})
 		return
@@ -1002,6 +1082,9 @@ func (api *API) OverviewViewAdd(c *gin.Context) {
 		IsLinked:      isLinked,
 		TaskSectionID: taskSectionID,
 		GithubID:      githubID,
+		LabelID:       labelID,
+		Name:          name,
+		Query:         query,
 	}
 
 	viewCollection := database.GetViewCollection(api.DB)
@@ -1038,6 +1121,20 @@ func (api *API) ViewDoesExist(db *mongo.Database, userID primitive.ObjectID, par
 			return false, errors.New("'github_id' is required for github type views")
 		}
 		dbQuery["$and"] = append(dbQuery["$and"].([]bson.M), bson.M{"github_id": *params.GithubID})
+	} else if params.Type == string(constants.ViewLabel) {
+		if params.LabelID == nil {
+			return false, errors.New("'label_id' is required for label type views")
+		}
+		labelObjectID, err := primitive.ObjectIDFromHex(*params.LabelID)
+		if err != nil {
+			return false, errors.New("'label_id' is not a valid ObjectID")
+		}
+		dbQuery["$and"] = append(dbQuery["$and"].([]bson.M), bson.M{"label_id": labelObjectID})
+	} else if params.Type == string(constants.ViewSavedQuery) {
+		if params.Query == nil {
+			return false, errors.New("'query' is required for saved query type views")
+		}
+		dbQuery["$and"] = append(dbQuery["$and"].([]bson.M), bson.M{"query": *params.Query})
 	} else if params.Type != string(constants.ViewLinear) && params.Type != string(constants.ViewSlack) && params.Type != string(constants.ViewJira) && params.Type != string(constants.ViewMeetingPreparation) && params.Type != string(constants.ViewDueToday) {
 		return false, errors.New("unsupported view type")
 	}
@@ -1184,6 +1281,11 @@ func (api *API) OverviewSupportedViewsList(c *gin.Context) {
 		Handle500(c)
 		return
 	}
+	supportedLabelViews, err := api.getSupportedLabelViews(api.DB, userID)
+	if err != nil {
+		Handle500(c)
+		return
+	}
 	isGithubLinked, err := api.IsServiceLinked(api.DB, userID, external.TASK_SERVICE_ID_GITHUB)
 	if err != nil {
 		Handle500(c)
@@ -1308,6 +1410,14 @@ func (api *API) OverviewSupportedViewsList(c *gin.Context) {
 			AuthorizationURL: githubAuthURL,
 			Views:            supportedGithubViews,
 		},
+		{
+			Type:     constants.ViewLabel,
+			Name:     "Labels",
+			Logo:     external.TaskServiceGeneralTask.LogoV2,
+			IsNested: true,
+			IsLinked: true,
+			Views:    supportedLabelViews,
+		},
 	}
 	err = api.updateIsAddedForSupportedViews(api.DB, userID, &supportedViews)
 	if err != nil {
@@ -1374,6 +1484,21 @@ func (api *API) getSupportedGithubViews(db *mongo.Database, userID primitive.Obj
 	return supportedViewItems, nil
 }
 
+func (api *API) getSupportedLabelViews(db *mongo.Database, userID primitive.ObjectID) ([]SupportedViewItem, error) {
+	labels, err := database.GetLabels(db, userID)
+	if err != nil {
+		return []SupportedViewItem{}, err
+	}
+	supportedViewItems := []SupportedViewItem{}
+	for _, label := range *labels {
+		supportedViewItems = append(supportedViewItems, SupportedViewItem{
+			Name:    label.Name,
+			LabelID: label.ID.Hex(),
+		})
+	}
+	return supportedViewItems, nil
+}
+
 func (api *API) updateIsAddedForSupportedViews(db *mongo.Database, userID primitive.ObjectID, supportedViews *[]SupportedView) error {
 	if supportedViews == nil {
 		return errors.New("supportedViews must not be nil")
@@ -1404,6 +1529,14 @@ func (api *API) getViewFromSupportedView(db *mongo.Database, userID primitive.Ob
 		return api.getView(db, userID, viewType, &[]bson.M{
 			{"github_id": view.GithubID},
 		})
+	} else if viewType == constants.ViewLabel {
+		labelID, err := primitive.ObjectIDFromHex(view.LabelID)
+		if err != nil {
+			return nil, err
+		}
+		return api.getView(db, userID, viewType, &[]bson.M{
+			{"label_id": labelID},
+		})
 	}
 	return nil, errors.New("invalid view type")
 }
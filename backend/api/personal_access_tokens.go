@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
+)
+
+type PersonalAccessTokenCreateParams struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes" binding:"required"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+type PersonalAccessTokenResult struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type personalAccessTokenRoute struct {
+	PathPrefix string
+	ReadScopes []string
+	// write scopes also grant read access
+	WriteScopes []string
+}
+
+// personal access tokens can only reach endpoints listed here
+var personalAccessTokenRoutes = []personalAccessTokenRoute{
+	{PathPrefix: "/tasks/", ReadScopes: []string{constants.ScopeTasksRead}, WriteScopes: []string{constants.ScopeTasksWrite}},
+	{PathPrefix: "/sections/", ReadScopes: []string{constants.ScopeTasksRead}, WriteScopes: []string{constants.ScopeTasksWrite}},
+	{PathPrefix: "/recurring_task_templates/", ReadScopes: []string{constants.ScopeTasksRead}, WriteScopes: []string{constants.ScopeTasksWrite}},
+	{PathPrefix: "/calendars/", WriteScopes: []string{constants.ScopeCalendar}},
+	{PathPrefix: "/events/", WriteScopes: []string{constants.ScopeCalendar}},
+	{PathPrefix: "/meeting_banner/", WriteScopes: []string{constants.ScopeCalendar}},
+	{PathPrefix: "/notes/", WriteScopes: []string{constants.ScopeNotes}},
+}
+
+func (api *API) PersonalAccessTokenCreate(c *gin.Context) {
+	var params PersonalAccessTokenCreateParams
+	err := c.BindJSON(&params)
+	if err != nil || params.Name == "" || len(params.Scopes) == 0 {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(constants.PersonalAccessTokenScopes, scope) {
+			c.JSON(400, gin.H{"detail": "invalid scope: " + scope})
+			return
+		}
+	}
+	var expiresAt primitive.DateTime
+	if params.ExpiresAt != "" {
+		expiresAtTime, err := time.Parse(time.RFC3339, params.ExpiresAt)
+		if err != nil {
+			c.JSON(400, gin.H{"detail": "expires_at is not a valid date"})
+			return
+		}
+		if !expiresAtTime.After(api.GetCurrentTime()) {
+			c.JSON(400, gin.H{"detail": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = primitive.NewDateTimeFromTime(expiresAtTime)
+	}
+
+	token, err := generatePersonalAccessToken()
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to generate personal access token")
+		Handle500(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	insertResult, err := database.GetPersonalAccessTokenCollection(api.DB).InsertOne(context.Background(), database.PersonalAccessToken{
+		UserID:    userID,
+		Name:      params.Name,
+		TokenHash: hashPersonalAccessToken(token),
+		Scopes:    params.Scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: primitive.NewDateTimeFromTime(api.GetCurrentTime()),
+	})
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to create personal access token")
+		Handle500(c)
+		return
+	}
+
+	// this is the only time the token is returned, as only its hash is stored
+	c.JSON(201, gin.H{"id": insertResult.InsertedID.(primitive.ObjectID), "token": token})
+}
+
+func (api *API) PersonalAccessTokensList(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	cursor, err := database.GetPersonalAccessTokenCollection(api.DB).Find(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"is_revoked": false},
+		}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to fetch personal access tokens")
+		Handle500(c)
+		return
+	}
+	var tokens []database.PersonalAccessToken
+	err = cursor.All(context.Background(), &tokens)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to iterate through personal access tokens")
+		Handle500(c)
+		return
+	}
+
+	results := []PersonalAccessTokenResult{}
+	for _, token := range tokens {
+		result := PersonalAccessTokenResult{
+			ID:        token.ID.Hex(),
+			Name:      token.Name,
+			Scopes:    token.Scopes,
+			CreatedAt: token.CreatedAt.Time().UTC().Format(time.RFC3339),
+		}
+		if token.ExpiresAt != 0 {
+			result.ExpiresAt = token.ExpiresAt.Time().UTC().Format(time.RFC3339)
+		}
+		if token.LastUsedAt != 0 {
+			result.LastUsedAt = token.LastUsedAt.Time().UTC().Format(time.RFC3339)
+		}
+		results = append(results, result)
+	}
+	c.JSON(200, results)
+}
+
+func (api *API) PersonalAccessTokenDelete(c *gin.Context) {
+	tokenID, err := primitive.ObjectIDFromHex(c.Param("token_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	res, err := database.GetPersonalAccessTokenCollection(api.DB).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"_id": tokenID},
+			{"user_id": userID},
+			{"is_revoked": false},
+		}},
+		bson.M{"$set": bson.M{"is_revoked": true}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to revoke personal access token")
+		Handle500(c)
+		return
+	}
+	if res.MatchedCount == 0 {
+		Handle404(c)
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+func generatePersonalAccessToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+	return constants.PersonalAccessTokenPrefix + hex.EncodeToString(randomBytes), nil
+}
+
+func hashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func isPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, constants.PersonalAccessTokenPrefix)
+}
+
+// returns the token if it has not been revoked or expired, and records that it was used
+func getValidPersonalAccessToken(db *mongo.Database, token string) (*database.PersonalAccessToken, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var personalAccessToken database.PersonalAccessToken
+	err := database.GetPersonalAccessTokenCollection(db).FindOneAndUpdate(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"token_hash": hashPersonalAccessToken(token)},
+			{"is_revoked": false},
+			{"$or": []bson.M{
+				{"expires_at": bson.M{"$exists": false}},
+				{"expires_at": bson.M{"$gt": now}},
+			}},
+		}},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	).Decode(&personalAccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &personalAccessToken, nil
+}
+
+func personalAccessTokenHasAccess(scopes []string, method string, path string) bool {
+	for _, route := range personalAccessTokenRoutes {
+		if !strings.HasPrefix(path, route.PathPrefix) {
+			continue
+		}
+		for _, scope := range scopes {
+			if slices.Contains(route.WriteScopes, scope) {
+				return true
+			}
+			if method == http.MethodGet && slices.Contains(route.ReadScopes, scope) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
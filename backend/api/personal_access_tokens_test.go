@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type personalAccessTokenCreateResponse struct {
+	ID    string `json:"id"`
+	Token string `json:"token"`
+}
+
+func createTestPersonalAccessToken(t *testing.T, api *API, authToken string, params PersonalAccessTokenCreateParams) personalAccessTokenCreateResponse {
+	bodyParams, err := json.Marshal(params)
+	assert.NoError(t, err)
+	response := ServeRequest(t, authToken, "POST", "/personal_access_tokens/", bytes.NewBuffer(bodyParams), http.StatusCreated, api)
+	var result personalAccessTokenCreateResponse
+	assert.NoError(t, json.Unmarshal(response, &result))
+	return result
+}
+
+func TestPersonalAccessTokenCreate(t *testing.T) {
+	authToken := login("test_personal_access_token_create@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	UnauthorizedTest(t, "POST", "/personal_access_tokens/", nil)
+	t.Run("MissingName", func(t *testing.T) {
+		bodyParams, err := json.Marshal(PersonalAccessTokenCreateParams{Scopes: []string{constants.ScopeTasksRead}})
+		assert.NoError(t, err)
+		ServeRequest(t, authToken, "POST", "/personal_access_tokens/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+	})
+	t.Run("InvalidScope", func(t *testing.T) {
+		bodyParams, err := json.Marshal(PersonalAccessTokenCreateParams{Name: "ci", Scopes: []string{"admin"}})
+		assert.NoError(t, err)
+		response := ServeRequest(t, authToken, "POST", "/personal_access_tokens/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+		assert.Equal(t, `{"detail":"invalid scope: admin"}`, string(response))
+	})
+	t.Run("ExpiryInPast", func(t *testing.T) {
+		bodyParams, err := json.Marshal(PersonalAccessTokenCreateParams{Name: "ci", Scopes: []string{constants.ScopeTasksRead}, ExpiresAt: "2020-01-01T00:00:00Z"})
+		assert.NoError(t, err)
+		ServeRequest(t, authToken, "POST", "/personal_access_tokens/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+	})
+	t.Run("Success", func(t *testing.T) {
+		result := createTestPersonalAccessToken(t, api, authToken, PersonalAccessTokenCreateParams{
+			Name:   "ci",
+			Scopes: []string{constants.ScopeTasksWrite},
+		})
+		assert.Equal(t, 68, len(result.Token))
+
+		tokenID, err := primitive.ObjectIDFromHex(result.ID)
+		assert.NoError(t, err)
+		var token database.PersonalAccessToken
+		err = database.GetPersonalAccessTokenCollection(api.DB).FindOne(context.Background(), bson.M{"_id": tokenID}).Decode(&token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, token.UserID)
+		assert.Equal(t, "ci", token.Name)
+		assert.Equal(t, []string{constants.ScopeTasksWrite}, token.Scopes)
+		// the plaintext token is never stored
+		assert.Equal(t, hashPersonalAccessToken(result.Token), token.TokenHash)
+	})
+}
+
+func TestPersonalAccessTokensList(t *testing.T) {
+	authToken := login("test_personal_access_token_list@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	UnauthorizedTest(t, "GET", "/personal_access_tokens/", nil)
+	t.Run("Success", func(t *testing.T) {
+		created := createTestPersonalAccessToken(t, api, authToken, PersonalAccessTokenCreateParams{
+			Name:      "scripts",
+			Scopes:    []string{constants.ScopeNotes, constants.ScopeCalendar},
+			ExpiresAt: "2099-01-01T00:00:00Z",
+		})
+		response := ServeRequest(t, authToken, "GET", "/personal_access_tokens/", nil, http.StatusOK, api)
+		var results []PersonalAccessTokenResult
+		assert.NoError(t, json.Unmarshal(response, &results))
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, created.ID, results[0].ID)
+		assert.Equal(t, "scripts", results[0].Name)
+		assert.Equal(t, []string{constants.ScopeNotes, constants.ScopeCalendar}, results[0].Scopes)
+		assert.Equal(t, "2099-01-01T00:00:00Z", results[0].ExpiresAt)
+		assert.Equal(t, "", results[0].LastUsedAt)
+	})
+}
+
+func TestPersonalAccessTokenDelete(t *testing.T) {
+	authToken := login("test_personal_access_token_delete@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	UnauthorizedTest(t, "DELETE", "/personal_access_tokens/"+primitive.NewObjectID().Hex()+"/", nil)
+	t.Run("NotFound", func(t *testing.T) {
+		ServeRequest(t, authToken, "DELETE", "/personal_access_tokens/"+primitive.NewObjectID().Hex()+"/", nil, http.StatusNotFound, api)
+	})
+	t.Run("Success", func(t *testing.T) {
+		created := createTestPersonalAccessToken(t, api, authToken, PersonalAccessTokenCreateParams{
+			Name:   "revoke me",
+			Scopes: []string{constants.ScopeTasksRead},
+		})
+		ServeRequest(t, created.Token, "GET", "/tasks/v4/", nil, http.StatusOK, api)
+		ServeRequest(t, authToken, "DELETE", "/personal_access_tokens/"+created.ID+"/", nil, http.StatusNoContent, api)
+		ServeRequest(t, created.Token, "GET", "/tasks/v4/", nil, http.StatusUnauthorized, api)
+		ServeRequest(t, authToken, "DELETE", "/personal_access_tokens/"+created.ID+"/", nil, http.StatusNotFound, api)
+	})
+}
+
+func TestPersonalAccessTokenAuthorization(t *testing.T) {
+	authToken := login("test_personal_access_token_authorization@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	readToken := createTestPersonalAccessToken(t, api, authToken, PersonalAccessTokenCreateParams{
+		Name:   "read",
+		Scopes: []string{constants.ScopeTasksRead},
+	})
+	t.Run("ReadScopeAllowsRead", func(t *testing.T) {
+		ServeRequest(t, readToken.Token, "GET", "/tasks/v4/", nil, http.StatusOK, api)
+	})
+	t.Run("ReadScopeRejectsWrite", func(t *testing.T) {
+		ServeRequest(t, readToken.Token, "PATCH", "/tasks/modify/"+primitive.NewObjectID().Hex()+"/", bytes.NewBuffer([]byte(`{"is_completed":true}`)), http.StatusForbidden, api)
+	})
+	t.Run("RejectsOtherEndpoints", func(t *testing.T) {
+		ServeRequest(t, readToken.Token, "GET", "/settings/", nil, http.StatusForbidden, api)
+		ServeRequest(t, readToken.Token, "GET", "/personal_access_tokens/", nil, http.StatusForbidden, api)
+	})
+	t.Run("RecordsLastUsed", func(t *testing.T) {
+		tokenID, _ := primitive.ObjectIDFromHex(readToken.ID)
+		var token database.PersonalAccessToken
+		err := database.GetPersonalAccessTokenCollection(api.DB).FindOne(context.Background(), bson.M{"_id": tokenID}).Decode(&token)
+		assert.NoError(t, err)
+		assert.NotEqual(t, primitive.DateTime(0), token.LastUsedAt)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		expiredToken := createTestPersonalAccessToken(t, api, authToken, PersonalAccessTokenCreateParams{
+			Name:      "expired",
+			Scopes:    []string{constants.ScopeTasksRead},
+			ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+		})
+		tokenID, _ := primitive.ObjectIDFromHex(expiredToken.ID)
+		_, err := database.GetPersonalAccessTokenCollection(api.DB).UpdateOne(
+			context.Background(),
+			bson.M{"_id": tokenID},
+			bson.M{"$set": bson.M{"expires_at": primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour))}},
+		)
+		assert.NoError(t, err)
+		ServeRequest(t, expiredToken.Token, "GET", "/tasks/v4/", nil, http.StatusUnauthorized, api)
+	})
+}
+
+func TestPersonalAccessTokenHasAccess(t *testing.T) {
+	assert.True(t, personalAccessTokenHasAccess([]string{constants.ScopeTasksRead}, "GET", "/tasks/v4/"))
+	assert.False(t, personalAccessTokenHasAccess([]string{constants.ScopeTasksRead}, "POST", "/tasks/create/:source_id/"))
+	assert.True(t, personalAccessTokenHasAccess([]string{constants.ScopeTasksWrite}, "GET", "/tasks/v4/"))
+	assert.True(t, personalAccessTokenHasAccess([]string{constants.ScopeTasksWrite}, "POST", "/tasks/create/:source_id/"))
+	assert.True(t, personalAccessTokenHasAccess([]string{constants.ScopeCalendar}, "GET", "/events/"))
+	assert.False(t, personalAccessTokenHasAccess([]string{constants.ScopeCalendar}, "GET", "/notes/"))
+	assert.True(t, personalAccessTokenHasAccess([]string{constants.ScopeNotes}, "PATCH", "/notes/modify/:note_id/"))
+	assert.False(t, personalAccessTokenHasAccess([]string{constants.ScopeTasksWrite, constants.ScopeNotes}, "GET", "/settings/"))
+}
@@ -0,0 +1,438 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/settings"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PLANNER_DEFAULT_DAYS      = 7
+	PLANNER_MAX_DAYS          = 28
+	PLANNER_BLOCK_GRANULARITY = 15 * time.Minute
+)
+
+const (
+	PlannerUnscheduledNoTimeAllocation = "no_time_allocation"
+	PlannerUnscheduledCompleted        = "completed"
+	PlannerUnscheduledNoFreeSlot       = "no_free_slot"
+	PlannerUnscheduledPastDueDate      = "no_free_slot_before_due_date"
+)
+
+type PlannerParams struct {
+	TaskIDs       []string   `json:"task_ids" binding:"required"`
+	DatetimeStart *time.Time `json:"datetime_start"`
+	DatetimeEnd   *time.Time `json:"datetime_end"`
+	// when set, the proposed blocks are created as events linked to their tasks
+	Confirm    bool   `json:"confirm"`
+	AccountID  string `json:"account_id"`
+	CalendarID string `json:"calendar_id"`
+}
+
+type PlannerBlock struct {
+	TaskID        primitive.ObjectID `json:"task_id"`
+	EventID       primitive.ObjectID `json:"event_id,omitempty"`
+	Title         string             `json:"title"`
+	DatetimeStart string             `json:"datetime_start"`
+	DatetimeEnd   string             `json:"datetime_end"`
+}
+
+type PlannerUnscheduledTask struct {
+	TaskID primitive.ObjectID `json:"task_id"`
+	Title  string             `json:"title"`
+	Reason string             `json:"reason"`
+}
+
+type PlannerResult struct {
+	Blocks      []PlannerBlock           `json:"blocks"`
+	Unscheduled []PlannerUnscheduledTask `json:"unscheduled"`
+}
+
+type plannerWorkingHours struct {
+	startHour       int
+	endHour         int
+	includeWeekends bool
+}
+
+type plannerRange struct {
+	start time.Time
+	end   time.Time
+}
+
+type plannerBlock struct {
+	task  database.Task
+	start time.Time
+	end   time.Time
+}
+
+// Planner proposes time blocks for the tasks in the free gaps between the user's events,
+// and creates them as linked events when the plan is confirmed
+func (api *API) Planner(c *gin.Context) {
+	var params PlannerParams
+	err := c.BindJSON(&params)
+	if err != nil || len(params.TaskIDs) == 0 {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	timezoneOffset, err := GetTimezoneOffsetFromHeader(c)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	timeNow := api.GetCurrentTime().In(api.getPlannerLocation(userID, timezoneOffset))
+	datetimeStart := timeNow
+	if params.DatetimeStart != nil && params.DatetimeStart.After(timeNow) {
+		datetimeStart = params.DatetimeStart.In(timeNow.Location())
+	}
+	datetimeEnd := datetimeStart.AddDate(0, 0, PLANNER_DEFAULT_DAYS)
+	if params.DatetimeEnd != nil {
+		datetimeEnd = params.DatetimeEnd.In(timeNow.Location())
+	}
+	if !datetimeEnd.After(datetimeStart) || datetimeEnd.After(datetimeStart.AddDate(0, 0, PLANNER_MAX_DAYS)) {
+		c.JSON(400, gin.H{"detail": fmt.Sprintf("'datetime_end' must be after 'datetime_start' and within %d days", PLANNER_MAX_DAYS)})
+		return
+	}
+
+	tasks := []database.Task{}
+	for _, taskIDHex := range params.TaskIDs {
+		taskID, err := primitive.ObjectIDFromHex(taskIDHex)
+		if err != nil {
+			c.JSON(404, gin.H{"detail": fmt.Sprintf("task not found: %s", taskIDHex)})
+			return
+		}
+		task, err := database.GetTask(api.DB, taskID, userID)
+		if err != nil {
+			c.JSON(404, gin.H{"detail": fmt.Sprintf("task not found: %s", taskIDHex)})
+			return
+		}
+		tasks = append(tasks, *task)
+	}
+
+	var userSettings []database.UserSetting
+	err = database.FindWithCollection(database.GetUserSettingsCollection(api.DB), userID, nil, &userSettings, nil)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load settings")
+		Handle500(c)
+		return
+	}
+	busyRanges, err := api.getPlannerBusyRanges(userID, datetimeStart, datetimeEnd)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load events")
+		Handle500(c)
+		return
+	}
+	workingRanges := getPlannerWorkingRanges(datetimeStart, datetimeEnd, getPlannerWorkingHours(userSettings))
+	blocks, unscheduled := planTaskBlocks(tasks, getPlannerFreeRanges(workingRanges, busyRanges), timeNow.Location())
+
+	result := PlannerResult{Blocks: []PlannerBlock{}, Unscheduled: unscheduled}
+	if params.Confirm {
+		accountID := params.AccountID
+		calendarID := params.CalendarID
+		if accountID == "" {
+			accountID = getPlannerSettingValue(userSettings, constants.SettingFieldCalendarForNewTasks)
+			calendarID = getPlannerSettingValue(userSettings, constants.SettingFieldCalendarIDForNewTasks)
+		}
+		if accountID == "" {
+			c.JSON(400, gin.H{"detail": "'account_id' is required to create events"})
+			return
+		}
+		for _, block := range blocks {
+			eventID, err := api.createPlannerEvent(userID, accountID, calendarID, block)
+			if err != nil {
+				api.Logger.Error().Err(err).Msg("failed to create planned event")
+				// the blocks created so far are returned so a retry can leave out their tasks
+				c.JSON(503, gin.H{"detail": "failed to create planned events", "blocks": result.Blocks})
+				return
+			}
+			result.Blocks = append(result.Blocks, plannerBlockToResult(block, eventID))
+		}
+		c.JSON(201, result)
+		return
+	}
+	for _, block := range blocks {
+		result.Blocks = append(result.Blocks, plannerBlockToResult(block, primitive.NilObjectID))
+	}
+	c.JSON(200, result)
+}
+
+// getPlannerLocation prefers the user's stored timezone, which follows daylight saving time,
+// so that working hours don't shift by an hour for days after a change in offset
+func (api *API) getPlannerLocation(userID primitive.ObjectID, timezoneOffset time.Duration) *time.Location {
+	location, err := database.GetUserLocation(api.DB, userID)
+	if err != nil || location == nil {
+		return time.FixedZone("", int(-1*timezoneOffset.Seconds()))
+	}
+	return location
+}
+
+// getPlannerBusyRanges returns the user's events across all calendar accounts, ignoring calendars the user can only read
+func (api *API) getPlannerBusyRanges(userID primitive.ObjectID, datetimeStart time.Time, datetimeEnd time.Time) ([]plannerRange, error) {
+	events, err := database.GetCalendarEvents(api.DB, userID, &[]bson.M{
+		{"datetime_start": bson.M{"$lt": primitive.NewDateTimeFromTime(datetimeEnd)}},
+		{"datetime_end": bson.M{"$gt": primitive.NewDateTimeFromTime(datetimeStart)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	calendarAccounts, err := database.GetCalendarAccounts(api.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	calendarToAccessRole := createCalendarToAccessRoleMap(calendarAccounts)
+	busyRanges := []plannerRange{}
+	for _, event := range *events {
+		accessRole, ok := calendarToAccessRole[calendarKey{event.SourceAccountID, event.CalendarID}]
+		if ok && accessRole != constants.AccessControlOwner && accessRole != "writer" {
+			continue
+		}
+		busyRanges = append(busyRanges, plannerRange{start: event.DatetimeStart.Time(), end: event.DatetimeEnd.Time()})
+	}
+	return busyRanges, nil
+}
+
+func (api *API) createPlannerEvent(userID primitive.ObjectID, accountID string, calendarID string, block plannerBlock) (primitive.ObjectID, error) {
+	taskSourceResult, err := api.ExternalConfig.GetSourceResult(external.TASK_SOURCE_ID_GCAL)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	// generate ID for event so we can use this when inserting into database
+	externalEventID := primitive.NewObjectID()
+	title := ""
+	if block.task.Title != nil {
+		title = *block.task.Title
+	}
+	eventCreateObject := external.EventCreateObject{
+		ID:            externalEventID,
+		AccountID:     accountID,
+		CalendarID:    calendarID,
+		Summary:       title,
+		DatetimeStart: &block.start,
+		DatetimeEnd:   &block.end,
+		LinkedTaskID:  block.task.ID,
+	}
+	err = taskSourceResult.Source.CreateNewEvent(api.DB, userID, accountID, eventCreateObject)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	event, err := database.UpdateOrCreateCalendarEvent(
+		api.DB,
+		userID,
+		externalEventID.Hex(),
+		external.TASK_SOURCE_ID_GCAL,
+		database.CalendarEvent{
+			UserID:          userID,
+			IDExternal:      externalEventID.Hex(),
+			SourceID:        external.TASK_SOURCE_ID_GCAL,
+			SourceAccountID: accountID,
+			CalendarID:      calendarID,
+			Title:           title,
+			DatetimeStart:   primitive.NewDateTimeFromTime(block.start),
+			DatetimeEnd:     primitive.NewDateTimeFromTime(block.end),
+			LinkedTaskID:    block.task.ID,
+			LinkedSourceID:  block.task.SourceID,
+		},
+		nil,
+	)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return event.ID, nil
+}
+
+func getPlannerSettingValue(userSettings []database.UserSetting, fieldKey string) string {
+	for _, userSetting := range userSettings {
+		if userSetting.FieldKey == fieldKey {
+			return userSetting.FieldValue
+		}
+	}
+	return ""
+}
+
+func getPlannerWorkingHours(userSettings []database.UserSetting) plannerWorkingHours {
+	startHour, err := strconv.Atoi(settings.GetSettingValue(userSettings, settings.PlannerWorkingHoursStartSetting))
+	if err != nil {
+		startHour, _ = strconv.Atoi(settings.PlannerWorkingHoursStartSetting.DefaultChoice)
+	}
+	endHour, err := strconv.Atoi(settings.GetSettingValue(userSettings, settings.PlannerWorkingHoursEndSetting))
+	if err != nil {
+		endHour, _ = strconv.Atoi(settings.PlannerWorkingHoursEndSetting.DefaultChoice)
+	}
+	return plannerWorkingHours{
+		startHour:       startHour,
+		endHour:         endHour,
+		includeWeekends: settings.GetSettingValue(userSettings, settings.PlannerIncludeWeekendsSetting) == "true",
+	}
+}
+
+// getPlannerWorkingRanges returns the working hours of each day between start and end, in the timezone of start
+func getPlannerWorkingRanges(datetimeStart time.Time, datetimeEnd time.Time, workingHours plannerWorkingHours) []plannerRange {
+	workingRanges := []plannerRange{}
+	if workingHours.endHour <= workingHours.startHour {
+		return workingRanges
+	}
+	location := datetimeStart.Location()
+	for day := time.Date(datetimeStart.Year(), datetimeStart.Month(), datetimeStart.Day(), 0, 0, 0, 0, location); day.Before(datetimeEnd); day = day.AddDate(0, 0, 1) {
+		if !workingHours.includeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), workingHours.startHour, 0, 0, 0, location)
+		end := time.Date(day.Year(), day.Month(), day.Day(), workingHours.endHour, 0, 0, 0, location)
+		if start.Before(datetimeStart) {
+			start = datetimeStart
+		}
+		if end.After(datetimeEnd) {
+			end = datetimeEnd
+		}
+		if end.After(start) {
+			workingRanges = append(workingRanges, plannerRange{start: start, end: end})
+		}
+	}
+	return workingRanges
+}
+
+// getPlannerFreeRanges removes the busy ranges from the working ranges, with free ranges starting on the block granularity
+func getPlannerFreeRanges(workingRanges []plannerRange, busyRanges []plannerRange) []plannerRange {
+	sortedBusyRanges := append([]plannerRange{}, busyRanges...)
+	sort.Slice(sortedBusyRanges, func(i, j int) bool {
+		return sortedBusyRanges[i].start.Before(sortedBusyRanges[j].start)
+	})
+	freeRanges := []plannerRange{}
+	for _, workingRange := range workingRanges {
+		start := workingRange.start
+		for _, busyRange := range sortedBusyRanges {
+			if !busyRange.end.After(start) {
+				continue
+			}
+			if !busyRange.start.Before(workingRange.end) {
+				break
+			}
+			freeRanges = appendPlannerFreeRange(freeRanges, start, busyRange.start)
+			start = busyRange.end
+		}
+		freeRanges = appendPlannerFreeRange(freeRanges, start, workingRange.end)
+	}
+	return freeRanges
+}
+
+func appendPlannerFreeRange(freeRanges []plannerRange, start time.Time, end time.Time) []plannerRange {
+	// round up in the range's timezone, so blocks start on the quarter hour in half hour timezones too
+	_, offset := start.Zone()
+	offsetDuration := time.Duration(offset) * time.Second
+	roundedStart := start.Add(offsetDuration).Truncate(PLANNER_BLOCK_GRANULARITY).Add(-offsetDuration)
+	if roundedStart.Before(start) {
+		roundedStart = roundedStart.Add(PLANNER_BLOCK_GRANULARITY)
+	}
+	if end.After(roundedStart) {
+		freeRanges = append(freeRanges, plannerRange{start: roundedStart, end: end})
+	}
+	return freeRanges
+}
+
+// planTaskBlocks places each task in the earliest free range that fits it, starting with the tasks due first
+// and then the highest priority tasks. Tasks aren't split across ranges.
+func planTaskBlocks(tasks []database.Task, freeRanges []plannerRange, location *time.Location) ([]plannerBlock, []PlannerUnscheduledTask) {
+	sortedTasks := append([]database.Task{}, tasks...)
+	sort.SliceStable(sortedTasks, func(i, j int) bool {
+		dueDateI, hasDueDateI := getPlannerDeadline(sortedTasks[i], location)
+		dueDateJ, hasDueDateJ := getPlannerDeadline(sortedTasks[j], location)
+		if hasDueDateI != hasDueDateJ {
+			return hasDueDateI
+		}
+		if hasDueDateI && !dueDateI.Equal(dueDateJ) {
+			return dueDateI.Before(dueDateJ)
+		}
+		return getPlannerPriority(sortedTasks[i]) < getPlannerPriority(sortedTasks[j])
+	})
+
+	remainingRanges := append([]plannerRange{}, freeRanges...)
+	blocks := []plannerBlock{}
+	unscheduled := []PlannerUnscheduledTask{}
+	for _, task := range sortedTasks {
+		title := ""
+		if task.Title != nil {
+			title = *task.Title
+		}
+		if task.IsCompleted != nil && *task.IsCompleted {
+			unscheduled = append(unscheduled, PlannerUnscheduledTask{TaskID: task.ID, Title: title, Reason: PlannerUnscheduledCompleted})
+			continue
+		}
+		if task.TimeAllocation == nil || *task.TimeAllocation <= 0 {
+			unscheduled = append(unscheduled, PlannerUnscheduledTask{TaskID: task.ID, Title: title, Reason: PlannerUnscheduledNoTimeAllocation})
+			continue
+		}
+		duration := time.Duration(*task.TimeAllocation)
+		deadline, hasDeadline := getPlannerDeadline(task, location)
+
+		reason := PlannerUnscheduledNoFreeSlot
+		scheduled := false
+		for index, freeRange := range remainingRanges {
+			if freeRange.end.Sub(freeRange.start) < duration {
+				continue
+			}
+			end := freeRange.start.Add(duration)
+			if hasDeadline && end.After(deadline) {
+				reason = PlannerUnscheduledPastDueDate
+				break
+			}
+			blocks = append(blocks, plannerBlock{task: task, start: freeRange.start, end: end})
+			updatedRanges := appendPlannerFreeRange(append([]plannerRange{}, remainingRanges[:index]...), end, freeRange.end)
+			remainingRanges = append(updatedRanges, remainingRanges[index+1:]...)
+			scheduled = true
+			break
+		}
+		if !scheduled {
+			unscheduled = append(unscheduled, PlannerUnscheduledTask{TaskID: task.ID, Title: title, Reason: reason})
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].start.Before(blocks[j].start)
+	})
+	return blocks, unscheduled
+}
+
+// getPlannerDeadline returns when the task must be finished by. Due dates without a time are stored at midnight UTC,
+// and can be worked on until the end of that day in the user's timezone.
+func getPlannerDeadline(task database.Task, location *time.Location) (time.Time, bool) {
+	// due dates before 1971 are treated as empty
+	if task.DueDate == nil || task.DueDate.Time().UTC().Year() <= 1971 {
+		return time.Time{}, false
+	}
+	dueDate := task.DueDate.Time().UTC()
+	if dueDate.Hour() == 0 && dueDate.Minute() == 0 && dueDate.Second() == 0 {
+		return time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day()+1, 0, 0, 0, 0, location), true
+	}
+	return dueDate, true
+}
+
+// getPlannerPriority orders by priority, where 1 is the highest and tasks without a priority come last
+func getPlannerPriority(task database.Task) float64 {
+	if task.PriorityNormalized == nil || *task.PriorityNormalized <= 0 {
+		return math.MaxFloat64
+	}
+	return *task.PriorityNormalized
+}
+
+func plannerBlockToResult(block plannerBlock, eventID primitive.ObjectID) PlannerBlock {
+	title := ""
+	if block.task.Title != nil {
+		title = *block.task.Title
+	}
+	return PlannerBlock{
+		TaskID:        block.task.ID,
+		EventID:       eventID,
+		Title:         title,
+		DatetimeStart: block.start.Format(time.RFC3339),
+		DatetimeEnd:   block.end.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/testutils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetPlannerWorkingRanges(t *testing.T) {
+	location := time.FixedZone("", -7*60*60)
+	workingHours := plannerWorkingHours{startHour: 9, endHour: 17}
+	// Friday afternoon until Tuesday morning
+	datetimeStart := time.Date(2023, time.March, 3, 13, 10, 0, 0, location)
+	datetimeEnd := time.Date(2023, time.March, 7, 10, 0, 0, 0, location)
+
+	t.Run("Weekdays", func(t *testing.T) {
+		assert.Equal(t, []plannerRange{
+			{start: datetimeStart, end: time.Date(2023, time.March, 3, 17, 0, 0, 0, location)},
+			{start: time.Date(2023, time.March, 6, 9, 0, 0, 0, location), end: time.Date(2023, time.March, 6, 17, 0, 0, 0, location)},
+			{start: time.Date(2023, time.March, 7, 9, 0, 0, 0, location), end: datetimeEnd},
+		}, getPlannerWorkingRanges(datetimeStart, datetimeEnd, workingHours))
+	})
+	t.Run("IncludeWeekends", func(t *testing.T) {
+		workingHours := workingHours
+		workingHours.includeWeekends = true
+		assert.Equal(t, 5, len(getPlannerWorkingRanges(datetimeStart, datetimeEnd, workingHours)))
+	})
+	t.Run("InvalidHours", func(t *testing.T) {
+		assert.Equal(t, []plannerRange{}, getPlannerWorkingRanges(datetimeStart, datetimeEnd, plannerWorkingHours{startHour: 17, endHour: 9}))
+	})
+	t.Run("DaylightSavingTime", func(t *testing.T) {
+		location, err := time.LoadLocation("America/Los_Angeles")
+		assert.NoError(t, err)
+		workingHours := workingHours
+		workingHours.includeWeekends = true
+		// clocks go forward on Sunday March 12th
+		workingRanges := getPlannerWorkingRanges(time.Date(2023, time.March, 11, 0, 0, 0, 0, location), time.Date(2023, time.March, 14, 0, 0, 0, 0, location), workingHours)
+		assert.Equal(t, 3, len(workingRanges))
+		for _, workingRange := range workingRanges {
+			assert.Equal(t, 9, workingRange.start.Hour())
+			assert.Equal(t, 17, workingRange.end.Hour())
+		}
+		assert.Equal(t, time.Date(2023, time.March, 13, 16, 0, 0, 0, time.UTC), workingRanges[2].start.UTC())
+	})
+}
+
+func TestGetPlannerFreeRanges(t *testing.T) {
+	day := time.Date(2023, time.March, 6, 0, 0, 0, 0, time.UTC)
+	at := func(hour int, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	workingRanges := []plannerRange{{start: at(9, 0), end: at(17, 0)}}
+
+	t.Run("NoEvents", func(t *testing.T) {
+		assert.Equal(t, workingRanges, getPlannerFreeRanges(workingRanges, []plannerRange{}))
+	})
+	t.Run("OverlappingEvents", func(t *testing.T) {
+		assert.Equal(t, []plannerRange{
+			{start: at(9, 0), end: at(10, 0)},
+			{start: at(11, 45), end: at(13, 0)},
+			{start: at(14, 0), end: at(17, 0)},
+		}, getPlannerFreeRanges(workingRanges, []plannerRange{
+			{start: at(13, 0), end: at(14, 0)},
+			{start: at(10, 30), end: at(11, 40)},
+			{start: at(10, 0), end: at(11, 0)},
+			{start: at(8, 0), end: at(8, 30)},
+		}))
+	})
+	t.Run("EventCoversWorkingHours", func(t *testing.T) {
+		assert.Equal(t, []plannerRange{}, getPlannerFreeRanges(workingRanges, []plannerRange{{start: at(8, 0), end: at(18, 0)}}))
+	})
+	t.Run("HalfHourTimezone", func(t *testing.T) {
+		location := time.FixedZone("", 5*60*60+30*60)
+		start := time.Date(2023, time.March, 6, 9, 5, 0, 0, location)
+		end := time.Date(2023, time.March, 6, 10, 0, 0, 0, location)
+		assert.Equal(t, []plannerRange{
+			{start: time.Date(2023, time.March, 6, 9, 15, 0, 0, location), end: end},
+		}, getPlannerFreeRanges([]plannerRange{{start: start, end: end}}, []plannerRange{}))
+	})
+}
+
+func TestPlanTaskBlocks(t *testing.T) {
+	day := time.Date(2023, time.March, 6, 0, 0, 0, 0, time.UTC)
+	at := func(dayOffset int, hour int) time.Time {
+		return day.AddDate(0, 0, dayOffset).Add(time.Duration(hour) * time.Hour)
+	}
+	freeRanges := []plannerRange{
+		{start: at(0, 9), end: at(0, 10)},
+		{start: at(0, 13), end: at(0, 17)},
+		{start: at(1, 9), end: at(1, 17)},
+	}
+	newTask := func(title string, hours int64, dueDate *time.Time, priority float64) database.Task {
+		timeAllocation := hours * time.Hour.Nanoseconds()
+		task := database.Task{ID: primitive.NewObjectID(), Title: &title, TimeAllocation: &timeAllocation, PriorityNormalized: &priority}
+		if dueDate != nil {
+			primitiveDueDate := primitive.NewDateTimeFromTime(*dueDate)
+			task.DueDate = &primitiveDueDate
+		}
+		return task
+	}
+	// due dates without a time can be worked on until the end of the day
+	mondayDate := at(0, 0)
+	tuesdayDate := at(1, 0)
+	lowPriority := newTask("low priority", 1, nil, 4)
+	noPriority := newTask("no priority", 1, nil, 0)
+	urgent := newTask("urgent", 1, nil, 1)
+	dueTuesday := newTask("due tuesday", 3, &tuesdayDate, 4)
+	dueMonday := newTask("due monday", 5, &mondayDate, 1)
+	tooLong := newTask("too long", 9, nil, 1)
+	completed := true
+	completedTask := newTask("completed", 1, nil, 1)
+	completedTask.IsCompleted = &completed
+	noTimeAllocation := newTask("no time allocation", 1, nil, 1)
+	noTimeAllocation.TimeAllocation = nil
+
+	blocks, unscheduled := planTaskBlocks([]database.Task{lowPriority, noPriority, urgent, dueTuesday, dueMonday, tooLong, completedTask, noTimeAllocation}, freeRanges, time.UTC)
+	assert.Equal(t, []plannerBlock{
+		{task: urgent, start: at(0, 9), end: at(0, 10)},
+		{task: dueTuesday, start: at(0, 13), end: at(0, 16)},
+		{task: lowPriority, start: at(0, 16), end: at(0, 17)},
+		{task: noPriority, start: at(1, 9), end: at(1, 10)},
+	}, blocks)
+	assert.Equal(t, []PlannerUnscheduledTask{
+		{TaskID: dueMonday.ID, Title: "due monday", Reason: PlannerUnscheduledPastDueDate},
+		{TaskID: tooLong.ID, Title: "too long", Reason: PlannerUnscheduledNoFreeSlot},
+		{TaskID: completedTask.ID, Title: "completed", Reason: PlannerUnscheduledCompleted},
+		{TaskID: noTimeAllocation.ID, Title: "no time allocation", Reason: PlannerUnscheduledNoTimeAllocation},
+	}, unscheduled)
+}
+
+func TestPlanner(t *testing.T) {
+	authToken := login("test_planner@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+	// Monday at 10am in UTC-7
+	testTime := time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)
+	api.OverrideTime = &testTime
+	calendarCreateServer := testutils.GetMockAPIServer(t, 200, "{}")
+	api.ExternalConfig.GoogleOverrideURLs.CalendarCreateURL = &calendarCreateServer.URL
+	router := GetRouter(api)
+
+	_, err := database.UpdateOrCreateCalendarAccount(api.DB, userID, "planner_account", "gcal",
+		&database.CalendarAccount{
+			UserID:     userID,
+			IDExternal: "planner_account",
+			Calendars: []database.Calendar{
+				{AccessRole: constants.AccessControlOwner, CalendarID: "planner_calendar"},
+				{AccessRole: constants.AccessControlReader, CalendarID: "holidays_calendar"},
+			},
+		}, nil)
+	assert.NoError(t, err)
+	eventCollection := database.GetCalendarEventCollection(api.DB)
+	for _, event := range []database.CalendarEvent{
+		{UserID: userID, SourceAccountID: "planner_account", CalendarID: "planner_calendar", DatetimeStart: primitive.NewDateTimeFromTime(testTime), DatetimeEnd: primitive.NewDateTimeFromTime(testTime.Add(2 * time.Hour))},
+		{UserID: userID, SourceAccountID: "planner_account", CalendarID: "holidays_calendar", DatetimeStart: primitive.NewDateTimeFromTime(testTime.Add(2 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(testTime.Add(4 * time.Hour))},
+	} {
+		_, err = eventCollection.InsertOne(context.Background(), event)
+		assert.NoError(t, err)
+	}
+	title := "write design doc"
+	timeAllocation := (2 * time.Hour).Nanoseconds()
+	taskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &title, TimeAllocation: &timeAllocation, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+
+	plan := func(body string, expectedResponseCode int) PlannerResult {
+		request, _ := http.NewRequest("POST", "/planner/", bytes.NewBuffer([]byte(body)))
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		request.Header.Set("Timezone-Offset", "420")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, expectedResponseCode, recorder.Code)
+		responseBody, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		var result PlannerResult
+		json.Unmarshal(responseBody, &result)
+		return result
+	}
+
+	UnauthorizedTest(t, "POST", "/planner/", nil)
+	t.Run("MissingTasks", func(t *testing.T) {
+		plan(`{"task_ids": []}`, http.StatusBadRequest)
+	})
+	t.Run("TaskNotFound", func(t *testing.T) {
+		plan(fmt.Sprintf(`{"task_ids": ["%s"]}`, primitive.NewObjectID().Hex()), http.StatusNotFound)
+	})
+	t.Run("Propose", func(t *testing.T) {
+		result := plan(fmt.Sprintf(`{"task_ids": ["%s"]}`, taskID), http.StatusOK)
+		assert.Equal(t, 1, len(result.Blocks))
+		assert.Equal(t, taskID, result.Blocks[0].TaskID.Hex())
+		assert.Equal(t, primitive.NilObjectID, result.Blocks[0].EventID)
+		// the event on the read-only calendar doesn't block time
+		assert.Equal(t, "2023-03-06T12:00:00-07:00", result.Blocks[0].DatetimeStart)
+		assert.Equal(t, "2023-03-06T14:00:00-07:00", result.Blocks[0].DatetimeEnd)
+		assert.Equal(t, []PlannerUnscheduledTask{}, result.Unscheduled)
+	})
+	t.Run("ConfirmWithoutAccount", func(t *testing.T) {
+		plan(fmt.Sprintf(`{"task_ids": ["%s"], "confirm": true}`, taskID), http.StatusBadRequest)
+	})
+	t.Run("Confirm", func(t *testing.T) {
+		result := plan(fmt.Sprintf(`{"task_ids": ["%s"], "confirm": true, "account_id": "planner_account", "calendar_id": "planner_calendar"}`, taskID), http.StatusCreated)
+		assert.Equal(t, 1, len(result.Blocks))
+		event, err := database.GetCalendarEvent(api.DB, result.Blocks[0].EventID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, title, event.Title)
+		assert.Equal(t, taskID, event.LinkedTaskID.Hex())
+		assert.Equal(t, time.Date(2023, time.March, 6, 19, 0, 0, 0, time.UTC), event.DatetimeStart.Time().UTC())
+
+		// the created block is now busy
+		result = plan(fmt.Sprintf(`{"task_ids": ["%s"]}`, taskID), http.StatusOK)
+		assert.Equal(t, "2023-03-06T14:00:00-07:00", result.Blocks[0].DatetimeStart)
+	})
+	t.Run("ConfirmPartialFailure", func(t *testing.T) {
+		// the first event is created, and the second fails
+		requestCount := 0
+		failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			if requestCount > 1 {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			w.Write([]byte("{}"))
+		}))
+		defer failingServer.Close()
+		api.ExternalConfig.GoogleOverrideURLs.CalendarCreateURL = &failingServer.URL
+		defer func() { api.ExternalConfig.GoogleOverrideURLs.CalendarCreateURL = &calendarCreateServer.URL }()
+		otherTitle := "review design doc"
+		otherTaskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &otherTitle, TimeAllocation: &timeAllocation, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+
+		result := plan(fmt.Sprintf(`{"task_ids": ["%s", "%s"], "confirm": true, "account_id": "planner_account", "calendar_id": "planner_calendar"}`, taskID, otherTaskID), http.StatusServiceUnavailable)
+		assert.Equal(t, 1, len(result.Blocks))
+		_, err := database.GetCalendarEvent(api.DB, result.Blocks[0].EventID, userID)
+		assert.NoError(t, err)
+	})
+}
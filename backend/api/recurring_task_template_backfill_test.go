@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/jobs"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -29,7 +30,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		title := "hello!"
 		enabled := true
 		deleted := false
-		recurrenceRate := Daily
+		recurrenceRate := jobs.Daily
 
 		// 10:00:10
 		creationTimeSeconds := 60*60*10 + 60*0 + 10
@@ -74,7 +75,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		title := "hello!"
 		enabled := true
 		deleted := false
-		recurrenceRate := WeekDaily
+		recurrenceRate := jobs.WeekDaily
 
 		// 11:00:10
 		creationTimeSeconds := 60*60*11 + 60*0 + 10
@@ -119,7 +120,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		title := "hello!"
 		enabled := true
 		deleted := false
-		recurrenceRate := Weekly
+		recurrenceRate := jobs.Weekly
 		// 10:00:10
 		creationTimeSeconds := 60*60*10 + 60*0 + 10
 		creationDay := int(time.Monday)
@@ -166,7 +167,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		enabled := true
 		deleted := false
 		replace := true
-		recurrenceRate := Weekly
+		recurrenceRate := jobs.Weekly
 		// 10:00:10
 		creationTimeSeconds := 60*60*10 + 60*0 + 10
 		creationDay := int(time.Monday)
@@ -213,7 +214,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		title := "hello!"
 		enabled := true
 		deleted := false
-		recurrenceRate := Monthly
+		recurrenceRate := jobs.Monthly
 		// 10:00:10
 		creationTimeSeconds := 60*60*10 + 60*0 + 10
 		creationDay := 14
@@ -258,7 +259,7 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		title := "hello!"
 		enabled := true
 		deleted := false
-		recurrenceRate := Annually
+		recurrenceRate := jobs.Annually
 		// 10:00:10
 		creationTimeSeconds := 60*60*10 + 60*0 + 10
 		creationDay := 14
@@ -294,6 +295,53 @@ func TestRecurringTaskTemplateBackfill(t *testing.T) {
 		router.ServeHTTP(recorder, request)
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
+		tasks, err := database.GetActiveTasks(api.DB, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, len(*tasks))
+		assert.Equal(t, templateID, (*tasks)[5].RecurringTaskTemplateID)
+	})
+	t.Run("RecurrenceRuleTest", func(t *testing.T) {
+		authToken := login("rrule_recur@resonant-kelpie-404a42.netlify.app", "")
+		userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+		title := "hello!"
+		enabled := true
+		deleted := false
+		// 1st and 15th of the month, except November 1st
+		recurrenceRule := "FREQ=MONTHLY;BYMONTHDAY=1,15"
+		exceptionDates := []string{"20221101"}
+		// 10:00:10
+		creationTimeSeconds := 60*60*10 + 60*0 + 10
+
+		// October 10 (October 15 and November 15 should trigger)
+		lastBackfillTime := time.Date(2022, time.October, 10, 9, 0, 0, 0, time.UTC)
+
+		templateCollection := database.GetRecurringTaskTemplateCollection(api.DB)
+		insertResult, err := templateCollection.InsertOne(context.Background(), database.RecurringTaskTemplate{
+			UserID:                       userID,
+			Title:                        &title,
+			IsEnabled:                    &enabled,
+			IsDeleted:                    &deleted,
+			RecurrenceRule:               &recurrenceRule,
+			ExceptionDates:               &exceptionDates,
+			TimeOfDaySecondsToCreateTask: &creationTimeSeconds,
+			LastBackfillDatetime:         primitive.NewDateTimeFromTime(lastBackfillTime),
+			CreatedAt:                    primitive.NewDateTimeFromTime(lastBackfillTime),
+		})
+		templateID := insertResult.InsertedID.(primitive.ObjectID)
+		assert.NoError(t, err)
+
+		request, _ := http.NewRequest(
+			"GET",
+			"/recurring_task_templates/backfill_tasks/",
+			nil,
+		)
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		request.Header.Set("Timezone-Offset", "0")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
 		tasks, err := database.GetActiveTasks(api.DB, userID)
 		assert.NoError(t, err)
 		assert.Equal(t, 7, len(*tasks))
@@ -2,22 +2,26 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type RecurringTaskTemplateCreateParams struct {
-	Title                        *string  `json:"title,omitempty" binding:"required"`
-	Body                         *string  `json:"body,omitempty"`
-	IDTaskSection                *string  `json:"id_task_section,omitempty"`
-	PriorityNormalized           *float64 `json:"priority_normalized,omitempty"`
-	RecurrenceRate               *int     `json:"recurrence_rate,omitempty" binding:"required"`
-	TimeOfDaySecondsToCreateTask *int     `json:"time_of_day_seconds_to_create_task,omitempty" binding:"required"`
-	DayToCreateTask              *int     `json:"day_to_create_task,omitempty"`
-	MonthToCreateTask            *int     `json:"month_to_create_task,omitempty"`
-	ReplaceExisting              *bool    `json:"replace_existing,omitempty"`
+	Title                        *string   `json:"title,omitempty" binding:"required"`
+	Body                         *string   `json:"body,omitempty"`
+	IDTaskSection                *string   `json:"id_task_section,omitempty"`
+	PriorityNormalized           *float64  `json:"priority_normalized,omitempty"`
+	RecurrenceRate               *int      `json:"recurrence_rate,omitempty"`
+	TimeOfDaySecondsToCreateTask *int      `json:"time_of_day_seconds_to_create_task,omitempty" binding:"required"`
+	DayToCreateTask              *int      `json:"day_to_create_task,omitempty"`
+	MonthToCreateTask            *int      `json:"month_to_create_task,omitempty"`
+	RecurrenceRule               *string   `json:"recurrence_rule,omitempty"`
+	ExceptionDates               *[]string `json:"exception_dates,omitempty"`
+	ReplaceExisting              *bool     `json:"replace_existing,omitempty"`
 }
 
 func (api *API) RecurringTaskTemplateCreate(c *gin.Context) {
@@ -28,6 +32,17 @@ func (api *API) RecurringTaskTemplateCreate(c *gin.Context) {
 		return
 	}
 
+	// either a preset recurrence rate or a recurrence rule is required
+	if templateCreateParams.RecurrenceRate == nil && (templateCreateParams.RecurrenceRule == nil || *templateCreateParams.RecurrenceRule == "") {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	err = validateTemplateRecurrence(templateCreateParams.RecurrenceRule, templateCreateParams.ExceptionDates)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
+
 	userID := getUserIDFromContext(c)
 
 	var taskSection primitive.ObjectID
@@ -54,6 +69,8 @@ func (api *API) RecurringTaskTemplateCreate(c *gin.Context) {
 		TimeOfDaySecondsToCreateTask: templateCreateParams.TimeOfDaySecondsToCreateTask,
 		DayToCreateTask:              templateCreateParams.DayToCreateTask,
 		MonthToCreateTask:            templateCreateParams.MonthToCreateTask,
+		RecurrenceRule:               templateCreateParams.RecurrenceRule,
+		ExceptionDates:               templateCreateParams.ExceptionDates,
 		LastBackfillDatetime:         primitive.NewDateTimeFromTime(api.GetCurrentTime()),
 		CreatedAt:                    primitive.NewDateTimeFromTime(api.GetCurrentTime()),
 		UpdatedAt:                    primitive.NewDateTimeFromTime(api.GetCurrentTime()),
@@ -69,3 +86,19 @@ func (api *API) RecurringTaskTemplateCreate(c *gin.Context) {
 
 	c.JSON(200, gin.H{"template_id": insertID.InsertedID.(primitive.ObjectID)})
 }
+
+func validateTemplateRecurrence(recurrenceRule *string, exceptionDates *[]string) error {
+	if recurrenceRule != nil && *recurrenceRule != "" {
+		_, err := external.ParseRRule(*recurrenceRule)
+		if err != nil {
+			return err
+		}
+	}
+	if exceptionDates != nil {
+		_, err := external.ParseRecurrenceExceptionDates(*exceptionDates, time.UTC)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
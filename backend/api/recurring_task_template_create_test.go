@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -85,4 +86,48 @@ func TestRecurringTaskTemplateCreate(t *testing.T) {
 		assert.Equal(t, "hello!", *(templates[0].Title))
 		assert.Equal(t, primitive.NewDateTimeFromTime(currentTime), templates[0].CreatedAt)
 	})
+	t.Run("InvalidRecurrenceRule", func(t *testing.T) {
+		request, _ := http.NewRequest(
+			"POST",
+			"/recurring_task_templates/create/",
+			bytes.NewBuffer([]byte(`{"title": "hello!", "recurrence_rule": "FREQ=HOURLY", "time_of_day_seconds_to_create_task": 0}`)),
+		)
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"detail":"unsupported recurrence frequency: HOURLY"}`, string(body))
+	})
+	t.Run("InvalidExceptionDates", func(t *testing.T) {
+		request, _ := http.NewRequest(
+			"POST",
+			"/recurring_task_templates/create/",
+			bytes.NewBuffer([]byte(`{"title": "hello!", "recurrence_rule": "FREQ=DAILY", "exception_dates": ["tomorrow"], "time_of_day_seconds_to_create_task": 0}`)),
+		)
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("SuccessRecurrenceRule", func(t *testing.T) {
+		request, _ := http.NewRequest(
+			"POST",
+			"/recurring_task_templates/create/",
+			bytes.NewBuffer([]byte(`{"title": "every other tuesday", "recurrence_rule": "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", "exception_dates": ["20230418"], "time_of_day_seconds_to_create_task": 0}`)),
+		)
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var templates []database.RecurringTaskTemplate
+		err = database.FindWithCollection(database.GetRecurringTaskTemplateCollection(api.DB), userID, &[]bson.M{{"title": "every other tuesday"}}, &templates, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(templates))
+		assert.Nil(t, templates[0].RecurrenceRate)
+		assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", *templates[0].RecurrenceRule)
+		assert.Equal(t, []string{"20230418"}, *templates[0].ExceptionDates)
+	})
 }
@@ -19,9 +19,12 @@ type RecurringTaskTemplateModifyParams struct {
 	TimeOfDaySecondsToCreateTask *int     `json:"time_of_day_seconds_to_create_task,omitempty"`
 	DayToCreateTask              *int     `json:"day_to_create_task,omitempty"`
 	MonthToCreateTask            *int     `json:"month_to_create_task,omitempty"`
-	IsEnabled                    *bool    `json:"is_enabled,omitempty"`
-	IsDeleted                    *bool    `json:"is_deleted,omitempty"`
-	ReplaceExisting              *bool    `json:"replace_existing,omitempty"`
+	// an empty recurrence rule switches the template back to its recurrence rate
+	RecurrenceRule  *string   `json:"recurrence_rule,omitempty"`
+	ExceptionDates  *[]string `json:"exception_dates,omitempty"`
+	IsEnabled       *bool     `json:"is_enabled,omitempty"`
+	IsDeleted       *bool     `json:"is_deleted,omitempty"`
+	ReplaceExisting *bool     `json:"replace_existing,omitempty"`
 }
 
 func (api *API) RecurringTaskTemplateModify(c *gin.Context) {
@@ -64,6 +67,11 @@ func (api *API) RecurringTaskTemplateModify(c *gin.Context) {
 		c.JSON(400, gin.H{"detail": "template changes missing"})
 		return
 	}
+	err = validateTemplateRecurrence(modifyParams.RecurrenceRule, modifyParams.ExceptionDates)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": err.Error()})
+		return
+	}
 
 	updateTemplate := database.RecurringTaskTemplate{
 		Title:                        modifyParams.Title,
@@ -75,6 +83,8 @@ func (api *API) RecurringTaskTemplateModify(c *gin.Context) {
 		TimeOfDaySecondsToCreateTask: modifyParams.TimeOfDaySecondsToCreateTask,
 		DayToCreateTask:              modifyParams.DayToCreateTask,
 		MonthToCreateTask:            modifyParams.MonthToCreateTask,
+		RecurrenceRule:               modifyParams.RecurrenceRule,
+		ExceptionDates:               modifyParams.ExceptionDates,
 		IDTaskSection:                taskSection,
 		ReplaceExisting:              modifyParams.ReplaceExisting,
 		UpdatedAt:                    primitive.NewDateTimeFromTime(api.GetCurrentTime()),
@@ -45,12 +45,19 @@ func GetRouter(handlers *API) *gin.Engine {
 	router.POST("/tasks/create_external/slack/", handlers.SlackTaskCreate)
 
 	router.POST("/linear/webhook/", handlers.LinearWebhook)
+	router.POST("/github/webhook/", handlers.GithubWebhook)
+	router.POST("/jira/webhook/", handlers.JIRAWebhook)
+	router.POST("/asana/webhook/", handlers.AsanaWebhook)
+	router.POST("/google/calendar/webhook/", handlers.GoogleCalendarWebhook)
 
 	// Slack App (Workspace level) endpoint for oauth verification
 	// We need this as we don't actually use the token provided, but still need to access it to
 	// successfully install our app in a new Workspace
 	router.GET("/link_app/slack/", handlers.LinkSlackApp)
 
+	// calendar feeds are fetched by calendar clients, so the secret token in the URL identifies the user
+	router.GET("/calendar_feed/ics/:feed_token/", handlers.CalendarFeed)
+
 	// logout needs to use the token directly rather than the user so no need to run token middleware
 	router.POST("/logout/", handlers.Logout)
 
@@ -72,6 +79,9 @@ func GetRouter(handlers *API) *gin.Engine {
 	// Authenticated endpoints
 	router.GET("/meeting_banner/", handlers.MeetingBanner)
 
+	router.POST("/link/:service_name/credentials/", handlers.LinkCredentials)
+	router.POST("/link/:service_name/url/", handlers.LinkFeedURL)
+
 	router.GET("/linked_accounts/", handlers.LinkedAccountsList)
 	router.GET("/linked_accounts/supported_types/", handlers.SupportedAccountTypesList)
 	router.DELETE("/linked_accounts/:account_id/", handlers.DeleteLinkedAccount)
@@ -82,6 +92,11 @@ func GetRouter(handlers *API) *gin.Engine {
 	router.GET("/events/:event_id/", handlers.EventDetail)
 	router.DELETE("/events/delete/:event_id/", handlers.EventDelete)
 	router.PATCH("/events/modify/:event_id/", handlers.EventModify)
+	router.POST("/events/rsvp/:event_id/", handlers.EventRSVP)
+	router.POST("/events/free_busy/", handlers.EventFreeBusy)
+	router.POST("/planner/", handlers.Planner)
+	router.GET("/calendar_feed/", handlers.CalendarFeedURL)
+	router.POST("/calendar_feed/reset/", handlers.CalendarFeedURLReset)
 
 	router.GET("/tasks/fetch/", handlers.TasksFetch)
 	router.GET("/tasks/v3/", handlers.TasksListV3)
@@ -90,6 +105,10 @@ func GetRouter(handlers *API) *gin.Engine {
 	router.PATCH("/tasks/modify/:task_id/", handlers.TaskModify)
 	router.GET("/tasks/detail/:task_id/", handlers.TaskDetail)
 	router.POST("/tasks/:task_id/comments/add/", handlers.TaskAddComment)
+	router.POST("/tasks/:task_id/blocked_by/", handlers.TaskDependencyAdd)
+	router.DELETE("/tasks/:task_id/blocked_by/:blocking_task_id/", handlers.TaskDependencyDelete)
+	router.POST("/tasks/:task_id/labels/", handlers.TaskLabelAdd)
+	router.DELETE("/tasks/:task_id/labels/:label_id/", handlers.TaskLabelRemove)
 
 	router.GET("/recurring_task_templates/", handlers.RecurringTaskTemplateList)
 	router.GET("/recurring_task_templates/v2/", handlers.RecurringTaskTemplateListV2)
@@ -101,6 +120,13 @@ func GetRouter(handlers *API) *gin.Engine {
 	router.PATCH("/notes/modify/:note_id/", handlers.NoteModify)
 	router.POST("/notes/create/", handlers.NoteCreate)
 
+	router.GET("/search/", handlers.Search)
+
+	router.POST("/time_entries/start/", handlers.TimeEntryStart)
+	router.POST("/time_entries/stop/", handlers.TimeEntryStop)
+	router.GET("/time_entries/running/", handlers.TimeEntryRunning)
+	router.GET("/time_entries/report/weekly/", handlers.TimeEntryWeeklyReport)
+
 	router.GET("/ping_authed/", handlers.Ping)
 
 	router.GET("/settings/", handlers.SettingsList)
@@ -112,12 +138,26 @@ func GetRouter(handlers *API) *gin.Engine {
 	router.GET("/user_info/", handlers.UserInfoGet)
 	router.PATCH("/user_info/", handlers.UserInfoUpdate)
 
+	router.GET("/personal_access_tokens/", handlers.PersonalAccessTokensList)
+	router.POST("/personal_access_tokens/", handlers.PersonalAccessTokenCreate)
+	router.DELETE("/personal_access_tokens/:token_id/", handlers.PersonalAccessTokenDelete)
+
+	router.GET("/webhooks/", handlers.WebhooksList)
+	router.POST("/webhooks/", handlers.WebhookCreate)
+	router.DELETE("/webhooks/:webhook_id/", handlers.WebhookDelete)
+	router.GET("/webhooks/:webhook_id/deliveries/", handlers.WebhookDeliveriesList)
+
 	router.GET("/sections/", handlers.SectionList)
 	router.GET("/sections/v2/", handlers.SectionListV2)
 	router.POST("/sections/create/", handlers.SectionAdd)
 	router.PATCH("/sections/modify/:section_id/", handlers.SectionModify)
 	router.DELETE("/sections/delete/:section_id/", handlers.SectionDelete)
 
+	router.GET("/labels/", handlers.LabelsList)
+	router.POST("/labels/create/", handlers.LabelCreate)
+	router.PATCH("/labels/modify/:label_id/", handlers.LabelModify)
+	router.DELETE("/labels/delete/:label_id/", handlers.LabelDelete)
+
 	// Currently frontend is using endpoint with trailing slash, so we need to support both
 	router.GET("/overview/views", handlers.OverviewViewsList)
 	router.GET("/overview/views/", handlers.OverviewViewsList)
@@ -137,6 +177,8 @@ func GetRouter(handlers *API) *gin.Engine {
 
 	router.GET("/daily_task_completion/", handlers.DailyTaskCompletionList)
 
+	router.GET("/stream/", handlers.Stream)
+
 	// Add business middleware. Endpoints below this require business mode to be enabled
 	router.Use(BusinessMiddleware(handlers.DB))
 	router.GET("/dashboard/data/", handlers.DashboardData)
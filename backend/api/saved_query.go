@@ -0,0 +1,369 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	savedQueryDateLayout   = "2006-01-02"
+	savedQuerySourceGithub = "github"
+)
+
+var savedQueryTermRegex = regexp.MustCompile(`^(-?)([a-z_]+)(:|>=|<=|>|<)(.+)$`)
+var savedQueryRelativeTimeRegex = regexp.MustCompile(`^(-?\d+)([hdw])$`)
+
+// due dates before 1972 are how sources store an empty due date, as in the due today overview
+var savedQueryEmptyDueDateCutoff = primitive.NewDateTimeFromTime(time.Unix(63090000, 0))
+
+// savedQuerySourceIDs maps the names accepted by "source:" to task source IDs
+var savedQuerySourceIDs = map[string]string{
+	"asana":        external.TASK_SOURCE_ID_ASANA,
+	"caldav":       external.TASK_SOURCE_ID_CALDAV,
+	"general_task": external.TASK_SOURCE_ID_GT_TASK,
+	"gt":           external.TASK_SOURCE_ID_GT_TASK,
+	"jira":         external.TASK_SOURCE_ID_JIRA,
+	"linear":       external.TASK_SOURCE_ID_LINEAR,
+	"slack":        external.TASK_SOURCE_ID_SLACK_SAVED,
+}
+
+var savedQueryComparisonOperators = map[string]string{
+	":":  "$eq",
+	">=": "$gte",
+	"<=": "$lte",
+	">":  "$gt",
+	"<":  "$lt",
+}
+
+type savedQueryTerm struct {
+	isNegated bool
+	key       string
+	operator  string
+	value     string
+}
+
+// savedQuery is a parsed saved search, which either matches tasks or pull requests
+type savedQuery struct {
+	isPullRequestQuery bool
+	hasStatusTerm      bool
+	filters            []bson.M
+}
+
+// tokenizeSavedQuery splits a query on whitespace, keeping double quoted values together
+func tokenizeSavedQuery(query string) ([]string, error) {
+	tokens := []string{}
+	var currentToken strings.Builder
+	isQuoted := false
+	for _, char := range query {
+		if char == '"' {
+			isQuoted = !isQuoted
+			continue
+		}
+		if !isQuoted && (char == ' ' || char == '\t' || char == '\n') {
+			if currentToken.Len() > 0 {
+				tokens = append(tokens, currentToken.String())
+				currentToken.Reset()
+			}
+			continue
+		}
+		currentToken.WriteRune(char)
+	}
+	if isQuoted {
+		return nil, errors.New("unterminated quote in query")
+	}
+	if currentToken.Len() > 0 {
+		tokens = append(tokens, currentToken.String())
+	}
+	return tokens, nil
+}
+
+func parseSavedQueryTerms(query string) ([]savedQueryTerm, error) {
+	tokens, err := tokenizeSavedQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("query cannot be empty")
+	}
+	terms := []savedQueryTerm{}
+	for _, token := range tokens {
+		matches := savedQueryTermRegex.FindStringSubmatch(token)
+		if matches == nil {
+			// bare words search the title
+			isNegated := strings.HasPrefix(token, "-") && len(token) > 1
+			terms = append(terms, savedQueryTerm{isNegated: isNegated, key: "title", operator: ":", value: strings.TrimPrefix(token, "-")})
+			continue
+		}
+		terms = append(terms, savedQueryTerm{isNegated: matches[1] == "-", key: matches[2], operator: matches[3], value: matches[4]})
+	}
+	return terms, nil
+}
+
+// parseSavedQuery converts a query like `source:linear priority>=0.5 due<7d -status:done` into MongoDB filters.
+// Relative times and dates are evaluated in the location of timeNow, and labelIDs maps lowercase label names to IDs.
+func parseSavedQuery(query string, timeNow time.Time, labelIDs map[string]primitive.ObjectID) (*savedQuery, error) {
+	terms, err := parseSavedQueryTerms(query)
+	if err != nil {
+		return nil, err
+	}
+	result := savedQuery{filters: []bson.M{}}
+	for _, term := range terms {
+		if term.key == "source" && !term.isNegated && strings.Contains(","+term.value+",", ","+savedQuerySourceGithub+",") {
+			if term.value != savedQuerySourceGithub {
+				return nil, errors.New("pull requests cannot be combined with other sources")
+			}
+			result.isPullRequestQuery = true
+		}
+	}
+
+	for _, term := range terms {
+		var filter bson.M
+		switch term.key {
+		case "source":
+			filter, err = getSavedQuerySourceFilter(term, result.isPullRequestQuery)
+		case "status":
+			result.hasStatusTerm = true
+			filter, err = getSavedQueryStatusFilter(term, result.isPullRequestQuery)
+		case "title":
+			if term.operator != ":" {
+				return nil, fmt.Errorf("'%s' only supports ':'", term.key)
+			}
+			filter = bson.M{"title": bson.M{"$regex": regexp.QuoteMeta(term.value), "$options": "i"}}
+		case "priority":
+			filter, err = getSavedQueryPriorityFilter(term, result.isPullRequestQuery)
+		case "due":
+			filter, err = getSavedQueryDueFilter(term, timeNow, result.isPullRequestQuery)
+		case "label":
+			filter, err = getSavedQueryLabelFilter(term, labelIDs, result.isPullRequestQuery)
+		case "repo":
+			if !result.isPullRequestQuery || term.operator != ":" {
+				return nil, errors.New("'repo' is only supported as 'repo:' with 'source:github'")
+			}
+			filter = bson.M{"repository_name": bson.M{"$regex": "^" + regexp.QuoteMeta(term.value) + "$", "$options": "i"}}
+		default:
+			return nil, fmt.Errorf("unsupported query term '%s'", term.key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if term.isNegated {
+			filter = bson.M{"$nor": []bson.M{filter}}
+		}
+		result.filters = append(result.filters, filter)
+	}
+	return &result, nil
+}
+
+func getSavedQuerySourceFilter(term savedQueryTerm, isPullRequestQuery bool) (bson.M, error) {
+	if term.operator != ":" {
+		return nil, errors.New("'source' only supports ':'")
+	}
+	sourceIDs := []string{}
+	for _, sourceName := range strings.Split(term.value, ",") {
+		if sourceName == savedQuerySourceGithub {
+			sourceIDs = append(sourceIDs, external.TASK_SOURCE_ID_GITHUB_PR)
+			continue
+		}
+		sourceID, exists := savedQuerySourceIDs[sourceName]
+		if !exists {
+			return nil, fmt.Errorf("unsupported source '%s'", sourceName)
+		}
+		if isPullRequestQuery {
+			return nil, errors.New("pull requests cannot be combined with other sources")
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+	return bson.M{"source_id": bson.M{"$in": sourceIDs}}, nil
+}
+
+func getSavedQueryStatusFilter(term savedQueryTerm, isPullRequestQuery bool) (bson.M, error) {
+	if term.operator != ":" {
+		return nil, errors.New("'status' only supports ':'")
+	}
+	switch strings.ToLower(term.value) {
+	case "done", "completed":
+		return bson.M{"is_completed": true}, nil
+	case "open":
+		return bson.M{"is_completed": false}, nil
+	}
+	if isPullRequestQuery {
+		return bson.M{"required_action": bson.M{"$regex": "^" + regexp.QuoteMeta(term.value) + "$", "$options": "i"}}, nil
+	}
+	// otherwise match the status name in the task's source, i.e. "status:\"In Progress\""
+	return bson.M{"status.state": bson.M{"$regex": "^" + regexp.QuoteMeta(term.value) + "$", "$options": "i"}}, nil
+}
+
+func getSavedQueryPriorityFilter(term savedQueryTerm, isPullRequestQuery bool) (bson.M, error) {
+	if isPullRequestQuery {
+		return nil, errors.New("'priority' is not supported for pull requests")
+	}
+	priority, err := strconv.ParseFloat(term.value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid priority '%s'", term.value)
+	}
+	return bson.M{"priority_normalized": bson.M{savedQueryComparisonOperators[term.operator]: priority}}, nil
+}
+
+func getSavedQueryDueFilter(term savedQueryTerm, timeNow time.Time, isPullRequestQuery bool) (bson.M, error) {
+	if isPullRequestQuery {
+		return nil, errors.New("'due' is not supported for pull requests")
+	}
+	if term.value == "none" && term.operator == ":" {
+		return bson.M{"$or": []bson.M{
+			{"due_date": nil},
+			{"due_date": bson.M{"$lt": savedQueryEmptyDueDateCutoff}},
+		}}, nil
+	}
+	dueTime, err := parseSavedQueryTime(term.value, timeNow)
+	if err != nil {
+		return nil, err
+	}
+	dueDateFilter := bson.M{"due_date": bson.M{savedQueryComparisonOperators[term.operator]: primitive.NewDateTimeFromTime(dueTime)}}
+	if term.operator == ":" {
+		// matches the whole day, which due dates without a time store as midnight UTC
+		startOfDay := time.Date(dueTime.Year(), dueTime.Month(), dueTime.Day(), 0, 0, 0, 0, time.UTC)
+		dueDateFilter = bson.M{"due_date": bson.M{
+			"$gte": primitive.NewDateTimeFromTime(startOfDay),
+			"$lt":  primitive.NewDateTimeFromTime(startOfDay.AddDate(0, 0, 1)),
+		}}
+	}
+	return bson.M{"$and": []bson.M{
+		dueDateFilter,
+		{"due_date": bson.M{"$gte": savedQueryEmptyDueDateCutoff}},
+	}}, nil
+}
+
+// parseSavedQueryTime accepts a time relative to now (i.e. 12h, 7d, -2w) or a date (i.e. 2023-01-31)
+func parseSavedQueryTime(value string, timeNow time.Time) (time.Time, error) {
+	if value == "today" {
+		value = "0d"
+	}
+	matches := savedQueryRelativeTimeRegex.FindStringSubmatch(value)
+	if matches != nil {
+		amount, _ := strconv.Atoi(matches[1])
+		switch matches[2] {
+		case "h":
+			return timeNow.Add(time.Duration(amount) * time.Hour), nil
+		case "d":
+			return timeNow.AddDate(0, 0, amount), nil
+		default:
+			return timeNow.AddDate(0, 0, 7*amount), nil
+		}
+	}
+	date, err := time.ParseInLocation(savedQueryDateLayout, value, timeNow.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s'", value)
+	}
+	return date, nil
+}
+
+func getSavedQueryLabelFilter(term savedQueryTerm, labelIDs map[string]primitive.ObjectID, isPullRequestQuery bool) (bson.M, error) {
+	if isPullRequestQuery {
+		return nil, errors.New("'label' is not supported for pull requests")
+	}
+	if term.operator != ":" {
+		return nil, errors.New("'label' only supports ':'")
+	}
+	matchingLabelIDs := []primitive.ObjectID{}
+	for _, labelName := range strings.Split(term.value, ",") {
+		// unknown labels match nothing until they are created
+		if labelID, exists := labelIDs[strings.ToLower(labelName)]; exists {
+			matchingLabelIDs = append(matchingLabelIDs, labelID)
+		}
+	}
+	return bson.M{"$or": []bson.M{
+		{"label_ids": bson.M{"$in": matchingLabelIDs}},
+		{"external_label_ids": bson.M{"$in": matchingLabelIDs}},
+	}}, nil
+}
+
+func (api *API) parseSavedQueryForUser(query string, userID primitive.ObjectID, timezoneOffset time.Duration) (*savedQuery, error) {
+	labels, err := database.GetLabels(api.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	labelIDs := map[string]primitive.ObjectID{}
+	for _, label := range *labels {
+		labelIDs[strings.ToLower(label.Name)] = label.ID
+	}
+	return parseSavedQuery(query, api.GetCurrentLocalizedTime(timezoneOffset), labelIDs)
+}
+
+// GetSavedQueryOverviewResult lists the tasks, or pull requests, matching the view's query. Completed items are hidden unless the query has a status term.
+func (api *API) GetSavedQueryOverviewResult(view database.View, userID primitive.ObjectID, timezoneOffset time.Duration) (OrderingIDGetter, error) {
+	if view.UserID != userID {
+		return nil, errors.New("invalid user")
+	}
+	query, err := api.parseSavedQueryForUser(view.Query, userID, timezoneOffset)
+	if err != nil {
+		return nil, err
+	}
+	filters := query.filters
+	if !query.hasStatusTerm {
+		filters = append(filters, bson.M{"is_completed": false})
+	}
+	name := view.Name
+	if name == "" {
+		name = view.Query
+	}
+	timeNow := api.GetCurrentLocalizedTime(timezoneOffset)
+	timeStartOfDay := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, time.FixedZone("", 0))
+
+	if query.isPullRequestQuery {
+		pullRequests, err := database.GetPullRequests(api.DB, userID, &filters)
+		if err != nil {
+			return nil, err
+		}
+		pullResults := []*PullRequestResult{}
+		for _, pullRequest := range *pullRequests {
+			pullRequestResult := getResultFromPullRequest(pullRequest)
+			pullResults = append(pullResults, &pullRequestResult)
+		}
+		api.sortPullRequestResults(pullResults)
+		return &OverviewResult[PullRequestResult]{
+			ID:                     view.ID,
+			Name:                   name,
+			Logo:                   external.TaskServiceGithub.LogoV2,
+			Type:                   constants.ViewSavedQuery,
+			IsLinked:               view.IsLinked,
+			Sources:                []SourcesResult{},
+			TaskSectionID:          view.TaskSectionID,
+			IsReorderable:          view.IsReorderable,
+			IDOrdering:             view.IDOrdering,
+			ViewItems:              pullResults,
+			ViewItemIDs:            GetPullRequestViewItemsIDs(pullResults),
+			HasTasksCompletedToday: api.getCompletedInLastDay(database.GetPullRequestCollection(api.DB), userID, timeStartOfDay, &query.filters),
+		}, nil
+	}
+
+	filters = append(filters, bson.M{"is_deleted": bson.M{"$ne": true}})
+	tasks, err := database.GetTasks(api.DB, userID, &filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	taskResults := api.taskListToTaskResultList(tasks, userID)
+	return &OverviewResult[TaskResult]{
+		ID:                     view.ID,
+		Name:                   name,
+		Logo:                   external.TaskServiceGeneralTask.LogoV2,
+		Type:                   constants.ViewSavedQuery,
+		IsLinked:               view.IsLinked,
+		Sources:                []SourcesResult{},
+		TaskSectionID:          view.TaskSectionID,
+		IsReorderable:          view.IsReorderable,
+		IDOrdering:             view.IDOrdering,
+		ViewItems:              taskResults,
+		ViewItemIDs:            GetTaskSectionViewItemIDs(taskResults),
+		HasTasksCompletedToday: api.getCompletedInLastDay(database.GetTaskCollection(api.DB), userID, timeStartOfDay, &query.filters),
+	}, nil
+}
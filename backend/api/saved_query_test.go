@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseSavedQuery(t *testing.T) {
+	timeNow := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	labelID := primitive.NewObjectID()
+	labelIDs := map[string]primitive.ObjectID{"on-call": labelID}
+
+	t.Run("Example", func(t *testing.T) {
+		query, err := parseSavedQuery("source:linear priority>=0.5 due<7d -status:done", timeNow, labelIDs)
+		assert.NoError(t, err)
+		assert.False(t, query.isPullRequestQuery)
+		assert.True(t, query.hasStatusTerm)
+		assert.Equal(t, []bson.M{
+			{"source_id": bson.M{"$in": []string{external.TASK_SOURCE_ID_LINEAR}}},
+			{"priority_normalized": bson.M{"$gte": 0.5}},
+			{"$and": []bson.M{
+				{"due_date": bson.M{"$lt": primitive.NewDateTimeFromTime(timeNow.AddDate(0, 0, 7))}},
+				{"due_date": bson.M{"$gte": savedQueryEmptyDueDateCutoff}},
+			}},
+			{"$nor": []bson.M{{"is_completed": true}}},
+		}, query.filters)
+	})
+	t.Run("TitleAndLabels", func(t *testing.T) {
+		query, err := parseSavedQuery(`"on call" label:On-Call,unknown due:2023-03-02`, timeNow, labelIDs)
+		assert.NoError(t, err)
+		assert.False(t, query.hasStatusTerm)
+		assert.Equal(t, []bson.M{
+			{"title": bson.M{"$regex": "on call", "$options": "i"}},
+			{"$or": []bson.M{
+				{"label_ids": bson.M{"$in": []primitive.ObjectID{labelID}}},
+				{"external_label_ids": bson.M{"$in": []primitive.ObjectID{labelID}}},
+			}},
+			{"$and": []bson.M{
+				{"due_date": bson.M{
+					"$gte": primitive.NewDateTimeFromTime(time.Date(2023, time.March, 2, 0, 0, 0, 0, time.UTC)),
+					"$lt":  primitive.NewDateTimeFromTime(time.Date(2023, time.March, 3, 0, 0, 0, 0, time.UTC)),
+				}},
+				{"due_date": bson.M{"$gte": savedQueryEmptyDueDateCutoff}},
+			}},
+		}, query.filters)
+	})
+	t.Run("DueTodayWestOfUTC", func(t *testing.T) {
+		// already March 2nd in UTC, but still March 1st for the user
+		localTimeNow := time.Date(2023, time.March, 1, 20, 0, 0, 0, time.FixedZone("", -8*60*60))
+		query, err := parseSavedQuery("due:today", localTimeNow, labelIDs)
+		assert.NoError(t, err)
+		assert.Equal(t, []bson.M{
+			{"$and": []bson.M{
+				{"due_date": bson.M{
+					"$gte": primitive.NewDateTimeFromTime(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)),
+					"$lt":  primitive.NewDateTimeFromTime(time.Date(2023, time.March, 2, 0, 0, 0, 0, time.UTC)),
+				}},
+				{"due_date": bson.M{"$gte": savedQueryEmptyDueDateCutoff}},
+			}},
+		}, query.filters)
+	})
+	t.Run("PullRequests", func(t *testing.T) {
+		query, err := parseSavedQuery("source:github repo:general-task/task-manager", timeNow, labelIDs)
+		assert.NoError(t, err)
+		assert.True(t, query.isPullRequestQuery)
+		assert.Equal(t, []bson.M{
+			{"source_id": bson.M{"$in": []string{external.TASK_SOURCE_ID_GITHUB_PR}}},
+			{"repository_name": bson.M{"$regex": "^general-task/task-manager$", "$options": "i"}},
+		}, query.filters)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for query, expectedError := range map[string]string{
+			"":                           "query cannot be empty",
+			`"unterminated`:              "unterminated quote in query",
+			"assignee:me":                "unsupported query term 'assignee'",
+			"source:trello":              "unsupported source 'trello'",
+			"source:github,linear":       "pull requests cannot be combined with other sources",
+			"source:github priority>0.5": "'priority' is not supported for pull requests",
+			"priority>high":              "invalid priority 'high'",
+			"due<soon":                   "invalid time 'soon'",
+			"repo:task-manager":          "'repo' is only supported as 'repo:' with 'source:github'",
+		} {
+			_, err := parseSavedQuery(query, timeNow, labelIDs)
+			assert.EqualError(t, err, expectedError)
+		}
+	})
+}
+
+func TestSavedQueryOverviewView(t *testing.T) {
+	authToken := login("test_saved_query_view@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	notCompleted := false
+	highPriority := 0.8
+	lowPriority := 0.2
+	highPriorityTaskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_LINEAR, PriorityNormalized: &highPriority})
+	insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_LINEAR, PriorityNormalized: &lowPriority})
+	insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_JIRA, PriorityNormalized: &highPriority})
+
+	ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "saved_query"}`)), http.StatusBadRequest, api)
+	response := ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "saved_query", "query": "priority>>0.5"}`)), http.StatusBadRequest, api)
+	assert.Equal(t, `{"detail":"invalid 'query': invalid priority '>0.5'"}`, string(response))
+	ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "saved_query", "name": "Urgent Linear", "query": "source:linear priority>=0.5"}`)), http.StatusOK, api)
+	ServeRequest(t, authToken, "POST", "/overview/views/", bytes.NewBuffer([]byte(`{"type": "saved_query", "query": "source:linear priority>=0.5"}`)), http.StatusBadRequest, api)
+
+	var view database.View
+	err := database.GetViewCollection(api.DB).FindOne(context.Background(), bson.M{"user_id": userID, "type": constants.ViewSavedQuery}).Decode(&view)
+	assert.NoError(t, err)
+	result, err := api.GetSavedQueryOverviewResult(view, userID, 0)
+	assert.NoError(t, err)
+	taskResult := result.(*OverviewResult[TaskResult])
+	assert.Equal(t, "Urgent Linear", taskResult.Name)
+	assert.Equal(t, constants.ViewSavedQuery, taskResult.Type)
+	assert.Equal(t, []string{highPriorityTaskID}, taskResult.ViewItemIDs)
+}
+
+func TestSavedQueryDueFilter(t *testing.T) {
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := primitive.NewObjectID()
+	timeNow := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	insertDueTask := func(dueDate *time.Time) string {
+		task := database.Task{UserID: userID}
+		if dueDate != nil {
+			primitiveDueDate := primitive.NewDateTimeFromTime(*dueDate)
+			task.DueDate = &primitiveDueDate
+		}
+		return insertTestTask(t, userID, task)
+	}
+	dueTomorrow := timeNow.AddDate(0, 0, 1)
+	zeroTime := time.Time{}
+	unixEpoch := time.Unix(0, 0)
+	dueTomorrowTaskID := insertDueTask(&dueTomorrow)
+	undatedTaskIDs := []string{insertDueTask(nil), insertDueTask(&zeroTime), insertDueTask(&unixEpoch)}
+
+	getMatchingTaskIDs := func(queryString string) []string {
+		query, err := parseSavedQuery(queryString, timeNow, map[string]primitive.ObjectID{})
+		assert.NoError(t, err)
+		var tasks []database.Task
+		err = database.FindWithCollection(database.GetTaskCollection(api.DB), userID, &query.filters, &tasks, nil)
+		assert.NoError(t, err)
+		taskIDs := []string{}
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID.Hex())
+		}
+		return taskIDs
+	}
+
+	t.Run("BeforeExcludesUndated", func(t *testing.T) {
+		assert.Equal(t, []string{dueTomorrowTaskID}, getMatchingTaskIDs("due<7d"))
+		assert.Equal(t, []string{dueTomorrowTaskID}, getMatchingTaskIDs("due<=7d"))
+	})
+	t.Run("NoneIncludesEmptyDates", func(t *testing.T) {
+		assert.ElementsMatch(t, undatedTaskIDs, getMatchingTaskIDs("due:none"))
+	})
+}
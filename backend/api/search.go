@@ -0,0 +1,327 @@
+package api
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SearchResultTypeTask        = "task"
+	SearchResultTypeNote        = "note"
+	SearchResultTypePullRequest = "pull_request"
+	SearchResultTypeEvent       = "event"
+)
+
+const (
+	SEARCH_RESULT_LIMIT     = 50
+	SEARCH_MAX_QUERY_LENGTH = 256
+	SEARCH_MAX_HIGHLIGHTS   = 3
+	SEARCH_SNIPPET_CONTEXT  = 60
+	SEARCH_HIGHLIGHT_START  = "<em>"
+	SEARCH_HIGHLIGHT_END    = "</em>"
+)
+
+// text search operators and whitespace, which aren't highlighted
+const searchWordSeparatorChars = " \t\n\"-"
+
+type SearchParams struct {
+	Query         string     `form:"q"`
+	Source        *string    `form:"source"`
+	DatetimeStart *time.Time `form:"datetime_start"`
+	DatetimeEnd   *time.Time `form:"datetime_end"`
+	IsCompleted   *bool      `form:"is_completed"`
+}
+
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+type SearchResult struct {
+	ID          primitive.ObjectID `json:"id"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	SourceID    string             `json:"source_id"`
+	Deeplink    string             `json:"deeplink"`
+	Date        primitive.DateTime `json:"date"`
+	IsCompleted bool               `json:"is_completed"`
+	Score       float64            `json:"score"`
+	Highlights  []SearchHighlight  `json:"highlights"`
+}
+
+type searchTask struct {
+	database.Task `bson:",inline"`
+	SearchScore   float64 `bson:"search_score"`
+}
+
+type searchNote struct {
+	database.Note `bson:",inline"`
+	SearchScore   float64 `bson:"search_score"`
+}
+
+type searchPullRequest struct {
+	database.PullRequest `bson:",inline"`
+	SearchScore          float64 `bson:"search_score"`
+}
+
+type searchEvent struct {
+	database.CalendarEvent `bson:",inline"`
+	SearchScore            float64 `bson:"search_score"`
+}
+
+// searchField is a searched field of a result, in the order highlights should be shown
+type searchField struct {
+	name  string
+	value string
+}
+
+func (api *API) Search(c *gin.Context) {
+	var params SearchParams
+	err := c.BindQuery(&params)
+	params.Query = strings.TrimSpace(params.Query)
+	if err != nil || params.Query == "" || len(params.Query) > SEARCH_MAX_QUERY_LENGTH {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	var sourceIDs []string
+	if params.Source != nil {
+		sourceIDs = getSearchSourceIDs(*params.Source)
+	}
+
+	userID := getUserIDFromContext(c)
+	results := []SearchResult{}
+	highlightRegex := getSearchHighlightRegex(params.Query)
+	getFilters := func(dateField string, hasCompletion bool) []bson.M {
+		filters := []bson.M{}
+		if sourceIDs != nil {
+			filters = append(filters, bson.M{"source_id": bson.M{"$in": sourceIDs}})
+		}
+		if params.DatetimeStart != nil {
+			filters = append(filters, bson.M{dateField: bson.M{"$gte": primitive.NewDateTimeFromTime(*params.DatetimeStart)}})
+		}
+		if params.DatetimeEnd != nil {
+			filters = append(filters, bson.M{dateField: bson.M{"$lte": primitive.NewDateTimeFromTime(*params.DatetimeEnd)}})
+		}
+		if hasCompletion && params.IsCompleted != nil {
+			filters = append(filters, bson.M{"is_completed": *params.IsCompleted})
+		}
+		return filters
+	}
+
+	var tasks []searchTask
+	taskFilters := append(getFilters("created_at_external", true), bson.M{"is_deleted": bson.M{"$ne": true}})
+	err = database.SearchWithCollection(database.GetTaskCollection(api.DB), userID, params.Query, &taskFilters, &tasks, SEARCH_RESULT_LIMIT)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to search tasks")
+		Handle500(c)
+		return
+	}
+	for _, task := range tasks {
+		fields := []searchField{{name: "title", value: derefString(task.Title)}, {name: "body", value: derefString(task.Body)}}
+		if task.Comments != nil {
+			for _, comment := range *task.Comments {
+				fields = append(fields, searchField{name: "comments", value: comment.Body})
+			}
+		}
+		results = append(results, SearchResult{
+			ID:          task.ID,
+			Type:        SearchResultTypeTask,
+			Title:       derefString(task.Title),
+			SourceID:    task.SourceID,
+			Deeplink:    task.Deeplink,
+			Date:        task.CreatedAtExternal,
+			IsCompleted: task.IsCompleted != nil && *task.IsCompleted,
+			Score:       task.SearchScore,
+			Highlights:  getSearchHighlights(highlightRegex, fields),
+		})
+	}
+
+	var pullRequests []searchPullRequest
+	pullRequestFilters := getFilters("created_at_external", true)
+	err = database.SearchWithCollection(database.GetPullRequestCollection(api.DB), userID, params.Query, &pullRequestFilters, &pullRequests, SEARCH_RESULT_LIMIT)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to search pull requests")
+		Handle500(c)
+		return
+	}
+	for _, pullRequest := range pullRequests {
+		fields := []searchField{{name: "title", value: pullRequest.Title}, {name: "body", value: pullRequest.Body}}
+		for _, comment := range pullRequest.Comments {
+			fields = append(fields, searchField{name: "comments", value: comment.Body})
+		}
+		results = append(results, SearchResult{
+			ID:          pullRequest.ID,
+			Type:        SearchResultTypePullRequest,
+			Title:       pullRequest.Title,
+			SourceID:    pullRequest.SourceID,
+			Deeplink:    pullRequest.Deeplink,
+			Date:        pullRequest.CreatedAtExternal,
+			IsCompleted: pullRequest.IsCompleted != nil && *pullRequest.IsCompleted,
+			Score:       pullRequest.SearchScore,
+			Highlights:  getSearchHighlights(highlightRegex, fields),
+		})
+	}
+
+	// notes and events can't be completed, and notes don't have a source
+	if params.IsCompleted == nil {
+		var events []searchEvent
+		eventFilters := getFilters("datetime_start", false)
+		err = database.SearchWithCollection(database.GetCalendarEventCollection(api.DB), userID, params.Query, &eventFilters, &events, SEARCH_RESULT_LIMIT)
+		if err != nil {
+			api.Logger.Error().Err(err).Msg("failed to search events")
+			Handle500(c)
+			return
+		}
+		for _, event := range events {
+			results = append(results, SearchResult{
+				ID:         event.ID,
+				Type:       SearchResultTypeEvent,
+				Title:      event.Title,
+				SourceID:   event.SourceID,
+				Deeplink:   event.Deeplink,
+				Date:       event.DatetimeStart,
+				Score:      event.SearchScore,
+				Highlights: getSearchHighlights(highlightRegex, []searchField{{name: "title", value: event.Title}, {name: "body", value: event.Body}}),
+			})
+		}
+	}
+	if params.IsCompleted == nil && sourceIDs == nil {
+		var notes []searchNote
+		noteFilters := append(getFilters("created_at", false), bson.M{"is_deleted": bson.M{"$ne": true}})
+		err = database.SearchWithCollection(database.GetNoteCollection(api.DB), userID, params.Query, &noteFilters, &notes, SEARCH_RESULT_LIMIT)
+		if err != nil {
+			api.Logger.Error().Err(err).Msg("failed to search notes")
+			Handle500(c)
+			return
+		}
+		for _, note := range notes {
+			results = append(results, SearchResult{
+				ID:         note.ID,
+				Type:       SearchResultTypeNote,
+				Title:      derefString(note.Title),
+				Date:       note.CreatedAt,
+				Score:      note.SearchScore,
+				Highlights: getSearchHighlights(highlightRegex, []searchField{{name: "title", value: derefString(note.Title)}, {name: "body", value: derefString(note.Body)}}),
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > SEARCH_RESULT_LIMIT {
+		results = results[:SEARCH_RESULT_LIMIT]
+	}
+	c.JSON(200, results)
+}
+
+// getSearchSourceIDs accepts the source names used by saved queries (i.e. linear, github) as well as source IDs
+func getSearchSourceIDs(source string) []string {
+	sourceIDs := []string{}
+	for _, sourceName := range strings.Split(source, ",") {
+		sourceName = strings.TrimSpace(sourceName)
+		if sourceID, exists := savedQuerySourceIDs[sourceName]; exists {
+			sourceIDs = append(sourceIDs, sourceID)
+		} else if sourceName == savedQuerySourceGithub {
+			sourceIDs = append(sourceIDs, external.TASK_SOURCE_ID_GITHUB_PR)
+		} else if sourceName != "" {
+			sourceIDs = append(sourceIDs, sourceName)
+		}
+	}
+	return sourceIDs
+}
+
+// getSearchHighlightRegex matches the words of the query, ignoring text search operators like quotes and negation
+func getSearchHighlightRegex(query string) *regexp.Regexp {
+	words := []string{}
+	for _, word := range strings.FieldsFunc(query, func(char rune) bool {
+		return strings.ContainsRune(searchWordSeparatorChars, char)
+	}) {
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	// longer words first, so the longest overlapping match is highlighted
+	sort.SliceStable(words, func(i, j int) bool {
+		return len(words[i]) > len(words[j])
+	})
+	return regexp.MustCompile("(?i)" + strings.Join(words, "|"))
+}
+
+// getSearchHighlights returns escaped snippets around the first match in each matching field, with matches wrapped in <em> tags
+func getSearchHighlights(highlightRegex *regexp.Regexp, fields []searchField) []SearchHighlight {
+	highlights := []SearchHighlight{}
+	if highlightRegex == nil {
+		return highlights
+	}
+	for _, field := range fields {
+		if len(highlights) >= SEARCH_MAX_HIGHLIGHTS {
+			break
+		}
+		firstMatch := highlightRegex.FindStringIndex(field.value)
+		if firstMatch == nil {
+			continue
+		}
+		start := firstMatch[0] - SEARCH_SNIPPET_CONTEXT
+		end := firstMatch[1] + SEARCH_SNIPPET_CONTEXT
+		prefix, suffix := "…", "…"
+		if start <= 0 {
+			start, prefix = 0, ""
+		}
+		if end >= len(field.value) {
+			end, suffix = len(field.value), ""
+		}
+		// don't split multi-byte characters or words
+		for start > 0 && !utf8.RuneStart(field.value[start]) {
+			start--
+		}
+		for end < len(field.value) && !utf8.RuneStart(field.value[end]) {
+			end++
+		}
+		if start > 0 {
+			if space := strings.IndexAny(field.value[start:firstMatch[0]], " \t\n"); space >= 0 {
+				start += space + 1
+			}
+		}
+		if end < len(field.value) {
+			if space := strings.LastIndexAny(field.value[firstMatch[1]:end], " \t\n"); space >= 0 {
+				end = firstMatch[1] + space
+			}
+		}
+		snippet := field.value[start:end]
+
+		var highlightedSnippet strings.Builder
+		highlightedSnippet.WriteString(prefix)
+		lastIndex := 0
+		for _, match := range highlightRegex.FindAllStringIndex(snippet, -1) {
+			highlightedSnippet.WriteString(html.EscapeString(snippet[lastIndex:match[0]]))
+			highlightedSnippet.WriteString(SEARCH_HIGHLIGHT_START + html.EscapeString(snippet[match[0]:match[1]]) + SEARCH_HIGHLIGHT_END)
+			lastIndex = match[1]
+		}
+		highlightedSnippet.WriteString(html.EscapeString(snippet[lastIndex:]))
+		highlightedSnippet.WriteString(suffix)
+		highlights = append(highlights, SearchHighlight{
+			Field:   field.name,
+			Snippet: strings.Join(strings.Fields(highlightedSnippet.String()), " "),
+		})
+	}
+	return highlights
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
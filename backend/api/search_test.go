@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetSearchHighlights(t *testing.T) {
+	highlightRegex := getSearchHighlightRegex(`deploy -rollback "release notes"`)
+	t.Run("NoMatch", func(t *testing.T) {
+		assert.Equal(t, []SearchHighlight{}, getSearchHighlights(highlightRegex, []searchField{{name: "title", value: "unrelated"}}))
+	})
+	t.Run("ShortField", func(t *testing.T) {
+		assert.Equal(t, []SearchHighlight{
+			{Field: "title", Snippet: "<em>Deploy</em> the <em>release</em> &amp; write <em>notes</em>"},
+		}, getSearchHighlights(highlightRegex, []searchField{{name: "title", value: "Deploy the release & write notes"}, {name: "body", value: ""}}))
+	})
+	t.Run("LongField", func(t *testing.T) {
+		padding := "lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor"
+		highlights := getSearchHighlights(highlightRegex, []searchField{{name: "comments", value: padding + " <b>deploy</b> " + padding}})
+		assert.Equal(t, 1, len(highlights))
+		assert.Equal(t, "…amet consectetur adipiscing elit sed do eiusmod tempor &lt;b&gt;<em>deploy</em>&lt;/b&gt; lorem ipsum dolor sit amet consectetur adipiscing elit…", highlights[0].Snippet)
+	})
+	t.Run("EmptyQuery", func(t *testing.T) {
+		assert.Nil(t, getSearchHighlightRegex(`"-"`))
+		assert.Equal(t, []SearchHighlight{}, getSearchHighlights(nil, []searchField{{name: "title", value: "deploy"}}))
+	})
+}
+
+func TestSearch(t *testing.T) {
+	authToken := login("test_search@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	// the test DB isn't migrated
+	for _, collection := range []*mongo.Collection{
+		database.GetTaskCollection(api.DB),
+		database.GetNoteCollection(api.DB),
+		database.GetPullRequestCollection(api.DB),
+		database.GetCalendarEventCollection(api.DB),
+	} {
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "title", Value: "text"}, {Key: "body", Value: "text"}},
+		})
+		assert.NoError(t, err)
+	}
+
+	completed := true
+	notCompleted := false
+	taskTitle := "Investigate checkout outage"
+	completedTaskTitle := "Checkout outage postmortem"
+	noteTitle := "Outage retro notes"
+	linearTaskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &taskTitle, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_LINEAR})
+	completedTaskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &completedTaskTitle, IsCompleted: &completed, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	insertTestTask(t, primitive.NewObjectID(), database.Task{UserID: primitive.NewObjectID(), Title: &taskTitle, SourceID: external.TASK_SOURCE_ID_LINEAR})
+	_, err := database.GetNoteCollection(api.DB).InsertOne(context.Background(), database.Note{UserID: userID, Title: &noteTitle, CreatedAt: primitive.NewDateTimeFromTime(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))})
+	assert.NoError(t, err)
+	_, err = database.GetCalendarEventCollection(api.DB).InsertOne(context.Background(), database.CalendarEvent{UserID: userID, Title: "Outage review", SourceID: external.TASK_SOURCE_ID_GCAL})
+	assert.NoError(t, err)
+
+	search := func(query string) []SearchResult {
+		response := ServeRequest(t, authToken, "GET", "/search/?q="+query, nil, http.StatusOK, api)
+		var results []SearchResult
+		assert.NoError(t, json.Unmarshal(response, &results))
+		return results
+	}
+	getResultTypes := func(results []SearchResult) []string {
+		resultTypes := []string{}
+		for _, result := range results {
+			resultTypes = append(resultTypes, result.Type)
+		}
+		return resultTypes
+	}
+
+	UnauthorizedTest(t, "GET", "/search/?q=outage", nil)
+	t.Run("MissingQuery", func(t *testing.T) {
+		ServeRequest(t, authToken, "GET", "/search/?q=%20", nil, http.StatusBadRequest, api)
+	})
+	t.Run("AllTypes", func(t *testing.T) {
+		results := search("outage")
+		assert.ElementsMatch(t, []string{SearchResultTypeTask, SearchResultTypeTask, SearchResultTypeNote, SearchResultTypeEvent}, getResultTypes(results))
+		for index := 1; index < len(results); index++ {
+			assert.GreaterOrEqual(t, results[index-1].Score, results[index].Score)
+		}
+	})
+	t.Run("Filters", func(t *testing.T) {
+		results := search("outage&source=linear")
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, linearTaskID, results[0].ID.Hex())
+		assert.Equal(t, []SearchHighlight{{Field: "title", Snippet: "Investigate checkout <em>outage</em>"}}, results[0].Highlights)
+
+		results = search("outage&is_completed=true")
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, completedTaskID, results[0].ID.Hex())
+
+		results = search("outage&datetime_end=2023-02-01T00:00:00Z")
+		assert.Equal(t, []string{SearchResultTypeNote}, getResultTypes(results))
+	})
+}
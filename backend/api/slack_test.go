@@ -1,14 +1,17 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/jjPlusPlus/task-manager/backend/testutils"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestLinkSlack(t *testing.T) {
@@ -125,12 +128,26 @@ func TestLinkSlackApp(t *testing.T) {
 		router.ServeHTTP(recorder, request)
 		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	})
-	t.Run("Success", func(t *testing.T) {
+	t.Run("FailedMissingTeam", func(t *testing.T) {
 		api, dbCleanup := GetAPIWithDBCleanup()
 		defer dbCleanup()
 		server := testutils.GetMockAPIServer(t, http.StatusOK, `{"access_token":"sample-access-token"}`)
 		(api.ExternalConfig.SlackApp.OauthConfig.(*external.OauthConfig)).Config.Endpoint.TokenURL = server.URL
 
+		router := GetRouter(api)
+		request, _ := http.NewRequest("GET", "/link_app/slack/?code=123abc&state=", nil)
+		authToken := login("authorize_success@resonant-kelpie-404a42.netlify.app", "")
+		request.AddCookie(&http.Cookie{Name: "authToken", Value: authToken})
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+	t.Run("Success", func(t *testing.T) {
+		api, dbCleanup := GetAPIWithDBCleanup()
+		defer dbCleanup()
+		server := testutils.GetMockAPIServer(t, http.StatusOK, `{"access_token":"sample-access-token","team":{"id":"TLINKAPP","name":"General Task"}}`)
+		(api.ExternalConfig.SlackApp.OauthConfig.(*external.OauthConfig)).Config.Endpoint.TokenURL = server.URL
+
 		router := GetRouter(api)
 		request, _ := http.NewRequest("GET", "/link_app/slack/?code=123abc&state=", nil)
 		authToken := login("authorize_success@resonant-kelpie-404a42.netlify.app", "")
@@ -138,5 +155,10 @@ func TestLinkSlackApp(t *testing.T) {
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, request)
 		assert.Equal(t, http.StatusFound, recorder.Code)
+
+		var appToken database.ExternalAPIToken
+		err := database.GetExternalTokenCollection(api.DB).FindOne(context.Background(), bson.M{"service_id": external.TASK_SERVICE_ID_SLACK_APP, "account_id": "TLINKAPP"}).Decode(&appToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "General Task", appToken.DisplayID)
 	})
 }
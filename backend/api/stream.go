@@ -0,0 +1,41 @@
+package api
+
+import (
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+)
+
+// keeps proxies from closing idle connections
+const STREAM_HEARTBEAT_INTERVAL = 25 * time.Second
+
+func (api *API) Stream(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	changes, unsubscribe := database.SubscribeToChanges(userID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(STREAM_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			c.SSEvent(string(change.Type), change)
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{})
+		}
+		c.Writer.Flush()
+	}
+}
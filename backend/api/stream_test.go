@@ -0,0 +1,47 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStream(t *testing.T) {
+	authToken := login("test_stream@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+	server := httptest.NewServer(GetRouter(api))
+	defer server.Close()
+
+	UnauthorizedTest(t, "GET", "/stream/", nil)
+	t.Run("Success", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", server.URL+"/stream/", nil)
+		request.Header.Set("Authorization", "Bearer "+authToken)
+		// headers are only flushed once the stream has subscribed to changes
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+		title := "stream task"
+		task, err := database.UpdateOrCreateTask(api.DB, userID, primitive.NewObjectID().Hex(), external.TASK_SOURCE_ID_GT_TASK, nil, database.Task{Title: &title}, nil)
+		assert.NoError(t, err)
+
+		reader := bufio.NewReader(response.Body)
+		eventLine, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, "event:task", strings.TrimSpace(eventLine))
+		dataLine, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, `data:{"type":"task","id":"`+task.ID.Hex()+`"}`, strings.TrimSpace(dataLine))
+	})
+}
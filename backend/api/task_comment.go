@@ -68,3 +68,26 @@ func (api *API) TaskAddComment(c *gin.Context) {
 	api.UpdateTaskInDB(c, task, userID, &updateTask)
 	c.JSON(200, gin.H{})
 }
+
+// adds a comment delivered by a source's webhook, which may be retried or echo a comment made from here
+func (api *API) addExternalComment(task *database.Task, commentToAdd database.Comment) error {
+	commentsNew := []database.Comment{}
+	isAdded := false
+	if task.Comments != nil {
+		for _, comment := range *task.Comments {
+			if comment.ExternalID == commentToAdd.ExternalID {
+				return nil
+			}
+			// comments added from here don't know their external ID until the source sends it back
+			if !isAdded && comment.ExternalID == "" && comment.Body == commentToAdd.Body {
+				comment = commentToAdd
+				isAdded = true
+			}
+			commentsNew = append(commentsNew, comment)
+		}
+	}
+	if !isAdded {
+		commentsNew = append(commentsNew, commentToAdd)
+	}
+	return api.updateComments(task, task.UserID, commentsNew)
+}
@@ -22,6 +22,11 @@ type TaskCreateParams struct {
 	TimeDuration  *int       `json:"time_duration"`
 	IDTaskSection *string    `json:"id_task_section"`
 	ParentTaskID  *string    `json:"parent_task_id"`
+	// source specific fields, only used when creating a task in that source
+	JIRAParams   *external.JIRATaskCreationParams   `json:"jira"`
+	LinearParams *external.LinearTaskCreationParams `json:"linear"`
+	AsanaParams  *external.AsanaTaskCreationParams  `json:"asana"`
+	CalDAVParams *external.CalDAVTaskCreationParams `json:"caldav"`
 }
 
 func (api *API) TaskCreate(c *gin.Context) {
@@ -50,13 +55,27 @@ func (api *API) TaskCreate(c *gin.Context) {
 		}
 	}
 
+	if sourceID == external.TASK_SOURCE_ID_JIRA && (taskCreateParams.JIRAParams == nil || taskCreateParams.JIRAParams.ProjectID == "" || taskCreateParams.JIRAParams.IssueTypeID == "") {
+		c.JSON(400, gin.H{"detail": "'jira.project_id' and 'jira.issue_type_id' are required"})
+		return
+	}
+	if sourceID == external.TASK_SOURCE_ID_LINEAR && (taskCreateParams.LinearParams == nil || taskCreateParams.LinearParams.TeamID == "") {
+		c.JSON(400, gin.H{"detail": "'linear.team_id' is required"})
+		return
+	}
+
 	if sourceID != external.TASK_SOURCE_ID_GT_TASK {
+		serviceID, err := api.ExternalConfig.GetServiceIDForSource(sourceID)
+		if err != nil {
+			Handle404(c)
+			return
+		}
 		externalAPICollection := database.GetExternalTokenCollection(api.DB)
 		count, err := externalAPICollection.CountDocuments(
 			context.Background(),
 			bson.M{"$and": []bson.M{
 				{"account_id": taskCreateParams.AccountID},
-				{"source_id": sourceID},
+				{"service_id": serviceID},
 				{"user_id": userID},
 			}},
 		)
@@ -99,6 +118,10 @@ func (api *API) TaskCreate(c *gin.Context) {
 		TimeAllocation: timeAllocation,
 		IDTaskSection:  IDTaskSection,
 		ParentTaskID:   parentID,
+		JIRAParams:     taskCreateParams.JIRAParams,
+		LinearParams:   taskCreateParams.LinearParams,
+		AsanaParams:    taskCreateParams.AsanaParams,
+		CalDAVParams:   taskCreateParams.CalDAVParams,
 	}
 	taskID, err := taskSourceResult.Source.CreateNewTask(api.DB, userID, taskCreateParams.AccountID, taskCreationObject)
 	if err != nil {
@@ -115,6 +138,7 @@ func (api *API) TaskCreate(c *gin.Context) {
 		c.JSON(500, gin.H{"detail": "failed to move task to front of folder"})
 		return
 	}
+	api.queueTaskWebhookEvent(userID, constants.WebhookEventTaskCreated, taskID)
 	c.JSON(200, gin.H{"task_id": taskID})
 }
 
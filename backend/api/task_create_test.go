@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/testutils"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -65,8 +67,13 @@ func TestCreateTask(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "{\"detail\":\"invalid or missing parameter\"}", string(body))
 	})
+	t.Run("MissingSourceParams", func(t *testing.T) {
+		ServeRequest(t, authToken, "POST", "/tasks/create/jira/", bytes.NewBuffer([]byte(`{"title": "foobar", "account_id": "cloud_id"}`)), http.StatusBadRequest, api)
+		ServeRequest(t, authToken, "POST", "/tasks/create/linear/", bytes.NewBuffer([]byte(`{"title": "foobar", "account_id": "linear@example.com"}`)), http.StatusBadRequest, api)
+	})
 	t.Run("WrongAccountID", func(t *testing.T) {
-		// this currently isn't possible because only GT tasks are supported, but we should add this when it's possible
+		body := ServeRequest(t, authToken, "POST", "/tasks/create/linear/", bytes.NewBuffer([]byte(`{"title": "foobar", "account_id": "not_linked@example.com", "linear": {"team_id": "team"}}`)), http.StatusNotFound, api)
+		assert.Equal(t, "{\"detail\":\"account ID not found\"}", string(body))
 	})
 	t.Run("BadTaskSection", func(t *testing.T) {
 		authToken = login("create_task_bad_task_section@resonant-kelpie-404a42.netlify.app", "")
@@ -243,3 +250,37 @@ func TestCreateTask(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("{\"task_id\":\"%s\"}", task.ID.Hex()), string(body))
 	})
 }
+
+func TestCreateLinearTask(t *testing.T) {
+	authToken := login("create_linear_task@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	_, err := database.GetExternalTokenCollection(api.DB).InsertOne(context.Background(), database.ExternalAPIToken{
+		UserID:     userID,
+		ServiceID:  external.TASK_SERVICE_ID_LINEAR,
+		AccountID:  "create_linear_task@example.com",
+		ExternalID: "linear_user_id",
+	})
+	assert.NoError(t, err)
+	createServer := testutils.GetMockAPIServer(t, 200, `{"data": {"issueCreate": {"success": true, "issue": {"id": "linear_issue_id", "url": "https://linear.app/issue/GT-1"}}}}`)
+	defer createServer.Close()
+	api.ExternalConfig.Linear.ConfigValues.TaskUpdateURL = &createServer.URL
+
+	t.Run("Success", func(t *testing.T) {
+		body := ServeRequest(t, authToken, "POST", "/tasks/create/linear/", bytes.NewBuffer([]byte(`{"title": "file the ticket", "account_id": "create_linear_task@example.com", "linear": {"team_id": "team", "priority": 2}}`)), http.StatusOK, api)
+		var result map[string]string
+		assert.NoError(t, json.Unmarshal(body, &result))
+		taskID, err := primitive.ObjectIDFromHex(result["task_id"])
+		assert.NoError(t, err)
+
+		task, err := database.GetTask(api.DB, taskID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "linear_issue_id", task.IDExternal)
+		assert.Equal(t, external.TASK_SOURCE_ID_LINEAR, task.SourceID)
+		assert.Equal(t, "https://linear.app/issue/GT-1", task.Deeplink)
+		assert.Equal(t, "file the ticket", *task.Title)
+		assert.Equal(t, 2.0, *task.PriorityNormalized)
+	})
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TaskDependencyAddParams struct {
+	BlockingTaskID string `json:"blocking_task_id" binding:"required"`
+}
+
+type externalTaskKey struct {
+	sourceID   string
+	idExternal string
+}
+
+// taskDependencyGraph holds the user's resolved "blocked by" links
+type taskDependencyGraph struct {
+	// blocking task IDs for each blocked task, in the order the links were created
+	blockedBy map[primitive.ObjectID][]primitive.ObjectID
+	// tasks which are neither completed nor deleted, missing tasks are treated as inactive
+	isActive map[primitive.ObjectID]bool
+}
+
+func (api *API) TaskDependencyAdd(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("task_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	var params TaskDependencyAddParams
+	err = c.BindJSON(&params)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	blockingTaskID, err := primitive.ObjectIDFromHex(params.BlockingTaskID)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid blocking task id"})
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	_, err = database.GetTask(api.DB, taskID, userID)
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	_, err = database.GetTask(api.DB, blockingTaskID, userID)
+	if err != nil {
+		c.JSON(404, gin.H{"detail": "blocking task not found"})
+		return
+	}
+
+	graph, err := api.getTaskDependencyGraph(userID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load task dependencies")
+		Handle500(c)
+		return
+	}
+	for _, existingBlockingTaskID := range graph.blockedBy[taskID] {
+		if existingBlockingTaskID == blockingTaskID {
+			c.JSON(200, gin.H{})
+			return
+		}
+	}
+	if graph.wouldCreateCycle(taskID, blockingTaskID) {
+		c.JSON(400, gin.H{"detail": "task dependencies cannot form a cycle"})
+		return
+	}
+
+	_, err = database.GetTaskDependencyCollection(api.DB).InsertOne(context.Background(), database.TaskDependency{
+		UserID:         userID,
+		TaskID:         taskID,
+		BlockingTaskID: blockingTaskID,
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+	})
+	// a concurrent request already added the same link
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(200, gin.H{})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to insert task dependency")
+		Handle500(c)
+		return
+	}
+	database.PublishChange(api.DB, userID, database.ChangeTypeTask, taskID)
+	c.JSON(201, gin.H{})
+}
+
+// TaskDependencyDelete only removes links created in General Task, as imported links are managed in their source
+func (api *API) TaskDependencyDelete(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("task_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	blockingTaskID, err := primitive.ObjectIDFromHex(c.Param("blocking_task_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	res, err := database.GetTaskDependencyCollection(api.DB).DeleteMany(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"task_id": taskID},
+			{"blocking_task_id": blockingTaskID},
+		}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to delete task dependency")
+		Handle500(c)
+		return
+	}
+	if res.DeletedCount == 0 {
+		Handle404(c)
+		return
+	}
+	database.PublishChange(api.DB, userID, database.ChangeTypeTask, taskID)
+	c.JSON(204, gin.H{})
+}
+
+func (api *API) getTaskDependencyGraph(userID primitive.ObjectID) (*taskDependencyGraph, error) {
+	graph := taskDependencyGraph{
+		blockedBy: map[primitive.ObjectID][]primitive.ObjectID{},
+		isActive:  map[primitive.ObjectID]bool{},
+	}
+	dependencies, err := database.GetTaskDependencies(api.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*dependencies) == 0 {
+		return &graph, nil
+	}
+
+	taskIDs := []primitive.ObjectID{}
+	blockingTaskIDExternals := []string{}
+	for _, dependency := range *dependencies {
+		taskIDs = append(taskIDs, dependency.TaskID)
+		if dependency.BlockingTaskID != primitive.NilObjectID {
+			taskIDs = append(taskIDs, dependency.BlockingTaskID)
+		} else {
+			blockingTaskIDExternals = append(blockingTaskIDExternals, dependency.BlockingTaskIDExternal)
+		}
+	}
+	tasks, err := database.GetTasks(api.DB, userID, &[]bson.M{{"$or": []bson.M{
+		{"_id": bson.M{"$in": taskIDs}},
+		{"id_external": bson.M{"$in": blockingTaskIDExternals}},
+	}}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	externalTaskIDs := map[externalTaskKey]primitive.ObjectID{}
+	for _, task := range *tasks {
+		graph.isActive[task.ID] = (task.IsCompleted == nil || !*task.IsCompleted) && (task.IsDeleted == nil || !*task.IsDeleted)
+		externalTaskIDs[externalTaskKey{sourceID: task.SourceID, idExternal: task.IDExternal}] = task.ID
+	}
+
+	for _, dependency := range *dependencies {
+		blockingTaskID := dependency.BlockingTaskID
+		if blockingTaskID == primitive.NilObjectID {
+			// the blocking issue hasn't been fetched, i.e. it's assigned to someone else
+			var exists bool
+			blockingTaskID, exists = externalTaskIDs[externalTaskKey{sourceID: dependency.BlockingTaskSourceID, idExternal: dependency.BlockingTaskIDExternal}]
+			if !exists {
+				continue
+			}
+		}
+		isDuplicate := false
+		for _, existingBlockingTaskID := range graph.blockedBy[dependency.TaskID] {
+			isDuplicate = isDuplicate || existingBlockingTaskID == blockingTaskID
+		}
+		if !isDuplicate {
+			graph.blockedBy[dependency.TaskID] = append(graph.blockedBy[dependency.TaskID], blockingTaskID)
+		}
+	}
+	return &graph, nil
+}
+
+// getActiveBlockingTaskIDs returns the tasks which still block the task
+func (graph *taskDependencyGraph) getActiveBlockingTaskIDs(taskID primitive.ObjectID) []primitive.ObjectID {
+	blockingTaskIDs := []primitive.ObjectID{}
+	for _, blockingTaskID := range graph.blockedBy[taskID] {
+		if graph.isActive[blockingTaskID] {
+			blockingTaskIDs = append(blockingTaskIDs, blockingTaskID)
+		}
+	}
+	return blockingTaskIDs
+}
+
+// wouldCreateCycle checks whether the blocking task is already (transitively) blocked by the task
+func (graph *taskDependencyGraph) wouldCreateCycle(taskID primitive.ObjectID, blockingTaskID primitive.ObjectID) bool {
+	visited := map[primitive.ObjectID]bool{}
+	toVisit := []primitive.ObjectID{blockingTaskID}
+	for len(toVisit) > 0 {
+		currentTaskID := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		if currentTaskID == taskID {
+			return true
+		}
+		if visited[currentTaskID] {
+			continue
+		}
+		visited[currentTaskID] = true
+		toVisit = append(toVisit, graph.blockedBy[currentTaskID]...)
+	}
+	return false
+}
+
+// getUnblockedTaskIDs returns the active tasks which were blocked by the completed task and have no other active blockers
+func (graph *taskDependencyGraph) getUnblockedTaskIDs(completedTaskID primitive.ObjectID) []primitive.ObjectID {
+	graph.isActive[completedTaskID] = false
+	unblockedTaskIDs := []primitive.ObjectID{}
+	for taskID, blockingTaskIDs := range graph.blockedBy {
+		if !graph.isActive[taskID] || len(graph.getActiveBlockingTaskIDs(taskID)) > 0 {
+			continue
+		}
+		for _, blockingTaskID := range blockingTaskIDs {
+			if blockingTaskID == completedTaskID {
+				unblockedTaskIDs = append(unblockedTaskIDs, taskID)
+				break
+			}
+		}
+	}
+	sort.Slice(unblockedTaskIDs, func(i, j int) bool {
+		return unblockedTaskIDs[i].Hex() < unblockedTaskIDs[j].Hex()
+	})
+	return unblockedTaskIDs
+}
+
+// surfaceUnblockedTasks notifies clients of the tasks unblocked by completing a task
+func (api *API) surfaceUnblockedTasks(userID primitive.ObjectID, completedTaskID primitive.ObjectID) []primitive.ObjectID {
+	graph, err := api.getTaskDependencyGraph(userID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load task dependencies")
+		return []primitive.ObjectID{}
+	}
+	unblockedTaskIDs := graph.getUnblockedTaskIDs(completedTaskID)
+	for _, unblockedTaskID := range unblockedTaskIDs {
+		database.PublishChange(api.DB, userID, database.ChangeTypeTask, unblockedTaskID)
+	}
+	return unblockedTaskIDs
+}
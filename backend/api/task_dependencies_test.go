@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTaskDependencyGraph(t *testing.T) {
+	taskA := primitive.NewObjectID()
+	taskB := primitive.NewObjectID()
+	taskC := primitive.NewObjectID()
+	taskD := primitive.NewObjectID()
+	// A is blocked by B, which is blocked by C. D is blocked by both B and C
+	graph := taskDependencyGraph{
+		blockedBy: map[primitive.ObjectID][]primitive.ObjectID{
+			taskA: {taskB},
+			taskB: {taskC},
+			taskD: {taskB, taskC},
+		},
+		isActive: map[primitive.ObjectID]bool{taskA: true, taskB: true, taskC: true, taskD: true},
+	}
+
+	t.Run("WouldCreateCycle", func(t *testing.T) {
+		assert.True(t, graph.wouldCreateCycle(taskA, taskA))
+		assert.True(t, graph.wouldCreateCycle(taskC, taskA))
+		assert.True(t, graph.wouldCreateCycle(taskB, taskA))
+		assert.False(t, graph.wouldCreateCycle(taskA, taskC))
+		assert.True(t, graph.wouldCreateCycle(taskC, taskD))
+		assert.False(t, graph.wouldCreateCycle(taskD, taskA))
+	})
+	t.Run("GetUnblockedTaskIDs", func(t *testing.T) {
+		assert.Equal(t, []primitive.ObjectID{taskB}, graph.getUnblockedTaskIDs(taskC))
+		assert.Equal(t, []primitive.ObjectID{taskB}, graph.getActiveBlockingTaskIDs(taskD))
+		unblockedTaskIDs := graph.getUnblockedTaskIDs(taskB)
+		assert.ElementsMatch(t, []primitive.ObjectID{taskA, taskD}, unblockedTaskIDs)
+		assert.Equal(t, []primitive.ObjectID{}, graph.getActiveBlockingTaskIDs(taskD))
+	})
+}
+
+func TestTaskDependencies(t *testing.T) {
+	authToken := login("test_task_dependencies@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	notCompleted := false
+	blockedTaskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	blockingTaskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	linearTaskID := insertTestTask(t, userID, database.Task{UserID: userID, IsCompleted: &notCompleted, SourceID: external.TASK_SOURCE_ID_LINEAR, IDExternal: "linear-issue-id"})
+	otherUserTaskID := insertTestTask(t, primitive.NewObjectID(), database.Task{UserID: primitive.NewObjectID(), SourceID: external.TASK_SOURCE_ID_GT_TASK})
+
+	addDependency := func(taskID string, blockingTaskID string, expectedStatus int) []byte {
+		bodyParams, err := json.Marshal(TaskDependencyAddParams{BlockingTaskID: blockingTaskID})
+		assert.NoError(t, err)
+		return ServeRequest(t, authToken, "POST", "/tasks/"+taskID+"/blocked_by/", bytes.NewBuffer(bodyParams), expectedStatus, api)
+	}
+	getBlockedBy := func(taskID string) []primitive.ObjectID {
+		response := ServeRequest(t, authToken, "GET", "/tasks/detail/"+taskID+"/", nil, http.StatusOK, api)
+		var result TaskResultV4
+		assert.NoError(t, json.Unmarshal(response, &result))
+		return result.BlockedBy
+	}
+
+	UnauthorizedTest(t, "POST", "/tasks/"+blockedTaskID+"/blocked_by/", nil)
+	t.Run("InvalidBlockingTask", func(t *testing.T) {
+		addDependency(blockedTaskID, "invalid", http.StatusBadRequest)
+		addDependency(blockedTaskID, otherUserTaskID, http.StatusNotFound)
+	})
+	t.Run("Success", func(t *testing.T) {
+		addDependency(blockedTaskID, blockingTaskID, http.StatusCreated)
+		// adding the same link again is a no-op
+		addDependency(blockedTaskID, blockingTaskID, http.StatusOK)
+		blockingObjectID, _ := primitive.ObjectIDFromHex(blockingTaskID)
+		assert.Equal(t, []primitive.ObjectID{blockingObjectID}, getBlockedBy(blockedTaskID))
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		response := addDependency(blockingTaskID, blockedTaskID, http.StatusBadRequest)
+		assert.Equal(t, `{"detail":"task dependencies cannot form a cycle"}`, string(response))
+		addDependency(blockedTaskID, blockedTaskID, http.StatusBadRequest)
+	})
+	t.Run("ImportedCrossSource", func(t *testing.T) {
+		blockedObjectID, _ := primitive.ObjectIDFromHex(blockedTaskID)
+		err := database.ReplaceImportedTaskDependencies(api.DB, userID, blockedObjectID, external.TASK_SOURCE_ID_LINEAR, []string{"linear-issue-id", "unfetched-issue-id"}, time.Now())
+		assert.NoError(t, err)
+		linearObjectID, _ := primitive.ObjectIDFromHex(linearTaskID)
+		assert.Contains(t, getBlockedBy(blockedTaskID), linearObjectID)
+		assert.Equal(t, 2, len(getBlockedBy(blockedTaskID)))
+		// imported links can't be removed here
+		ServeRequest(t, authToken, "DELETE", "/tasks/"+blockedTaskID+"/blocked_by/"+linearTaskID+"/", nil, http.StatusNotFound, api)
+	})
+	t.Run("CompletingBlockersUnblocks", func(t *testing.T) {
+		// the linear issue is completed in Linear
+		linearObjectID, _ := primitive.ObjectIDFromHex(linearTaskID)
+		_, err := database.GetTaskCollection(api.DB).UpdateOne(context.Background(), bson.M{"_id": linearObjectID}, bson.M{"$set": bson.M{"is_completed": true}})
+		assert.NoError(t, err)
+		response := ServeRequest(t, authToken, "PATCH", "/tasks/modify/"+blockingTaskID+"/", bytes.NewBuffer([]byte(`{"is_completed": true}`)), http.StatusOK, api)
+		assert.Equal(t, `{"unblocked_task_ids":["`+blockedTaskID+`"]}`, string(response))
+		assert.Nil(t, getBlockedBy(blockedTaskID))
+	})
+	t.Run("Delete", func(t *testing.T) {
+		ServeRequest(t, authToken, "DELETE", "/tasks/"+blockedTaskID+"/blocked_by/"+blockingTaskID+"/", nil, http.StatusNoContent, api)
+		ServeRequest(t, authToken, "DELETE", "/tasks/"+blockedTaskID+"/blocked_by/"+blockingTaskID+"/", nil, http.StatusNotFound, api)
+	})
+}
@@ -24,5 +24,22 @@ func (api *API) TaskDetail(c *gin.Context) {
 	}
 
 	taskResult := api.taskToTaskResultV4(task)
+	dependencyGraph, err := api.getTaskDependencyGraph(userID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load task dependencies")
+		Handle500(c)
+		return
+	}
+	blockedBy := dependencyGraph.getActiveBlockingTaskIDs(task.ID)
+	if len(blockedBy) > 0 {
+		taskResult.BlockedBy = blockedBy
+	}
+	taskIDToTimeLogged, err := database.GetTaskTimeLogged(api.DB, userID, api.GetCurrentTime())
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load time logged")
+		Handle500(c)
+		return
+	}
+	taskResult.TimeLoggedSeconds = int64(taskIDToTimeLogged[task.ID].Seconds())
 	c.JSON(200, taskResult)
 }
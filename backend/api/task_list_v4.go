@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
 )
 
 type TaskSourceV4 struct {
@@ -39,6 +40,9 @@ type TaskResultV4 struct {
 	SlackMessageParams       *database.SlackMessageParams `json:"slack_message_params,omitempty"`
 	MeetingPreparationParams *MeetingPreparationParams    `json:"meeting_preparation_params,omitempty"`
 	SubTaskIDs               []primitive.ObjectID         `json:"subtask_ids,omitempty"`
+	BlockedBy                []primitive.ObjectID         `json:"blocked_by,omitempty"`
+	LabelIDs                 []primitive.ObjectID         `json:"label_ids,omitempty"`
+	TimeLoggedSeconds        int64                        `json:"time_logged_seconds,omitempty"`
 	NUXNumber                int                          `json:"id_nux_number,omitempty"`
 	LinearCycle              *database.LinearCycle        `json:"linear_cycle,omitempty"`
 	CreatedAt                string                       `json:"created_at,omitempty"`
@@ -47,10 +51,21 @@ type TaskResultV4 struct {
 	DeletedAt                string                       `json:"deleted_at,omitempty"`
 	SharedAccess             string                       `json:"shared_access,omitempty"`
 	SharedUntil              string                       `json:"shared_until,omitempty"`
+	ReminderOffsets          *[]int                       `json:"reminder_offsets,omitempty"`
 }
 
 func (api *API) TasksListV4(c *gin.Context) {
 	userID := getUserIDFromContext(c)
+	// optionally only return tasks with the label, from any source
+	filterLabelID := primitive.NilObjectID
+	if labelIDHex := c.Query("label_id"); labelIDHex != "" {
+		var err error
+		filterLabelID, err = primitive.ObjectIDFromHex(labelIDHex)
+		if err != nil {
+			c.JSON(400, gin.H{"detail": "invalid 'label_id' parameter"})
+			return
+		}
+	}
 	var userObject database.User
 	userCollection := database.GetUserCollection(api.DB)
 	err := userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&userObject)
@@ -91,7 +106,7 @@ func (api *API) TasksListV4(c *gin.Context) {
 	// Remove meeting prep tasks from task list
 	allTasksWithoutMeetingPreparation := []*TaskResultV4{}
 	for _, task := range allTasks {
-		if task.MeetingPreparationParams == nil {
+		if task.MeetingPreparationParams == nil && (filterLabelID == primitive.NilObjectID || slices.Contains(task.LabelIDs, filterLabelID)) {
 			allTasksWithoutMeetingPreparation = append(allTasksWithoutMeetingPreparation, task)
 		}
 	}
@@ -109,7 +124,26 @@ func (api *API) mergeTasksV4(
 	allTasks = append(allTasks, *activeTasks...)
 	allTasks = append(allTasks, *completedTasks...)
 	allTasks = append(allTasks, *deletedTasks...)
-	return api.taskListToTaskResultListV4(&allTasks), nil
+	taskResults := api.taskListToTaskResultListV4(&allTasks)
+
+	dependencyGraph, err := api.getTaskDependencyGraph(userID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load task dependencies")
+		return nil, err
+	}
+	taskIDToTimeLogged, err := database.GetTaskTimeLogged(db, userID, api.GetCurrentTime())
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load time logged")
+		return nil, err
+	}
+	for _, taskResult := range taskResults {
+		blockedBy := dependencyGraph.getActiveBlockingTaskIDs(taskResult.ID)
+		if len(blockedBy) > 0 {
+			taskResult.BlockedBy = blockedBy
+		}
+		taskResult.TimeLoggedSeconds = int64(taskIDToTimeLogged[taskResult.ID].Seconds())
+	}
+	return taskResults, nil
 }
 
 // shares a lot of duplicate code with taskListToTaskResultList
@@ -226,6 +260,10 @@ func (api *API) taskToTaskResultV4(t *database.Task) *TaskResultV4 {
 		DeletedAt:          t.DeletedAt.Time().UTC().Format(time.RFC3339),
 		SharedUntil:        t.SharedUntil.Time().UTC().Format(time.RFC3339),
 		SharedAccess:       sharedAccess,
+		ReminderOffsets:    t.ReminderOffsets,
+	}
+	if labelIDs := getTaskLabelIDs(t); len(labelIDs) > 0 {
+		taskResult.LabelIDs = labelIDs
 	}
 
 	if t.ParentTaskID != primitive.NilObjectID {
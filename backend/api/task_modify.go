@@ -10,6 +10,7 @@ import (
 	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/jobs"
 	"github.com/jjPlusPlus/task-manager/backend/logging"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -41,6 +42,8 @@ type TaskItemChangeableFields struct {
 	DeletedAt      primitive.DateTime `json:"deleted_at,omitempty" bson:"deleted_at"`
 	SharedAccess   *string            `json:"shared_access,omitempty" bson:"shared_access,omitempty"`
 	SharedUntil    primitive.DateTime `json:"shared_until,omitempty" bson:"shared_until,omitempty"`
+	// minutes before the due date, an empty list turns off reminders for the task
+	ReminderOffsets *[]int `json:"reminder_offsets,omitempty" bson:"reminder_offsets,omitempty"`
 }
 
 type TaskModifyParams struct {
@@ -116,6 +119,7 @@ func (api *API) TaskModify(c *gin.Context) {
 			dueDate = &result
 		}
 	}
+	unblockedTaskIDs := []primitive.ObjectID{}
 	if modifyParams.TaskItemChangeableFields != (TaskItemChangeableFields{}) {
 		updateTask := database.Task{
 			Title:              modifyParams.TaskItemChangeableFields.Title,
@@ -134,6 +138,7 @@ func (api *API) TaskModify(c *gin.Context) {
 			Status:             modifyParams.TaskItemChangeableFields.Task.Status,
 			PreviousStatus:     modifyParams.TaskItemChangeableFields.Task.PreviousStatus,
 			CompletedStatus:    modifyParams.TaskItemChangeableFields.Task.CompletedStatus,
+			ReminderOffsets:    modifyParams.TaskItemChangeableFields.ReminderOffsets,
 		}
 		if dueDate != nil {
 			updateTask.DueDate = dueDate
@@ -182,7 +187,19 @@ func (api *API) TaskModify(c *gin.Context) {
 				updateTask.Title = &tempTitle
 			}
 		}
-		api.UpdateTaskInDB(c, task, userID, &updateTask)
+		err = api.UpdateTaskInDBWithError(task, userID, &updateTask)
+		if err != nil {
+			Handle500(c)
+			return
+		}
+		if updateTask.IsCompleted != nil && *updateTask.IsCompleted && (task.IsCompleted == nil || !*task.IsCompleted) {
+			taskOwnerID := userID
+			if updateTask.UserID != primitive.NilObjectID {
+				taskOwnerID = updateTask.UserID
+			}
+			api.queueTaskWebhookEvent(taskOwnerID, constants.WebhookEventTaskCompleted, task.ID)
+			unblockedTaskIDs = api.surfaceUnblockedTasks(taskOwnerID, task.ID)
+		}
 	}
 
 	// handle reorder task
@@ -191,8 +208,16 @@ func (api *API) TaskModify(c *gin.Context) {
 		if err != nil {
 			return
 		}
+		database.PublishChange(api.DB, userID, database.ChangeTypeTask, taskID)
+		if modifyParams.IDTaskSection != nil && *modifyParams.IDTaskSection != task.IDTaskSection.Hex() {
+			api.queueTaskWebhookEvent(userID, constants.WebhookEventTaskMoved, taskID)
+		}
 	}
 
+	if len(unblockedTaskIDs) > 0 {
+		c.JSON(200, gin.H{"unblocked_task_ids": unblockedTaskIDs})
+		return
+	}
 	c.JSON(200, gin.H{})
 }
 
@@ -242,6 +267,14 @@ func ValidateFields(c *gin.Context, updateFields *TaskItemChangeableFields, task
 			*updateFields.TimeAllocation *= constants.NANOSECONDS_IN_SECOND
 		}
 	}
+	if updateFields.ReminderOffsets != nil {
+		for _, offset := range *updateFields.ReminderOffsets {
+			if offset < 0 || time.Duration(offset)*time.Minute > jobs.REMINDER_MAX_OFFSET {
+				c.JSON(400, gin.H{"detail": "invalid reminder offset"})
+				return false
+			}
+		}
+	}
 	if updateFields.Task.ExternalPriority != nil {
 		matched := false
 		for _, priority := range task.AllExternalPriorities {
@@ -409,5 +442,9 @@ func (api *API) UpdateTaskInDBWithError(task *database.Task, userID primitive.Ob
 		return errors.New("failed to update task")
 	}
 
+	database.PublishChange(api.DB, userID, database.ChangeTypeTask, task.ID)
+	if updateFields.UserID != primitive.NilObjectID && updateFields.UserID != userID {
+		database.PublishChange(api.DB, updateFields.UserID, database.ChangeTypeTask, task.ID)
+	}
 	return nil
 }
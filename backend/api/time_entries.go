@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const TIME_ENTRY_REPORT_DAYS = 7
+
+type TimeEntryStartParams struct {
+	TaskID  *string `json:"task_id"`
+	EventID *string `json:"event_id"`
+}
+
+type TimeEntryReportParams struct {
+	// the start of the week in the user's timezone, days in the report begin at the same time of day
+	DatetimeStart *time.Time `form:"datetime_start" binding:"required"`
+}
+
+type TimeEntryResult struct {
+	ID            primitive.ObjectID `json:"id"`
+	TaskID        primitive.ObjectID `json:"task_id,omitempty"`
+	EventID       primitive.ObjectID `json:"event_id,omitempty"`
+	IsRunning     bool               `json:"is_running"`
+	DatetimeStart string             `json:"datetime_start"`
+	DatetimeEnd   string             `json:"datetime_end,omitempty"`
+	Seconds       int64              `json:"seconds"`
+}
+
+type TimeEntryReportDay struct {
+	DatetimeStart string `json:"datetime_start"`
+	Seconds       int64  `json:"seconds"`
+}
+
+type TimeEntryReportItem struct {
+	TaskID  primitive.ObjectID `json:"task_id,omitempty"`
+	EventID primitive.ObjectID `json:"event_id,omitempty"`
+	Title   string             `json:"title"`
+	Seconds int64              `json:"seconds"`
+}
+
+type TimeEntryReport struct {
+	DatetimeStart string                `json:"datetime_start"`
+	DatetimeEnd   string                `json:"datetime_end"`
+	Seconds       int64                 `json:"seconds"`
+	Days          []TimeEntryReportDay  `json:"days"`
+	Items         []TimeEntryReportItem `json:"items"`
+}
+
+// TimeEntryStart starts a timer for a task or event, stopping the user's running timer if there is one
+func (api *API) TimeEntryStart(c *gin.Context) {
+	var params TimeEntryStartParams
+	err := c.BindJSON(&params)
+	if err != nil || (params.TaskID == nil) == (params.EventID == nil) {
+		c.JSON(400, gin.H{"detail": "exactly one of 'task_id' or 'event_id' is required"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	timeEntry := database.TimeEntry{UserID: userID, IsRunning: true}
+	if params.TaskID != nil {
+		timeEntry.TaskID, err = primitive.ObjectIDFromHex(*params.TaskID)
+		if err == nil {
+			_, err = database.GetTask(api.DB, timeEntry.TaskID, userID)
+		}
+		if err != nil {
+			c.JSON(404, gin.H{"detail": "task not found"})
+			return
+		}
+	} else {
+		timeEntry.EventID, err = primitive.ObjectIDFromHex(*params.EventID)
+		if err == nil {
+			_, err = database.GetCalendarEvent(api.DB, timeEntry.EventID, userID)
+		}
+		if err != nil {
+			c.JSON(404, gin.H{"detail": "event not found"})
+			return
+		}
+	}
+
+	timeNow := api.GetCurrentTime()
+	_, err = api.stopRunningTimeEntry(userID, timeNow)
+	if err != nil && err != mongo.ErrNoDocuments {
+		api.Logger.Error().Err(err).Msg("failed to stop running time entry")
+		Handle500(c)
+		return
+	}
+	timeEntry.DatetimeStart = primitive.NewDateTimeFromTime(timeNow)
+	timeEntry.CreatedAt = primitive.NewDateTimeFromTime(timeNow)
+	insertResult, err := database.GetTimeEntryCollection(api.DB).InsertOne(context.Background(), timeEntry)
+	// a concurrent request started another timer
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(400, gin.H{"detail": "a timer is already running"})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to insert time entry")
+		Handle500(c)
+		return
+	}
+	if timeEntry.TaskID != primitive.NilObjectID {
+		database.PublishChange(api.DB, userID, database.ChangeTypeTask, timeEntry.TaskID)
+	}
+	c.JSON(201, gin.H{"id": insertResult.InsertedID.(primitive.ObjectID).Hex()})
+}
+
+func (api *API) TimeEntryStop(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	timeNow := api.GetCurrentTime()
+	timeEntry, err := api.stopRunningTimeEntry(userID, timeNow)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(404, gin.H{"detail": "no timer is running"})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to stop running time entry")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, timeEntryToTimeEntryResult(*timeEntry, timeNow))
+}
+
+func (api *API) TimeEntryRunning(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	timeEntry, err := database.GetRunningTimeEntry(api.DB, userID)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(404, gin.H{"detail": "no timer is running"})
+		return
+	}
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to fetch running time entry")
+		Handle500(c)
+		return
+	}
+	c.JSON(200, timeEntryToTimeEntryResult(*timeEntry, api.GetCurrentTime()))
+}
+
+// TimeEntryWeeklyReport totals the time logged in the week, per day and per task or event
+func (api *API) TimeEntryWeeklyReport(c *gin.Context) {
+	var params TimeEntryReportParams
+	err := c.BindQuery(&params)
+	if err != nil {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	userID := getUserIDFromContext(c)
+	report, err := api.getTimeEntryReport(userID, *params.DatetimeStart, api.GetCurrentTime())
+	if err != nil {
+		Handle500(c)
+		return
+	}
+	c.JSON(200, report)
+}
+
+func (api *API) getTimeEntryReport(userID primitive.ObjectID, datetimeStart time.Time, timeNow time.Time) (*TimeEntryReport, error) {
+	datetimeEnd := datetimeStart.AddDate(0, 0, TIME_ENTRY_REPORT_DAYS)
+	timeEntries, err := database.GetTimeEntriesInRange(api.DB, userID, datetimeStart, datetimeEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	report := TimeEntryReport{
+		DatetimeStart: datetimeStart.Format(time.RFC3339),
+		DatetimeEnd:   datetimeEnd.Format(time.RFC3339),
+		Days:          []TimeEntryReportDay{},
+		Items:         []TimeEntryReportItem{},
+	}
+	for day := 0; day < TIME_ENTRY_REPORT_DAYS; day++ {
+		dayStart := datetimeStart.AddDate(0, 0, day)
+		var duration time.Duration
+		for _, timeEntry := range *timeEntries {
+			duration += database.GetTimeEntryDurationInRange(timeEntry, dayStart, dayStart.AddDate(0, 0, 1), timeNow)
+		}
+		report.Days = append(report.Days, TimeEntryReportDay{
+			DatetimeStart: dayStart.Format(time.RFC3339),
+			Seconds:       int64(duration.Seconds()),
+		})
+		report.Seconds += int64(duration.Seconds())
+	}
+
+	// items are ordered by when they were first worked on in the week
+	itemIndexes := map[primitive.ObjectID]int{}
+	for _, timeEntry := range *timeEntries {
+		itemID := timeEntry.TaskID
+		if itemID == primitive.NilObjectID {
+			itemID = timeEntry.EventID
+		}
+		index, exists := itemIndexes[itemID]
+		if !exists {
+			index = len(report.Items)
+			itemIndexes[itemID] = index
+			report.Items = append(report.Items, TimeEntryReportItem{
+				TaskID:  timeEntry.TaskID,
+				EventID: timeEntry.EventID,
+				Title:   api.getTimeEntryItemTitle(timeEntry, userID),
+			})
+		}
+		report.Items[index].Seconds += int64(database.GetTimeEntryDurationInRange(timeEntry, datetimeStart, datetimeEnd, timeNow).Seconds())
+	}
+	return &report, nil
+}
+
+// getTimeEntryItemTitle returns an empty title if the task or event has since been removed
+func (api *API) getTimeEntryItemTitle(timeEntry database.TimeEntry, userID primitive.ObjectID) string {
+	if timeEntry.TaskID != primitive.NilObjectID {
+		task, err := database.GetTask(api.DB, timeEntry.TaskID, userID)
+		if err != nil || task.Title == nil {
+			return ""
+		}
+		return *task.Title
+	}
+	event, err := database.GetCalendarEvent(api.DB, timeEntry.EventID, userID)
+	if err != nil {
+		return ""
+	}
+	return event.Title
+}
+
+func (api *API) stopRunningTimeEntry(userID primitive.ObjectID, timeNow time.Time) (*database.TimeEntry, error) {
+	var timeEntry database.TimeEntry
+	err := database.GetTimeEntryCollection(api.DB).FindOneAndUpdate(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"user_id": userID}, {"is_running": true}}},
+		bson.M{"$set": bson.M{"is_running": false, "datetime_end": primitive.NewDateTimeFromTime(timeNow)}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&timeEntry)
+	if err != nil {
+		return nil, err
+	}
+	if timeEntry.TaskID != primitive.NilObjectID {
+		database.PublishChange(api.DB, userID, database.ChangeTypeTask, timeEntry.TaskID)
+	}
+	return &timeEntry, nil
+}
+
+func timeEntryToTimeEntryResult(timeEntry database.TimeEntry, timeNow time.Time) TimeEntryResult {
+	result := TimeEntryResult{
+		ID:            timeEntry.ID,
+		TaskID:        timeEntry.TaskID,
+		EventID:       timeEntry.EventID,
+		IsRunning:     timeEntry.IsRunning,
+		DatetimeStart: timeEntry.DatetimeStart.Time().UTC().Format(time.RFC3339),
+	}
+	datetimeEnd := timeNow
+	if !timeEntry.IsRunning {
+		datetimeEnd = timeEntry.DatetimeEnd.Time()
+		result.DatetimeEnd = datetimeEnd.UTC().Format(time.RFC3339)
+	}
+	result.Seconds = int64(datetimeEnd.Sub(timeEntry.DatetimeStart.Time()).Seconds())
+	return result
+}
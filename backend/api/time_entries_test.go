@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTimeEntries(t *testing.T) {
+	authToken := login("test_time_entries@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	weekStart := time.Date(2023, time.March, 6, 8, 0, 0, 0, time.UTC)
+	timeNow := weekStart.Add(9 * time.Hour)
+	api.OverrideTime = &timeNow
+
+	taskTitle := "Write launch plan"
+	taskID := insertTestTask(t, userID, database.Task{UserID: userID, Title: &taskTitle, SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	otherUserTaskID := insertTestTask(t, primitive.NewObjectID(), database.Task{UserID: primitive.NewObjectID(), SourceID: external.TASK_SOURCE_ID_GT_TASK})
+	eventID, err := database.GetCalendarEventCollection(api.DB).InsertOne(context.Background(), database.CalendarEvent{UserID: userID, Title: "Design review"})
+	assert.NoError(t, err)
+	eventIDHex := eventID.InsertedID.(primitive.ObjectID).Hex()
+
+	getTimeLogged := func() int64 {
+		response := ServeRequest(t, authToken, "GET", "/tasks/detail/"+taskID+"/", nil, http.StatusOK, api)
+		var result TaskResultV4
+		assert.NoError(t, json.Unmarshal(response, &result))
+		return result.TimeLoggedSeconds
+	}
+
+	UnauthorizedTest(t, "POST", "/time_entries/start/", nil)
+	t.Run("StartInvalid", func(t *testing.T) {
+		ServeRequest(t, authToken, "POST", "/time_entries/start/", bytes.NewBuffer([]byte(`{}`)), http.StatusBadRequest, api)
+		ServeRequest(t, authToken, "POST", "/time_entries/start/", bytes.NewBuffer([]byte(`{"task_id": "`+taskID+`", "event_id": "`+eventIDHex+`"}`)), http.StatusBadRequest, api)
+		ServeRequest(t, authToken, "POST", "/time_entries/start/", bytes.NewBuffer([]byte(`{"task_id": "`+otherUserTaskID+`"}`)), http.StatusNotFound, api)
+		ServeRequest(t, authToken, "POST", "/time_entries/stop/", nil, http.StatusNotFound, api)
+		ServeRequest(t, authToken, "GET", "/time_entries/running/", nil, http.StatusNotFound, api)
+	})
+	t.Run("StartingStopsRunningTimer", func(t *testing.T) {
+		ServeRequest(t, authToken, "POST", "/time_entries/start/", bytes.NewBuffer([]byte(`{"task_id": "`+taskID+`"}`)), http.StatusCreated, api)
+		timeNow = timeNow.Add(90 * time.Minute)
+		assert.Equal(t, int64(90*60), getTimeLogged())
+
+		ServeRequest(t, authToken, "POST", "/time_entries/start/", bytes.NewBuffer([]byte(`{"event_id": "`+eventIDHex+`"}`)), http.StatusCreated, api)
+		timeNow = timeNow.Add(30 * time.Minute)
+		response := ServeRequest(t, authToken, "GET", "/time_entries/running/", nil, http.StatusOK, api)
+		var running TimeEntryResult
+		assert.NoError(t, json.Unmarshal(response, &running))
+		assert.Equal(t, eventIDHex, running.EventID.Hex())
+		assert.True(t, running.IsRunning)
+		assert.Equal(t, int64(30*60), running.Seconds)
+		assert.Equal(t, int64(90*60), getTimeLogged())
+	})
+	t.Run("Stop", func(t *testing.T) {
+		response := ServeRequest(t, authToken, "POST", "/time_entries/stop/", nil, http.StatusOK, api)
+		var stopped TimeEntryResult
+		assert.NoError(t, json.Unmarshal(response, &stopped))
+		assert.False(t, stopped.IsRunning)
+		assert.Equal(t, "2023-03-06T19:00:00Z", stopped.DatetimeEnd)
+		ServeRequest(t, authToken, "POST", "/time_entries/stop/", nil, http.StatusNotFound, api)
+	})
+	t.Run("WeeklyReport", func(t *testing.T) {
+		ServeRequest(t, authToken, "GET", "/time_entries/report/weekly/", nil, http.StatusBadRequest, api)
+		response := ServeRequest(t, authToken, "GET", "/time_entries/report/weekly/?datetime_start=2023-03-06T00:00:00-08:00", nil, http.StatusOK, api)
+		var report TimeEntryReport
+		assert.NoError(t, json.Unmarshal(response, &report))
+		assert.Equal(t, "2023-03-06T00:00:00-08:00", report.DatetimeStart)
+		assert.Equal(t, "2023-03-13T00:00:00-08:00", report.DatetimeEnd)
+		assert.Equal(t, int64(120*60), report.Seconds)
+		assert.Equal(t, 7, len(report.Days))
+		assert.Equal(t, int64(120*60), report.Days[0].Seconds)
+		assert.Equal(t, int64(0), report.Days[1].Seconds)
+		assert.Equal(t, 2, len(report.Items))
+		assert.Equal(t, TimeEntryReportItem{TaskID: report.Items[0].TaskID, Title: taskTitle, Seconds: 90 * 60}, report.Items[0])
+		assert.Equal(t, taskID, report.Items[0].TaskID.Hex())
+		assert.Equal(t, "Design review", report.Items[1].Title)
+		assert.Equal(t, int64(30*60), report.Items[1].Seconds)
+	})
+}
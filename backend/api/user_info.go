@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/utils"
@@ -20,11 +21,13 @@ type UserInfo struct {
 	IsCompanyEmail      bool   `json:"is_company_email"`
 	LinearName          string `json:"linear_name,omitempty"`
 	LinearDisplayName   string `json:"linear_display_name,omitempty"`
+	Timezone            string `json:"timezone,omitempty"`
 }
 
 type UserInfoParams struct {
-	AgreedToTerms      *bool `json:"agreed_to_terms" bson:"agreed_to_terms,omitempty"`
-	OptedIntoMarketing *bool `json:"opted_into_marketing" bson:"opted_into_marketing,omitempty"`
+	AgreedToTerms      *bool   `json:"agreed_to_terms" bson:"agreed_to_terms,omitempty"`
+	OptedIntoMarketing *bool   `json:"opted_into_marketing" bson:"opted_into_marketing,omitempty"`
+	Timezone           *string `json:"timezone" bson:"timezone,omitempty"` // IANA timezone, i.e. America/Los_Angeles
 }
 
 func (api *API) UserInfoGet(c *gin.Context) {
@@ -48,6 +51,7 @@ func (api *API) UserInfoGet(c *gin.Context) {
 		IsCompanyEmail:      isCompanyEmail(userObject.Email),
 		LinearName:          userObject.LinearName,
 		LinearDisplayName:   userObject.LinearDisplayName,
+		Timezone:            userObject.Timezone,
 	})
 }
 
@@ -68,6 +72,12 @@ func (api *API) UserInfoUpdate(c *gin.Context) {
 		c.JSON(400, gin.H{"detail": "invalid or missing parameters."})
 		return
 	}
+	if params.Timezone != nil {
+		if _, err := time.LoadLocation(*params.Timezone); *params.Timezone == "" || err != nil {
+			c.JSON(400, gin.H{"detail": "invalid timezone"})
+			return
+		}
+	}
 
 	userID, _ := c.Get("user")
 	userCollection := database.GetUserCollection(api.DB)
@@ -91,6 +91,22 @@ func TestUserInfo(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "{\"detail\":\"invalid or missing parameters.\"}", string(body))
 	})
+	t.Run("InvalidTimezone", func(t *testing.T) {
+		api, dbCleanup := GetAPIWithDBCleanup()
+		defer dbCleanup()
+		router := GetRouter(api)
+		request, _ := http.NewRequest(
+			"PATCH",
+			"/user_info/",
+			bytes.NewBuffer([]byte(`{"timezone":"Pacific Time"}`)))
+		request.Header.Add("Authorization", "Bearer "+authToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"detail\":\"invalid timezone\"}", string(body))
+	})
 	t.Run("SuccessUpdate", func(t *testing.T) {
 		api, dbCleanup := GetAPIWithDBCleanup()
 		defer dbCleanup()
@@ -144,4 +160,27 @@ func TestUserInfo(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "{\"agreed_to_terms\":true,\"opted_into_marketing\":false,\"business_mode_enabled\":false,\"name\":\"\",\"is_employee\":true,\"email\":\"userinfo2@resonant-kelpie-404a42.netlify.app\",\"is_company_email\":true}", string(body))
 	})
+	t.Run("SuccessUpdateTimezone", func(t *testing.T) {
+		api, dbCleanup := GetAPIWithDBCleanup()
+		defer dbCleanup()
+		router := GetRouter(api)
+		timezoneAuthToken := login("userinfo_timezone@resonant-kelpie-404a42.netlify.app", "")
+		request, _ := http.NewRequest(
+			"PATCH",
+			"/user_info/",
+			bytes.NewBuffer([]byte(`{"timezone":"America/Los_Angeles"}`)))
+		request.Header.Add("Authorization", "Bearer "+timezoneAuthToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		request, _ = http.NewRequest("GET", "/user_info/", nil)
+		request.Header.Add("Authorization", "Bearer "+timezoneAuthToken)
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"agreed_to_terms\":false,\"opted_into_marketing\":false,\"business_mode_enabled\":false,\"name\":\"\",\"is_employee\":true,\"email\":\"userinfo_timezone@resonant-kelpie-404a42.netlify.app\",\"is_company_email\":true,\"timezone\":\"America/Los_Angeles\"}", string(body))
+	})
 }
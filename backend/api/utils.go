@@ -11,6 +11,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/jjPlusPlus/task-manager/backend/logging"
@@ -99,6 +100,14 @@ func UserTokenMiddleware(db *mongo.Database) func(c *gin.Context) {
 			// This means the auth token format was incorrect
 			return
 		}
+		if isPersonalAccessToken(token) {
+			personalAccessToken, err := getValidPersonalAccessToken(db, token)
+			if err == nil {
+				c.Set("user", personalAccessToken.UserID)
+				c.Set("token_scopes", personalAccessToken.Scopes)
+			}
+			return
+		}
 		internalAPITokenCollection := database.GetInternalTokenCollection(db)
 		var internalToken database.InternalAPIToken
 		err = internalAPITokenCollection.FindOne(context.Background(), bson.M{"token": token}).Decode(&internalToken)
@@ -124,6 +133,12 @@ func AuthorizationMiddleware(db *mongo.Database) func(c *gin.Context) {
 			}
 			log.Error().Err(err).Msg("token auth failed")
 			c.AbortWithStatusJSON(401, gin.H{"detail": "unauthorized"})
+			return
+		}
+		// personal access tokens are limited to the endpoints their scopes allow
+		if scopes, exists := c.Get("token_scopes"); exists && !personalAccessTokenHasAccess(scopes.([]string), c.Request.Method, c.FullPath()) {
+			c.AbortWithStatusJSON(403, gin.H{"detail": "token does not have the required scope"})
+			return
 		}
 	}
 }
@@ -149,6 +164,9 @@ func LoggingMiddleware(db *mongo.Database) func(c *gin.Context) {
 
 func getToken(c *gin.Context) (string, error) {
 	token := c.Request.Header.Get("Authorization")
+	if strings.HasPrefix(token, "Bearer "+constants.PersonalAccessTokenPrefix) {
+		return token[7:], nil
+	}
 	//Token is 36 characters + 6 for Bearer prefix + 1 for space = 43
 	if len(token) != 43 {
 		return "", errors.New("incorrect auth token format")
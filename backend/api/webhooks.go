@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/jobs"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/exp/slices"
+)
+
+const WEBHOOK_DELIVERIES_LIST_LIMIT = 100
+
+type WebhookCreateParams struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+}
+
+type WebhookResult struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type WebhookDeliveryResult struct {
+	ID          string `json:"id"`
+	EventType   string `json:"event_type"`
+	Payload     string `json:"payload"`
+	Attempts    int    `json:"attempts"`
+	IsDelivered bool   `json:"is_delivered"`
+	IsAbandoned bool   `json:"is_abandoned"`
+	LastError   string `json:"last_error,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type webhookNoteData struct {
+	ID          primitive.ObjectID `json:"id"`
+	Title       string             `json:"title"`
+	SharedUntil string             `json:"shared_until,omitempty"`
+}
+
+func (api *API) WebhookCreate(c *gin.Context) {
+	var params WebhookCreateParams
+	err := c.BindJSON(&params)
+	if err != nil || len(params.EventTypes) == 0 {
+		c.JSON(400, gin.H{"detail": "invalid or missing parameter"})
+		return
+	}
+	parsedURL, err := url.Parse(params.URL)
+	if err != nil || parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		c.JSON(400, gin.H{"detail": "url must be a valid https URL"})
+		return
+	}
+	for _, eventType := range params.EventTypes {
+		if !slices.Contains(constants.WebhookEventTypes, eventType) {
+			c.JSON(400, gin.H{"detail": "invalid event type: " + eventType})
+			return
+		}
+	}
+
+	secretBytes := make([]byte, 32)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to generate webhook secret")
+		Handle500(c)
+		return
+	}
+	secret := hex.EncodeToString(secretBytes)
+
+	userID := getUserIDFromContext(c)
+	insertResult, err := database.GetWebhookSubscriptionCollection(api.DB).InsertOne(context.Background(), database.WebhookSubscription{
+		UserID:     userID,
+		URL:        params.URL,
+		Secret:     secret,
+		EventTypes: params.EventTypes,
+		CreatedAt:  primitive.NewDateTimeFromTime(api.GetCurrentTime()),
+	})
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to create webhook")
+		Handle500(c)
+		return
+	}
+
+	// the secret is only returned here, and is used by receivers to verify X-GeneralTask-Signature
+	c.JSON(201, gin.H{"id": insertResult.InsertedID.(primitive.ObjectID), "secret": secret})
+}
+
+func (api *API) WebhooksList(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	var subscriptions []database.WebhookSubscription
+	err := database.FindWithCollection(database.GetWebhookSubscriptionCollection(api.DB), userID, &[]bson.M{{"is_deleted": false}}, &subscriptions, nil)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to fetch webhooks")
+		Handle500(c)
+		return
+	}
+
+	results := []WebhookResult{}
+	for _, subscription := range subscriptions {
+		results = append(results, WebhookResult{
+			ID:         subscription.ID.Hex(),
+			URL:        subscription.URL,
+			EventTypes: subscription.EventTypes,
+			CreatedAt:  subscription.CreatedAt.Time().UTC().Format(time.RFC3339),
+		})
+	}
+	c.JSON(200, results)
+}
+
+func (api *API) WebhookDelete(c *gin.Context) {
+	webhookID, err := primitive.ObjectIDFromHex(c.Param("webhook_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	res, err := database.GetWebhookSubscriptionCollection(api.DB).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"_id": webhookID},
+			{"user_id": userID},
+			{"is_deleted": false},
+		}},
+		bson.M{"$set": bson.M{"is_deleted": true}},
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to delete webhook")
+		Handle500(c)
+		return
+	}
+	if res.MatchedCount == 0 {
+		Handle404(c)
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+func (api *API) WebhookDeliveriesList(c *gin.Context) {
+	webhookID, err := primitive.ObjectIDFromHex(c.Param("webhook_id"))
+	if err != nil {
+		Handle404(c)
+		return
+	}
+	userID := getUserIDFromContext(c)
+	var deliveries []database.WebhookDelivery
+	err = database.FindWithCollection(
+		database.GetWebhookDeliveryCollection(api.DB),
+		userID,
+		&[]bson.M{{"subscription_id": webhookID}},
+		&deliveries,
+		options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(WEBHOOK_DELIVERIES_LIST_LIMIT),
+	)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to fetch webhook deliveries")
+		Handle500(c)
+		return
+	}
+
+	results := []WebhookDeliveryResult{}
+	for _, delivery := range deliveries {
+		results = append(results, WebhookDeliveryResult{
+			ID:          delivery.ID.Hex(),
+			EventType:   delivery.EventType,
+			Payload:     delivery.Payload,
+			Attempts:    delivery.Attempts,
+			IsDelivered: delivery.IsDelivered,
+			IsAbandoned: delivery.IsAbandoned,
+			LastError:   delivery.LastError,
+			CreatedAt:   delivery.CreatedAt.Time().UTC().Format(time.RFC3339),
+		})
+	}
+	c.JSON(200, results)
+}
+
+func (api *API) queueWebhookEvent(userID primitive.ObjectID, eventType string, data interface{}) {
+	_, err := jobs.QueueWebhookEvent(api.DB, userID, eventType, data, api.GetCurrentTime())
+	if err != nil {
+		api.Logger.Error().Err(err).Str("eventType", eventType).Msg("failed to queue webhook event")
+	}
+}
+
+func (api *API) queueTaskWebhookEvent(userID primitive.ObjectID, eventType string, taskID primitive.ObjectID) {
+	task, err := database.GetTask(api.DB, taskID, userID)
+	if err != nil {
+		api.Logger.Error().Err(err).Msg("failed to load task for webhook event")
+		return
+	}
+	api.queueWebhookEvent(userID, eventType, jobs.GetWebhookTaskData(task))
+}
+
+func getWebhookNoteData(note *database.Note) webhookNoteData {
+	data := webhookNoteData{ID: note.ID}
+	if note.Title != nil {
+		data.Title = *note.Title
+	}
+	if note.SharedUntil != 0 {
+		data.SharedUntil = note.SharedUntil.Time().UTC().Format(time.RFC3339)
+	}
+	return data
+}
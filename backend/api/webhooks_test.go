@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/jobs"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type webhookCreateResponse struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func createTestWebhook(t *testing.T, api *API, authToken string, params WebhookCreateParams) webhookCreateResponse {
+	bodyParams, err := json.Marshal(params)
+	assert.NoError(t, err)
+	response := ServeRequest(t, authToken, "POST", "/webhooks/", bytes.NewBuffer(bodyParams), http.StatusCreated, api)
+	var result webhookCreateResponse
+	assert.NoError(t, json.Unmarshal(response, &result))
+	return result
+}
+
+func TestWebhookCreate(t *testing.T) {
+	authToken := login("test_webhook_create@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	UnauthorizedTest(t, "POST", "/webhooks/", nil)
+	t.Run("InvalidURL", func(t *testing.T) {
+		bodyParams, err := json.Marshal(WebhookCreateParams{URL: "ftp://example.com", EventTypes: []string{constants.WebhookEventTaskCreated}})
+		assert.NoError(t, err)
+		ServeRequest(t, authToken, "POST", "/webhooks/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+	})
+	t.Run("InsecureURL", func(t *testing.T) {
+		bodyParams, err := json.Marshal(WebhookCreateParams{URL: "http://example.com/hook", EventTypes: []string{constants.WebhookEventTaskCreated}})
+		assert.NoError(t, err)
+		response := ServeRequest(t, authToken, "POST", "/webhooks/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+		assert.Equal(t, `{"detail":"url must be a valid https URL"}`, string(response))
+	})
+	t.Run("InvalidEventType", func(t *testing.T) {
+		bodyParams, err := json.Marshal(WebhookCreateParams{URL: "https://example.com/hook", EventTypes: []string{"task.exploded"}})
+		assert.NoError(t, err)
+		response := ServeRequest(t, authToken, "POST", "/webhooks/", bytes.NewBuffer(bodyParams), http.StatusBadRequest, api)
+		assert.Equal(t, `{"detail":"invalid event type: task.exploded"}`, string(response))
+	})
+	t.Run("Success", func(t *testing.T) {
+		created := createTestWebhook(t, api, authToken, WebhookCreateParams{
+			URL:        "https://example.com/hook",
+			EventTypes: []string{constants.WebhookEventTaskCompleted},
+		})
+		assert.Equal(t, 64, len(created.Secret))
+
+		response := ServeRequest(t, authToken, "GET", "/webhooks/", nil, http.StatusOK, api)
+		var results []WebhookResult
+		assert.NoError(t, json.Unmarshal(response, &results))
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, created.ID, results[0].ID)
+		assert.Equal(t, "https://example.com/hook", results[0].URL)
+		assert.Equal(t, []string{constants.WebhookEventTaskCompleted}, results[0].EventTypes)
+	})
+}
+
+func TestWebhookDelete(t *testing.T) {
+	authToken := login("test_webhook_delete@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+
+	UnauthorizedTest(t, "DELETE", "/webhooks/"+primitive.NewObjectID().Hex()+"/", nil)
+	t.Run("NotFound", func(t *testing.T) {
+		ServeRequest(t, authToken, "DELETE", "/webhooks/"+primitive.NewObjectID().Hex()+"/", nil, http.StatusNotFound, api)
+	})
+	t.Run("Success", func(t *testing.T) {
+		created := createTestWebhook(t, api, authToken, WebhookCreateParams{
+			URL:        "https://example.com/hook",
+			EventTypes: []string{constants.WebhookEventTaskCreated},
+		})
+		ServeRequest(t, authToken, "DELETE", "/webhooks/"+created.ID+"/", nil, http.StatusNoContent, api)
+		response := ServeRequest(t, authToken, "GET", "/webhooks/", nil, http.StatusOK, api)
+		assert.Equal(t, "[]", string(response))
+	})
+}
+
+func TestWebhookEvents(t *testing.T) {
+	authToken := login("test_webhook_events@resonant-kelpie-404a42.netlify.app", "")
+	api, dbCleanup := GetAPIWithDBCleanup()
+	defer dbCleanup()
+	userID := getUserIDFromAuthToken(t, api.DB, authToken)
+
+	// not a public address so deliveries are recorded but never succeed
+	created := createTestWebhook(t, api, authToken, WebhookCreateParams{
+		URL:        "https://localhost:1/hook",
+		EventTypes: []string{constants.WebhookEventTaskCreated, constants.WebhookEventTaskCompleted},
+	})
+	subscriptionID, _ := primitive.ObjectIDFromHex(created.ID)
+	getDeliveries := func() []database.WebhookDelivery {
+		var deliveries []database.WebhookDelivery
+		err := database.FindWithCollection(database.GetWebhookDeliveryCollection(api.DB), userID, &[]bson.M{{"subscription_id": subscriptionID}}, &deliveries, nil)
+		assert.NoError(t, err)
+		return deliveries
+	}
+
+	var taskID string
+	t.Run("TaskCreated", func(t *testing.T) {
+		response := ServeRequest(t, authToken, "POST", "/tasks/create/gt_task/", bytes.NewBuffer([]byte(`{"title": "webhook task"}`)), http.StatusOK, api)
+		var result map[string]string
+		assert.NoError(t, json.Unmarshal(response, &result))
+		taskID = result["task_id"]
+
+		deliveries := getDeliveries()
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, constants.WebhookEventTaskCreated, deliveries[0].EventType)
+		var event struct {
+			Type string          `json:"type"`
+			Data jobs.WebhookTaskData `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &event))
+		assert.Equal(t, constants.WebhookEventTaskCreated, event.Type)
+		assert.Equal(t, taskID, event.Data.ID.Hex())
+		assert.Equal(t, "webhook task", event.Data.Title)
+	})
+	t.Run("TaskCompleted", func(t *testing.T) {
+		ServeRequest(t, authToken, "PATCH", "/tasks/modify/"+taskID+"/", bytes.NewBuffer([]byte(`{"is_completed": true}`)), http.StatusOK, api)
+		deliveries := getDeliveries()
+		assert.Equal(t, 2, len(deliveries))
+		assert.Equal(t, constants.WebhookEventTaskCompleted, deliveries[1].EventType)
+
+		// completing an already completed task does not fire again
+		ServeRequest(t, authToken, "PATCH", "/tasks/modify/"+taskID+"/", bytes.NewBuffer([]byte(`{"is_completed": true}`)), http.StatusOK, api)
+		assert.Equal(t, 2, len(getDeliveries()))
+	})
+	t.Run("UnsubscribedEventIgnored", func(t *testing.T) {
+		ServeRequest(t, authToken, "PATCH", "/tasks/modify/"+taskID+"/", bytes.NewBuffer([]byte(`{"id_task_section": "`+primitive.NewObjectID().Hex()+`"}`)), http.StatusOK, api)
+		assert.Equal(t, 2, len(getDeliveries()))
+	})
+	t.Run("DeliveriesList", func(t *testing.T) {
+		response := ServeRequest(t, authToken, "GET", "/webhooks/"+created.ID+"/deliveries/", nil, http.StatusOK, api)
+		var results []WebhookDeliveryResult
+		assert.NoError(t, json.Unmarshal(response, &results))
+		assert.Equal(t, 2, len(results))
+	})
+}
@@ -0,0 +1,4 @@
+package constants
+
+// used for labels created without a color, and for labels imported from Jira, which don't have colors
+const DefaultLabelColor = "#767676"
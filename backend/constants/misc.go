@@ -11,3 +11,11 @@ const (
 	StringSharedAccessDomain           = "domain"
 	StringSharedAccessMeetingAttendees = "meeting_attendees"
 )
+
+// Attendee responses to an event invitation, using the names google calendar gives them
+const (
+	EventResponseNeedsAction = "needsAction"
+	EventResponseAccepted    = "accepted"
+	EventResponseDeclined    = "declined"
+	EventResponseTentative   = "tentative"
+)
@@ -25,6 +25,8 @@ const (
 	ViewGithub             ViewType = "github"
 	ViewMeetingPreparation ViewType = "meeting_preparation"
 	ViewDueToday           ViewType = "due_today"
+	ViewLabel              ViewType = "label"
+	ViewSavedQuery         ViewType = "saved_query"
 )
 
 const (
@@ -0,0 +1,14 @@
+package constants
+
+// personal access tokens are prefixed so they can be told apart from session tokens
+const PersonalAccessTokenPrefix = "gtp_"
+
+// Valid scopes for personal access tokens
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeCalendar   = "calendar"
+	ScopeNotes      = "notes"
+)
+
+var PersonalAccessTokenScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeCalendar, ScopeNotes}
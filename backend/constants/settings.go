@@ -44,6 +44,19 @@ const (
 	SettingMoveEmptyListsToBottom = "move_empty_lists_to_bottom"
 	// Lab settings
 	LabSmartPrioritizeEnabled = "lab_smart_prioritize_enabled"
+	// Reminder settings
+	SettingFieldReminderDefaultOffset  = "reminder_default_offset"
+	SettingFieldReminderDeliveryMethod = "reminder_delivery_method"
+	ChoiceKeyReminderNone              = "none"
+	ChoiceKeyReminderDeliverySlack     = "slack"
+	ChoiceKeyReminderDeliveryEmail     = "email"
+	// Daily digest settings
+	SettingFieldDailyDigestEnabled        = "daily_digest_enabled"
+	SettingFieldDailyDigestDeliveryMethod = "daily_digest_delivery_method"
+	// Planner settings
+	SettingFieldPlannerWorkingHoursStart = "planner_working_hours_start"
+	SettingFieldPlannerWorkingHoursEnd   = "planner_working_hours_end"
+	SettingFieldPlannerIncludeWeekends   = "planner_include_weekends"
 	// Misc settings
 	HasDismissedMulticalPrompt = "has_dismissed_multical_prompt"
 )
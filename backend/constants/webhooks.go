@@ -0,0 +1,11 @@
+package constants
+
+// Event types users can subscribe to with outbound webhooks
+const (
+	WebhookEventTaskCreated   = "task.created"
+	WebhookEventTaskCompleted = "task.completed"
+	WebhookEventTaskMoved     = "task.moved"
+	WebhookEventNoteShared    = "note.shared"
+)
+
+var WebhookEventTypes = []string{WebhookEventTaskCreated, WebhookEventTaskCompleted, WebhookEventTaskMoved, WebhookEventNoteShared}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChangeType string
+
+const (
+	ChangeTypeTask          ChangeType = "task"
+	ChangeTypePullRequest   ChangeType = "pull_request"
+	ChangeTypeCalendarEvent ChangeType = "event"
+)
+
+// subscribers which fall this far behind start dropping notifications rather than blocking writers
+const CHANGE_SUBSCRIBER_BUFFER_SIZE = 64
+
+// old changes are overwritten once the capped collection is full, as they're only needed until every server has read them
+const CHANGES_COLLECTION_SIZE_BYTES = 16 * 1024 * 1024
+const CHANGE_LISTENER_RETRY_INTERVAL = time.Second
+
+// mongo's NamespaceExists error code
+const namespaceExistsErrorCode = 48
+
+type Change struct {
+	Type ChangeType         `json:"type"`
+	ID   primitive.ObjectID `json:"id"`
+}
+
+// changeRecord is how changes are shared between servers, through the capped changes collection
+type changeRecord struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	Type   ChangeType         `bson:"type"`
+	ItemID primitive.ObjectID `bson:"item_id"`
+}
+
+// ChangeHub fans out document change notifications to subscribers in this process.
+// Changes reach the hub through the changes collection, so subscribers see changes made by every server.
+type ChangeHub struct {
+	mutex       sync.RWMutex
+	subscribers map[primitive.ObjectID]map[chan Change]bool
+}
+
+var changeHub = NewChangeHub()
+
+func NewChangeHub() *ChangeHub {
+	return &ChangeHub{subscribers: make(map[primitive.ObjectID]map[chan Change]bool)}
+}
+
+func (hub *ChangeHub) Subscribe(userID primitive.ObjectID) (<-chan Change, func()) {
+	changes := make(chan Change, CHANGE_SUBSCRIBER_BUFFER_SIZE)
+	hub.mutex.Lock()
+	if hub.subscribers[userID] == nil {
+		hub.subscribers[userID] = make(map[chan Change]bool)
+	}
+	hub.subscribers[userID][changes] = true
+	hub.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			hub.mutex.Lock()
+			defer hub.mutex.Unlock()
+			delete(hub.subscribers[userID], changes)
+			if len(hub.subscribers[userID]) == 0 {
+				delete(hub.subscribers, userID)
+			}
+			close(changes)
+		})
+	}
+	return changes, unsubscribe
+}
+
+func (hub *ChangeHub) Publish(userID primitive.ObjectID, changeType ChangeType, itemID primitive.ObjectID) {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+	for changes := range hub.subscribers[userID] {
+		select {
+		case changes <- Change{Type: changeType, ID: itemID}:
+		default:
+		}
+	}
+}
+
+var startChangeListenerOnce sync.Once
+
+func SubscribeToChanges(userID primitive.ObjectID) (<-chan Change, func()) {
+	startChangeListenerOnce.Do(func() {
+		// changes published from now on are relayed, including ones published before the listener connects
+		go listenForChanges(primitive.NewObjectIDFromTimestamp(time.Now()))
+	})
+	return changeHub.Subscribe(userID)
+}
+
+// PublishChange notifies subscribers on every server that an item has changed
+func PublishChange(db *mongo.Database, userID primitive.ObjectID, changeType ChangeType, itemID primitive.ObjectID) {
+	_, err := GetChangeCollection(db).InsertOne(context.Background(), changeRecord{UserID: userID, Type: changeType, ItemID: itemID})
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to publish change")
+	}
+}
+
+// listenForChanges tails the changes collection for as long as the process runs, relaying changes to the hub.
+// It holds its own connection, as the connections of API instances can be cleaned up while it's running.
+func listenForChanges(startID primitive.ObjectID) {
+	logger := logging.GetSentryLogger()
+	for {
+		db, cleanup, err := GetDBConnection()
+		if err != nil {
+			time.Sleep(CHANGE_LISTENER_RETRY_INTERVAL)
+			continue
+		}
+		startID, err = relayChanges(db, changeHub, startID)
+		cleanup()
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to relay changes")
+			time.Sleep(CHANGE_LISTENER_RETRY_INTERVAL)
+		}
+	}
+}
+
+// relayChanges publishes changes from startID onwards to the hub until the connection fails, returning where to resume from
+func relayChanges(db *mongo.Database, hub *ChangeHub, startID primitive.ObjectID) (primitive.ObjectID, error) {
+	err := createChangeCollection(db)
+	if err != nil {
+		return startID, err
+	}
+	for {
+		// IDs are only ordered to the second across servers, so the last second is read again after a restart
+		cursor, err := GetChangeCollection(db).Find(
+			context.Background(),
+			bson.M{"_id": bson.M{"$gte": primitive.NewObjectIDFromTimestamp(startID.Timestamp())}},
+			options.Find().SetCursorType(options.TailableAwait),
+		)
+		if err != nil {
+			return startID, err
+		}
+		for cursor.Next(context.Background()) {
+			var record changeRecord
+			err = cursor.Decode(&record)
+			if err != nil {
+				cursor.Close(context.Background())
+				return startID, err
+			}
+			hub.Publish(record.UserID, record.Type, record.ItemID)
+			startID = record.ID
+		}
+		err = cursor.Err()
+		cursor.Close(context.Background())
+		if err != nil {
+			return startID, err
+		}
+		// tailable cursors die immediately when there's nothing to read yet
+		time.Sleep(CHANGE_LISTENER_RETRY_INTERVAL)
+	}
+}
+
+func createChangeCollection(db *mongo.Database) error {
+	err := db.CreateCollection(
+		context.Background(),
+		GetChangeCollection(db).Name(),
+		options.CreateCollection().SetCapped(true).SetSizeInBytes(CHANGES_COLLECTION_SIZE_BYTES),
+	)
+	if commandError, ok := err.(mongo.CommandError); ok && commandError.HasErrorCode(namespaceExistsErrorCode) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChangeHub(t *testing.T) {
+	t.Run("PublishToSubscriber", func(t *testing.T) {
+		hub := NewChangeHub()
+		userID := primitive.NewObjectID()
+		taskID := primitive.NewObjectID()
+		changes, unsubscribe := hub.Subscribe(userID)
+		defer unsubscribe()
+
+		hub.Publish(userID, ChangeTypeTask, taskID)
+		assert.Equal(t, Change{Type: ChangeTypeTask, ID: taskID}, <-changes)
+	})
+	t.Run("OtherUsersNotNotified", func(t *testing.T) {
+		hub := NewChangeHub()
+		changes, unsubscribe := hub.Subscribe(primitive.NewObjectID())
+		defer unsubscribe()
+
+		hub.Publish(primitive.NewObjectID(), ChangeTypeTask, primitive.NewObjectID())
+		assert.Equal(t, 0, len(changes))
+	})
+	t.Run("SlowSubscriberDoesNotBlock", func(t *testing.T) {
+		hub := NewChangeHub()
+		userID := primitive.NewObjectID()
+		changes, unsubscribe := hub.Subscribe(userID)
+		defer unsubscribe()
+
+		for i := 0; i < CHANGE_SUBSCRIBER_BUFFER_SIZE+10; i++ {
+			hub.Publish(userID, ChangeTypePullRequest, primitive.NewObjectID())
+		}
+		assert.Equal(t, CHANGE_SUBSCRIBER_BUFFER_SIZE, len(changes))
+	})
+	t.Run("Unsubscribe", func(t *testing.T) {
+		hub := NewChangeHub()
+		userID := primitive.NewObjectID()
+		changes, unsubscribe := hub.Subscribe(userID)
+		unsubscribe()
+		unsubscribe()
+
+		hub.Publish(userID, ChangeTypeCalendarEvent, primitive.NewObjectID())
+		_, ok := <-changes
+		assert.False(t, ok)
+		assert.Equal(t, 0, len(hub.subscribers))
+	})
+	t.Run("RelayFromOtherServers", func(t *testing.T) {
+		db, dbCleanup, err := GetDBConnection()
+		assert.NoError(t, err)
+		hub := NewChangeHub()
+		userID := primitive.NewObjectID()
+		taskID := primitive.NewObjectID()
+		changes, unsubscribe := hub.Subscribe(userID)
+		defer unsubscribe()
+		relayed := make(chan error)
+		go func() {
+			_, err := relayChanges(db, hub, primitive.NewObjectIDFromTimestamp(time.Now()))
+			relayed <- err
+		}()
+
+		// any server can publish, as the change goes through the changes collection rather than this hub
+		PublishChange(db, userID, ChangeTypeTask, taskID)
+		select {
+		case change := <-changes:
+			assert.Equal(t, Change{Type: ChangeTypeTask, ID: taskID}, change)
+		case <-time.After(5 * time.Second):
+			t.Error("change was not relayed")
+		}
+		// relaying stops once its connection is closed
+		dbCleanup()
+		assert.Error(t, <-relayed)
+	})
+}
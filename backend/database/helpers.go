@@ -28,7 +28,7 @@ func UpdateOrCreateTask(
 	taskCollection := GetTaskCollection(db)
 	logger := logging.GetSentryLogger()
 
-	mongoResult, err := FindOneAndUpdateWithCollection(taskCollection, userID, IDExternal, sourceID, fieldsToInsertIfMissing, fieldsToUpdate, additionalFilters)
+	mongoResult, isChanged, err := updateOrCreateWithCollection(taskCollection, userID, IDExternal, sourceID, fieldsToInsertIfMissing, fieldsToUpdate, additionalFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +39,9 @@ func UpdateOrCreateTask(
 		logger.Error().Err(err).Msg("failed to update or create task")
 		return nil, err
 	}
+	if isChanged {
+		PublishChange(db, userID, ChangeTypeTask, task.ID)
+	}
 	return &task, nil
 }
 
@@ -74,7 +77,7 @@ func UpdateOrCreateCalendarEvent(
 	additionalFilters *[]bson.M,
 ) (*CalendarEvent, error) {
 	eventCollection := GetCalendarEventCollection(db)
-	mongoResult, err := FindOneAndUpdateWithCollection(eventCollection, userID, IDExternal, sourceID, nil, fields, additionalFilters)
+	mongoResult, isChanged, err := updateOrCreateWithCollection(eventCollection, userID, IDExternal, sourceID, nil, fields, additionalFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +89,9 @@ func UpdateOrCreateCalendarEvent(
 		logger.Error().Err(err).Msg("failed to update or create event")
 		return nil, err
 	}
+	if isChanged {
+		PublishChange(db, userID, ChangeTypeCalendarEvent, event.ID)
+	}
 	return &event, nil
 }
 
@@ -98,7 +104,7 @@ func UpdateOrCreatePullRequest(
 	additionalFilters *[]bson.M,
 ) (*PullRequest, error) {
 	pullRequestCollection := GetPullRequestCollection(db)
-	mongoResult, err := FindOneAndUpdateWithCollection(pullRequestCollection, userID, IDExternal, sourceID, nil, fields, additionalFilters)
+	mongoResult, isChanged, err := updateOrCreateWithCollection(pullRequestCollection, userID, IDExternal, sourceID, nil, fields, additionalFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +116,9 @@ func UpdateOrCreatePullRequest(
 		logger.Error().Err(err).Msg("failed to update or create pull request")
 		return nil, err
 	}
+	if isChanged {
+		PublishChange(db, userID, ChangeTypePullRequest, pullRequest.ID)
+	}
 	return &pullRequest, nil
 }
 
@@ -149,6 +158,48 @@ func FindOneAndUpdateWithCollection(
 	return mongoResult, nil
 }
 
+// updateOrCreateWithCollection upserts the same way as FindOneAndUpdateWithCollection, and also returns whether the
+// document was inserted or had any of its fields changed, so that unchanged items aren't published to clients
+func updateOrCreateWithCollection(
+	collection *mongo.Collection,
+	userID primitive.ObjectID,
+	IDExternal string,
+	sourceID string,
+	fieldsToInsertIfMissing interface{},
+	fields interface{},
+	additionalFilters *[]bson.M,
+) (*mongo.SingleResult, bool, error) {
+	dbQuery := getDBQuery(userID, IDExternal, sourceID, additionalFilters)
+	logger := logging.GetSentryLogger()
+	isChanged := false
+	if fieldsToInsertIfMissing != nil {
+		result, err := collection.UpdateOne(
+			context.Background(),
+			dbQuery,
+			bson.M{"$setOnInsert": fieldsToInsertIfMissing},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to update or create item")
+			return nil, false, err
+		}
+		isChanged = result.UpsertedCount > 0
+	}
+	// the modified count is zero when every field already had the value being set
+	result, err := collection.UpdateOne(
+		context.Background(),
+		dbQuery,
+		bson.M{"$set": fields},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update or create item")
+		return nil, false, err
+	}
+	isChanged = isChanged || result.ModifiedCount > 0 || result.UpsertedCount > 0
+	return collection.FindOne(context.Background(), dbQuery), isChanged, nil
+}
+
 func GetTask(db *mongo.Database, itemID primitive.ObjectID, userID primitive.ObjectID) (*Task, error) {
 	logger := logging.GetSentryLogger()
 	taskCollection := GetTaskCollection(db)
@@ -407,6 +458,32 @@ func GetTaskByExternalIDWithoutUser(db *mongo.Database, externalID string, logEr
 	return &task, nil
 }
 
+func GetTasksByExternalIDWithoutUser(db *mongo.Database, externalID string, sourceID string) (*[]Task, error) {
+	cursor, err := GetTaskCollection(db).Find(
+		context.Background(),
+		bson.M{
+			"$and": []bson.M{
+				{"id_external": externalID},
+				{"source_id": sourceID},
+			},
+		},
+	)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch tasks for external ID")
+		return nil, err
+	}
+
+	var tasks []Task
+	err = cursor.All(context.Background(), &tasks)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch tasks for external ID")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
 func GetCalendarEventWithoutUserID(db *mongo.Database, itemID primitive.ObjectID) (*CalendarEvent, error) {
 	logger := logging.GetSentryLogger()
 	mongoResult := GetCalendarEventCollection(db).FindOne(
@@ -670,6 +747,32 @@ func GetActivePRs(db *mongo.Database, userID primitive.ObjectID) (*[]PullRequest
 	return &pullRequests, nil
 }
 
+func GetPullRequestsByRepositoryWithoutUser(db *mongo.Database, repositoryID string, number int) (*[]PullRequest, error) {
+	cursor, err := GetPullRequestCollection(db).Find(
+		context.Background(),
+		bson.M{
+			"$and": []bson.M{
+				{"repository_id": repositoryID},
+				{"number": number},
+			},
+		},
+	)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch PRs for repository")
+		return nil, err
+	}
+
+	var pullRequests []PullRequest
+	err = cursor.All(context.Background(), &pullRequests)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch PRs for repository")
+		return nil, err
+	}
+	return &pullRequests, nil
+}
+
 func GetActiveItemsWithCollection(collection *mongo.Collection, userID primitive.ObjectID) (*mongo.Cursor, error) {
 	cursor, err := collection.Find(
 		context.Background(),
@@ -712,6 +815,208 @@ func GetPullRequests(db *mongo.Database, userID primitive.ObjectID, additionalFi
 	return &pullRequests, nil
 }
 
+func GetRunningTimeEntry(db *mongo.Database, userID primitive.ObjectID) (*TimeEntry, error) {
+	var timeEntry TimeEntry
+	err := GetTimeEntryCollection(db).FindOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"user_id": userID}, {"is_running": true}}},
+	).Decode(&timeEntry)
+	if err != nil {
+		return nil, err
+	}
+	return &timeEntry, nil
+}
+
+// GetTimeEntriesInRange returns the user's time entries overlapping the range, including the running entry
+func GetTimeEntriesInRange(db *mongo.Database, userID primitive.ObjectID, rangeStart time.Time, rangeEnd time.Time) (*[]TimeEntry, error) {
+	var timeEntries []TimeEntry
+	err := FindWithCollection(GetTimeEntryCollection(db), userID, &[]bson.M{GetTimeEntryRangeFilter(rangeStart, rangeEnd)}, &timeEntries, options.Find().SetSort(bson.M{"datetime_start": 1}))
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch time entries for user")
+		return nil, err
+	}
+	return &timeEntries, nil
+}
+
+func GetTimeEntryRangeFilter(rangeStart time.Time, rangeEnd time.Time) bson.M {
+	return bson.M{"$and": []bson.M{
+		{"datetime_start": bson.M{"$lt": primitive.NewDateTimeFromTime(rangeEnd)}},
+		{"$or": []bson.M{
+			{"is_running": true},
+			{"datetime_end": bson.M{"$gt": primitive.NewDateTimeFromTime(rangeStart)}},
+		}},
+	}}
+}
+
+// GetTimeEntryDurationInRange clips the entry to the range, running entries end at timeNow
+func GetTimeEntryDurationInRange(timeEntry TimeEntry, rangeStart time.Time, rangeEnd time.Time, timeNow time.Time) time.Duration {
+	entryStart := timeEntry.DatetimeStart.Time()
+	entryEnd := timeEntry.DatetimeEnd.Time()
+	if timeEntry.IsRunning {
+		entryEnd = timeNow
+	}
+	if entryStart.Before(rangeStart) {
+		entryStart = rangeStart
+	}
+	if entryEnd.After(rangeEnd) {
+		entryEnd = rangeEnd
+	}
+	if !entryEnd.After(entryStart) {
+		return 0
+	}
+	return entryEnd.Sub(entryStart)
+}
+
+// GetTaskTimeLogged returns the total time logged on each of the user's tasks, including the running entry
+func GetTaskTimeLogged(db *mongo.Database, userID primitive.ObjectID, timeNow time.Time) (map[primitive.ObjectID]time.Duration, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"task_id": bson.M{"$exists": true}},
+			{"is_running": false},
+		}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$task_id",
+			"duration_ms": bson.M{"$sum": bson.M{"$subtract": bson.A{"$datetime_end", "$datetime_start"}}},
+		}}},
+	}
+	cursor, err := GetTimeEntryCollection(db).Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var totals []struct {
+		TaskID     primitive.ObjectID `bson:"_id"`
+		DurationMS int64              `bson:"duration_ms"`
+	}
+	err = cursor.All(context.Background(), &totals)
+	if err != nil {
+		return nil, err
+	}
+	taskIDToTimeLogged := map[primitive.ObjectID]time.Duration{}
+	for _, total := range totals {
+		taskIDToTimeLogged[total.TaskID] = time.Duration(total.DurationMS) * time.Millisecond
+	}
+
+	runningTimeEntry, err := GetRunningTimeEntry(db, userID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if runningTimeEntry != nil && runningTimeEntry.TaskID != primitive.NilObjectID {
+		taskIDToTimeLogged[runningTimeEntry.TaskID] += timeNow.Sub(runningTimeEntry.DatetimeStart.Time())
+	}
+	return taskIDToTimeLogged, nil
+}
+
+func GetLabels(db *mongo.Database, userID primitive.ObjectID) (*[]Label, error) {
+	var labels []Label
+	err := FindWithCollection(GetLabelCollection(db), userID, nil, &labels, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch labels for user")
+		return nil, err
+	}
+	return &labels, nil
+}
+
+func GetLabel(db *mongo.Database, labelID primitive.ObjectID, userID primitive.ObjectID) (*Label, error) {
+	var label Label
+	err := GetLabelCollection(db).FindOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"_id": labelID}, {"user_id": userID}}},
+	).Decode(&label)
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// GetOrCreateLabels returns the IDs of the user's labels with the given names, creating any which are missing
+func GetOrCreateLabels(db *mongo.Database, userID primitive.ObjectID, labels []Label, timeNow time.Time) ([]primitive.ObjectID, error) {
+	labelIDs := []primitive.ObjectID{}
+	for _, label := range labels {
+		var dbLabel Label
+		getOrCreateLabel := func() error {
+			return GetLabelCollection(db).FindOneAndUpdate(
+				context.Background(),
+				bson.M{"$and": []bson.M{{"user_id": userID}, {"name": label.Name}}},
+				bson.M{"$setOnInsert": bson.M{
+					"color":      label.Color,
+					"created_at": primitive.NewDateTimeFromTime(timeNow),
+					"updated_at": primitive.NewDateTimeFromTime(timeNow),
+				}},
+				options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+			).Decode(&dbLabel)
+		}
+		err := getOrCreateLabel()
+		// a concurrent sync created the same label, which now exists
+		if mongo.IsDuplicateKeyError(err) {
+			err = getOrCreateLabel()
+		}
+		if err != nil {
+			return nil, err
+		}
+		labelIDs = append(labelIDs, dbLabel.ID)
+	}
+	return labelIDs, nil
+}
+
+func GetTaskDependencies(db *mongo.Database, userID primitive.ObjectID) (*[]TaskDependency, error) {
+	var dependencies []TaskDependency
+	err := FindWithCollection(GetTaskDependencyCollection(db), userID, nil, &dependencies, nil)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch task dependencies for user")
+		return nil, err
+	}
+	return &dependencies, nil
+}
+
+// ReplaceImportedTaskDependencies syncs the task's links imported from sourceID, leaving links created in General Task untouched
+func ReplaceImportedTaskDependencies(db *mongo.Database, userID primitive.ObjectID, taskID primitive.ObjectID, sourceID string, blockingTaskIDExternals []string, timeNow time.Time) error {
+	_, err := GetTaskDependencyCollection(db).DeleteMany(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"task_id": taskID},
+			{"imported_from_source_id": sourceID},
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if len(blockingTaskIDExternals) == 0 {
+		return nil
+	}
+	dependencies := []interface{}{}
+	for _, blockingTaskIDExternal := range blockingTaskIDExternals {
+		dependencies = append(dependencies, TaskDependency{
+			UserID:                 userID,
+			TaskID:                 taskID,
+			BlockingTaskIDExternal: blockingTaskIDExternal,
+			BlockingTaskSourceID:   sourceID,
+			ImportedFromSourceID:   sourceID,
+			CreatedAt:              primitive.NewDateTimeFromTime(timeNow),
+		})
+	}
+	_, err = GetTaskDependencyCollection(db).InsertMany(context.Background(), dependencies)
+	return err
+}
+
+// SearchWithCollection runs a text search over the user's documents, ranked by relevance with the score in "search_score"
+func SearchWithCollection(collection *mongo.Collection, userID primitive.ObjectID, query string, additionalFilters *[]bson.M, result interface{}, limit int64) error {
+	filters := []bson.M{{"$text": bson.M{"$search": query}}}
+	if additionalFilters != nil {
+		filters = append(filters, *additionalFilters...)
+	}
+	searchScore := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"search_score": searchScore}).
+		SetSort(bson.M{"search_score": searchScore}).
+		SetLimit(limit)
+	return FindWithCollection(collection, userID, &filters, result, findOptions)
+}
+
 func FindWithCollection(collection *mongo.Collection, userID primitive.ObjectID, additionalFilters *[]bson.M, result interface{}, findOptions *options.FindOptions) error {
 	filter := bson.M{
 		"$and": []bson.M{
@@ -960,6 +1265,22 @@ func GetCalendarAccounts(db *mongo.Database, userID primitive.ObjectID) (*[]Cale
 	return &accounts, nil
 }
 
+func GetCalendarAccount(db *mongo.Database, userID primitive.ObjectID, accountID string, sourceID string) (*CalendarAccount, error) {
+	var account CalendarAccount
+	err := GetCalendarAccountCollection(db).FindOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"id_external": accountID},
+			{"source_id": sourceID},
+		}},
+	).Decode(&account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
 func GetTaskSections(db *mongo.Database, userID primitive.ObjectID) (*[]TaskSection, error) {
 	var sections []TaskSection
 	err := FindWithCollection(GetTaskSectionCollection(db), userID, &[]bson.M{{"user_id": userID}}, &sections, nil)
@@ -989,6 +1310,18 @@ func MarkCompleteWithCollection(collection *mongo.Collection, itemID primitive.O
 	return nil
 }
 
+// GetUserLocation returns the user's IANA timezone, or nil if it hasn't been set
+func GetUserLocation(db *mongo.Database, userID primitive.ObjectID) (*time.Location, error) {
+	user, err := GetUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Timezone == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(user.Timezone)
+}
+
 func GetUser(db *mongo.Database, userID primitive.ObjectID) (*User, error) {
 	var userObject User
 	err := GetUserCollection(db).FindOne(
@@ -1131,6 +1464,30 @@ func GetExternalTokens(db *mongo.Database, userID primitive.ObjectID, serviceID
 	return &tokens, nil
 }
 
+func GetExternalTokensByAccountIDs(db *mongo.Database, accountIDs []string, serviceID string) (*[]ExternalAPIToken, error) {
+	cursor, err := GetExternalTokenCollection(db).Find(
+		context.Background(),
+		bson.M{
+			"$and": []bson.M{
+				{"service_id": serviceID},
+				{"account_id": bson.M{"$in": accountIDs}},
+			},
+		},
+	)
+	logger := logging.GetSentryLogger()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load external api tokens")
+		return nil, err
+	}
+	var tokens []ExternalAPIToken
+	err = cursor.All(context.Background(), &tokens)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load external api tokens")
+		return nil, err
+	}
+	return &tokens, nil
+}
+
 func GetAllExternalTokens(db *mongo.Database, userID primitive.ObjectID) ([]ExternalAPIToken, error) {
 	var tokens []ExternalAPIToken
 	externalAPITokenCollection := GetExternalTokenCollection(db)
@@ -1390,6 +1747,38 @@ func GetInternalTokenCollection(db *mongo.Database) *mongo.Collection {
 	return db.Collection("internal_api_tokens")
 }
 
+func GetPersonalAccessTokenCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("personal_access_tokens")
+}
+
+func GetWebhookSubscriptionCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("webhook_subscriptions")
+}
+
+func GetWebhookDeliveryCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("webhook_deliveries")
+}
+
+func GetReminderDeliveryCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("reminder_deliveries")
+}
+
+func GetLabelCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("labels")
+}
+
+func GetTimeEntryCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("time_entries")
+}
+
+func GetTaskDependencyCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("task_dependencies")
+}
+
+func GetAsanaWebhookCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("asana_webhooks")
+}
+
 func GetWaitlistCollection(db *mongo.Database) *mongo.Collection {
 	return db.Collection("waitlist")
 }
@@ -1430,6 +1819,10 @@ func GetJobLocksCollection(db *mongo.Database) *mongo.Collection {
 	return db.Collection("job_locks")
 }
 
+func GetChangeCollection(db *mongo.Database) *mongo.Collection {
+	return db.Collection("changes")
+}
+
 func GetDashboardTeamCollection(db *mongo.Database) *mongo.Collection {
 	return db.Collection("dashboard_teams")
 }
@@ -1438,10 +1831,13 @@ func GetDashboardTeamMemberCollection(db *mongo.Database) *mongo.Collection {
 	return db.Collection("dashboard_team_members")
 }
 
+// the Microsoft Graph calendar scope covers all of the user's calendars
+const MicrosoftCalendarScope = "Calendars.ReadWrite"
+
 func HasUserGrantedMultiCalendarScope(scopes []string) bool {
-	return slices.Contains(scopes, "https://www.googleapis.com/auth/calendar")
+	return slices.Contains(scopes, "https://www.googleapis.com/auth/calendar") || slices.Contains(scopes, MicrosoftCalendarScope)
 }
 
 func HasUserGrantedPrimaryCalendarScope(scopes []string) bool {
-	return slices.Contains(scopes, "https://www.googleapis.com/auth/calendar.events")
+	return slices.Contains(scopes, "https://www.googleapis.com/auth/calendar.events") || slices.Contains(scopes, MicrosoftCalendarScope)
 }
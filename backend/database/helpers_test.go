@@ -926,6 +926,23 @@ func TestUpdateOrCreateTask(t *testing.T) {
 		assert.Equal(t, task1.ID, newTask.ID)
 		assert.True(t, *newTask.IsCompleted)
 	})
+	t.Run("OnlyChangesArePublished", func(t *testing.T) {
+		countChanges := func() int64 {
+			count, err := GetChangeCollection(db).CountDocuments(context.Background(), bson.M{"item_id": task1.ID})
+			assert.NoError(t, err)
+			return count
+		}
+		title := "published title"
+		_, err := UpdateOrCreateTask(db, userID, task1.IDExternal, task1.SourceID, nil, Task{Title: &title}, nil)
+		assert.NoError(t, err)
+		changeCount := countChanges()
+		assert.NotEqual(t, int64(0), changeCount)
+
+		// syncing the same fields again doesn't notify clients
+		_, err = UpdateOrCreateTask(db, userID, task1.IDExternal, task1.SourceID, nil, Task{Title: &title}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, changeCount, countChanges())
+	})
 }
 
 func TestUpdateOrCreatePullRequest(t *testing.T) {
@@ -1788,3 +1805,53 @@ func TestGetCalendarEventByExternalId(t *testing.T) {
 		assert.Equal(t, event, respEvent.ID)
 	})
 }
+
+func TestGetTimeEntryDurationInRange(t *testing.T) {
+	rangeStart := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	rangeEnd := rangeStart.Add(24 * time.Hour)
+	timeNow := rangeStart.Add(10 * time.Hour)
+	getTimeEntry := func(start time.Time, end time.Time) TimeEntry {
+		return TimeEntry{DatetimeStart: primitive.NewDateTimeFromTime(start), DatetimeEnd: primitive.NewDateTimeFromTime(end)}
+	}
+
+	t.Run("InRange", func(t *testing.T) {
+		assert.Equal(t, time.Hour, GetTimeEntryDurationInRange(getTimeEntry(rangeStart.Add(time.Hour), rangeStart.Add(2*time.Hour)), rangeStart, rangeEnd, timeNow))
+	})
+	t.Run("Clipped", func(t *testing.T) {
+		assert.Equal(t, time.Hour, GetTimeEntryDurationInRange(getTimeEntry(rangeStart.Add(-time.Hour), rangeStart.Add(time.Hour)), rangeStart, rangeEnd, timeNow))
+		assert.Equal(t, time.Hour, GetTimeEntryDurationInRange(getTimeEntry(rangeEnd.Add(-time.Hour), rangeEnd.Add(time.Hour)), rangeStart, rangeEnd, timeNow))
+	})
+	t.Run("OutOfRange", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), GetTimeEntryDurationInRange(getTimeEntry(rangeEnd, rangeEnd.Add(time.Hour)), rangeStart, rangeEnd, timeNow))
+	})
+	t.Run("Running", func(t *testing.T) {
+		timeEntry := TimeEntry{IsRunning: true, DatetimeStart: primitive.NewDateTimeFromTime(rangeStart.Add(9 * time.Hour))}
+		assert.Equal(t, time.Hour, GetTimeEntryDurationInRange(timeEntry, rangeStart, rangeEnd, timeNow))
+	})
+}
+
+func TestGetTaskTimeLogged(t *testing.T) {
+	db, dbCleanup, err := GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	userID := primitive.NewObjectID()
+	taskID := primitive.NewObjectID()
+	otherTaskID := primitive.NewObjectID()
+	timeNow := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err = GetTimeEntryCollection(db).InsertMany(context.Background(), []interface{}{
+		TimeEntry{UserID: userID, TaskID: taskID, DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(-5 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(timeNow.Add(-4 * time.Hour))},
+		TimeEntry{UserID: userID, TaskID: taskID, IsRunning: true, DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(-30 * time.Minute))},
+		TimeEntry{UserID: userID, TaskID: otherTaskID, DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(-3 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(timeNow.Add(-1 * time.Hour))},
+		TimeEntry{UserID: userID, EventID: primitive.NewObjectID(), DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(-3 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(timeNow.Add(-1 * time.Hour))},
+		TimeEntry{UserID: primitive.NewObjectID(), TaskID: taskID, DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(-3 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(timeNow.Add(-1 * time.Hour))},
+	})
+	assert.NoError(t, err)
+
+	taskIDToTimeLogged, err := GetTaskTimeLogged(db, userID, timeNow)
+	assert.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]time.Duration{
+		taskID:      90 * time.Minute,
+		otherTaskID: 2 * time.Hour,
+	}, taskIDToTimeLogged)
+}
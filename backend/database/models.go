@@ -21,6 +21,8 @@ type User struct {
 	LinearDisplayName     string             `bson:"linear_display_name"`
 	GPTSuggestionsLeft    int                `bson:"gpt_suggestions_left"`
 	GPTLastSuggestionTime primitive.DateTime `bson:"gpt_last_suggestion_time"`
+	Timezone              string             `bson:"timezone,omitempty"` // IANA timezone, i.e. America/Los_Angeles
+	CalendarFeedToken     string             `bson:"calendar_feed_token,omitempty"`
 }
 
 type UserChangeable struct {
@@ -38,21 +40,36 @@ type InternalAPIToken struct {
 	UserID primitive.ObjectID `bson:"user_id"`
 }
 
+// PersonalAccessToken model
+type PersonalAccessToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id"`
+	Name       string             `bson:"name"`
+	TokenHash  string             `bson:"token_hash"`
+	Scopes     []string           `bson:"scopes"`
+	ExpiresAt  primitive.DateTime `bson:"expires_at,omitempty"`
+	LastUsedAt primitive.DateTime `bson:"last_used_at,omitempty"`
+	IsRevoked  bool               `bson:"is_revoked"`
+	CreatedAt  primitive.DateTime `bson:"created_at"`
+}
+
 // ExternalAPIToken model
 type ExternalAPIToken struct {
-	ID                  primitive.ObjectID `bson:"_id,omitempty"`
-	ServiceID           string             `bson:"service_id"`
-	Token               string             `bson:"token"`
-	UserID              primitive.ObjectID `bson:"user_id"`
-	AccountID           string             `bson:"account_id"`
-	DisplayID           string             `bson:"display_id"`
-	IsUnlinkable        bool               `bson:"is_unlinkable"`
-	IsPrimaryLogin      bool               `bson:"is_primary_login"`
-	IsBadToken          bool               `bson:"is_bad_token"`
-	ExternalID          string             `bson:"external_id"`
-	LastFullRefreshTime primitive.DateTime `bson:"last_full_refresh_time"`
-	Scopes              []string           `bson:"scopes"`
-	Timezone            string             `bson:"timezone"`
+	ID                     primitive.ObjectID `bson:"_id,omitempty"`
+	ServiceID              string             `bson:"service_id"`
+	Token                  string             `bson:"token"`
+	UserID                 primitive.ObjectID `bson:"user_id"`
+	AccountID              string             `bson:"account_id"`
+	DisplayID              string             `bson:"display_id"`
+	IsUnlinkable           bool               `bson:"is_unlinkable"`
+	IsPrimaryLogin         bool               `bson:"is_primary_login"`
+	IsBadToken             bool               `bson:"is_bad_token"`
+	ExternalID             string             `bson:"external_id"`
+	LastFullRefreshTime    primitive.DateTime `bson:"last_full_refresh_time"`
+	LastBackgroundSyncTime primitive.DateTime `bson:"last_background_sync_time"`
+	BackgroundSyncFailures int                `bson:"background_sync_failures"`
+	Scopes                 []string           `bson:"scopes"`
+	Timezone               string             `bson:"timezone"`
 }
 
 type AtlassianSiteConfiguration struct {
@@ -96,6 +113,8 @@ type Task struct {
 	ParentTaskID primitive.ObjectID `bson:"parent_task_id,omitempty"`
 	// required for recurring tasks
 	RecurringTaskTemplateID primitive.ObjectID `bson:"recurring_task_template_id,omitempty"`
+	// unique per template occurrence, so an occurrence is only ever created once
+	RecurringTaskOccurrenceKey string `bson:"recurring_task_occurrence_key,omitempty"`
 	// generic task values (for all sources)
 	IDExternal         string              `bson:"id_external,omitempty"`
 	IDOrdering         int                 `bson:"id_ordering,omitempty"`
@@ -120,6 +139,11 @@ type Task struct {
 	PriorityNormalized *float64            `bson:"priority_normalized,omitempty"`
 	TaskNumber         *int                `bson:"task_number,omitempty"`
 	Comments           *[]Comment          `bson:"comments,omitempty"`
+	// minutes before the due date (or meeting start) to send reminders, nil uses the user's default
+	ReminderOffsets *[]int `bson:"reminder_offsets,omitempty"`
+	// labels attached in General Task, and labels imported from the task's source (replaced on every sync)
+	LabelIDs         *[]primitive.ObjectID `bson:"label_ids,omitempty"`
+	ExternalLabelIDs *[]primitive.ObjectID `bson:"external_label_ids,omitempty"`
 	// used for external priority handling
 	ExternalPriority      *ExternalTaskPriority   `bson:"priority,omitempty"`
 	AllExternalPriorities []*ExternalTaskPriority `bson:"all_priorities,omitempty"`
@@ -134,6 +158,8 @@ type Task struct {
 	SlackMessageParams *SlackMessageParams `bson:"slack_message_params,omitempty"`
 	// info required for JIRA integration
 	JIRATaskParams *JIRATaskParams `bson:"jira_task_params,omitempty"`
+	// info required for Asana integration
+	AsanaTaskParams *AsanaTaskParams `bson:"asana_task_params,omitempty"`
 	// meeting prep fields
 	MeetingPreparationParams *MeetingPreparationParams `bson:"meeting_preparation_params,omitempty"`
 	IsMeetingPreparationTask bool                      `bson:"is_meeting_preparation_task,omitempty"`
@@ -155,6 +181,8 @@ type RecurringTaskTemplate struct {
 	TimeOfDaySecondsToCreateTask *int               `bson:"time_of_day_seconds_to_create_task,omitempty" json:"time_of_day_seconds_to_create_task,omitempty"`
 	DayToCreateTask              *int               `bson:"day_to_create_task,omitempty" json:"day_to_create_task,omitempty"`
 	MonthToCreateTask            *int               `bson:"month_to_create_task,omitempty" json:"month_to_create_task,omitempty"`
+	RecurrenceRule               *string            `bson:"recurrence_rule,omitempty" json:"recurrence_rule,omitempty"` // RFC 5545 RRULE, takes precedence over the recurrence rate
+	ExceptionDates               *[]string          `bson:"exception_dates,omitempty" json:"exception_dates,omitempty"` // EXDATE values, i.e. 20230418 or 20230418T090000Z
 	LastBackfillDatetime         primitive.DateTime `bson:"last_backfill_datetime,omitempty" json:"last_backfill_datetime,omitempty"`
 	// existing template tasks replaced by new task
 	ReplaceExisting *bool `bson:"replace_existing,omitempty" json:"replace_existing,omitempty"`
@@ -209,15 +237,31 @@ type Calendar struct {
 	Title           string `bson:"title,omitempty"`
 	ColorBackground string `bson:"color_background,omitempty"`
 	ColorForeground string `bson:"color_foreground,omitempty"`
+	// incremental sync state, the stored events are kept up to date between SyncedFrom and SyncedUntil
+	SyncToken   string             `bson:"sync_token,omitempty"`
+	SyncedFrom  primitive.DateTime `bson:"synced_from,omitempty"`
+	SyncedUntil primitive.DateTime `bson:"synced_until,omitempty"`
+	// push notification channel for changes to the calendar
+	WatchChannelID  string             `bson:"watch_channel_id,omitempty"`
+	WatchResourceID string             `bson:"watch_resource_id,omitempty"`
+	WatchToken      string             `bson:"watch_token,omitempty"`
+	WatchExpiration primitive.DateTime `bson:"watch_expiration,omitempty"`
+}
+
+type CalendarColor struct {
+	Background string `bson:"background,omitempty"`
+	Foreground string `bson:"foreground,omitempty"`
 }
 
 type CalendarAccount struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	UserID     primitive.ObjectID `bson:"user_id,omitempty"`
-	IDExternal string             `bson:"id_external,omitempty"`
-	Calendars  []Calendar         `bson:"calendars,omitempty"`
-	Scopes     []string           `bson:"scopes,omitempty"`
-	SourceID   string             `bson:"source_id,omitempty"`
+	ID                   primitive.ObjectID       `bson:"_id,omitempty"`
+	UserID               primitive.ObjectID       `bson:"user_id,omitempty"`
+	IDExternal           string                   `bson:"id_external,omitempty"`
+	Calendars            []Calendar               `bson:"calendars,omitempty"`
+	Scopes               []string                 `bson:"scopes,omitempty"`
+	SourceID             string                   `bson:"source_id,omitempty"`
+	EventColors          map[string]CalendarColor `bson:"event_colors,omitempty"`
+	CalendarsRefreshedAt primitive.DateTime       `bson:"calendars_refreshed_at,omitempty"`
 }
 
 type CalendarEvent struct {
@@ -236,18 +280,31 @@ type CalendarEvent struct {
 	DatetimeEnd     primitive.DateTime `bson:"datetime_end,omitempty"`
 	DatetimeStart   primitive.DateTime `bson:"datetime_start,omitempty"`
 	//time in nanoseconds
-	TimeAllocation      int64              `bson:"time_allocated"`
-	CallLogo            string             `bson:"call_logo,omitempty"`
-	CallPlatform        string             `bson:"call_platform,omitempty"`
-	CallURL             string             `bson:"call_url,omitempty"`
-	CanModify           bool               `bson:"can_modify,omitempty"`
-	LinkedTaskID        primitive.ObjectID `bson:"linked_task_id,omitempty"`
-	LinkedViewID        primitive.ObjectID `bson:"linked_view_id,omitempty"`
-	LinkedPullRequestID primitive.ObjectID `bson:"linked_pull_request_id,omitempty"`
-	LinkedSourceID      string             `bson:"linked_task_source_id,omitempty"`
-	ColorBackground     string             `bson:"color_background,omitempty"`
-	ColorForeground     string             `bson:"color_foreground,omitempty"`
-	AttendeeEmails      []string           `bson:"attendee_emails,omitempty"`
+	TimeAllocation      int64                   `bson:"time_allocated"`
+	CallLogo            string                  `bson:"call_logo,omitempty"`
+	CallPlatform        string                  `bson:"call_platform,omitempty"`
+	CallURL             string                  `bson:"call_url,omitempty"`
+	CanModify           bool                    `bson:"can_modify,omitempty"`
+	LinkedTaskID        primitive.ObjectID      `bson:"linked_task_id,omitempty"`
+	LinkedViewID        primitive.ObjectID      `bson:"linked_view_id,omitempty"`
+	LinkedPullRequestID primitive.ObjectID      `bson:"linked_pull_request_id,omitempty"`
+	LinkedSourceID      string                  `bson:"linked_task_source_id,omitempty"`
+	ColorBackground     string                  `bson:"color_background,omitempty"`
+	ColorForeground     string                  `bson:"color_foreground,omitempty"`
+	AttendeeEmails      []string                `bson:"attendee_emails,omitempty"`
+	Attendees           []CalendarEventAttendee `bson:"attendees,omitempty"`
+	// set on instances of a recurring event, the original start identifies the instance even after it's moved
+	RecurringEventID  string             `bson:"recurring_event_id,omitempty"`
+	OriginalStartTime primitive.DateTime `bson:"original_start_time,omitempty"`
+}
+
+// CalendarEventAttendee is an invitee of an event, the response status is one of the constants.EventResponse values
+type CalendarEventAttendee struct {
+	Name           string `bson:"name,omitempty" json:"name"`
+	Email          string `bson:"email,omitempty" json:"email"`
+	ResponseStatus string `bson:"response_status,omitempty" json:"response_status"`
+	IsSelf         bool   `bson:"is_self,omitempty" json:"is_self"`
+	IsOrganizer    bool   `bson:"is_organizer,omitempty" json:"is_organizer"`
 }
 
 type MeetingPreparationParams struct {
@@ -275,6 +332,11 @@ type JIRATaskParams struct {
 	HasDueDateField  *bool `bson:"has_due_date_field,omitempty"`
 }
 
+type AsanaTaskParams struct {
+	ProjectID       string `bson:"project_id,omitempty"`
+	PriorityFieldID string `bson:"priority_field_id,omitempty"`
+}
+
 // Note that this model is used in the request for Slack, and thus should match
 // the payload from the Slack request.
 type SlackMessageParams struct {
@@ -412,6 +474,10 @@ type View struct {
 	IsLinked      bool               `bson:"is_linked"`
 	GithubID      string             `bson:"github_id"`
 	TaskSectionID primitive.ObjectID `bson:"task_section_id"`
+	LabelID       primitive.ObjectID `bson:"label_id,omitempty"`
+	// saved query views only
+	Name  string `bson:"name,omitempty"`
+	Query string `bson:"query,omitempty"`
 }
 
 type Repository struct {
@@ -467,3 +533,92 @@ type DashboardTeamMember struct {
 	Name      string             `bson:"name,omitempty"`
 	CreatedAt primitive.DateTime `bson:"created_at,omitempty"`
 }
+
+type AsanaWebhook struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	ResourceID string             `bson:"resource_id"`
+	WebhookID  string             `bson:"webhook_id"`
+	// included in the target URL, so only Asana can complete the handshake which sets the secret
+	HandshakeNonce string             `bson:"handshake_nonce"`
+	Secret         string             `bson:"secret"`
+	UserID         primitive.ObjectID `bson:"user_id"`
+	AccountID      string             `bson:"account_id"`
+	CreatedAt      primitive.DateTime `bson:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id"`
+	URL        string             `bson:"url"`
+	Secret     string             `bson:"secret"`
+	EventTypes []string           `bson:"event_types"`
+	IsDeleted  bool               `bson:"is_deleted"`
+	CreatedAt  primitive.DateTime `bson:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	SubscriptionID primitive.ObjectID `bson:"subscription_id"`
+	UserID         primitive.ObjectID `bson:"user_id"`
+	EventType      string             `bson:"event_type"`
+	Payload        string             `bson:"payload"`
+	Attempts       int                `bson:"attempts"`
+	IsDelivered    bool               `bson:"is_delivered"`
+	IsAbandoned    bool               `bson:"is_abandoned"`
+	// only ever a generic reason, so receivers' responses aren't exposed to the user
+	LastError     string             `bson:"last_error,omitempty"`
+	NextAttemptAt primitive.DateTime `bson:"next_attempt_at,omitempty"`
+	CreatedAt     primitive.DateTime `bson:"created_at"`
+	UpdatedAt     primitive.DateTime `bson:"updated_at"`
+}
+
+type ReminderDelivery struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	TaskID primitive.ObjectID `bson:"task_id"`
+	// unique per task and reminder time, so a reminder is only ever sent once
+	Key            string             `bson:"key"`
+	DeliveryMethod string             `bson:"delivery_method"`
+	RemindAt       primitive.DateTime `bson:"remind_at"`
+	IsSent         bool               `bson:"is_sent"`
+	LastError      string             `bson:"last_error,omitempty"`
+	CreatedAt      primitive.DateTime `bson:"created_at"`
+	UpdatedAt      primitive.DateTime `bson:"updated_at"`
+}
+
+// Label is unique per user by name, so labels imported from different sources with the same name are grouped together
+type Label struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Name      string             `bson:"name"`
+	Color     string             `bson:"color"`
+	CreatedAt primitive.DateTime `bson:"created_at"`
+	UpdatedAt primitive.DateTime `bson:"updated_at"`
+}
+
+// TimeEntry is time spent on a task or calendar event, the entry is running until it's stopped
+type TimeEntry struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	UserID        primitive.ObjectID `bson:"user_id"`
+	TaskID        primitive.ObjectID `bson:"task_id,omitempty"`
+	EventID       primitive.ObjectID `bson:"event_id,omitempty"`
+	IsRunning     bool               `bson:"is_running"`
+	DatetimeStart primitive.DateTime `bson:"datetime_start"`
+	DatetimeEnd   primitive.DateTime `bson:"datetime_end,omitempty"`
+	CreatedAt     primitive.DateTime `bson:"created_at"`
+}
+
+// TaskDependency records that TaskID is blocked by another task, which may be from a different source
+type TaskDependency struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	TaskID primitive.ObjectID `bson:"task_id"`
+	// set for links created in General Task
+	BlockingTaskID primitive.ObjectID `bson:"blocking_task_id,omitempty"`
+	// set for links imported from a source, where the blocking issue may not have been fetched yet
+	BlockingTaskIDExternal string `bson:"blocking_task_id_external,omitempty"`
+	BlockingTaskSourceID   string `bson:"blocking_task_source_id,omitempty"`
+	// source whose native relation this link mirrors, empty for links created in General Task
+	ImportedFromSourceID string             `bson:"imported_from_source_id,omitempty"`
+	CreatedAt            primitive.DateTime `bson:"created_at"`
+}
@@ -23,9 +23,17 @@ type AsanaService struct {
 }
 
 type AsanaConfigValues struct {
-	UserInfoURL   *string
-	TaskFetchURL  *string
-	TaskUpdateURL *string
+	UserInfoURL       *string
+	TaskFetchURL      *string
+	TaskDetailsURL    *string
+	TaskUpdateURL     *string
+	TaskCreateURL     *string
+	SectionFetchURL   *string
+	SectionAddTaskURL *string
+	CommentFetchURL   *string
+	CommentCreateURL  *string
+	CommentDetailsURL *string
+	WebhookCreateURL  *string
 }
 
 func getAsanaConfig() *OauthConfig {
@@ -110,3 +118,15 @@ func (asana AsanaService) HandleSignupCallback(db *mongo.Database, params Callba
 func getAsanaHttpClient(db *mongo.Database, userID primitive.ObjectID, accountID string) *http.Client {
 	return getExternalOauth2Client(db, userID, accountID, TASK_SERVICE_ID_ASANA, getAsanaConfig())
 }
+
+// returns the client and URL to use for an Asana request, preferring the override set in tests
+func getAsanaClientAndURL(db *mongo.Database, userID primitive.ObjectID, accountID string, overrideURL *string, defaultURL string) (*http.Client, string, error) {
+	if overrideURL != nil {
+		return http.DefaultClient, *overrideURL, nil
+	}
+	client := getAsanaHttpClient(db, userID, accountID)
+	if client == nil {
+		return nil, "", errors.New("could not load asana token")
+	}
+	return client, defaultURL, nil
+}
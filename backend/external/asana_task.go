@@ -1,17 +1,25 @@
 package external
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/jjPlusPlus/task-manager/backend/config"
 	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AsanaTaskSource struct {
@@ -21,8 +29,17 @@ type AsanaTaskSource struct {
 const (
 	AsanaUserInfoURL = "https://app.asana.com/api/1.0/users/me"
 	AsanaTasksURL    = "https://app.asana.com/api/1.0/tasks/"
+	AsanaProjectsURL = "https://app.asana.com/api/1.0/projects/"
+	AsanaSectionsURL = "https://app.asana.com/api/1.0/sections/"
+	AsanaStoriesURL  = "https://app.asana.com/api/1.0/stories/"
+	AsanaWebhooksURL = "https://app.asana.com/api/1.0/webhooks"
 )
 
+const AsanaTaskFields = "this.html_notes,this.name,this.due_at,this.due_on,this.permalink_url,this.created_at,this.completed,this.completed_at,this.memberships.project.name,this.memberships.section.name,this.custom_fields.name,this.custom_fields.resource_subtype,this.custom_fields.enum_value.name,this.custom_fields.enum_options.name,this.custom_fields.enum_options.color,this.custom_fields.enum_options.enabled"
+const AsanaCommentFields = "this.text,this.resource_subtype,this.created_at,this.created_by.name,this.created_by.email"
+const AsanaCommentSubtype = "comment_added"
+const AsanaPriorityFieldName = "priority"
+
 type AsanaUserInfoResponse struct {
 	Data struct {
 		Workspaces []struct {
@@ -31,28 +48,138 @@ type AsanaUserInfoResponse struct {
 	} `json:"data"`
 }
 
+type AsanaResource struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
+}
+
+type AsanaMembership struct {
+	Project AsanaResource  `json:"project"`
+	Section *AsanaResource `json:"section"`
+}
+
+type AsanaEnumOption struct {
+	GID     string `json:"gid"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	Enabled bool   `json:"enabled"`
+}
+
+type AsanaCustomField struct {
+	GID             string            `json:"gid"`
+	Name            string            `json:"name"`
+	ResourceSubtype string            `json:"resource_subtype"`
+	EnumValue       *AsanaEnumOption  `json:"enum_value"`
+	EnumOptions     []AsanaEnumOption `json:"enum_options"`
+}
+
+type AsanaTask struct {
+	GID          string             `json:"gid"`
+	DueOn        string             `json:"due_on"`
+	HTMLNotes    string             `json:"html_notes"`
+	Name         string             `json:"name"`
+	PermalinkURL string             `json:"permalink_url"`
+	CreatedAt    primitive.DateTime `json:"created_at"`
+	Completed    bool               `json:"completed"`
+	CompletedAt  string             `json:"completed_at"`
+	Memberships  []AsanaMembership  `json:"memberships"`
+	CustomFields []AsanaCustomField `json:"custom_fields"`
+}
+
 type AsanaTasksResponse struct {
-	Data []struct {
-		GID          string             `json:"gid"`
-		DueOn        string             `json:"due_on"`
-		HTMLNotes    string             `json:"html_notes"`
-		Name         string             `json:"name"`
-		PermalinkURL string             `json:"permalink_url"`
-		CreatedAt    primitive.DateTime `json:"created_at"`
-	} `json:"data"`
+	Data []AsanaTask `json:"data"`
+}
+
+type AsanaTaskResponse struct {
+	Data AsanaTask `json:"data"`
+}
+
+type AsanaSectionsResponse struct {
+	Data []AsanaResource `json:"data"`
+}
+
+type AsanaStory struct {
+	GID             string `json:"gid"`
+	Text            string `json:"text"`
+	ResourceSubtype string `json:"resource_subtype"`
+	CreatedAt       string `json:"created_at"`
+	CreatedBy       struct {
+		GID   string `json:"gid"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"created_by"`
+}
+
+type AsanaStoriesResponse struct {
+	Data []AsanaStory `json:"data"`
+}
+
+type AsanaStoryResponse struct {
+	Data AsanaStory `json:"data"`
 }
 
 type AsanaTasksUpdateFields struct {
-	Name      *string `json:"name,omitempty"`
-	HTMLNotes *string `json:"html_notes,omitempty"`
-	DueOn     *string `json:"due_on,omitempty"`
-	Completed *bool   `json:"completed,omitempty"`
+	Name         *string           `json:"name,omitempty"`
+	HTMLNotes    *string           `json:"html_notes,omitempty"`
+	DueOn        *string           `json:"due_on,omitempty"`
+	Completed    *bool             `json:"completed,omitempty"`
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
 }
 
 type AsanaTasksUpdateBody struct {
 	Data AsanaTasksUpdateFields `json:"data"`
 }
 
+type AsanaTaskCreationParams struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	ProjectID   string `json:"project_id,omitempty"`
+	SectionID   string `json:"section_id,omitempty"`
+}
+
+type AsanaTaskCreateFields struct {
+	Name      string   `json:"name"`
+	HTMLNotes string   `json:"html_notes,omitempty"`
+	DueOn     string   `json:"due_on,omitempty"`
+	Assignee  string   `json:"assignee"`
+	Workspace string   `json:"workspace,omitempty"`
+	Projects  []string `json:"projects,omitempty"`
+}
+
+type AsanaTaskCreateBody struct {
+	Data AsanaTaskCreateFields `json:"data"`
+}
+
+type AsanaSectionAddTaskBody struct {
+	Data struct {
+		Task string `json:"task"`
+	} `json:"data"`
+}
+
+type AsanaCommentCreateBody struct {
+	Data struct {
+		Text string `json:"text"`
+	} `json:"data"`
+}
+
+type AsanaWebhookFilter struct {
+	ResourceType    string `json:"resource_type"`
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+}
+
+type AsanaWebhookCreateBody struct {
+	Data struct {
+		Resource string               `json:"resource"`
+		Target   string               `json:"target"`
+		Filters  []AsanaWebhookFilter `json:"filters"`
+	} `json:"data"`
+}
+
+type AsanaWebhookCreateResponse struct {
+	Data struct {
+		GID string `json:"gid"`
+	} `json:"data"`
+}
+
 func (asanaTask AsanaTaskSource) GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult) {
 	result <- emptyCalendarResult(errors.New("asana cannot fetch events"))
 }
@@ -79,7 +206,7 @@ func (asanaTask AsanaTaskSource) GetTasks(db *mongo.Database, userID primitive.O
 	}
 	workspaceID := userInfo.Data.Workspaces[0].ID
 
-	taskFetchURL := fmt.Sprintf(AsanaTasksURL+"?assignee=me&workspace=%s&completed_since=3022-01-01&opt_fields=this.html_notes,this.name,this.due_at,this.due_on,this.permalink_url", workspaceID)
+	taskFetchURL := fmt.Sprintf(AsanaTasksURL+"?assignee=me&workspace=%s&completed_since=3022-01-01&opt_fields=%s", workspaceID, AsanaTaskFields)
 	if asanaTask.Asana.ConfigValues.TaskFetchURL != nil {
 		taskFetchURL = *asanaTask.Asana.ConfigValues.TaskFetchURL
 		client = http.DefaultClient
@@ -95,58 +222,327 @@ func (asanaTask AsanaTaskSource) GetTasks(db *mongo.Database, userID primitive.O
 		return
 	}
 
+	// tasks in the same project share the same sections, so only fetch them once
+	projectToStatuses := map[string][]*database.ExternalTaskStatus{}
 	var tasks []*database.Task
 	for _, asanaTaskData := range asanaTasks.Data {
-		title := asanaTaskData.Name
-		body := asanaTaskData.HTMLNotes
-		task := &database.Task{
-			UserID:            userID,
-			IDExternal:        asanaTaskData.GID,
-			IDTaskSection:     constants.IDTaskSectionDefault,
-			Deeplink:          asanaTaskData.PermalinkURL,
-			SourceID:          TASK_SOURCE_ID_ASANA,
-			Title:             &title,
-			Body:              &body,
-			SourceAccountID:   accountID,
-			CreatedAtExternal: asanaTaskData.CreatedAt,
-		}
-		dueDate, err := time.Parse(constants.YEAR_MONTH_DAY_FORMAT, asanaTaskData.DueOn)
-		if err == nil {
-			dueDatePrim := primitive.NewDateTimeFromTime(dueDate)
-			task.DueDate = &dueDatePrim
-		}
-		isCompleted := false
-		dbTask, err := database.UpdateOrCreateTask(
-			db,
-			userID,
-			task.IDExternal,
-			task.SourceID,
-			task,
-			database.Task{
-				Title:       task.Title,
-				Body:        task.Body,
-				DueDate:     task.DueDate,
-				IsCompleted: &isCompleted,
-			},
-			nil,
-		)
+		task, err := asanaTask.updateOrCreateAsanaTask(db, userID, accountID, asanaTaskData, projectToStatuses)
 		if err != nil {
 			result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_ASANA)
 			return
 		}
-		task.HasBeenReordered = dbTask.HasBeenReordered
-		task.ID = dbTask.ID
-		task.IDOrdering = dbTask.IDOrdering
-		task.IDTaskSection = dbTask.IDTaskSection
-		task.TimeAllocation = dbTask.TimeAllocation
 		tasks = append(tasks, task)
 	}
 
+	for projectID := range projectToStatuses {
+		err = asanaTask.registerAsanaWebhook(db, userID, accountID, projectID)
+		if err != nil {
+			logger.Error().Err(err).Str("projectID", projectID).Msg("failed to register asana webhook")
+		}
+	}
+
 	result <- TaskResult{
 		Tasks: tasks,
 	}
 }
 
+// fetches a single task, used to apply changes delivered by webhooks
+func (asanaTask AsanaTaskSource) RefreshTask(db *mongo.Database, userID primitive.ObjectID, accountID string, taskID string) (*database.Task, error) {
+	client, taskDetailsURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.TaskDetailsURL, fmt.Sprintf(AsanaTasksURL+"%s?opt_fields=%s", taskID, AsanaTaskFields))
+	if err != nil {
+		return nil, err
+	}
+	var asanaTaskResponse AsanaTaskResponse
+	err = getJSON(client, taskDetailsURL, &asanaTaskResponse)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to fetch asana task")
+		return nil, err
+	}
+	return asanaTask.updateOrCreateAsanaTask(db, userID, accountID, asanaTaskResponse.Data, map[string][]*database.ExternalTaskStatus{})
+}
+
+func (asanaTask AsanaTaskSource) updateOrCreateAsanaTask(db *mongo.Database, userID primitive.ObjectID, accountID string, asanaTaskData AsanaTask, projectToStatuses map[string][]*database.ExternalTaskStatus) (*database.Task, error) {
+	logger := logging.GetSentryLogger()
+	title := asanaTaskData.Name
+	body := asanaTaskData.HTMLNotes
+	task := &database.Task{
+		UserID:            userID,
+		IDExternal:        asanaTaskData.GID,
+		IDTaskSection:     constants.IDTaskSectionDefault,
+		Deeplink:          asanaTaskData.PermalinkURL,
+		SourceID:          TASK_SOURCE_ID_ASANA,
+		Title:             &title,
+		Body:              &body,
+		SourceAccountID:   accountID,
+		CreatedAtExternal: asanaTaskData.CreatedAt,
+	}
+	dueDate, err := time.Parse(constants.YEAR_MONTH_DAY_FORMAT, asanaTaskData.DueOn)
+	if err == nil {
+		dueDatePrim := primitive.NewDateTimeFromTime(dueDate)
+		task.DueDate = &dueDatePrim
+	}
+	isCompleted := asanaTaskData.Completed
+	updateFields := database.Task{
+		Title:       task.Title,
+		Body:        task.Body,
+		DueDate:     task.DueDate,
+		IsCompleted: &isCompleted,
+	}
+	if isCompleted {
+		completedAt, err := time.Parse(time.RFC3339, asanaTaskData.CompletedAt)
+		if err == nil {
+			updateFields.CompletedAt = primitive.NewDateTimeFromTime(completedAt)
+		}
+	}
+
+	asanaTaskParams := database.AsanaTaskParams{}
+	// a task can live in several projects, but only the first one is used for its status
+	for _, membership := range asanaTaskData.Memberships {
+		if membership.Section == nil {
+			continue
+		}
+		allStatuses, exists := projectToStatuses[membership.Project.GID]
+		if !exists {
+			allStatuses, err = asanaTask.getAsanaSectionStatuses(db, userID, accountID, membership.Project)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to fetch asana sections")
+				break
+			}
+			projectToStatuses[membership.Project.GID] = allStatuses
+		}
+		asanaTaskParams.ProjectID = membership.Project.GID
+		updateFields.AllStatuses = allStatuses
+		updateFields.Status = &database.ExternalTaskStatus{
+			ExternalID:        membership.Section.GID,
+			State:             membership.Section.Name,
+			Type:              membership.Project.Name,
+			IsValidTransition: true,
+		}
+		break
+	}
+
+	for _, customField := range asanaTaskData.CustomFields {
+		if !strings.EqualFold(customField.Name, AsanaPriorityFieldName) || customField.ResourceSubtype != "enum" {
+			continue
+		}
+		asanaTaskParams.PriorityFieldID = customField.GID
+		updateFields.AllExternalPriorities = getAsanaExternalPriorities(customField.EnumOptions)
+		if customField.EnumValue != nil {
+			for _, priority := range updateFields.AllExternalPriorities {
+				if priority.ExternalID == customField.EnumValue.GID {
+					updateFields.ExternalPriority = priority
+					updateFields.PriorityNormalized = &priority.PriorityNormalized
+				}
+			}
+		}
+		break
+	}
+	if asanaTaskParams != (database.AsanaTaskParams{}) {
+		updateFields.AsanaTaskParams = &asanaTaskParams
+	}
+
+	comments, err := asanaTask.getAsanaComments(db, userID, accountID, asanaTaskData.GID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch asana comments")
+	} else if len(comments) > 0 {
+		updateFields.Comments = &comments
+	}
+
+	dbTask, err := database.UpdateOrCreateTask(
+		db,
+		userID,
+		task.IDExternal,
+		task.SourceID,
+		task,
+		updateFields,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	task.HasBeenReordered = dbTask.HasBeenReordered
+	task.ID = dbTask.ID
+	task.IDOrdering = dbTask.IDOrdering
+	task.IDTaskSection = dbTask.IDTaskSection
+	task.TimeAllocation = dbTask.TimeAllocation
+	task.IsCompleted = updateFields.IsCompleted
+	task.Status = updateFields.Status
+	task.AllStatuses = updateFields.AllStatuses
+	task.ExternalPriority = updateFields.ExternalPriority
+	task.AllExternalPriorities = updateFields.AllExternalPriorities
+	task.PriorityNormalized = updateFields.PriorityNormalized
+	task.Comments = updateFields.Comments
+	return task, nil
+}
+
+// sections are the closest thing Asana has to a workflow status
+func (asanaTask AsanaTaskSource) getAsanaSectionStatuses(db *mongo.Database, userID primitive.ObjectID, accountID string, project AsanaResource) ([]*database.ExternalTaskStatus, error) {
+	client, sectionFetchURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.SectionFetchURL, fmt.Sprintf(AsanaProjectsURL+"%s/sections?opt_fields=this.name", project.GID))
+	if err != nil {
+		return nil, err
+	}
+	var sections AsanaSectionsResponse
+	err = getJSON(client, sectionFetchURL, &sections)
+	if err != nil {
+		return nil, err
+	}
+	allStatuses := []*database.ExternalTaskStatus{}
+	for idx, section := range sections.Data {
+		allStatuses = append(allStatuses, &database.ExternalTaskStatus{
+			ExternalID:        section.GID,
+			State:             section.Name,
+			Type:              project.Name,
+			Position:          float64(idx),
+			IsValidTransition: true,
+		})
+	}
+	return allStatuses, nil
+}
+
+func getAsanaExternalPriorities(enumOptions []AsanaEnumOption) []*database.ExternalTaskPriority {
+	enabledOptions := []AsanaEnumOption{}
+	for _, option := range enumOptions {
+		if option.Enabled {
+			enabledOptions = append(enabledOptions, option)
+		}
+	}
+	var allPriorities []*database.ExternalTaskPriority
+	for idx, option := range enabledOptions {
+		allPriorities = append(allPriorities, &database.ExternalTaskPriority{
+			ExternalID:         option.GID,
+			Name:               option.Name,
+			Color:              option.Color,
+			PriorityNormalized: getNormalizedPriority(idx, len(enabledOptions)),
+		})
+	}
+	return allPriorities
+}
+
+func (asanaTask AsanaTaskSource) getAsanaComments(db *mongo.Database, userID primitive.ObjectID, accountID string, taskID string) ([]database.Comment, error) {
+	client, commentFetchURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.CommentFetchURL, fmt.Sprintf(AsanaTasksURL+"%s/stories?opt_fields=%s", taskID, AsanaCommentFields))
+	if err != nil {
+		return nil, err
+	}
+	var stories AsanaStoriesResponse
+	err = getJSON(client, commentFetchURL, &stories)
+	if err != nil {
+		return nil, err
+	}
+	comments := []database.Comment{}
+	for _, story := range stories.Data {
+		// stories also include system activity such as assignment changes
+		if story.ResourceSubtype != AsanaCommentSubtype {
+			continue
+		}
+		comments = append(comments, GetDatabaseCommentFromAsanaStory(story))
+	}
+	return comments, nil
+}
+
+// fetches a single comment, used to apply comments delivered by webhooks
+func (asanaTask AsanaTaskSource) GetComment(db *mongo.Database, userID primitive.ObjectID, accountID string, storyID string) (*database.Comment, error) {
+	client, commentDetailsURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.CommentDetailsURL, fmt.Sprintf(AsanaStoriesURL+"%s?opt_fields=%s", storyID, AsanaCommentFields))
+	if err != nil {
+		return nil, err
+	}
+	var story AsanaStoryResponse
+	err = getJSON(client, commentDetailsURL, &story)
+	if err != nil {
+		return nil, err
+	}
+	if story.Data.ResourceSubtype != AsanaCommentSubtype {
+		return nil, errors.New("asana story is not a comment")
+	}
+	comment := GetDatabaseCommentFromAsanaStory(story.Data)
+	return &comment, nil
+}
+
+func GetDatabaseCommentFromAsanaStory(story AsanaStory) database.Comment {
+	createdAt, _ := time.Parse(time.RFC3339, story.CreatedAt)
+	return database.Comment{
+		ExternalID: story.GID,
+		Body:       story.Text,
+		User: database.ExternalUser{
+			ExternalID:  story.CreatedBy.GID,
+			Name:        story.CreatedBy.Name,
+			DisplayName: story.CreatedBy.Name,
+			Email:       story.CreatedBy.Email,
+		},
+		CreatedAt: primitive.NewDateTimeFromTime(createdAt),
+	}
+}
+
+// registers a webhook for the project once, so changes made in Asana show up without waiting for a refresh
+func (asanaTask AsanaTaskSource) registerAsanaWebhook(db *mongo.Database, userID primitive.ObjectID, accountID string, projectID string) error {
+	nonceBytes := make([]byte, 32)
+	_, err := rand.Read(nonceBytes)
+	if err != nil {
+		return err
+	}
+	handshakeNonce := hex.EncodeToString(nonceBytes)
+
+	webhookCollection := database.GetAsanaWebhookCollection(db)
+	// Asana sends the handshake before responding, so the record must exist beforehand to receive the secret
+	result, err := webhookCollection.UpdateOne(
+		context.Background(),
+		bson.M{"resource_id": projectID},
+		bson.M{"$setOnInsert": database.AsanaWebhook{
+			ResourceID:     projectID,
+			HandshakeNonce: handshakeNonce,
+			UserID:         userID,
+			AccountID:      accountID,
+			CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil || result.UpsertedCount == 0 {
+		return err
+	}
+	webhookRecordID := result.UpsertedID.(primitive.ObjectID)
+
+	webhookID, err := asanaTask.createAsanaWebhook(db, userID, accountID, projectID, webhookRecordID, handshakeNonce)
+	if err != nil {
+		// remove the record so registration is retried on the next refresh
+		_, deleteErr := webhookCollection.DeleteOne(context.Background(), bson.M{"_id": webhookRecordID})
+		if deleteErr != nil {
+			logger := logging.GetSentryLogger()
+			logger.Error().Err(deleteErr).Msg("failed to remove asana webhook")
+		}
+		return err
+	}
+	_, err = webhookCollection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": webhookRecordID},
+		bson.M{"$set": bson.M{"webhook_id": webhookID}},
+	)
+	return err
+}
+
+func (asanaTask AsanaTaskSource) createAsanaWebhook(db *mongo.Database, userID primitive.ObjectID, accountID string, projectID string, webhookRecordID primitive.ObjectID, handshakeNonce string) (string, error) {
+	client, webhookCreateURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.WebhookCreateURL, AsanaWebhooksURL)
+	if err != nil {
+		return "", err
+	}
+	var body AsanaWebhookCreateBody
+	body.Data.Resource = projectID
+	body.Data.Target = config.GetConfigValue("SERVER_URL") + "asana/webhook/?webhook_id=" + webhookRecordID.Hex() + "&nonce=" + handshakeNonce
+	body.Data.Filters = []AsanaWebhookFilter{
+		{ResourceType: "task"},
+		{ResourceType: "story", ResourceSubtype: AsanaCommentSubtype},
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	var response AsanaWebhookCreateResponse
+	err = requestJSON(client, "POST", webhookCreateURL, string(bodyJSON), &response)
+	if err != nil {
+		return "", err
+	}
+	return response.Data.GID, nil
+}
+
 func (asanaTask AsanaTaskSource) GetPullRequests(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- PullRequestResult) {
 	result <- emptyPullRequestResult(nil, false)
 }
@@ -160,6 +556,10 @@ func (asanaTask AsanaTaskSource) ModifyTask(db *mongo.Database, userID primitive
 		client = http.DefaultClient
 	}
 	body := asanaTask.GetTaskUpdateBody(updateFields)
+	// priorities are stored in a custom field, so the field ID is needed to update them
+	if updateFields.ExternalPriority != nil && task != nil && task.AsanaTaskParams != nil && task.AsanaTaskParams.PriorityFieldID != "" {
+		body.Data.CustomFields = map[string]string{task.AsanaTaskParams.PriorityFieldID: updateFields.ExternalPriority.ExternalID}
+	}
 	bodyJson, err := json.Marshal(*body)
 	if err != nil {
 		return err
@@ -170,9 +570,31 @@ func (asanaTask AsanaTaskSource) ModifyTask(db *mongo.Database, userID primitive
 		logger.Error().Err(err).Msg("failed to update asana task")
 		return err
 	}
+
+	if updateFields.Status != nil && (task == nil || task.Status == nil || task.Status.ExternalID != updateFields.Status.ExternalID) {
+		err = asanaTask.addTaskToSection(db, userID, accountID, issueID, updateFields.Status.ExternalID)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to move asana task to section")
+			return err
+		}
+	}
 	return nil
 }
 
+func (asanaTask AsanaTaskSource) addTaskToSection(db *mongo.Database, userID primitive.ObjectID, accountID string, taskID string, sectionID string) error {
+	client, sectionAddTaskURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.SectionAddTaskURL, fmt.Sprintf(AsanaSectionsURL+"%s/addTask", sectionID))
+	if err != nil {
+		return err
+	}
+	var body AsanaSectionAddTaskBody
+	body.Data.Task = taskID
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return requestJSON(client, "POST", sectionAddTaskURL, string(bodyJSON), EmptyResponsePlaceholder)
+}
+
 func (asanaTask AsanaTaskSource) GetTaskUpdateBody(updateFields *database.Task) *AsanaTasksUpdateBody {
 	var dueDate *string
 	if updateFields.DueDate != nil && updateFields.DueDate.Time().UTC().Year() > 1971 {
@@ -191,7 +613,73 @@ func (asanaTask AsanaTaskSource) GetTaskUpdateBody(updateFields *database.Task)
 }
 
 func (asanaTask AsanaTaskSource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
-	return primitive.NilObjectID, errors.New("has not been implemented yet")
+	logger := logging.GetSentryLogger()
+	params := AsanaTaskCreationParams{}
+	if task.AsanaParams != nil {
+		params = *task.AsanaParams
+	}
+	// Asana requires either a workspace or a project, so default to the workspace used when fetching tasks
+	if params.WorkspaceID == "" && params.ProjectID == "" {
+		client, userInfoURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.UserInfoURL, AsanaUserInfoURL)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		var userInfo AsanaUserInfoResponse
+		err = getJSON(client, userInfoURL, &userInfo)
+		if err != nil || len(userInfo.Data.Workspaces) == 0 {
+			logger.Error().Err(err).Msg("failed to get asana workspace ID")
+			if err == nil {
+				err = errors.New("user has not workspaces")
+			}
+			return primitive.NilObjectID, err
+		}
+		params.WorkspaceID = userInfo.Data.Workspaces[0].ID
+	}
+
+	createFields := AsanaTaskCreateFields{
+		Name:      task.Title,
+		HTMLNotes: getAsanaHTMLNotes(task.Body),
+		// assigned to the creator so that the task comes back with the rest of their tasks
+		Assignee:  "me",
+		Workspace: params.WorkspaceID,
+	}
+	if params.ProjectID != "" {
+		createFields.Projects = []string{params.ProjectID}
+	}
+	if task.DueDate != nil {
+		createFields.DueOn = task.DueDate.Format(constants.YEAR_MONTH_DAY_FORMAT)
+	}
+	bodyJSON, err := json.Marshal(AsanaTaskCreateBody{Data: createFields})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	client, taskCreateURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.TaskCreateURL, AsanaTasksURL+"?opt_fields=this.permalink_url")
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	var createdTask AsanaTaskResponse
+	err = requestJSON(client, "POST", taskCreateURL, string(bodyJSON), &createdTask)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create asana task")
+		return primitive.NilObjectID, err
+	}
+
+	if params.SectionID != "" {
+		err = asanaTask.addTaskToSection(db, userID, accountID, createdTask.Data.GID, params.SectionID)
+		if err != nil {
+			// the task exists at this point, so it's still saved and picked up in its project's first section
+			logger.Error().Err(err).Msg("failed to move asana task to section")
+		}
+	}
+	return insertCreatedExternalTask(db, userID, accountID, TASK_SOURCE_ID_ASANA, createdTask.Data.GID, createdTask.Data.PermalinkURL, task, nil)
+}
+
+// html_notes must be wrapped in a body tag, and plain text needs escaping
+func getAsanaHTMLNotes(body string) string {
+	if body == "" {
+		return ""
+	}
+	return "<body>" + html.EscapeString(body) + "</body>"
 }
 
 func (asanaTask AsanaTaskSource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
@@ -202,10 +690,30 @@ func (asanaTask AsanaTaskSource) ModifyEvent(db *mongo.Database, userID primitiv
 	return errors.New("has not been implemented yet")
 }
 
-func (asanaTask AsanaTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (asanaTask AsanaTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
 	return errors.New("has not been implemented yet")
 }
 
-func (asanaTask AsanaTaskSource) AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error {
+func (asanaTask AsanaTaskSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
 	return errors.New("has not been implemented yet")
 }
+
+func (asanaTask AsanaTaskSource) AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error {
+	client, commentCreateURL, err := getAsanaClientAndURL(db, userID, accountID, asanaTask.Asana.ConfigValues.CommentCreateURL, fmt.Sprintf(AsanaTasksURL+"%s/stories", task.IDExternal))
+	if err != nil {
+		return err
+	}
+	var body AsanaCommentCreateBody
+	body.Data.Text = comment.Body
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	err = requestJSON(client, "POST", commentCreateURL, string(bodyJSON), EmptyResponsePlaceholder)
+	if err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to create asana comment")
+		return err
+	}
+	return nil
+}
@@ -2,6 +2,10 @@ package external
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -423,3 +427,227 @@ func TestModifyAsanaTask(t *testing.T) {
 		assert.Equal(t, expected, *body)
 	})
 }
+
+func TestLoadAsanaTasksWithProjectFields(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	taskServer := testutils.GetMockAPIServer(t, 200, `{"data": [{"gid": "asana_project_task", "name": "Task!", "permalink_url": "https://example.com/", "memberships": [{"project": {"gid": "project_id", "name": "Roadmap"}, "section": {"gid": "section_2", "name": "Doing"}}], "custom_fields": [{"gid": "priority_field", "name": "Priority", "resource_subtype": "enum", "enum_value": {"gid": "option_low", "name": "Low"}, "enum_options": [{"gid": "option_high", "name": "High", "color": "red", "enabled": true}, {"gid": "option_old", "name": "Old", "enabled": false}, {"gid": "option_low", "name": "Low", "color": "green", "enabled": true}]}]}]}`)
+	defer taskServer.Close()
+	userInfoServer := testutils.GetMockAPIServer(t, 200, DefaultUserInfoResponse)
+	defer userInfoServer.Close()
+	sectionServer := testutils.GetMockAPIServer(t, 200, `{"data": [{"gid": "section_1", "name": "To do"}, {"gid": "section_2", "name": "Doing"}]}`)
+	defer sectionServer.Close()
+	commentServer := testutils.GetMockAPIServer(t, 200, `{"data": [{"gid": "story_1", "resource_subtype": "assigned", "text": "assigned to you"}, {"gid": "story_2", "resource_subtype": "comment_added", "text": "on it", "created_at": "2023-04-19T10:00:00.000Z", "created_by": {"gid": "asana_user", "name": "Asana User"}}]}`)
+	defer commentServer.Close()
+	webhookServer := testutils.GetMockAPIServer(t, 201, `{"data": {"gid": "webhook_id"}}`)
+	defer webhookServer.Close()
+
+	asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{
+		TaskFetchURL:     &taskServer.URL,
+		UserInfoURL:      &userInfoServer.URL,
+		SectionFetchURL:  &sectionServer.URL,
+		CommentFetchURL:  &commentServer.URL,
+		WebhookCreateURL: &webhookServer.URL,
+	}}}
+	userID := primitive.NewObjectID()
+
+	var taskResult = make(chan TaskResult)
+	go asanaTask.GetTasks(db, userID, "sample_account@email.com", taskResult)
+	result := <-taskResult
+	assert.NoError(t, result.Error)
+	assert.Equal(t, 1, len(result.Tasks))
+
+	task, err := database.GetTask(db, result.Tasks[0].ID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "section_2", task.Status.ExternalID)
+	assert.Equal(t, "Doing", task.Status.State)
+	assert.Equal(t, 2, len(task.AllStatuses))
+	assert.Equal(t, "section_1", task.AllStatuses[0].ExternalID)
+	assert.Equal(t, 2, len(task.AllExternalPriorities))
+	assert.Equal(t, "option_low", task.ExternalPriority.ExternalID)
+	assert.Equal(t, 4.0, *task.PriorityNormalized)
+	assert.Equal(t, "project_id", task.AsanaTaskParams.ProjectID)
+	assert.Equal(t, "priority_field", task.AsanaTaskParams.PriorityFieldID)
+	assert.Equal(t, 1, len(*task.Comments))
+	assert.Equal(t, "story_2", (*task.Comments)[0].ExternalID)
+	assert.Equal(t, "on it", (*task.Comments)[0].Body)
+
+	var webhook database.AsanaWebhook
+	err = database.GetAsanaWebhookCollection(db).FindOne(context.Background(), bson.M{"resource_id": "project_id"}).Decode(&webhook)
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook_id", webhook.WebhookID)
+	assert.Equal(t, userID, webhook.UserID)
+	assert.Equal(t, 64, len(webhook.HandshakeNonce))
+	assert.Equal(t, "", webhook.Secret)
+}
+
+func TestModifyAsanaTaskProjectFields(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	task := &database.Task{
+		IDExternal:      "6942069420",
+		Status:          &database.ExternalTaskStatus{ExternalID: "section_1"},
+		AsanaTaskParams: &database.AsanaTaskParams{ProjectID: "project_id", PriorityFieldID: "priority_field"},
+	}
+
+	t.Run("UpdatePriority", func(t *testing.T) {
+		var requestBody []byte
+		taskUpdateServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		}))
+		defer taskUpdateServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{TaskUpdateURL: &taskUpdateServer.URL}}}
+
+		err := asanaTask.ModifyTask(db, primitive.NewObjectID(), "sample_account@email.com", "6942069420", &database.Task{
+			ExternalPriority: &database.ExternalTaskPriority{ExternalID: "option_high"},
+		}, task)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"custom_fields":{"priority_field":"option_high"}}}`, string(requestBody))
+	})
+	t.Run("MoveToSectionBadResponse", func(t *testing.T) {
+		taskUpdateServer := testutils.GetMockAPIServer(t, 200, `{}`)
+		defer taskUpdateServer.Close()
+		sectionServer := testutils.GetMockAPIServer(t, 400, "")
+		defer sectionServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{
+			TaskUpdateURL:     &taskUpdateServer.URL,
+			SectionAddTaskURL: &sectionServer.URL,
+		}}}
+
+		err := asanaTask.ModifyTask(db, primitive.NewObjectID(), "sample_account@email.com", "6942069420", &database.Task{
+			Status: &database.ExternalTaskStatus{ExternalID: "section_2"},
+		}, task)
+		assert.Error(t, err)
+		assert.Equal(t, "bad status code: 400", err.Error())
+	})
+	t.Run("MoveToSectionSuccess", func(t *testing.T) {
+		taskUpdateServer := testutils.GetMockAPIServer(t, 200, `{}`)
+		defer taskUpdateServer.Close()
+		var requestBody []byte
+		sectionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data": {}}`))
+		}))
+		defer sectionServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{
+			TaskUpdateURL:     &taskUpdateServer.URL,
+			SectionAddTaskURL: &sectionServer.URL,
+		}}}
+
+		err := asanaTask.ModifyTask(db, primitive.NewObjectID(), "sample_account@email.com", "6942069420", &database.Task{
+			Status: &database.ExternalTaskStatus{ExternalID: "section_2"},
+		}, task)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"task":"6942069420"}}`, string(requestBody))
+	})
+}
+
+func TestCreateAsanaTask(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	userInfoServer := testutils.GetMockAPIServer(t, 200, DefaultUserInfoResponse)
+	defer userInfoServer.Close()
+
+	t.Run("BadCreateResponse", func(t *testing.T) {
+		taskCreateServer := testutils.GetMockAPIServer(t, 400, "")
+		defer taskCreateServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{
+			UserInfoURL:   &userInfoServer.URL,
+			TaskCreateURL: &taskCreateServer.URL,
+		}}}
+
+		_, err := asanaTask.CreateNewTask(db, primitive.NewObjectID(), "sample_account@email.com", TaskCreationObject{Title: "new task"})
+		assert.Error(t, err)
+		assert.Equal(t, "bad status code: 400", err.Error())
+	})
+	t.Run("Success", func(t *testing.T) {
+		var requestBody []byte
+		taskCreateServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data": {"gid": "created_task", "permalink_url": "https://app.asana.com/0/0/created_task"}}`))
+		}))
+		defer taskCreateServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{
+			UserInfoURL:   &userInfoServer.URL,
+			TaskCreateURL: &taskCreateServer.URL,
+		}}}
+		userID := primitive.NewObjectID()
+		dueDate, _ := time.Parse(constants.YEAR_MONTH_DAY_FORMAT, "2023-04-20")
+
+		taskID, err := asanaTask.CreateNewTask(db, userID, "sample_account@email.com", TaskCreationObject{
+			Title:   "new task",
+			Body:    "a & b",
+			DueDate: &dueDate,
+		})
+		assert.NoError(t, err)
+		var createBody AsanaTaskCreateBody
+		assert.NoError(t, json.Unmarshal(requestBody, &createBody))
+		assert.Equal(t, AsanaTaskCreateFields{
+			Name:      "new task",
+			HTMLNotes: "<body>a &amp; b</body>",
+			DueOn:     "2023-04-20",
+			Assignee:  "me",
+			Workspace: "6942069420",
+		}, createBody.Data)
+
+		task, err := database.GetTask(db, taskID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "created_task", task.IDExternal)
+		assert.Equal(t, TASK_SOURCE_ID_ASANA, task.SourceID)
+		assert.Equal(t, "https://app.asana.com/0/0/created_task", task.Deeplink)
+		assert.Equal(t, "new task", *task.Title)
+	})
+}
+
+func TestAddAsanaComment(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	t.Run("BadResponse", func(t *testing.T) {
+		commentServer := testutils.GetMockAPIServer(t, 400, "")
+		defer commentServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{CommentCreateURL: &commentServer.URL}}}
+
+		err := asanaTask.AddComment(db, primitive.NewObjectID(), "sample_account@email.com", database.Comment{Body: "hello"}, &database.Task{IDExternal: "6942069420"})
+		assert.Error(t, err)
+		assert.Equal(t, "bad status code: 400", err.Error())
+	})
+	t.Run("Success", func(t *testing.T) {
+		var requestBody []byte
+		commentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data": {"gid": "story_id"}}`))
+		}))
+		defer commentServer.Close()
+		asanaTask := AsanaTaskSource{Asana: AsanaService{ConfigValues: AsanaConfigValues{CommentCreateURL: &commentServer.URL}}}
+
+		err := asanaTask.AddComment(db, primitive.NewObjectID(), "sample_account@email.com", database.Comment{Body: "hello"}, &database.Task{IDExternal: "6942069420"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"text":"hello"}}`, string(requestBody))
+	})
+}
+
+func TestGetAsanaExternalPriorities(t *testing.T) {
+	priorities := getAsanaExternalPriorities([]AsanaEnumOption{
+		{GID: "high", Name: "High", Color: "red", Enabled: true},
+		{GID: "disabled", Name: "Disabled", Enabled: false},
+		{GID: "medium", Name: "Medium", Enabled: true},
+		{GID: "low", Name: "Low", Enabled: true},
+	})
+	assert.Equal(t, 3, len(priorities))
+	assert.Equal(t, database.ExternalTaskPriority{ExternalID: "high", Name: "High", Color: "red", PriorityNormalized: 1.0}, *priorities[0])
+	assert.Equal(t, 2.5, priorities[1].PriorityNormalized)
+	assert.Equal(t, 4.0, priorities[2].PriorityNormalized)
+}
@@ -39,6 +39,8 @@ type AtlassianConfigValues struct {
 	FieldsListURL   *string
 	IssueUpdateURL  *string
 	IssueDeleteURL  *string
+	IssueCreateURL  *string
+	CurrentUserURL  *string
 }
 
 // AtlassianConfig ...
@@ -0,0 +1,357 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CalDAVService struct{}
+
+// server URLs and the hrefs servers return are user-controlled, so requests are kept from reaching anything inside our network
+var calDAVHTTPClient = utils.NewPublicHTTPClient(constants.ExternalTimeout)
+
+var errCalDAVOtherServer = errors.New("caldav url is not on the linked server")
+
+// CalDAVCredentials are stored as the token of the linked account, as CalDAV servers only support basic auth
+type CalDAVCredentials struct {
+	ServerURL string `json:"server_url"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+type CalDAVCollection struct {
+	URL         string
+	DisplayName string
+}
+
+type calDAVMultistatus struct {
+	Responses []calDAVResponse `xml:"response"`
+}
+
+type calDAVResponse struct {
+	Href      string           `xml:"href"`
+	Propstats []calDAVPropstat `xml:"propstat"`
+}
+
+type calDAVPropstat struct {
+	Prop   calDAVProp `xml:"prop"`
+	Status string     `xml:"status"`
+}
+
+type calDAVHref struct {
+	Href string `xml:"href"`
+}
+
+type calDAVProp struct {
+	CurrentUserPrincipal *calDAVHref `xml:"current-user-principal"`
+	CalendarHomeSet      *calDAVHref `xml:"calendar-home-set"`
+	DisplayName          string      `xml:"displayname"`
+	ResourceType         struct {
+		Calendar *struct{} `xml:"calendar"`
+	} `xml:"resourcetype"`
+	SupportedComponents *struct {
+		Components []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"comp"`
+	} `xml:"supported-calendar-component-set"`
+	ETag         string `xml:"getetag"`
+	CalendarData string `xml:"calendar-data"`
+}
+
+const calDAVPropfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <d:current-user-principal/>
+    <d:displayname/>
+    <d:resourcetype/>
+    <c:calendar-home-set/>
+    <c:supported-calendar-component-set/>
+  </d:prop>
+</d:propfind>`
+
+func (caldav CalDAVService) GetLinkURL(stateTokenID primitive.ObjectID, userID primitive.ObjectID) (*string, error) {
+	return nil, errors.New("caldav is linked with credentials")
+}
+
+func (caldav CalDAVService) GetSignupURL(stateTokenID primitive.ObjectID, forcePrompt bool) (*string, error) {
+	return nil, errors.New("caldav does not support signup")
+}
+
+func (caldav CalDAVService) HandleLinkCallback(db *mongo.Database, params CallbackParams, userID primitive.ObjectID) error {
+	logger := logging.GetSentryLogger()
+	if params.BasicAuth == nil || params.BasicAuth.ServerURL == "" || params.BasicAuth.Username == "" {
+		return errors.New("missing caldav credentials")
+	}
+	credentials := CalDAVCredentials{
+		ServerURL: params.BasicAuth.ServerURL,
+		Username:  params.BasicAuth.Username,
+		Password:  params.BasicAuth.Password,
+	}
+	serverURL, err := url.Parse(credentials.ServerURL)
+	if err != nil || (serverURL.Scheme != "http" && serverURL.Scheme != "https") {
+		return errors.New("invalid caldav server url")
+	}
+
+	// check the credentials work before saving them
+	collections, err := GetCalDAVTaskCollections(credentials)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to discover caldav collections")
+		return errors.New("unable to connect to caldav server")
+	}
+	if len(collections) == 0 {
+		return errors.New("no task lists found on caldav server")
+	}
+
+	tokenString, err := json.Marshal(&credentials)
+	if err != nil {
+		logger.Error().Err(err).Msg("error parsing token")
+		return errors.New("internal server error")
+	}
+	accountID := credentials.Username + "@" + serverURL.Host
+	_, err = database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"user_id": userID}, {"service_id": TASK_SERVICE_ID_CALDAV}, {"account_id": accountID}}},
+		bson.M{"$set": &database.ExternalAPIToken{
+			UserID:         userID,
+			ServiceID:      TASK_SERVICE_ID_CALDAV,
+			Token:          string(tokenString),
+			AccountID:      accountID,
+			DisplayID:      accountID,
+			IsUnlinkable:   true,
+			IsPrimaryLogin: false,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("error saving token")
+		return errors.New("internal server error")
+	}
+	return nil
+}
+
+func (caldav CalDAVService) HandleSignupCallback(db *mongo.Database, params CallbackParams) (primitive.ObjectID, *bool, *string, error) {
+	return primitive.NilObjectID, nil, nil, errors.New("caldav does not support signup")
+}
+
+func getCalDAVCredentials(db *mongo.Database, userID primitive.ObjectID, accountID string) (*CalDAVCredentials, error) {
+	token, err := getExternalToken(db, userID, accountID, TASK_SERVICE_ID_CALDAV)
+	if err != nil {
+		return nil, err
+	}
+	var credentials CalDAVCredentials
+	err = json.Unmarshal([]byte(token.Token), &credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+// GetCalDAVTaskCollections finds the calendars that can hold VTODOs, starting from the server URL.
+// The server URL may point to the server root, a principal, a calendar home, or a single calendar.
+func GetCalDAVTaskCollections(credentials CalDAVCredentials) ([]CalDAVCollection, error) {
+	currentURL := credentials.ServerURL
+	// follow the principal and calendar home links until we reach the calendar home
+	for i := 0; i < 3; i++ {
+		responses, err := calDAVPropfind(credentials, currentURL, "0", calDAVPropfindBody)
+		if err != nil {
+			return nil, err
+		}
+		if len(responses) == 0 {
+			return nil, errors.New("empty propfind response")
+		}
+		prop := getCalDAVProp(responses[0])
+		if prop.ResourceType.Calendar != nil {
+			if !calDAVSupportsTodos(prop) {
+				return []CalDAVCollection{}, nil
+			}
+			return []CalDAVCollection{{URL: currentURL, DisplayName: prop.DisplayName}}, nil
+		}
+		if prop.CalendarHomeSet != nil && prop.CalendarHomeSet.Href != "" {
+			homeURL, err := resolveCalDAVHref(currentURL, prop.CalendarHomeSet.Href)
+			if err != nil {
+				return nil, err
+			}
+			return getCalDAVCollectionsInHome(credentials, homeURL)
+		}
+		if prop.CurrentUserPrincipal == nil || prop.CurrentUserPrincipal.Href == "" {
+			// no links to follow, so this should be the calendar home itself
+			return getCalDAVCollectionsInHome(credentials, currentURL)
+		}
+		principalURL, err := resolveCalDAVHref(currentURL, prop.CurrentUserPrincipal.Href)
+		if err != nil {
+			return nil, err
+		}
+		if principalURL == currentURL {
+			return getCalDAVCollectionsInHome(credentials, currentURL)
+		}
+		currentURL = principalURL
+	}
+	return nil, errors.New("unable to find caldav calendar home")
+}
+
+func getCalDAVCollectionsInHome(credentials CalDAVCredentials, homeURL string) ([]CalDAVCollection, error) {
+	responses, err := calDAVPropfind(credentials, homeURL, "1", calDAVPropfindBody)
+	if err != nil {
+		return nil, err
+	}
+	collections := []CalDAVCollection{}
+	for _, response := range responses {
+		prop := getCalDAVProp(response)
+		if prop.ResourceType.Calendar == nil || !calDAVSupportsTodos(prop) {
+			continue
+		}
+		collectionURL, err := resolveCalDAVHref(homeURL, response.Href)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, CalDAVCollection{URL: collectionURL, DisplayName: prop.DisplayName})
+	}
+	return collections, nil
+}
+
+func calDAVSupportsTodos(prop calDAVProp) bool {
+	// calendars without the property accept every component type
+	if prop.SupportedComponents == nil {
+		return true
+	}
+	for _, component := range prop.SupportedComponents.Components {
+		if strings.EqualFold(component.Name, ICalComponentTodo) {
+			return true
+		}
+	}
+	return false
+}
+
+// merges the successful propstats of a response, as servers return missing properties in a separate 404 propstat
+func getCalDAVProp(response calDAVResponse) calDAVProp {
+	prop := calDAVProp{}
+	for _, propstat := range response.Propstats {
+		if !strings.Contains(propstat.Status, " 200 ") {
+			continue
+		}
+		if propstat.Prop.CurrentUserPrincipal != nil {
+			prop.CurrentUserPrincipal = propstat.Prop.CurrentUserPrincipal
+		}
+		if propstat.Prop.CalendarHomeSet != nil {
+			prop.CalendarHomeSet = propstat.Prop.CalendarHomeSet
+		}
+		if propstat.Prop.DisplayName != "" {
+			prop.DisplayName = propstat.Prop.DisplayName
+		}
+		if propstat.Prop.ResourceType.Calendar != nil {
+			prop.ResourceType = propstat.Prop.ResourceType
+		}
+		if propstat.Prop.SupportedComponents != nil {
+			prop.SupportedComponents = propstat.Prop.SupportedComponents
+		}
+		if propstat.Prop.ETag != "" {
+			prop.ETag = propstat.Prop.ETag
+		}
+		if propstat.Prop.CalendarData != "" {
+			prop.CalendarData = propstat.Prop.CalendarData
+		}
+	}
+	return prop
+}
+
+func resolveCalDAVHref(baseURL string, href string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	reference, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(reference).String(), nil
+}
+
+// isOnCalDAVServer checks that a URL is on the linked server, as requests to it carry the server's credentials
+func isOnCalDAVServer(serverURL string, requestURL string) bool {
+	server, err := url.Parse(serverURL)
+	if err != nil {
+		return false
+	}
+	request, err := url.Parse(requestURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(server.Scheme, request.Scheme) && strings.EqualFold(server.Host, request.Host)
+}
+
+func calDAVPropfind(credentials CalDAVCredentials, requestURL string, depth string, body string) ([]calDAVResponse, error) {
+	return calDAVMultistatusRequest(credentials, "PROPFIND", requestURL, depth, body)
+}
+
+func calDAVMultistatusRequest(credentials CalDAVCredentials, method string, requestURL string, depth string, body string) ([]calDAVResponse, error) {
+	responseBody, _, err := calDAVRequest(credentials, method, requestURL, body, map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml; charset=utf-8",
+	}, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+	var multistatus calDAVMultistatus
+	err = xml.Unmarshal(responseBody, &multistatus)
+	if err != nil {
+		return nil, err
+	}
+	return multistatus.Responses, nil
+}
+
+// getCalDAVHTTPClient allows linking a local server, such as Radicale, when CALDAV_ALLOW_LOCAL_SERVERS is set in dev
+func getCalDAVHTTPClient() *http.Client {
+	if config.GetEnvironment() == config.Dev && config.GetConfigValue("CALDAV_ALLOW_LOCAL_SERVERS") == "true" {
+		return &http.Client{Timeout: constants.ExternalTimeout}
+	}
+	return calDAVHTTPClient
+}
+
+// calDAVRequest sends an authenticated request, returning the body and headers of the response.
+// Requests are only sent to the linked server, as they carry its credentials.
+func calDAVRequest(credentials CalDAVCredentials, method string, requestURL string, body string, headers map[string]string, expectedStatusCodes ...int) ([]byte, http.Header, error) {
+	if !isOnCalDAVServer(credentials.ServerURL, requestURL) {
+		return nil, nil, errCalDAVOtherServer
+	}
+	request, err := http.NewRequest(method, requestURL, bytes.NewBuffer([]byte(body)))
+	if err != nil {
+		return nil, nil, err
+	}
+	request.SetBasicAuth(credentials.Username, credentials.Password)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := getCalDAVHTTPClient().Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, statusCode := range expectedStatusCodes {
+		if response.StatusCode == statusCode {
+			return responseBody, response.Header, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("bad status code: %d", response.StatusCode)
+}
@@ -0,0 +1,408 @@
+package external
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CalDAVTaskSource struct {
+	CalDAV CalDAVService
+}
+
+type CalDAVTaskCreationParams struct {
+	CollectionURL string `json:"collection_url,omitempty"`
+}
+
+const (
+	CalDAVStatusNeedsAction = "NEEDS-ACTION"
+	CalDAVStatusInProcess   = "IN-PROCESS"
+	CalDAVStatusCompleted   = "COMPLETED"
+	CalDAVStatusCancelled   = "CANCELLED"
+)
+
+const calDAVTodoQueryBody = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <d:getetag/>
+    <c:calendar-data/>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VTODO"/>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+// VTODO priorities go from 1 (highest) to 9 (lowest), and clients generally only use high, medium and low
+var calDAVPriorities = []*database.ExternalTaskPriority{
+	{ExternalID: "1", Name: "High", PriorityNormalized: 1.0},
+	{ExternalID: "5", Name: "Medium", PriorityNormalized: 2.5},
+	{ExternalID: "9", Name: "Low", PriorityNormalized: 4.0},
+}
+
+var calDAVStatuses = []*database.ExternalTaskStatus{
+	{ExternalID: CalDAVStatusNeedsAction, State: "To Do", Type: CalDAVStatusNeedsAction, Position: 0, IsValidTransition: true},
+	{ExternalID: CalDAVStatusInProcess, State: "In Progress", Type: CalDAVStatusInProcess, Position: 1, IsValidTransition: true},
+	{ExternalID: CalDAVStatusCompleted, State: "Completed", Type: CalDAVStatusCompleted, Position: 2, IsValidTransition: true, IsCompletedStatus: true},
+}
+
+func (caldavTask CalDAVTaskSource) GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult) {
+	result <- emptyCalendarResult(errors.New("caldav task cannot fetch events"))
+}
+
+func (caldavTask CalDAVTaskSource) GetTasks(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- TaskResult) {
+	logger := logging.GetSentryLogger()
+	credentials, err := getCalDAVCredentials(db, userID, accountID)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to load caldav credentials")
+		result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_CALDAV)
+		return
+	}
+	collections, err := GetCalDAVTaskCollections(*credentials)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to discover caldav collections")
+		result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_CALDAV)
+		return
+	}
+
+	var tasks []*database.Task
+	for _, collection := range collections {
+		responses, err := calDAVMultistatusRequest(*credentials, "REPORT", collection.URL, "1", calDAVTodoQueryBody)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch caldav tasks")
+			result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_CALDAV)
+			return
+		}
+		for _, response := range responses {
+			prop := getCalDAVProp(response)
+			if prop.CalendarData == "" {
+				continue
+			}
+			todo, err := getCalDAVTodo(prop.CalendarData)
+			if err != nil {
+				// one malformed task shouldn't stop the rest of the list from syncing
+				logger.Error().Err(err).Str("href", response.Href).Msg("failed to parse caldav task")
+				continue
+			}
+			if isCalDAVTodoCompleted(todo) {
+				continue
+			}
+			href, err := resolveCalDAVHref(collection.URL, response.Href)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to parse caldav task href")
+				continue
+			}
+
+			task := getTaskFromCalDAVTodo(todo, userID, accountID, href)
+			isCompleted := false
+			updateFields := database.Task{
+				Title:                 task.Title,
+				Body:                  task.Body,
+				IsCompleted:           &isCompleted,
+				Status:                task.Status,
+				AllStatuses:           task.AllStatuses,
+				CompletedStatus:       task.CompletedStatus,
+				PriorityNormalized:    task.PriorityNormalized,
+				ExternalPriority:      task.ExternalPriority,
+				AllExternalPriorities: task.AllExternalPriorities,
+			}
+			if task.DueDate != nil {
+				updateFields.DueDate = task.DueDate
+			} else {
+				dueDate := primitive.NewDateTimeFromTime(time.Unix(0, 0))
+				updateFields.DueDate = &dueDate
+			}
+			dbTask, err := database.UpdateOrCreateTask(
+				db,
+				userID,
+				task.IDExternal,
+				task.SourceID,
+				task,
+				updateFields,
+				nil,
+			)
+			if err != nil {
+				logger.Error().Err(err).Msg("could not create task")
+				result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_CALDAV)
+				return
+			}
+			task.HasBeenReordered = dbTask.HasBeenReordered
+			task.ID = dbTask.ID
+			task.IDOrdering = dbTask.IDOrdering
+			task.IDTaskSection = dbTask.IDTaskSection
+			task.TimeAllocation = dbTask.TimeAllocation
+			tasks = append(tasks, task)
+		}
+	}
+
+	result <- TaskResult{Tasks: tasks, ServiceID: TASK_SERVICE_ID_CALDAV, AccountID: accountID}
+}
+
+func getCalDAVTodo(calendarData string) (*ICalComponent, error) {
+	calendar, err := ParseICal(calendarData)
+	if err != nil {
+		return nil, err
+	}
+	todos := calendar.GetComponents(ICalComponentTodo)
+	if len(todos) == 0 {
+		return nil, errors.New("calendar object has no VTODO")
+	}
+	// recurring tasks may include overridden instances, the first VTODO is the master
+	return todos[0], nil
+}
+
+func isCalDAVTodoCompleted(todo *ICalComponent) bool {
+	status := strings.ToUpper(todo.GetText(ICalPropertyStatus))
+	return status == CalDAVStatusCompleted || status == CalDAVStatusCancelled || todo.GetProperty(ICalPropertyCompleted) != nil
+}
+
+func getTaskFromCalDAVTodo(todo *ICalComponent, userID primitive.ObjectID, accountID string, href string) *database.Task {
+	title := todo.GetText(ICalPropertySummary)
+	body := todo.GetText(ICalPropertyDesc)
+	task := &database.Task{
+		UserID:                userID,
+		IDExternal:            href,
+		IDTaskSection:         constants.IDTaskSectionDefault,
+		SourceID:              TASK_SOURCE_ID_CALDAV,
+		Title:                 &title,
+		Body:                  &body,
+		SourceAccountID:       accountID,
+		AllStatuses:           calDAVStatuses,
+		CompletedStatus:       getCalDAVStatus(CalDAVStatusCompleted),
+		AllExternalPriorities: calDAVPriorities,
+	}
+	createdAt, _, err := ParseICalTime(todo.GetProperty(ICalPropertyCreated), time.UTC)
+	if err == nil {
+		task.CreatedAtExternal = primitive.NewDateTimeFromTime(createdAt)
+	}
+	updatedAt, _, err := ParseICalTime(todo.GetProperty(ICalPropertyModified), time.UTC)
+	if err == nil {
+		task.UpdatedAt = primitive.NewDateTimeFromTime(updatedAt)
+	}
+	dueDate, _, err := ParseICalTime(todo.GetProperty(ICalPropertyDue), time.UTC)
+	if err == nil {
+		primDueDate := primitive.NewDateTimeFromTime(dueDate)
+		task.DueDate = &primDueDate
+	}
+
+	status := strings.ToUpper(todo.GetText(ICalPropertyStatus))
+	if status == "" {
+		status = CalDAVStatusNeedsAction
+	}
+	task.Status = getCalDAVStatus(status)
+
+	priority, err := strconv.Atoi(todo.GetText(ICalPropertyPriority))
+	// 0 means the priority is undefined
+	if err == nil && priority > 0 {
+		task.ExternalPriority = getCalDAVPriority(priority)
+		if task.ExternalPriority != nil {
+			task.PriorityNormalized = &task.ExternalPriority.PriorityNormalized
+		}
+	}
+	return task
+}
+
+func getCalDAVStatus(status string) *database.ExternalTaskStatus {
+	for _, calDAVStatus := range calDAVStatuses {
+		if calDAVStatus.ExternalID == status {
+			return calDAVStatus
+		}
+	}
+	return calDAVStatuses[0]
+}
+
+func getCalDAVPriority(priority int) *database.ExternalTaskPriority {
+	if priority <= 4 {
+		return calDAVPriorities[0]
+	} else if priority == 5 {
+		return calDAVPriorities[1]
+	} else if priority <= 9 {
+		return calDAVPriorities[2]
+	}
+	return nil
+}
+
+func (caldavTask CalDAVTaskSource) GetPullRequests(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- PullRequestResult) {
+	result <- emptyPullRequestResult(nil, false)
+}
+
+func (caldavTask CalDAVTaskSource) ModifyTask(db *mongo.Database, userID primitive.ObjectID, accountID string, issueID string, updateFields *database.Task, task *database.Task) error {
+	logger := logging.GetSentryLogger()
+	credentials, err := getCalDAVCredentials(db, userID, accountID)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to load caldav credentials")
+		return err
+	}
+	// the ETag makes sure we don't overwrite a change made elsewhere since we fetched the task
+	calendarData, headers, err := calDAVRequest(*credentials, "GET", issueID, "", nil, http.StatusOK)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch caldav task")
+		return err
+	}
+	conditionHeaders := map[string]string{}
+	if etag := headers.Get("ETag"); etag != "" {
+		conditionHeaders["If-Match"] = etag
+	}
+
+	if updateFields.IsDeleted != nil && *updateFields.IsDeleted {
+		_, _, err = calDAVRequest(*credentials, "DELETE", issueID, "", conditionHeaders, http.StatusOK, http.StatusNoContent)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to delete caldav task")
+		}
+		return err
+	}
+
+	calendar, err := ParseICal(string(calendarData))
+	if err != nil {
+		return err
+	}
+	todos := calendar.GetComponents(ICalComponentTodo)
+	if len(todos) == 0 {
+		return errors.New("calendar object has no VTODO")
+	}
+	updateCalDAVTodo(todos[0], updateFields, time.Now())
+
+	conditionHeaders["Content-Type"] = "text/calendar; charset=utf-8"
+	_, _, err = calDAVRequest(*credentials, "PUT", issueID, calendar.Serialize(), conditionHeaders, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update caldav task")
+		return err
+	}
+	return nil
+}
+
+func updateCalDAVTodo(todo *ICalComponent, updateFields *database.Task, now time.Time) {
+	if updateFields.Title != nil {
+		todo.SetText(ICalPropertySummary, *updateFields.Title)
+	}
+	if updateFields.Body != nil {
+		todo.SetText(ICalPropertyDesc, *updateFields.Body)
+	}
+	if updateFields.DueDate != nil {
+		dueDate := updateFields.DueDate.Time().UTC()
+		if dueDate.Year() <= 1971 {
+			todo.RemoveProperty(ICalPropertyDue)
+		} else if dueDate.Equal(dueDate.Truncate(24 * time.Hour)) {
+			value, params := FormatICalDate(dueDate)
+			todo.SetProperty(ICalPropertyDue, value, params)
+		} else {
+			todo.SetProperty(ICalPropertyDue, FormatICalDateTime(dueDate), nil)
+		}
+	}
+	if updateFields.ExternalPriority != nil {
+		todo.SetProperty(ICalPropertyPriority, updateFields.ExternalPriority.ExternalID, nil)
+	}
+
+	status := ""
+	if updateFields.Status != nil {
+		status = updateFields.Status.ExternalID
+	}
+	if updateFields.IsCompleted != nil {
+		if *updateFields.IsCompleted {
+			status = CalDAVStatusCompleted
+		} else if status == "" || status == CalDAVStatusCompleted {
+			status = CalDAVStatusNeedsAction
+		}
+	}
+	if status != "" {
+		todo.SetProperty(ICalPropertyStatus, status, nil)
+		if status == CalDAVStatusCompleted {
+			todo.SetProperty(ICalPropertyCompleted, FormatICalDateTime(now), nil)
+			todo.SetProperty(ICalPropertyPercent, "100", nil)
+		} else {
+			todo.RemoveProperty(ICalPropertyCompleted)
+			todo.RemoveProperty(ICalPropertyPercent)
+		}
+	}
+
+	sequence, _ := strconv.Atoi(todo.GetText(ICalPropertySequence))
+	todo.SetProperty(ICalPropertySequence, strconv.Itoa(sequence+1), nil)
+	todo.SetProperty(ICalPropertyModified, FormatICalDateTime(now), nil)
+	todo.SetProperty(ICalPropertyTimestamp, FormatICalDateTime(now), nil)
+}
+
+func (caldavTask CalDAVTaskSource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
+	logger := logging.GetSentryLogger()
+	credentials, err := getCalDAVCredentials(db, userID, accountID)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to load caldav credentials")
+		return primitive.NilObjectID, err
+	}
+	collectionURL := ""
+	if task.CalDAVParams != nil {
+		collectionURL = task.CalDAVParams.CollectionURL
+	}
+	if collectionURL == "" {
+		collections, err := GetCalDAVTaskCollections(*credentials)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to discover caldav collections")
+			return primitive.NilObjectID, err
+		}
+		if len(collections) == 0 {
+			return primitive.NilObjectID, errors.New("no task lists found on caldav server")
+		}
+		collectionURL = collections[0].URL
+	}
+	if !strings.HasSuffix(collectionURL, "/") {
+		collectionURL += "/"
+	}
+
+	uid := uuid.New().String()
+	now := time.Now()
+	todo := &ICalComponent{Name: ICalComponentTodo}
+	todo.SetProperty(ICalPropertyUID, uid, nil)
+	todo.SetProperty(ICalPropertyCreated, FormatICalDateTime(now), nil)
+	todo.SetProperty(ICalPropertyStatus, CalDAVStatusNeedsAction, nil)
+	updateFields := database.Task{Title: &task.Title}
+	if task.Body != "" {
+		updateFields.Body = &task.Body
+	}
+	if task.DueDate != nil {
+		dueDate := primitive.NewDateTimeFromTime(*task.DueDate)
+		updateFields.DueDate = &dueDate
+	}
+	updateCalDAVTodo(todo, &updateFields, now)
+	// new objects start at sequence 0
+	todo.SetProperty(ICalPropertySequence, "0", nil)
+
+	href := collectionURL + uid + ".ics"
+	_, _, err = calDAVRequest(*credentials, "PUT", href, NewICalCalendar(todo).Serialize(), map[string]string{
+		"Content-Type":  "text/calendar; charset=utf-8",
+		"If-None-Match": "*",
+	}, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create caldav task")
+		return primitive.NilObjectID, err
+	}
+	return insertCreatedExternalTask(db, userID, accountID, TASK_SOURCE_ID_CALDAV, href, "", task, nil)
+}
+
+func (caldavTask CalDAVTaskSource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (caldavTask CalDAVTaskSource) ModifyEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, eventID string, updateFields *EventModifyObject) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (caldavTask CalDAVTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (caldavTask CalDAVTaskSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (caldavTask CalDAVTaskSource) AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error {
+	return errors.New("caldav tasks do not support comments")
+}
@@ -0,0 +1,337 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const calDAVTestTodo = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//Example//EN\r\n" +
+	"BEGIN:VTODO\r\nUID:todo-1\r\nSUMMARY:Write report\r\nDESCRIPTION:for the quarter\r\n" +
+	"DUE;VALUE=DATE:20230420\r\nPRIORITY:1\r\nSTATUS:IN-PROCESS\r\nSEQUENCE:2\r\n" +
+	"END:VTODO\r\nEND:VCALENDAR\r\n"
+
+const calDAVTestCompletedTodo = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//Example//EN\r\n" +
+	"BEGIN:VTODO\r\nUID:todo-2\r\nSUMMARY:Already done\r\nSTATUS:COMPLETED\r\n" +
+	"END:VTODO\r\nEND:VCALENDAR\r\n"
+
+type calDAVTestRequest struct {
+	Method  string
+	Path    string
+	Headers http.Header
+	Body    string
+}
+
+// getCalDAVTestServer serves a principal at /principal/, a calendar home at /calendars/ and a task list at /calendars/tasks/
+func getCalDAVTestServer(t *testing.T, requests *[]calDAVTestRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		*requests = append(*requests, calDAVTestRequest{Method: r.Method, Path: r.URL.Path, Headers: r.Header, Body: string(body)})
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ok200 := "<d:status>HTTP/1.1 200 OK</d:status>"
+		switch {
+		case r.Method == "PROPFIND" && r.URL.Path == "/":
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`<d:multistatus xmlns:d="DAV:"><d:response><d:href>/</d:href><d:propstat><d:prop><d:current-user-principal><d:href>/principal/</d:href></d:current-user-principal></d:prop>` + ok200 + `</d:propstat></d:response></d:multistatus>`))
+		case r.Method == "PROPFIND" && r.URL.Path == "/principal/":
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response><d:href>/principal/</d:href><d:propstat><d:prop><c:calendar-home-set><d:href>/calendars/</d:href></c:calendar-home-set></d:prop>` + ok200 + `</d:propstat></d:response></d:multistatus>`))
+		case r.Method == "PROPFIND" && r.URL.Path == "/calendars/":
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+				`<d:response><d:href>/calendars/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop>` + ok200 + `</d:propstat></d:response>` +
+				`<d:response><d:href>/calendars/events/</d:href><d:propstat><d:prop><d:displayname>Events</d:displayname><d:resourcetype><d:collection/><c:calendar/></d:resourcetype><c:supported-calendar-component-set><c:comp name="VEVENT"/></c:supported-calendar-component-set></d:prop>` + ok200 + `</d:propstat></d:response>` +
+				`<d:response><d:href>/calendars/tasks/</d:href><d:propstat><d:prop><d:displayname>Tasks</d:displayname><d:resourcetype><d:collection/><c:calendar/></d:resourcetype><c:supported-calendar-component-set><c:comp name="VTODO"/></c:supported-calendar-component-set></d:prop>` + ok200 + `</d:propstat></d:response>` +
+				`</d:multistatus>`))
+		case r.Method == "REPORT" && r.URL.Path == "/calendars/tasks/":
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+				`<d:response><d:href>/calendars/tasks/todo-1.ics</d:href><d:propstat><d:prop><d:getetag>"1"</d:getetag><c:calendar-data>` + calDAVTestTodo + `</c:calendar-data></d:prop>` + ok200 + `</d:propstat></d:response>` +
+				`<d:response><d:href>/calendars/tasks/todo-2.ics</d:href><d:propstat><d:prop><d:getetag>"2"</d:getetag><c:calendar-data>` + calDAVTestCompletedTodo + `</c:calendar-data></d:prop>` + ok200 + `</d:propstat></d:response>` +
+				`</d:multistatus>`))
+		case r.Method == "GET" && r.URL.Path == "/calendars/tasks/todo-1.ics":
+			w.Header().Set("ETag", `"1"`)
+			w.Write([]byte(calDAVTestTodo))
+		case r.Method == "PUT" || r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func insertCalDAVToken(t *testing.T, db *mongo.Database, userID primitive.ObjectID, accountID string, serverURL string) {
+	token, err := json.Marshal(&CalDAVCredentials{ServerURL: serverURL, Username: "user", Password: "password"})
+	assert.NoError(t, err)
+	_, err = database.GetExternalTokenCollection(db).InsertOne(context.Background(), database.ExternalAPIToken{
+		UserID:    userID,
+		ServiceID: TASK_SERVICE_ID_CALDAV,
+		AccountID: accountID,
+		Token:     string(token),
+	})
+	assert.NoError(t, err)
+}
+
+// useCalDAVTestClient lets requests reach the test servers on localhost, returning a function which restores the public client
+func useCalDAVTestClient() func() {
+	publicClient := calDAVHTTPClient
+	calDAVHTTPClient = http.DefaultClient
+	return func() { calDAVHTTPClient = publicClient }
+}
+
+func TestCalDAVRequest(t *testing.T) {
+	t.Run("InternalAddress", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		_, err := GetCalDAVTaskCollections(CalDAVCredentials{ServerURL: server.URL + "/", Username: "user", Password: "password"})
+		assert.ErrorIs(t, err, utils.ErrNonPublicAddress)
+		assert.Equal(t, 0, len(requests))
+	})
+	t.Run("OtherServer", func(t *testing.T) {
+		defer useCalDAVTestClient()()
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		otherRequests := []calDAVTestRequest{}
+		otherServer := getCalDAVTestServer(t, &otherRequests)
+		defer otherServer.Close()
+		credentials := CalDAVCredentials{ServerURL: server.URL + "/", Username: "user", Password: "password"}
+		_, _, err := calDAVRequest(credentials, "GET", otherServer.URL+"/calendars/tasks/todo-1.ics", "", nil, http.StatusOK)
+		assert.Equal(t, errCalDAVOtherServer, err)
+		assert.Equal(t, 0, len(otherRequests))
+	})
+}
+
+func TestGetCalDAVTaskCollections(t *testing.T) {
+	defer useCalDAVTestClient()()
+	t.Run("BadCredentials", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		_, err := GetCalDAVTaskCollections(CalDAVCredentials{ServerURL: server.URL + "/", Username: "user", Password: "wrong"})
+		assert.EqualError(t, err, "bad status code: 401")
+	})
+	t.Run("FromServerRoot", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		collections, err := GetCalDAVTaskCollections(CalDAVCredentials{ServerURL: server.URL + "/", Username: "user", Password: "password"})
+		assert.NoError(t, err)
+		assert.Equal(t, []CalDAVCollection{{URL: server.URL + "/calendars/tasks/", DisplayName: "Tasks"}}, collections)
+		assert.Equal(t, 3, len(requests))
+	})
+	t.Run("FromCalendarHome", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		collections, err := GetCalDAVTaskCollections(CalDAVCredentials{ServerURL: server.URL + "/principal/", Username: "user", Password: "password"})
+		assert.NoError(t, err)
+		assert.Equal(t, []CalDAVCollection{{URL: server.URL + "/calendars/tasks/", DisplayName: "Tasks"}}, collections)
+	})
+}
+
+func TestIsOnCalDAVServer(t *testing.T) {
+	serverURL := "https://caldav.example.com/dav/"
+	assert.True(t, isOnCalDAVServer(serverURL, "https://caldav.example.com/dav/calendars/user/tasks/"))
+	assert.True(t, isOnCalDAVServer(serverURL, "https://CalDAV.example.com/other/"))
+	assert.False(t, isOnCalDAVServer(serverURL, "http://caldav.example.com/dav/calendars/user/tasks/"))
+	assert.False(t, isOnCalDAVServer(serverURL, "https://attacker.example.com/dav/"))
+	assert.False(t, isOnCalDAVServer(serverURL, "https://caldav.example.com:8443/dav/"))
+	assert.False(t, isOnCalDAVServer(serverURL, "/dav/calendars/user/tasks/"))
+}
+
+func TestGetTaskFromCalDAVTodo(t *testing.T) {
+	userID := primitive.NewObjectID()
+	todo, err := getCalDAVTodo(calDAVTestTodo)
+	assert.NoError(t, err)
+
+	task := getTaskFromCalDAVTodo(todo, userID, "user@example.com", "https://example.com/calendars/tasks/todo-1.ics")
+	assert.Equal(t, "Write report", *task.Title)
+	assert.Equal(t, "for the quarter", *task.Body)
+	assert.Equal(t, TASK_SOURCE_ID_CALDAV, task.SourceID)
+	assert.Equal(t, "https://example.com/calendars/tasks/todo-1.ics", task.IDExternal)
+	assert.Equal(t, primitive.NewDateTimeFromTime(time.Date(2023, time.April, 20, 0, 0, 0, 0, time.UTC)), *task.DueDate)
+	assert.Equal(t, CalDAVStatusInProcess, task.Status.ExternalID)
+	assert.Equal(t, "1", task.ExternalPriority.ExternalID)
+	assert.Equal(t, 1.0, *task.PriorityNormalized)
+	assert.False(t, isCalDAVTodoCompleted(todo))
+
+	completedTodo, err := getCalDAVTodo(calDAVTestCompletedTodo)
+	assert.NoError(t, err)
+	assert.True(t, isCalDAVTodoCompleted(completedTodo))
+
+	_, err = getCalDAVTodo("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	assert.EqualError(t, err, "calendar object has no VTODO")
+}
+
+func TestUpdateCalDAVTodo(t *testing.T) {
+	now := time.Date(2023, time.April, 21, 12, 30, 0, 0, time.UTC)
+
+	t.Run("UpdateFields", func(t *testing.T) {
+		todo, err := getCalDAVTodo(calDAVTestTodo)
+		assert.NoError(t, err)
+		title := "New title"
+		body := "new\nbody"
+		dueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.May, 1, 15, 0, 0, 0, time.UTC))
+		updateCalDAVTodo(todo, &database.Task{
+			Title:            &title,
+			Body:             &body,
+			DueDate:          &dueDate,
+			ExternalPriority: calDAVPriorities[2],
+			Status:           getCalDAVStatus(CalDAVStatusNeedsAction),
+		}, now)
+		assert.Equal(t, "New title", todo.GetText(ICalPropertySummary))
+		assert.Equal(t, `new\nbody`, todo.GetProperty(ICalPropertyDesc).Value)
+		assert.Equal(t, "20230501T150000Z", todo.GetProperty(ICalPropertyDue).Value)
+		assert.Equal(t, "9", todo.GetText(ICalPropertyPriority))
+		assert.Equal(t, CalDAVStatusNeedsAction, todo.GetText(ICalPropertyStatus))
+		assert.Equal(t, "3", todo.GetText(ICalPropertySequence))
+		assert.Equal(t, "20230421T123000Z", todo.GetText(ICalPropertyModified))
+	})
+	t.Run("AllDayDueDate", func(t *testing.T) {
+		todo, err := getCalDAVTodo(calDAVTestTodo)
+		assert.NoError(t, err)
+		dueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC))
+		updateCalDAVTodo(todo, &database.Task{DueDate: &dueDate}, now)
+		assert.Equal(t, &ICalProperty{Name: ICalPropertyDue, Value: "20230501", Params: map[string]string{ICalParamValue: ICalValueDate}}, todo.GetProperty(ICalPropertyDue))
+	})
+	t.Run("ClearDueDate", func(t *testing.T) {
+		todo, err := getCalDAVTodo(calDAVTestTodo)
+		assert.NoError(t, err)
+		dueDate := primitive.NewDateTimeFromTime(time.Unix(0, 0))
+		updateCalDAVTodo(todo, &database.Task{DueDate: &dueDate}, now)
+		assert.Nil(t, todo.GetProperty(ICalPropertyDue))
+	})
+	t.Run("MarkAsDone", func(t *testing.T) {
+		todo, err := getCalDAVTodo(calDAVTestTodo)
+		assert.NoError(t, err)
+		isCompleted := true
+		updateCalDAVTodo(todo, &database.Task{IsCompleted: &isCompleted}, now)
+		assert.Equal(t, CalDAVStatusCompleted, todo.GetText(ICalPropertyStatus))
+		assert.Equal(t, "20230421T123000Z", todo.GetText(ICalPropertyCompleted))
+		assert.Equal(t, "100", todo.GetText(ICalPropertyPercent))
+
+		isCompleted = false
+		updateCalDAVTodo(todo, &database.Task{IsCompleted: &isCompleted}, now)
+		assert.Equal(t, CalDAVStatusNeedsAction, todo.GetText(ICalPropertyStatus))
+		assert.Nil(t, todo.GetProperty(ICalPropertyCompleted))
+		assert.Nil(t, todo.GetProperty(ICalPropertyPercent))
+	})
+}
+
+func TestLoadCalDAVTasks(t *testing.T) {
+	defer useCalDAVTestClient()()
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	t.Run("BadCredentials", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		userID := primitive.NewObjectID()
+		token, err := json.Marshal(&CalDAVCredentials{ServerURL: server.URL + "/", Username: "user", Password: "wrong"})
+		assert.NoError(t, err)
+		_, err = database.GetExternalTokenCollection(db).InsertOne(context.Background(), database.ExternalAPIToken{
+			UserID:    userID,
+			ServiceID: TASK_SERVICE_ID_CALDAV,
+			AccountID: "user@example.com",
+			Token:     string(token),
+		})
+		assert.NoError(t, err)
+
+		var taskResult = make(chan TaskResult)
+		go CalDAVTaskSource{}.GetTasks(db, userID, "user@example.com", taskResult)
+		result := <-taskResult
+		assert.EqualError(t, result.Error, "bad status code: 401")
+		assert.Equal(t, 0, len(result.Tasks))
+	})
+	t.Run("Success", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		userID := primitive.NewObjectID()
+		insertCalDAVToken(t, db, userID, "user@example.com", server.URL+"/")
+
+		var taskResult = make(chan TaskResult)
+		go CalDAVTaskSource{}.GetTasks(db, userID, "user@example.com", taskResult)
+		result := <-taskResult
+		assert.NoError(t, result.Error)
+		assert.Equal(t, TASK_SERVICE_ID_CALDAV, result.ServiceID)
+		// completed tasks are not returned
+		assert.Equal(t, 1, len(result.Tasks))
+		assert.Equal(t, "Write report", *result.Tasks[0].Title)
+		assert.Equal(t, server.URL+"/calendars/tasks/todo-1.ics", result.Tasks[0].IDExternal)
+
+		dbTask, err := database.GetTaskByExternalIDWithoutUser(db, server.URL+"/calendars/tasks/todo-1.ics", false)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, dbTask.UserID)
+		assert.Equal(t, "Write report", *dbTask.Title)
+		assert.Equal(t, CalDAVStatusInProcess, dbTask.Status.ExternalID)
+	})
+}
+
+func TestModifyCalDAVTask(t *testing.T) {
+	defer useCalDAVTestClient()()
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	t.Run("Update", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		userID := primitive.NewObjectID()
+		insertCalDAVToken(t, db, userID, "user@example.com", server.URL+"/")
+
+		isCompleted := true
+		err := CalDAVTaskSource{}.ModifyTask(db, userID, "user@example.com", server.URL+"/calendars/tasks/todo-1.ics", &database.Task{IsCompleted: &isCompleted}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(requests))
+		assert.Equal(t, "PUT", requests[1].Method)
+		assert.Equal(t, `"1"`, requests[1].Headers.Get("If-Match"))
+		assert.True(t, strings.Contains(requests[1].Body, "STATUS:COMPLETED\r\n"))
+		assert.True(t, strings.Contains(requests[1].Body, "SUMMARY:Write report\r\n"))
+	})
+	t.Run("Delete", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		userID := primitive.NewObjectID()
+		insertCalDAVToken(t, db, userID, "user@example.com", server.URL+"/")
+
+		isDeleted := true
+		err := CalDAVTaskSource{}.ModifyTask(db, userID, "user@example.com", server.URL+"/calendars/tasks/todo-1.ics", &database.Task{IsDeleted: &isDeleted}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(requests))
+		assert.Equal(t, "DELETE", requests[1].Method)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		requests := []calDAVTestRequest{}
+		server := getCalDAVTestServer(t, &requests)
+		defer server.Close()
+		userID := primitive.NewObjectID()
+		insertCalDAVToken(t, db, userID, "user@example.com", server.URL+"/")
+
+		title := "New title"
+		err := CalDAVTaskSource{}.ModifyTask(db, userID, "user@example.com", server.URL+"/calendars/tasks/missing.ics", &database.Task{Title: &title}, nil)
+		assert.EqualError(t, err, "bad status code: 404")
+	})
+}
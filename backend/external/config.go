@@ -7,19 +7,25 @@ import (
 const (
 	TASK_SERVICE_ID_ASANA     = "asana"
 	TASK_SERVICE_ID_ATLASSIAN = "atlassian"
+	TASK_SERVICE_ID_CALDAV    = "caldav"
 	TASK_SERVICE_ID_GT        = "gt"
 	TASK_SERVICE_ID_GITHUB    = "github"
 	TASK_SERVICE_ID_GOOGLE    = "google"
 	TASK_SERVICE_ID_LINEAR    = "linear"
+	TASK_SERVICE_ID_MICROSOFT = "microsoft"
+	TASK_SERVICE_ID_ICS       = "ics"
 	TASK_SERVICE_ID_SLACK     = "slack"
 	TASK_SERVICE_ID_SLACK_APP = "slack_app"
 
 	TASK_SOURCE_ID_ASANA       = "asana_task"
+	TASK_SOURCE_ID_CALDAV      = "caldav_task"
 	TASK_SOURCE_ID_GCAL        = "gcal"
 	TASK_SOURCE_ID_GITHUB_PR   = "github_pr"
 	TASK_SOURCE_ID_GT_TASK     = "gt_task"
 	TASK_SOURCE_ID_JIRA        = "jira"
 	TASK_SOURCE_ID_LINEAR      = "linear_task"
+	TASK_SOURCE_ID_OUTLOOK     = "outlook"
+	TASK_SOURCE_ID_ICS         = "ics_calendar"
 	TASK_SOURCE_ID_SLACK_SAVED = "slack"
 )
 
@@ -32,6 +38,7 @@ type Config struct {
 	Linear                LinearConfig
 	Asana                 OauthConfigWrapper
 	Atlassian             AtlassianConfig
+	Microsoft             MicrosoftConfig
 	SlackOverrideURL      string
 	GoogleOverrideURLs    GoogleURLOverrides
 	OpenAIOverrideURL     string
@@ -48,6 +55,7 @@ func GetConfig() Config {
 		Linear:                LinearConfig{OauthConfig: getLinearOauthConfig()},
 		Asana:                 getAsanaConfig(),
 		Atlassian:             AtlassianConfig{OauthConfig: getAtlassianOauthConfig()},
+		Microsoft:             MicrosoftConfig{OauthConfig: getMicrosoftOauthConfig()},
 	}
 }
 
@@ -80,6 +88,17 @@ func (config Config) GetSourceResult(sourceID string) (*TaskSourceResult, error)
 	return &result, nil
 }
 
+func (config Config) GetServiceIDForSource(sourceID string) (string, error) {
+	for serviceID, serviceResult := range config.GetNameToService() {
+		for _, sourceResult := range serviceResult.Sources {
+			if sourceResult.Details.ID == sourceID {
+				return serviceID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("task service for source %s not found", sourceID)
+}
+
 func (config Config) getNameToSource() map[string]TaskSourceResult {
 	asanaService := AsanaService{Config: config.Asana}
 	atlassianService := AtlassianService{Config: config.Atlassian}
@@ -89,6 +108,7 @@ func (config Config) getNameToSource() map[string]TaskSourceResult {
 		OverrideURLs: config.GoogleOverrideURLs,
 	}
 	linearService := LinearService{Config: config.Linear}
+	microsoftService := MicrosoftService{Config: config.Microsoft}
 	githubService := GithubService{Config: config.Github}
 	slackService := SlackService{Config: config.Slack}
 
@@ -97,6 +117,10 @@ func (config Config) getNameToSource() map[string]TaskSourceResult {
 			Details: TaskSourceAsana,
 			Source:  AsanaTaskSource{Asana: asanaService},
 		},
+		TASK_SOURCE_ID_CALDAV: {
+			Details: TaskSourceCalDAV,
+			Source:  CalDAVTaskSource{CalDAV: CalDAVService{}},
+		},
 		TASK_SOURCE_ID_GCAL: {
 			Details: TaskSourceGoogleCalendar,
 			Source:  GoogleCalendarSource{Google: googleService},
@@ -113,6 +137,14 @@ func (config Config) getNameToSource() map[string]TaskSourceResult {
 			Details: TaskSourceLinear,
 			Source:  LinearTaskSource{Linear: linearService},
 		},
+		TASK_SOURCE_ID_OUTLOOK: {
+			Details: TaskSourceOutlookCalendar,
+			Source:  OutlookCalendarSource{Microsoft: microsoftService},
+		},
+		TASK_SOURCE_ID_ICS: {
+			Details: TaskSourceICSCalendar,
+			Source:  ICSCalendarSource{ICS: ICSService{}},
+		},
 		TASK_SOURCE_ID_GITHUB_PR: {
 			Details: TaskSourceGithubPR,
 			Source:  GithubPRSource{Github: githubService},
@@ -128,6 +160,7 @@ func (config Config) GetNameToService() map[string]TaskServiceResult {
 	asanaService := AsanaService{Config: config.Asana}
 	atlassianService := AtlassianService{Config: config.Atlassian}
 	linearService := LinearService{Config: config.Linear}
+	microsoftService := MicrosoftService{Config: config.Microsoft}
 	googleService := GoogleService{
 		LoginConfig:  config.GoogleLoginConfig,
 		LinkConfig:   config.GoogleAuthorizeConfig,
@@ -147,6 +180,11 @@ func (config Config) GetNameToService() map[string]TaskServiceResult {
 			Details: TaskServiceAtlassian,
 			Sources: []TaskSourceResult{{Source: JIRASource{Atlassian: atlassianService}, Details: TaskSourceJIRA}},
 		},
+		TASK_SERVICE_ID_CALDAV: {
+			Service: CalDAVService{},
+			Details: TaskServiceCalDAV,
+			Sources: []TaskSourceResult{{Source: CalDAVTaskSource{CalDAV: CalDAVService{}}, Details: TaskSourceCalDAV}},
+		},
 		TASK_SERVICE_ID_GT: {
 			Service: GeneralTaskService{},
 			Details: TaskServiceGeneralTask,
@@ -159,6 +197,16 @@ func (config Config) GetNameToService() map[string]TaskServiceResult {
 				{Source: GoogleCalendarSource{Google: googleService}, Details: TaskSourceGoogleCalendar},
 			},
 		},
+		TASK_SERVICE_ID_MICROSOFT: {
+			Service: microsoftService,
+			Details: TaskServiceMicrosoft,
+			Sources: []TaskSourceResult{{Source: OutlookCalendarSource{Microsoft: microsoftService}, Details: TaskSourceOutlookCalendar}},
+		},
+		TASK_SERVICE_ID_ICS: {
+			Service: ICSService{},
+			Details: TaskServiceICS,
+			Sources: []TaskSourceResult{{Source: ICSCalendarSource{ICS: ICSService{}}, Details: TaskSourceICSCalendar}},
+		},
 		TASK_SERVICE_ID_SLACK: {
 			Service: SlackService{Config: config.Slack},
 			Details: TaskServiceSlack,
@@ -186,6 +234,8 @@ type AuthType string
 
 var AuthTypeOauth2 AuthType = "oauth2"
 var AuthTypeOauth1 AuthType = "oauth1"
+var AuthTypeBasic AuthType = "basic"
+var AuthTypeURL AuthType = "url"
 
 type TaskServiceDetails struct {
 	ID           string
@@ -215,6 +265,15 @@ var TaskServiceAtlassian = TaskServiceDetails{
 	IsLinkable:   true,
 	IsSignupable: false,
 }
+var TaskServiceCalDAV = TaskServiceDetails{
+	ID:           TASK_SERVICE_ID_CALDAV,
+	Name:         "CalDAV",
+	Logo:         "/images/caldav.svg",
+	LogoV2:       "caldav",
+	AuthType:     AuthTypeBasic,
+	IsLinkable:   true,
+	IsSignupable: false,
+}
 var TaskServiceGeneralTask = TaskServiceDetails{
 	ID:           TASK_SERVICE_ID_GT,
 	Name:         "General Task",
@@ -242,6 +301,24 @@ var TaskServiceGoogle = TaskServiceDetails{
 	IsLinkable:   true,
 	IsSignupable: true,
 }
+var TaskServiceMicrosoft = TaskServiceDetails{
+	ID:           TASK_SERVICE_ID_MICROSOFT,
+	Name:         "Outlook Calendar",
+	Logo:         "/images/outlook.svg",
+	LogoV2:       "outlook",
+	AuthType:     AuthTypeOauth2,
+	IsLinkable:   true,
+	IsSignupable: false,
+}
+var TaskServiceICS = TaskServiceDetails{
+	ID:           TASK_SERVICE_ID_ICS,
+	Name:         "ICS Calendar",
+	Logo:         "/images/ics.svg",
+	LogoV2:       "ics",
+	AuthType:     AuthTypeURL,
+	IsLinkable:   true,
+	IsSignupable: false,
+}
 var TaskServiceSlack = TaskServiceDetails{
 	ID:           TASK_SERVICE_ID_SLACK,
 	Name:         "Slack",
@@ -278,7 +355,17 @@ var TaskSourceAsana = TaskSourceDetails{
 	Logo:                   "/images/asana.svg",
 	LogoV2:                 "asana",
 	IsCompletable:          true,
-	CanCreateTask:          false,
+	CanCreateTask:          true,
+	IsReplyable:            false,
+	CanCreateCalendarEvent: false,
+}
+var TaskSourceCalDAV = TaskSourceDetails{
+	ID:                     TASK_SOURCE_ID_CALDAV,
+	Name:                   "CalDAV",
+	Logo:                   "/images/caldav.svg",
+	LogoV2:                 "caldav",
+	IsCompletable:          true,
+	CanCreateTask:          true,
 	IsReplyable:            false,
 	CanCreateCalendarEvent: false,
 }
@@ -318,7 +405,7 @@ var TaskSourceJIRA = TaskSourceDetails{
 	Logo:                   "/images/jira.svg",
 	LogoV2:                 "jira",
 	IsCompletable:          true,
-	CanCreateTask:          false,
+	CanCreateTask:          true,
 	IsReplyable:            false,
 	CanCreateCalendarEvent: false,
 }
@@ -328,6 +415,26 @@ var TaskSourceLinear = TaskSourceDetails{
 	Logo:                   "/images/linear.png",
 	LogoV2:                 "linear",
 	IsCompletable:          true,
+	CanCreateTask:          true,
+	IsReplyable:            false,
+	CanCreateCalendarEvent: false,
+}
+var TaskSourceOutlookCalendar = TaskSourceDetails{
+	ID:                     TASK_SOURCE_ID_OUTLOOK,
+	Name:                   "Outlook Calendar",
+	Logo:                   "/images/outlook.svg",
+	LogoV2:                 "outlook",
+	IsCompletable:          true,
+	CanCreateTask:          false,
+	IsReplyable:            false,
+	CanCreateCalendarEvent: true,
+}
+var TaskSourceICSCalendar = TaskSourceDetails{
+	ID:                     TASK_SOURCE_ID_ICS,
+	Name:                   "ICS Calendar",
+	Logo:                   "/images/ics.svg",
+	LogoV2:                 "ics",
+	IsCompletable:          false,
 	CanCreateTask:          false,
 	IsReplyable:            false,
 	CanCreateCalendarEvent: false,
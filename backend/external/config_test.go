@@ -17,6 +17,7 @@ func TestGetConfig(t *testing.T) {
 	assert.Equal(t, getSlackConfig(), config.Slack)
 	assert.Equal(t, GetSlackAppConfig(), config.SlackApp)
 	assert.Equal(t, LinearConfig{OauthConfig: getLinearOauthConfig()}, config.Linear)
+	assert.Equal(t, MicrosoftConfig{OauthConfig: getMicrosoftOauthConfig()}, config.Microsoft)
 }
 
 func TestGetTaskServiceResult(t *testing.T) {
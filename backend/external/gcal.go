@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
 
 	"github.com/jjPlusPlus/task-manager/backend/logging"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/jjPlusPlus/task-manager/backend/config"
 	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/jjPlusPlus/task-manager/backend/utils"
@@ -21,13 +24,36 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/api/option"
 )
 
+const (
+	gcalEventStatusCancelled = "cancelled"
+	// full syncs cover more than the requested window, so that moving between nearby weeks stays incremental
+	gcalSyncWindowPadding = 7 * 24 * time.Hour
+	// the calendar list and colors rarely change, so they are only fetched again after this interval
+	gcalCalendarListRefreshInterval = time.Hour
+	// push channels last about a week, and are renewed once they expire within this window
+	gcalWatchRenewalWindow = 24 * time.Hour
+)
+
 type GoogleCalendarSource struct {
 	Google GoogleService
 }
 
+// CalendarBusyRange is a time a calendar is busy, as reported by a free/busy query
+type CalendarBusyRange struct {
+	DatetimeStart time.Time `json:"datetime_start"`
+	DatetimeEnd   time.Time `json:"datetime_end"`
+}
+
+type calendarSyncResult struct {
+	Calendar       database.Calendar
+	CalendarEvents []*database.CalendarEvent
+	Error          error
+}
+
 func processAndStoreEvent(event *calendar.Event, db *mongo.Database, userID primitive.ObjectID, accountID string, calendarID string, colors *calendar.Colors) *database.CalendarEvent {
 	//exclude all day events which won't have a start time.
 	if len(event.Start.DateTime) == 0 {
@@ -36,11 +62,19 @@ func processAndStoreEvent(event *calendar.Event, db *mongo.Database, userID prim
 
 	//exclude events we declined.
 	attendeeEmails := []string{}
+	attendees := []database.CalendarEventAttendee{}
 	for _, attendee := range event.Attendees {
-		if attendee.Self && attendee.ResponseStatus == "declined" {
+		if attendee.Self && attendee.ResponseStatus == constants.EventResponseDeclined {
 			return &database.CalendarEvent{}
 		}
 		attendeeEmails = append(attendeeEmails, attendee.Email)
+		attendees = append(attendees, database.CalendarEventAttendee{
+			Name:           attendee.DisplayName,
+			Email:          attendee.Email,
+			ResponseStatus: attendee.ResponseStatus,
+			IsSelf:         attendee.Self,
+			IsOrganizer:    attendee.Organizer,
+		})
 	}
 
 	dbStartTime, _ := time.Parse(time.RFC3339, event.Start.DateTime)
@@ -50,9 +84,7 @@ func processAndStoreEvent(event *calendar.Event, db *mongo.Database, userID prim
 	if event.Organizer != nil {
 		canModify = canModify || event.Organizer.Self
 	}
-	if calendarID == "primary" {
-		calendarID = accountID
-	}
+	calendarID = getGcalEventCalendarID(accountID, calendarID)
 	dbEvent := &database.CalendarEvent{
 		UserID:          userID,
 		IDExternal:      event.Id,
@@ -73,6 +105,16 @@ func processAndStoreEvent(event *calendar.Event, db *mongo.Database, userID prim
 		CallLogo:        conferenceCall.Logo,
 		CallPlatform:    conferenceCall.Platform,
 		AttendeeEmails:  attendeeEmails,
+		Attendees:       attendees,
+	}
+	if event.RecurringEventId != "" {
+		dbEvent.RecurringEventID = event.RecurringEventId
+		if event.OriginalStartTime != nil {
+			originalStartTime, err := time.Parse(time.RFC3339, event.OriginalStartTime.DateTime)
+			if err == nil {
+				dbEvent.OriginalStartTime = primitive.NewDateTimeFromTime(originalStartTime)
+			}
+		}
 	}
 	if colors != nil {
 		dbEvent.ColorBackground = colors.Event[event.ColorId].Background
@@ -97,34 +139,393 @@ func processAndStoreEvent(event *calendar.Event, db *mongo.Database, userID prim
 	return dbEvent
 }
 
-func (googleCalendar GoogleCalendarSource) fetchEvents(calendarService *calendar.Service, db *mongo.Database, userID primitive.ObjectID, accountID string, calendarId string, startTime time.Time, endTime time.Time, result chan<- CalendarResult, colors *calendar.Colors) {
-	calendarResponse, err := calendarService.Events.
-		List(calendarId).
-		TimeMin(startTime.Format(time.RFC3339)).
-		TimeMax(endTime.Format(time.RFC3339)).
-		MaxResults(2500).
-		SingleEvents(true).
-		OrderBy("startTime").
-		Do()
-	logger := logging.GetSentryLogger()
+// getGcalEventCalendarID returns the calendar ID stored on events, which uses the account ID for the primary calendar
+func getGcalEventCalendarID(accountID string, calendarID string) string {
+	if calendarID == "primary" {
+		return accountID
+	}
+	return calendarID
+}
+
+// isRemovedGcalEvent returns whether an event from an incremental sync should no longer be stored,
+// because it was cancelled, declined, or became an all day event
+func isRemovedGcalEvent(event *calendar.Event) bool {
+	if event.Status == gcalEventStatusCancelled || event.Start == nil || len(event.Start.DateTime) == 0 {
+		return true
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.Self && attendee.ResponseStatus == constants.EventResponseDeclined {
+			return true
+		}
+	}
+	return false
+}
+
+// isGcalSyncTokenExpired returns whether google rejected the sync token, after which a full sync is needed
+func isGcalSyncTokenExpired(err error) bool {
+	var apiError *googleapi.Error
+	return errors.As(err, &apiError) && apiError.Code == http.StatusGone
+}
+
+// listGcalEvents pages through the events in the time window, or the changes since the sync token when one is given
+func listGcalEvents(calendarService *calendar.Service, calendarID string, syncToken string, startTime time.Time, endTime time.Time) ([]*calendar.Event, string, error) {
+	events := []*calendar.Event{}
+	pageToken := ""
+	for {
+		call := calendarService.Events.List(calendarID).MaxResults(2500).SingleEvents(true)
+		if syncToken != "" {
+			call = call.SyncToken(syncToken)
+		} else {
+			call = call.TimeMin(startTime.Format(time.RFC3339)).TimeMax(endTime.Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		response, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, response.Items...)
+		if response.NextPageToken == "" {
+			return events, response.NextSyncToken, nil
+		}
+		pageToken = response.NextPageToken
+	}
+}
 
+func getStoredGcalEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, calendarID string, startTime time.Time, endTime time.Time) ([]*database.CalendarEvent, error) {
+	storedEvents, err := database.GetCalendarEvents(db, userID, &[]bson.M{
+		{"source_id": TASK_SOURCE_ID_GCAL},
+		{"source_account_id": accountID},
+		{"calendar_id": getGcalEventCalendarID(accountID, calendarID)},
+		{"datetime_end": bson.M{"$gte": startTime}},
+		{"datetime_start": bson.M{"$lte": endTime}},
+	})
 	if err != nil {
+		return nil, err
+	}
+	events := []*database.CalendarEvent{}
+	for index := range *storedEvents {
+		events = append(events, &(*storedEvents)[index])
+	}
+	return events, nil
+}
+
+// removeGcalEvent deletes the stored copy of an event, and flags its meeting prep task once no calendar has the event anymore
+func removeGcalEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, calendarID string, externalID string) error {
+	eventCollection := database.GetCalendarEventCollection(db)
+	storedEvents, err := database.GetCalendarEvents(db, userID, &[]bson.M{
+		{"source_id": TASK_SOURCE_ID_GCAL},
+		{"source_account_id": accountID},
+		{"calendar_id": getGcalEventCalendarID(accountID, calendarID)},
+		{"id_external": externalID},
+	})
+	if err != nil || len(*storedEvents) == 0 {
+		return err
+	}
+	for _, storedEvent := range *storedEvents {
+		_, err = eventCollection.DeleteOne(context.Background(), bson.M{"_id": storedEvent.ID})
+		if err != nil {
+			return err
+		}
+		database.PublishChange(db, userID, database.ChangeTypeCalendarEvent, storedEvent.ID)
+	}
+
+	remainingCount, err := eventCollection.CountDocuments(context.Background(), bson.M{"$and": []bson.M{
+		{"user_id": userID},
+		{"source_id": TASK_SOURCE_ID_GCAL},
+		{"id_external": externalID},
+	}})
+	if err != nil || remainingCount > 0 {
+		return err
+	}
+	_, err = database.GetTaskCollection(db).UpdateMany(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"is_meeting_preparation_task": true},
+			{"source_id": TASK_SOURCE_ID_GCAL},
+			{"meeting_preparation_params.id_external": externalID},
+		}},
+		bson.M{"$set": bson.M{"meeting_preparation_params.event_moved_or_deleted": true}},
+	)
+	return err
+}
+
+// removeStaleGcalEvents removes stored events that a full sync of the range no longer returned
+func removeStaleGcalEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, calendarID string, fetchedEventIDs map[string]bool, syncStart time.Time, syncEnd time.Time) error {
+	storedEvents, err := database.GetCalendarEvents(db, userID, &[]bson.M{
+		{"source_id": TASK_SOURCE_ID_GCAL},
+		{"source_account_id": accountID},
+		{"calendar_id": getGcalEventCalendarID(accountID, calendarID)},
+		{"datetime_end": bson.M{"$gt": syncStart}},
+		{"datetime_start": bson.M{"$lt": syncEnd}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, storedEvent := range *storedEvents {
+		if fetchedEventIDs[storedEvent.IDExternal] {
+			continue
+		}
+		err = removeGcalEvent(db, userID, accountID, calendarID, storedEvent.IDExternal)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isGcalCalendarSynced returns whether the stored events of the calendar are up to date for the whole time window
+func isGcalCalendarSynced(gcalCalendar database.Calendar, startTime time.Time, endTime time.Time) bool {
+	return gcalCalendar.SyncToken != "" &&
+		!startTime.Before(gcalCalendar.SyncedFrom.Time()) &&
+		!endTime.After(gcalCalendar.SyncedUntil.Time())
+}
+
+// updateGcalSyncedRange records the window of a full sync. The previously synced range is kept when the two
+// overlap, as it was brought up to date by the incremental sync just before.
+func updateGcalSyncedRange(gcalCalendar *database.Calendar, hadValidSyncToken bool, startTime time.Time, endTime time.Time) {
+	previousFrom := gcalCalendar.SyncedFrom.Time()
+	previousUntil := gcalCalendar.SyncedUntil.Time()
+	if !hadValidSyncToken || startTime.After(previousUntil) || endTime.Before(previousFrom) {
+		gcalCalendar.SyncedFrom = primitive.NewDateTimeFromTime(startTime)
+		gcalCalendar.SyncedUntil = primitive.NewDateTimeFromTime(endTime)
+		return
+	}
+	if startTime.Before(previousFrom) {
+		gcalCalendar.SyncedFrom = primitive.NewDateTimeFromTime(startTime)
+	}
+	if endTime.After(previousUntil) {
+		gcalCalendar.SyncedUntil = primitive.NewDateTimeFromTime(endTime)
+	}
+}
+
+func isGcalEventInWindow(event *database.CalendarEvent, startTime time.Time, endTime time.Time) bool {
+	return !event.DatetimeEnd.Time().Before(startTime) && !event.DatetimeStart.Time().After(endTime)
+}
+
+// syncCalendarEvents brings the stored events of a calendar up to date, using the sync token when the calendar
+// has been synced before, and returns the events in the time window
+func (googleCalendar GoogleCalendarSource) syncCalendarEvents(calendarService *calendar.Service, db *mongo.Database, userID primitive.ObjectID, accountID string, gcalCalendar database.Calendar, startTime time.Time, endTime time.Time, colors *calendar.Colors, result chan<- calendarSyncResult) {
+	logger := logging.GetSentryLogger()
+	handleError := func(err error) {
 		isBadToken := CheckAndHandleBadToken(err, db, userID, accountID, TASK_SERVICE_ID_GOOGLE)
 		if !isBadToken {
 			logger.Error().Err(err).Msg("unable to load calendar events")
 		}
-		result <- emptyCalendarResult(err)
-		return
+		result <- calendarSyncResult{Calendar: gcalCalendar, Error: err}
 	}
 
-	var events []*database.CalendarEvent
-	for _, event := range calendarResponse.Items {
-		dbEvent := processAndStoreEvent(event, db, userID, accountID, calendarId, colors)
-		if dbEvent != nil && !cmp.Equal(*dbEvent, (database.CalendarEvent{})) {
+	hadValidSyncToken := false
+	if gcalCalendar.SyncToken != "" {
+		changedEvents, nextSyncToken, err := listGcalEvents(calendarService, gcalCalendar.CalendarID, gcalCalendar.SyncToken, startTime, endTime)
+		if err != nil && !isGcalSyncTokenExpired(err) {
+			handleError(err)
+			return
+		}
+		if err == nil {
+			hadValidSyncToken = true
+			for _, event := range changedEvents {
+				if isRemovedGcalEvent(event) {
+					err = removeGcalEvent(db, userID, accountID, gcalCalendar.CalendarID, event.Id)
+					if err != nil {
+						logger.Error().Err(err).Msg("failed to remove calendar event")
+					}
+					continue
+				}
+				processAndStoreEvent(event, db, userID, accountID, gcalCalendar.CalendarID, colors)
+			}
+			gcalCalendar.SyncToken = nextSyncToken
+			if isGcalCalendarSynced(gcalCalendar, startTime, endTime) {
+				events, err := getStoredGcalEvents(db, userID, accountID, gcalCalendar.CalendarID, startTime, endTime)
+				if err != nil {
+					result <- calendarSyncResult{Calendar: gcalCalendar, Error: err}
+					return
+				}
+				googleCalendar.watchCalendar(calendarService, &gcalCalendar, time.Now())
+				result <- calendarSyncResult{Calendar: gcalCalendar, CalendarEvents: events}
+				return
+			}
+		}
+	}
+
+	// the padding keeps events that only touch the window in sync, and lets nearby windows reuse this sync
+	syncStart := startTime.Add(-gcalSyncWindowPadding)
+	syncEnd := endTime.Add(gcalSyncWindowPadding)
+	fetchedEvents, nextSyncToken, err := listGcalEvents(calendarService, gcalCalendar.CalendarID, "", syncStart, syncEnd)
+	if err != nil {
+		handleError(err)
+		return
+	}
+	events := []*database.CalendarEvent{}
+	fetchedEventIDs := map[string]bool{}
+	for _, event := range fetchedEvents {
+		dbEvent := processAndStoreEvent(event, db, userID, accountID, gcalCalendar.CalendarID, colors)
+		if dbEvent == nil || cmp.Equal(*dbEvent, (database.CalendarEvent{})) {
+			continue
+		}
+		fetchedEventIDs[dbEvent.IDExternal] = true
+		if isGcalEventInWindow(dbEvent, startTime, endTime) {
 			events = append(events, dbEvent)
 		}
 	}
-	result <- CalendarResult{events, nil}
+	err = removeStaleGcalEvents(db, userID, accountID, gcalCalendar.CalendarID, fetchedEventIDs, syncStart, syncEnd)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to remove stale calendar events")
+	}
+	updateGcalSyncedRange(&gcalCalendar, hadValidSyncToken, syncStart, syncEnd)
+	gcalCalendar.SyncToken = nextSyncToken
+	googleCalendar.watchCalendar(calendarService, &gcalCalendar, time.Now())
+	result <- calendarSyncResult{Calendar: gcalCalendar, CalendarEvents: events}
+}
+
+// watchCalendar registers a push channel for changes to the calendar, replacing the previous channel before it expires.
+// Google only delivers notifications to https addresses, so local servers rely on the periodic sync instead.
+func (googleCalendar GoogleCalendarSource) watchCalendar(calendarService *calendar.Service, gcalCalendar *database.Calendar, timeNow time.Time) {
+	address := getGoogleCalendarWebhookURL()
+	if !strings.HasPrefix(address, "https://") {
+		return
+	}
+	if gcalCalendar.WatchChannelID != "" && gcalCalendar.WatchExpiration.Time().After(timeNow.Add(gcalWatchRenewalWindow)) {
+		return
+	}
+	watchToken := uuid.New().String()
+	channel, err := calendarService.Events.Watch(gcalCalendar.CalendarID, &calendar.Channel{
+		Id:      uuid.New().String(),
+		Type:    "web_hook",
+		Address: address,
+		Token:   watchToken,
+	}).Do()
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to watch calendar %s", gcalCalendar.CalendarID)
+		return
+	}
+	// the previous channel is stopped once the calendar account is saved, see saveGcalCalendarAccount
+	gcalCalendar.WatchChannelID = channel.Id
+	gcalCalendar.WatchResourceID = channel.ResourceId
+	gcalCalendar.WatchToken = watchToken
+	gcalCalendar.WatchExpiration = primitive.DateTime(channel.Expiration)
+}
+
+func stopGcalWatchChannel(calendarService *calendar.Service, gcalCalendar database.Calendar) {
+	err := calendarService.Channels.Stop(&calendar.Channel{Id: gcalCalendar.WatchChannelID, ResourceId: gcalCalendar.WatchResourceID}).Do()
+	if err != nil {
+		log.Debug().Err(err).Msgf("failed to stop channel for calendar %s", gcalCalendar.CalendarID)
+	}
+}
+
+// saveGcalCalendarAccount stores the account, and stops the push channels of the account it replaced that it doesn't keep.
+// These are channels of calendars which are no longer in the list, channels which were renewed, and channels registered by
+// a concurrent sync that saved the account first.
+func saveGcalCalendarAccount(db *mongo.Database, calendarService *calendar.Service, calendarAccount database.CalendarAccount) error {
+	var previousAccount database.CalendarAccount
+	err := database.GetCalendarAccountCollection(db).FindOneAndUpdate(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"id_external": calendarAccount.IDExternal},
+			{"source_id": TASK_SOURCE_ID_GCAL},
+			{"user_id": calendarAccount.UserID},
+		}},
+		bson.M{"$set": calendarAccount},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&previousAccount)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	keptChannelIDs := map[string]bool{}
+	for _, gcalCalendar := range calendarAccount.Calendars {
+		keptChannelIDs[gcalCalendar.WatchChannelID] = true
+	}
+	for _, previousCalendar := range previousAccount.Calendars {
+		if previousCalendar.WatchChannelID != "" && !keptChannelIDs[previousCalendar.WatchChannelID] {
+			stopGcalWatchChannel(calendarService, previousCalendar)
+		}
+	}
+	return nil
+}
+
+func getGoogleCalendarWebhookURL() string {
+	return config.GetConfigValue("SERVER_URL") + "google/calendar/webhook/"
+}
+
+// shouldRefreshGcalCalendars returns whether the calendar list and colors need to be fetched again,
+// which is skipped on most syncs as they rarely change
+func shouldRefreshGcalCalendars(calendarAccount *database.CalendarAccount, scopes []string, timeNow time.Time) bool {
+	if calendarAccount == nil || len(calendarAccount.Calendars) == 0 {
+		return true
+	}
+	if database.HasUserGrantedMultiCalendarScope(calendarAccount.Scopes) != database.HasUserGrantedMultiCalendarScope(scopes) {
+		return true
+	}
+	return timeNow.Sub(calendarAccount.CalendarsRefreshedAt.Time()) >= gcalCalendarListRefreshInterval
+}
+
+// fetchGcalCalendars returns the calendars to sync and the color mapping. The error is set when the calendar
+// list could not be fetched, in which case only the primary calendar is returned.
+func fetchGcalCalendars(calendarService *calendar.Service, accountID string, scopes []string) ([]database.Calendar, *calendar.Colors, error) {
+	colors, err := calendarService.Colors.Get().Do()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get color mapping")
+	}
+
+	var calendarListErr error
+	if database.HasUserGrantedMultiCalendarScope(scopes) {
+		calendarList, err := calendarService.CalendarList.List().Do()
+		if err == nil && calendarList != nil {
+			calendars := []database.Calendar{}
+			for _, calendarEntry := range calendarList.Items {
+				cal := database.Calendar{
+					AccessRole: calendarEntry.AccessRole,
+					CalendarID: calendarEntry.Id,
+					ColorID:    calendarEntry.ColorId,
+					Title:      calendarEntry.Summary,
+				}
+				if colors != nil {
+					cal.ColorBackground = colors.Calendar[calendarEntry.ColorId].Background
+					cal.ColorForeground = colors.Calendar[calendarEntry.ColorId].Foreground
+				}
+				calendars = append(calendars, cal)
+			}
+			return calendars, colors, nil
+		}
+		log.Error().Err(err).Send()
+		calendarListErr = errors.New("failed to fetch calendar list")
+	}
+
+	// If we can't fetch the calendar list, we try fetching just the primary calendar
+	log.Debug().Msgf("could not fetch calendar list for accountID: %s", accountID)
+	return []database.Calendar{
+		{
+			CalendarID: accountID,
+			AccessRole: constants.AccessControlOwner,
+		},
+	}, colors, calendarListErr
+}
+
+func getStoredEventColors(colors *calendar.Colors) map[string]database.CalendarColor {
+	if colors == nil {
+		return nil
+	}
+	eventColors := map[string]database.CalendarColor{}
+	for colorID, color := range colors.Event {
+		eventColors[colorID] = database.CalendarColor{Background: color.Background, Foreground: color.Foreground}
+	}
+	return eventColors
+}
+
+func getGcalColors(eventColors map[string]database.CalendarColor) *calendar.Colors {
+	if eventColors == nil {
+		return nil
+	}
+	colors := &calendar.Colors{Event: map[string]calendar.ColorDefinition{}}
+	for colorID, color := range eventColors {
+		colors.Event[colorID] = calendar.ColorDefinition{Background: color.Background, Foreground: color.Foreground}
+	}
+	return colors
 }
 
 func (googleCalendar GoogleCalendarSource) GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult) {
@@ -133,9 +534,12 @@ func (googleCalendar GoogleCalendarSource) GetEvents(db *mongo.Database, userID
 		result <- emptyCalendarResult(err)
 		return
 	}
-	err = updateUserTimezone(calendarService, db, userID, accountID)
+	existingAccount, err := database.GetCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL)
 	if err != nil {
-		log.Error().Err(err).Send()
+		if err != mongo.ErrNoDocuments {
+			log.Error().Err(err).Msg("failed to load calendar account")
+		}
+		existingAccount = nil
 	}
 	calendarAccount := database.CalendarAccount{
 		UserID:     userID,
@@ -143,83 +547,83 @@ func (googleCalendar GoogleCalendarSource) GetEvents(db *mongo.Database, userID
 		SourceID:   TASK_SOURCE_ID_GCAL,
 		Scopes:     scopes,
 	}
-	var events []*database.CalendarEvent
 
-	fetchAllCalendars := false
-	var calendarList *calendar.CalendarList
-	if database.HasUserGrantedMultiCalendarScope(scopes) {
-		calendarList, err = calendarService.CalendarList.List().Do()
-		if err == nil && calendarList != nil {
-			fetchAllCalendars = true
-		} else {
+	timeNow := time.Now()
+	var colors *calendar.Colors
+	if shouldRefreshGcalCalendars(existingAccount, scopes, timeNow) {
+		err = updateUserTimezone(calendarService, db, userID, accountID)
+		if err != nil {
 			log.Error().Err(err).Send()
 		}
-	}
-
-	colors, err := calendarService.Colors.Get().Do()
-	if err != nil {
-		log.Error().Err(err).Msg("could not get color mapping")
-	}
-
-	// If we can't fetch the calendar list, we try fetching just the primary calendar
-	if !fetchAllCalendars {
-		log.Debug().Err(err).Msgf("could not fetch calendar list for accountID: %s", accountID)
-		eventChannel := make(chan CalendarResult)
-		go googleCalendar.fetchEvents(calendarService, db, userID, accountID, "primary", startTime, endTime, eventChannel, colors)
-		eventResult := <-eventChannel
-		if eventResult.Error != nil {
-			result <- emptyCalendarResult(errors.New("failed to fetch events"))
-		}
-		events = append(events, eventResult.CalendarEvents...)
-		calendarAccount.Calendars = []database.Calendar{
-			{
-				CalendarID: accountID,
-				AccessRole: constants.AccessControlOwner,
-				ColorID:    "",
-				Title:      "",
-			},
+		var calendarListErr error
+		calendarAccount.Calendars, colors, calendarListErr = fetchGcalCalendars(calendarService, accountID, scopes)
+		calendarAccount.EventColors = getStoredEventColors(colors)
+		// a failed calendar list is retried on the next sync rather than after the refresh interval
+		if calendarListErr == nil {
+			calendarAccount.CalendarsRefreshedAt = primitive.NewDateTimeFromTime(timeNow)
 		}
-		_, err = database.UpdateOrCreateCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL, calendarAccount, nil)
-		if err != nil {
-			result <- emptyCalendarResult(err)
+		if existingAccount != nil {
+			copyGcalSyncState(calendarAccount.Calendars, existingAccount.Calendars)
 		}
-		result <- CalendarResult{CalendarEvents: events, Error: nil}
-		return
+	} else {
+		calendarAccount.Calendars = existingAccount.Calendars
+		colors = getGcalColors(existingAccount.EventColors)
 	}
 
-	var calendars []database.Calendar
-	eventsChannels := []chan CalendarResult{}
-	for _, calendar := range calendarList.Items {
-		cal := database.Calendar{
-			AccessRole: calendar.AccessRole,
-			CalendarID: calendar.Id,
-			ColorID:    calendar.ColorId,
-			Title:      calendar.Summary,
-		}
-		if colors != nil {
-			cal.ColorBackground = colors.Calendar[calendar.ColorId].Background
-			cal.ColorForeground = colors.Calendar[calendar.ColorId].Foreground
-		}
-		calendars = append(calendars, cal)
-		eventChannel := make(chan CalendarResult)
-		go googleCalendar.fetchEvents(calendarService, db, userID, accountID, calendar.Id, startTime, endTime, eventChannel, colors)
-		eventsChannels = append(eventsChannels, eventChannel)
+	syncChannels := []chan calendarSyncResult{}
+	for _, gcalCalendar := range calendarAccount.Calendars {
+		syncChannel := make(chan calendarSyncResult)
+		go googleCalendar.syncCalendarEvents(calendarService, db, userID, accountID, gcalCalendar, startTime, endTime, colors, syncChannel)
+		syncChannels = append(syncChannels, syncChannel)
 	}
-	for _, eventChannel := range eventsChannels {
-		eventResult := <-eventChannel
-		if eventResult.Error != nil {
+	var events []*database.CalendarEvent
+	failedCount := 0
+	for index, syncChannel := range syncChannels {
+		syncResult := <-syncChannel
+		calendarAccount.Calendars[index] = syncResult.Calendar
+		if syncResult.Error != nil {
+			failedCount++
+			// the stored events are returned so that callers don't treat them as deleted
+			storedEvents, err := getStoredGcalEvents(db, userID, accountID, syncResult.Calendar.CalendarID, startTime, endTime)
+			if err == nil {
+				events = append(events, storedEvents...)
+			}
 			continue
 		}
-		events = append(events, eventResult.CalendarEvents...)
+		events = append(events, syncResult.CalendarEvents...)
 	}
-	calendarAccount.Calendars = calendars
-	_, err = database.UpdateOrCreateCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL, calendarAccount, nil)
+	err = saveGcalCalendarAccount(db, calendarService, calendarAccount)
 	if err != nil {
 		log.Error().Err(err).Msgf("could not create CalendarAccount: %+v", calendarAccount)
 	}
+	if len(syncChannels) > 0 && failedCount == len(syncChannels) {
+		result <- emptyCalendarResult(errors.New("failed to fetch events"))
+		return
+	}
 	result <- CalendarResult{CalendarEvents: events, Error: nil}
 }
 
+// copyGcalSyncState keeps the sync tokens and push channels of calendars that are still in the refreshed list
+func copyGcalSyncState(calendars []database.Calendar, previousCalendars []database.Calendar) {
+	calendarIDToPrevious := map[string]database.Calendar{}
+	for _, previousCalendar := range previousCalendars {
+		calendarIDToPrevious[previousCalendar.CalendarID] = previousCalendar
+	}
+	for index := range calendars {
+		previousCalendar, ok := calendarIDToPrevious[calendars[index].CalendarID]
+		if !ok {
+			continue
+		}
+		calendars[index].SyncToken = previousCalendar.SyncToken
+		calendars[index].SyncedFrom = previousCalendar.SyncedFrom
+		calendars[index].SyncedUntil = previousCalendar.SyncedUntil
+		calendars[index].WatchChannelID = previousCalendar.WatchChannelID
+		calendars[index].WatchResourceID = previousCalendar.WatchResourceID
+		calendars[index].WatchToken = previousCalendar.WatchToken
+		calendars[index].WatchExpiration = previousCalendar.WatchExpiration
+	}
+}
+
 func (googleCalendar GoogleCalendarSource) GetTasks(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- TaskResult) {
 	result <- emptyTaskResult(nil)
 }
@@ -255,7 +659,18 @@ func (googleCalendar GoogleCalendarSource) CreateNewEvent(db *mongo.Database, us
 			DateTime: event.DatetimeEnd.Format(time.RFC3339),
 			TimeZone: event.TimeZone,
 		},
-		Attendees: *createGcalAttendees(&event.Attendees),
+		Attendees:  *createGcalAttendees(&event.Attendees),
+		Recurrence: event.Recurrence,
+	}
+	if len(event.Recurrence) > 0 && event.TimeZone == "" {
+		// google needs a time zone to expand the recurrence in
+		timeZone := "UTC"
+		location, err := database.GetUserLocation(db, userID)
+		if err == nil && location != nil {
+			timeZone = location.String()
+		}
+		gcalEvent.Start.TimeZone = timeZone
+		gcalEvent.End.TimeZone = timeZone
 	}
 	if event.AddConferenceCall {
 		gcalEvent.ConferenceData = createConferenceCallRequest()
@@ -281,7 +696,7 @@ func (googleCalendar GoogleCalendarSource) CreateNewEvent(db *mongo.Database, us
 	return nil
 }
 
-func (googleCalendar GoogleCalendarSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (googleCalendar GoogleCalendarSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
 	// TODO: create a EventDeleteURL
 	calendarService, err := createGcalService(googleCalendar.Google.OverrideURLs.CalendarDeleteURL, userID, accountID, context.Background(), db)
 	if err != nil {
@@ -292,8 +707,25 @@ func (googleCalendar GoogleCalendarSource) DeleteEvent(db *mongo.Database, userI
 	if calendarID != "" {
 		calendarIDToDelete = calendarID
 	}
-	err = calendarService.Events.Delete(calendarIDToDelete, externalID).Do()
 	logger := logging.GetSentryLogger()
+	if !IsSingleEventScope(scope) {
+		instance, series, err := getGcalSeries(calendarService, calendarIDToDelete, externalID)
+		if err != nil {
+			logger.Error().Err(err).Msg("unable to fetch recurring event")
+			return err
+		}
+		if series != nil {
+			err = deleteGcalSeries(calendarService, calendarIDToDelete, instance, series, scope)
+			if err != nil {
+				logger.Error().Err(err).Msg("unable to delete recurring event")
+				return err
+			}
+			resetGcalSyncToken(db, userID, accountID, calendarIDToDelete)
+			log.Info().Msgf("gcal recurring event successfully deleted externalID=%s scope=%s", externalID, scope)
+			return nil
+		}
+	}
+	err = calendarService.Events.Delete(calendarIDToDelete, externalID).Do()
 	if err != nil {
 		logger.Error().Err(err).Msg("unable to create event")
 		return err
@@ -303,6 +735,190 @@ func (googleCalendar GoogleCalendarSource) DeleteEvent(db *mongo.Database, userI
 	return nil
 }
 
+// GetFreeBusy returns the busy times of each calendar, keyed by calendar ID (the email address for people's primary
+// calendars), along with the calendars which couldn't be read, e.g. those of people outside the user's organization
+func (googleCalendar GoogleCalendarSource) GetFreeBusy(db *mongo.Database, userID primitive.ObjectID, accountID string, calendarIDs []string, startTime time.Time, endTime time.Time) (map[string][]CalendarBusyRange, []string, error) {
+	calendarService, err := createGcalService(googleCalendar.Google.OverrideURLs.CalendarFetchURL, userID, accountID, context.Background(), db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := []*calendar.FreeBusyRequestItem{}
+	for _, calendarID := range calendarIDs {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: calendarID})
+	}
+	response, err := calendarService.Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: startTime.Format(time.RFC3339),
+		TimeMax: endTime.Format(time.RFC3339),
+		Items:   items,
+	}).Do()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	calendarIDToBusy := map[string][]CalendarBusyRange{}
+	unavailableCalendarIDs := []string{}
+	for _, calendarID := range calendarIDs {
+		freeBusyCalendar, exists := response.Calendars[calendarID]
+		if !exists || len(freeBusyCalendar.Errors) > 0 {
+			unavailableCalendarIDs = append(unavailableCalendarIDs, calendarID)
+			continue
+		}
+		busyRanges := []CalendarBusyRange{}
+		for _, period := range freeBusyCalendar.Busy {
+			periodStart, err := time.Parse(time.RFC3339, period.Start)
+			if err != nil {
+				return nil, nil, err
+			}
+			periodEnd, err := time.Parse(time.RFC3339, period.End)
+			if err != nil {
+				return nil, nil, err
+			}
+			busyRanges = append(busyRanges, CalendarBusyRange{DatetimeStart: periodStart, DatetimeEnd: periodEnd})
+		}
+		calendarIDToBusy[calendarID] = busyRanges
+	}
+	return calendarIDToBusy, unavailableCalendarIDs, nil
+}
+
+func (googleCalendar GoogleCalendarSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
+	calendarService, err := createGcalService(googleCalendar.Google.OverrideURLs.CalendarModifyURL, userID, accountID, context.Background(), db)
+	if err != nil {
+		return err
+	}
+
+	calendarIDToRespond := accountID
+	if calendarID != "" {
+		calendarIDToRespond = calendarID
+	}
+	gcalEvent, err := calendarService.Events.Get(calendarIDToRespond, externalID).Do()
+	if err != nil {
+		return err
+	}
+	// patches replace the whole attendee list, so it's sent back with only our response changed
+	isAttendee := false
+	for _, attendee := range gcalEvent.Attendees {
+		if attendee.Self {
+			attendee.ResponseStatus = response
+			isAttendee = true
+		}
+	}
+	if !isAttendee {
+		return errors.New("not an attendee of the event")
+	}
+	_, err = calendarService.Events.Patch(calendarIDToRespond, externalID, &calendar.Event{Attendees: gcalEvent.Attendees}).Do()
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("unable to respond to event")
+		return err
+	}
+	return nil
+}
+
+// getGcalSeries returns the event, and the recurring event it's an instance of, which is nil for single events
+func getGcalSeries(calendarService *calendar.Service, calendarID string, eventID string) (*calendar.Event, *calendar.Event, error) {
+	instance, err := calendarService.Events.Get(calendarID, eventID).Do()
+	if err != nil {
+		return nil, nil, err
+	}
+	if instance.RecurringEventId == "" || instance.OriginalStartTime == nil {
+		return instance, nil, nil
+	}
+	series, err := calendarService.Events.Get(calendarID, instance.RecurringEventId).Do()
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance, series, nil
+}
+
+// deleteGcalSeries deletes every event of the series, or ends the series before the instance so it and the following events are removed
+func deleteGcalSeries(calendarService *calendar.Service, calendarID string, instance *calendar.Event, series *calendar.Event, scope string) error {
+	seriesStart, originalStart, err := getGcalSeriesStarts(instance, series)
+	if err != nil {
+		return err
+	}
+	if scope == EventScopeAll || !originalStart.After(seriesStart) {
+		return calendarService.Events.Delete(calendarID, series.Id).Do()
+	}
+	previousRecurrence, _, err := splitGcalRecurrence(series.Recurrence, seriesStart, originalStart)
+	if err != nil {
+		return err
+	}
+	_, err = calendarService.Events.Patch(calendarID, series.Id, &calendar.Event{Recurrence: previousRecurrence}).Do()
+	return err
+}
+
+// resetGcalSyncToken makes the next sync of the calendar a full one, as incremental syncs don't always
+// list every instance a change to a series moved or removed
+func resetGcalSyncToken(db *mongo.Database, userID primitive.ObjectID, accountID string, calendarID string) {
+	_, err := database.GetCalendarAccountCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"id_external": accountID},
+			{"source_id": TASK_SOURCE_ID_GCAL},
+			{"calendars.calendar_id": calendarID},
+		}},
+		bson.M{"$unset": bson.M{"calendars.$.sync_token": ""}},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reset google calendar sync token")
+	}
+}
+
+// getGcalSeriesStarts returns the start of the series and the start the instance had in the series, both in the series' time zone
+func getGcalSeriesStarts(instance *calendar.Event, series *calendar.Event) (time.Time, time.Time, error) {
+	if series.Start == nil || series.Start.DateTime == "" || instance.OriginalStartTime.DateTime == "" {
+		return time.Time{}, time.Time{}, errors.New("all day recurring events are not supported")
+	}
+	seriesStart, err := time.Parse(time.RFC3339, series.Start.DateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	originalStart, err := time.Parse(time.RFC3339, instance.OriginalStartTime.DateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	// occurrences are expanded in the series' time zone, so they keep the same local time across daylight saving changes
+	location, err := time.LoadLocation(series.Start.TimeZone)
+	if err == nil && series.Start.TimeZone != "" {
+		seriesStart = seriesStart.In(location)
+		originalStart = originalStart.In(location)
+	}
+	return seriesStart, originalStart, nil
+}
+
+// splitGcalRecurrence splits the recurrence of a series at an occurrence, returning the recurrence which ends
+// before it and the recurrence of a new series starting at it. COUNT is shared out between the two.
+func splitGcalRecurrence(recurrence []string, seriesStart time.Time, splitStart time.Time) ([]string, []string, error) {
+	previousRecurrence := []string{}
+	followingRecurrence := []string{}
+	for _, line := range recurrence {
+		if !strings.HasPrefix(line, ICalPropertyRecurrence+":") {
+			previousRecurrence = append(previousRecurrence, line)
+			followingRecurrence = append(followingRecurrence, line)
+			continue
+		}
+		rule, err := ParseRRule(line)
+		if err != nil {
+			return nil, nil, err
+		}
+		followingRule := *rule
+		if rule.Count > 0 {
+			followingRule.Count = rule.Count - len(rule.Between(seriesStart, seriesStart, splitStart, nil))
+			if followingRule.Count < 1 {
+				followingRule.Count = 1
+			}
+		}
+		previousRule := *rule
+		until := splitStart.Add(-time.Second)
+		previousRule.Count = 0
+		previousRule.Until = &until
+		previousRecurrence = append(previousRecurrence, ICalPropertyRecurrence+":"+previousRule.String())
+		followingRecurrence = append(followingRecurrence, ICalPropertyRecurrence+":"+followingRule.String())
+	}
+	return previousRecurrence, followingRecurrence, nil
+}
+
 // returns true if the error was because of a bad token
 func CheckAndHandleBadToken(err error, db *mongo.Database, userID primitive.ObjectID, accountID string, serviceID string) bool {
 	if !strings.Contains(err.Error(), "oauth2: token expired and refresh token is not set") &&
@@ -411,6 +1027,20 @@ func (googleCalendar GoogleCalendarSource) ModifyEvent(db *mongo.Database, userI
 	if updateFields.CalendarID != "" {
 		calendarID = updateFields.CalendarID
 	}
+	if !IsSingleEventScope(updateFields.Scope) {
+		instance, series, err := getGcalSeries(calendarService, calendarID, eventID)
+		if err != nil {
+			return err
+		}
+		if series != nil {
+			err = modifyGcalSeries(calendarService, calendarID, instance, series, &gcalEvent, updateFields.Scope)
+			if err != nil {
+				return err
+			}
+			resetGcalSyncToken(db, userID, accountID, calendarID)
+			return nil
+		}
+	}
 	_, err = calendarService.Events.Patch(calendarID, eventID, &gcalEvent).Do()
 	if err != nil {
 		return err
@@ -418,6 +1048,97 @@ func (googleCalendar GoogleCalendarSource) ModifyEvent(db *mongo.Database, userI
 	return nil
 }
 
+// modifyGcalSeries applies the changes made to an instance to every event of the series, or splits the series
+// at the instance so a new series with the changes takes over from it
+func modifyGcalSeries(calendarService *calendar.Service, calendarID string, instance *calendar.Event, series *calendar.Event, gcalEvent *calendar.Event, scope string) error {
+	seriesStart, originalStart, err := getGcalSeriesStarts(instance, series)
+	if err != nil {
+		return err
+	}
+	seriesEnd, err := time.Parse(time.RFC3339, series.End.DateTime)
+	if err != nil {
+		return err
+	}
+	if scope == EventScopeAll || !originalStart.After(seriesStart) {
+		seriesPatch := *gcalEvent
+		seriesPatch.Start, seriesPatch.End, err = shiftGcalSeriesTimes(seriesStart, seriesEnd, instance, gcalEvent, series)
+		if err != nil {
+			return err
+		}
+		_, err = calendarService.Events.Patch(calendarID, series.Id, &seriesPatch).Do()
+		return err
+	}
+
+	previousRecurrence, followingRecurrence, err := splitGcalRecurrence(series.Recurrence, seriesStart, originalStart)
+	if err != nil {
+		return err
+	}
+	followingSeries := &calendar.Event{
+		Summary:        series.Summary,
+		Location:       series.Location,
+		Description:    series.Description,
+		Attendees:      series.Attendees,
+		ColorId:        series.ColorId,
+		ConferenceData: series.ConferenceData,
+		Reminders:      series.Reminders,
+		Visibility:     series.Visibility,
+		Recurrence:     followingRecurrence,
+	}
+	if gcalEvent.Summary != "" {
+		followingSeries.Summary = gcalEvent.Summary
+	}
+	if gcalEvent.Location != "" {
+		followingSeries.Location = gcalEvent.Location
+	}
+	if gcalEvent.Description != "" {
+		followingSeries.Description = gcalEvent.Description
+	}
+	if gcalEvent.Attendees != nil {
+		followingSeries.Attendees = gcalEvent.Attendees
+	}
+	followingSeries.Start, followingSeries.End, err = shiftGcalSeriesTimes(originalStart, originalStart.Add(seriesEnd.Sub(seriesStart)), instance, gcalEvent, series)
+	if err != nil {
+		return err
+	}
+	_, err = calendarService.Events.Patch(calendarID, series.Id, &calendar.Event{Recurrence: previousRecurrence}).Do()
+	if err != nil {
+		return err
+	}
+	_, err = calendarService.Events.Insert(calendarID, followingSeries).ConferenceDataVersion(1).Do()
+	return err
+}
+
+// shiftGcalSeriesTimes moves the start and end of a series by however much the change moves the instance,
+// keeping the series' time zone so occurrences don't drift across daylight saving changes
+func shiftGcalSeriesTimes(start time.Time, end time.Time, instance *calendar.Event, gcalEvent *calendar.Event, series *calendar.Event) (*calendar.EventDateTime, *calendar.EventDateTime, error) {
+	shiftedStart, err := shiftGcalTime(start, instance.Start, gcalEvent.Start)
+	if err != nil {
+		return nil, nil, err
+	}
+	shiftedEnd, err := shiftGcalTime(end, instance.End, gcalEvent.End)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &calendar.EventDateTime{DateTime: shiftedStart.Format(time.RFC3339), TimeZone: series.Start.TimeZone},
+		&calendar.EventDateTime{DateTime: shiftedEnd.Format(time.RFC3339), TimeZone: series.End.TimeZone},
+		nil
+}
+
+func shiftGcalTime(value time.Time, previous *calendar.EventDateTime, updated *calendar.EventDateTime) (time.Time, error) {
+	if updated == nil || previous == nil {
+		return value, nil
+	}
+	previousTime, err := time.Parse(time.RFC3339, previous.DateTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	updatedTime, err := time.Parse(time.RFC3339, updated.DateTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return value.Add(updatedTime.Sub(previousTime)), nil
+}
+
 func createConferenceCallRequest() *calendar.ConferenceData {
 	// todo - add client generated requestId
 	return &calendar.ConferenceData{
@@ -469,5 +1190,14 @@ func updateUserTimezone(calendarService *calendar.Service, db *mongo.Database, u
 		return err
 	}
 	_, err = database.GetExternalTokenCollection(db).UpdateByID(context.Background(), token.ID, bson.M{"$set": bson.M{"timezone": setting.Value}})
+	if err != nil {
+		return err
+	}
+	// the primary account decides the user's timezone, other accounts only fill it in if it's missing
+	userFilter := bson.M{"_id": userID}
+	if !token.IsPrimaryLogin {
+		userFilter = bson.M{"$and": []bson.M{{"_id": userID}, {"timezone": bson.M{"$in": []interface{}{nil, ""}}}}}
+	}
+	_, err = database.GetUserCollection(db).UpdateOne(context.Background(), userFilter, bson.M{"$set": bson.M{"timezone": setting.Value}})
 	return err
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/jjPlusPlus/task-manager/backend/testutils"
 
+	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,6 +25,9 @@ func TestGetEvents(t *testing.T) {
 	db, dbCleanup, err := database.GetDBConnection()
 	assert.NoError(t, err)
 	defer dbCleanup()
+	// only events in the requested window are returned, so requests cover the day of the mock events
+	windowStart, _ := time.Parse(time.RFC3339, "2021-03-06T00:00:00-05:00")
+	windowEnd := windowStart.AddDate(0, 0, 1)
 	t.Run("Success", func(t *testing.T) {
 		accountID := "exampleAccountID"
 		userID := primitive.NewObjectID()
@@ -94,7 +98,7 @@ func TestGetEvents(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
 			},
 		}
-		go googleCalendar.GetEvents(db, userID, accountID, time.Now(), time.Now(), nil, calendarResult)
+		go googleCalendar.GetEvents(db, userID, accountID, windowStart, windowEnd, nil, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 2, len(result.CalendarEvents))
@@ -192,7 +196,7 @@ func TestGetEvents(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
 			},
 		}
-		go googleCalendar.GetEvents(db, userID, "exampleAccountID", time.Now(), time.Now(), nil, calendarResult)
+		go googleCalendar.GetEvents(db, userID, "exampleAccountID", windowStart, windowEnd, nil, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 1, len(result.CalendarEvents))
@@ -258,7 +262,7 @@ func TestGetEvents(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
 			},
 		}
-		go googleCalendar.GetEvents(db, userID, "exampleAccountID", time.Now(), time.Now(), nil, calendarResult)
+		go googleCalendar.GetEvents(db, userID, "exampleAccountID", windowStart, windowEnd, nil, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 1, len(result.CalendarEvents))
@@ -290,7 +294,7 @@ func TestGetEvents(t *testing.T) {
 		}
 		defer server.Close()
 		var calendarResult = make(chan CalendarResult)
-		go googleCalendar.GetEvents(db, primitive.NewObjectID(), "exampleAccountID", time.Now(), time.Now(), nil, calendarResult)
+		go googleCalendar.GetEvents(db, primitive.NewObjectID(), "exampleAccountID", windowStart, windowEnd, nil, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 0, len(result.CalendarEvents))
@@ -359,7 +363,7 @@ func TestGetEvents(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
 			},
 		}
-		go googleCalendar.GetEvents(db, userID, "exampleAccountID", time.Now(), time.Now(), nil, calendarResult)
+		go googleCalendar.GetEvents(db, userID, "exampleAccountID", windowStart, windowEnd, nil, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 1, len(result.CalendarEvents))
@@ -431,7 +435,7 @@ func TestGetEvents(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
 			},
 		}
-		go googleCalendar.GetEvents(db, userID, "exampleAccountID", time.Now(), time.Now(), []string{"https://www.googleapis.com/auth/calendar"}, calendarResult)
+		go googleCalendar.GetEvents(db, userID, "exampleAccountID", windowStart, windowEnd, []string{"https://www.googleapis.com/auth/calendar"}, calendarResult)
 		result := <-calendarResult
 		assert.NoError(t, result.Error)
 		assert.Equal(t, 2, len(result.CalendarEvents)) // the event exists in both calendars
@@ -481,6 +485,463 @@ func TestGetEvents(t *testing.T) {
 	})
 }
 
+func TestGetEventsIncrementalSync(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	accountID := "incrementalAccountID"
+	userID := primitive.NewObjectID()
+	_, err = database.GetExternalTokenCollection(db).InsertOne(context.Background(), database.ExternalAPIToken{
+		UserID:    userID,
+		AccountID: accountID,
+		ServiceID: TASK_SERVICE_ID_GOOGLE,
+	})
+	assert.NoError(t, err)
+	windowStart, _ := time.Parse(time.RFC3339, "2021-03-06T00:00:00-05:00")
+	windowEnd := windowStart.AddDate(0, 0, 1)
+
+	keptEvent := &calendar.Event{
+		Id:      "kept_event",
+		Summary: "Kept Event",
+		Start:   &calendar.EventDateTime{DateTime: "2021-03-06T09:00:00-05:00"},
+		End:     &calendar.EventDateTime{DateTime: "2021-03-06T09:30:00-05:00"},
+	}
+	cancelledEvent := &calendar.Event{
+		Id:      "cancelled_event",
+		Summary: "Cancelled Event",
+		Start:   &calendar.EventDateTime{DateTime: "2021-03-06T15:00:00-05:00"},
+		End:     &calendar.EventDateTime{DateTime: "2021-03-06T15:30:00-05:00"},
+	}
+	movedEvent := &calendar.Event{
+		Id:      "moved_event",
+		Summary: "Moved Event",
+		Start:   &calendar.EventDateTime{DateTime: "2021-03-06T11:00:00-05:00"},
+		End:     &calendar.EventDateTime{DateTime: "2021-03-06T11:30:00-05:00"},
+	}
+	requests := []string{}
+	server := getGcalSyncServer(t, &requests, map[string]*calendar.Events{
+		"": {Items: []*calendar.Event{keptEvent, cancelledEvent, movedEvent}, NextSyncToken: "first_token"},
+		"first_token": {Items: []*calendar.Event{
+			{Id: "cancelled_event", Status: "cancelled"},
+			{
+				Id:      "moved_event",
+				Summary: "Moved Event",
+				Start:   &calendar.EventDateTime{DateTime: "2021-03-06T13:00:00-05:00"},
+				End:     &calendar.EventDateTime{DateTime: "2021-03-06T13:30:00-05:00"},
+			},
+		}, NextSyncToken: "second_token"},
+	})
+	defer server.Close()
+	googleCalendar := GoogleCalendarSource{
+		Google: GoogleService{
+			OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL},
+		},
+	}
+	getEvents := func() CalendarResult {
+		calendarResult := make(chan CalendarResult)
+		go googleCalendar.GetEvents(db, userID, accountID, windowStart, windowEnd, nil, calendarResult)
+		return <-calendarResult
+	}
+
+	t.Run("FullSync", func(t *testing.T) {
+		result := getEvents()
+		assert.NoError(t, result.Error)
+		assert.Equal(t, 3, len(result.CalendarEvents))
+		assert.Contains(t, requests, "/colors")
+
+		calendarAccount, err := database.GetCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL)
+		assert.NoError(t, err)
+		assert.Equal(t, "first_token", calendarAccount.Calendars[0].SyncToken)
+		assert.Equal(t, windowStart.Add(-gcalSyncWindowPadding), calendarAccount.Calendars[0].SyncedFrom.Time())
+		assert.Equal(t, windowEnd.Add(gcalSyncWindowPadding), calendarAccount.Calendars[0].SyncedUntil.Time())
+	})
+	t.Run("IncrementalSync", func(t *testing.T) {
+		cancelledDBEvent, err := database.GetCalendarEventByExternalId(db, "cancelled_event", userID)
+		assert.NoError(t, err)
+		isCompleted := false
+		taskResult, err := database.GetTaskCollection(db).InsertOne(context.Background(), database.Task{
+			UserID:                   userID,
+			IsCompleted:              &isCompleted,
+			SourceID:                 TASK_SOURCE_ID_GCAL,
+			IsMeetingPreparationTask: true,
+			MeetingPreparationParams: &database.MeetingPreparationParams{
+				CalendarEventID: cancelledDBEvent.ID,
+				IDExternal:      "cancelled_event",
+			},
+		})
+		assert.NoError(t, err)
+		requests = []string{}
+
+		result := getEvents()
+		assert.NoError(t, result.Error)
+		// the calendar list and colors are cached
+		assert.Equal(t, []string{"/calendars/incrementalAccountID/events"}, requests)
+		assert.Equal(t, 2, len(result.CalendarEvents))
+		idToEvent := map[string]*database.CalendarEvent{}
+		for _, event := range result.CalendarEvents {
+			idToEvent[event.IDExternal] = event
+		}
+		assert.Equal(t, "Kept Event", idToEvent["kept_event"].Title)
+		movedStart, _ := time.Parse(time.RFC3339, "2021-03-06T13:00:00-05:00")
+		assert.Equal(t, movedStart.UTC(), idToEvent["moved_event"].DatetimeStart.Time().UTC())
+
+		_, err = database.GetCalendarEventByExternalId(db, "cancelled_event", userID)
+		assert.Error(t, err)
+		var task database.Task
+		err = database.GetTaskCollection(db).FindOne(context.Background(), bson.M{"_id": taskResult.InsertedID}).Decode(&task)
+		assert.NoError(t, err)
+		assert.True(t, task.MeetingPreparationParams.EventMovedOrDeleted)
+
+		calendarAccount, err := database.GetCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL)
+		assert.NoError(t, err)
+		assert.Equal(t, "second_token", calendarAccount.Calendars[0].SyncToken)
+	})
+	t.Run("ExpiredSyncToken", func(t *testing.T) {
+		requests = []string{}
+		result := getEvents()
+		assert.NoError(t, result.Error)
+		// the expired token is followed by a full sync
+		assert.Equal(t, []string{"/calendars/incrementalAccountID/events", "/calendars/incrementalAccountID/events"}, requests)
+		assert.Equal(t, 3, len(result.CalendarEvents))
+
+		calendarAccount, err := database.GetCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_GCAL)
+		assert.NoError(t, err)
+		assert.Equal(t, "first_token", calendarAccount.Calendars[0].SyncToken)
+	})
+}
+
+func TestListGcalEvents(t *testing.T) {
+	requests := []string{}
+	server := getGcalSyncServer(t, &requests, map[string]*calendar.Events{
+		"":      {Items: []*calendar.Event{{Id: "first_page"}}, NextPageToken: "second_page"},
+		"token": {Items: []*calendar.Event{{Id: "changed", Status: "cancelled"}}, NextSyncToken: "next_token"},
+	})
+	defer server.Close()
+	calendarService, err := createGcalService(&server.URL, primitive.NewObjectID(), "accountID", context.Background(), nil)
+	assert.NoError(t, err)
+	startTime := time.Date(2021, time.March, 6, 0, 0, 0, 0, time.UTC)
+
+	t.Run("FullSync", func(t *testing.T) {
+		events, syncToken, err := listGcalEvents(calendarService, "primary", "", startTime, startTime.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "full_sync_token", syncToken)
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, "first_page", events[0].Id)
+		assert.Equal(t, "second_page", events[1].Id)
+	})
+	t.Run("IncrementalSync", func(t *testing.T) {
+		events, syncToken, err := listGcalEvents(calendarService, "primary", "token", startTime, startTime.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "next_token", syncToken)
+		assert.Equal(t, 1, len(events))
+		assert.True(t, isRemovedGcalEvent(events[0]))
+	})
+	t.Run("ExpiredSyncToken", func(t *testing.T) {
+		_, _, err := listGcalEvents(calendarService, "primary", "expired", startTime, startTime.AddDate(0, 0, 1))
+		assert.True(t, isGcalSyncTokenExpired(err))
+	})
+}
+
+func TestSaveGcalCalendarAccount(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	stoppedChannelIDs := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var channel calendar.Channel
+		err := json.NewDecoder(r.Body).Decode(&channel)
+		assert.NoError(t, err)
+		stoppedChannelIDs = append(stoppedChannelIDs, channel.Id)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	userID := primitive.NewObjectID()
+	calendarService, err := createGcalService(&server.URL, userID, "accountID", context.Background(), nil)
+	assert.NoError(t, err)
+	calendarAccount := database.CalendarAccount{UserID: userID, IDExternal: "accountID", SourceID: TASK_SOURCE_ID_GCAL}
+
+	calendarAccount.Calendars = []database.Calendar{
+		{CalendarID: "primary", WatchChannelID: "primary_channel"},
+		{CalendarID: "removed", WatchChannelID: "removed_channel"},
+		{CalendarID: "unwatched"},
+	}
+	err = saveGcalCalendarAccount(db, calendarService, calendarAccount)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, stoppedChannelIDs)
+
+	// the primary calendar's channel was replaced by a concurrent sync, and the other calendar was removed from the list
+	calendarAccount.Calendars = []database.Calendar{
+		{CalendarID: "primary", WatchChannelID: "renewed_channel"},
+		{CalendarID: "unwatched"},
+	}
+	err = saveGcalCalendarAccount(db, calendarService, calendarAccount)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"primary_channel", "removed_channel"}, stoppedChannelIDs)
+
+	calendarAccounts, err := database.GetCalendarAccounts(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*calendarAccounts))
+	assert.Equal(t, calendarAccount.Calendars, (*calendarAccounts)[0].Calendars)
+}
+
+func TestIsRemovedGcalEvent(t *testing.T) {
+	start := &calendar.EventDateTime{DateTime: "2021-03-06T15:00:00-05:00"}
+	assert.False(t, isRemovedGcalEvent(&calendar.Event{Status: "confirmed", Start: start}))
+	assert.True(t, isRemovedGcalEvent(&calendar.Event{Status: "cancelled"}))
+	assert.True(t, isRemovedGcalEvent(&calendar.Event{Start: &calendar.EventDateTime{Date: "2021-03-06"}}))
+	assert.True(t, isRemovedGcalEvent(&calendar.Event{Start: start, Attendees: []*calendar.EventAttendee{{Self: true, ResponseStatus: "declined"}}}))
+	assert.False(t, isRemovedGcalEvent(&calendar.Event{Start: start, Attendees: []*calendar.EventAttendee{{Self: false, ResponseStatus: "declined"}}}))
+}
+
+func TestUpdateGcalSyncedRange(t *testing.T) {
+	day := func(day int) time.Time {
+		return time.Date(2021, time.March, day, 0, 0, 0, 0, time.UTC)
+	}
+	syncedCalendar := func() database.Calendar {
+		return database.Calendar{SyncToken: "token", SyncedFrom: primitive.NewDateTimeFromTime(day(10)), SyncedUntil: primitive.NewDateTimeFromTime(day(20))}
+	}
+
+	t.Run("Overlapping", func(t *testing.T) {
+		gcalCalendar := syncedCalendar()
+		updateGcalSyncedRange(&gcalCalendar, true, day(15), day(25))
+		assert.Equal(t, day(10), gcalCalendar.SyncedFrom.Time().UTC())
+		assert.Equal(t, day(25), gcalCalendar.SyncedUntil.Time().UTC())
+		assert.True(t, isGcalCalendarSynced(gcalCalendar, day(12), day(24)))
+	})
+	t.Run("Disjoint", func(t *testing.T) {
+		gcalCalendar := syncedCalendar()
+		updateGcalSyncedRange(&gcalCalendar, true, day(22), day(25))
+		assert.Equal(t, day(22), gcalCalendar.SyncedFrom.Time().UTC())
+		assert.Equal(t, day(25), gcalCalendar.SyncedUntil.Time().UTC())
+	})
+	t.Run("ExpiredSyncToken", func(t *testing.T) {
+		gcalCalendar := syncedCalendar()
+		updateGcalSyncedRange(&gcalCalendar, false, day(15), day(25))
+		assert.Equal(t, day(15), gcalCalendar.SyncedFrom.Time().UTC())
+		assert.False(t, isGcalCalendarSynced(gcalCalendar, day(12), day(24)))
+	})
+	t.Run("NoSyncToken", func(t *testing.T) {
+		gcalCalendar := syncedCalendar()
+		gcalCalendar.SyncToken = ""
+		assert.False(t, isGcalCalendarSynced(gcalCalendar, day(12), day(14)))
+	})
+}
+
+func TestShouldRefreshGcalCalendars(t *testing.T) {
+	timeNow := time.Now()
+	multiCalendarScopes := []string{"https://www.googleapis.com/auth/calendar"}
+	calendarAccount := &database.CalendarAccount{
+		Calendars:            []database.Calendar{{CalendarID: "primary"}},
+		Scopes:               multiCalendarScopes,
+		CalendarsRefreshedAt: primitive.NewDateTimeFromTime(timeNow.Add(-time.Minute)),
+	}
+	assert.True(t, shouldRefreshGcalCalendars(nil, multiCalendarScopes, timeNow))
+	assert.False(t, shouldRefreshGcalCalendars(calendarAccount, multiCalendarScopes, timeNow))
+	assert.True(t, shouldRefreshGcalCalendars(calendarAccount, nil, timeNow))
+	assert.True(t, shouldRefreshGcalCalendars(calendarAccount, multiCalendarScopes, timeNow.Add(gcalCalendarListRefreshInterval)))
+}
+
+func TestSplitGcalRecurrence(t *testing.T) {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	seriesStart := time.Date(2023, time.March, 6, 9, 0, 0, 0, location)
+	// after the change to daylight saving time, so the UTC time of day differs from the series start
+	splitStart := time.Date(2023, time.March, 20, 9, 0, 0, 0, location)
+
+	t.Run("Count", func(t *testing.T) {
+		previous, following, err := splitGcalRecurrence([]string{"RRULE:FREQ=WEEKLY;COUNT=5", "EXDATE;TZID=America/Los_Angeles:20230327T090000"}, seriesStart, splitStart)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"RRULE:FREQ=WEEKLY;UNTIL=20230320T155959Z", "EXDATE;TZID=America/Los_Angeles:20230327T090000"}, previous)
+		assert.Equal(t, []string{"RRULE:FREQ=WEEKLY;COUNT=3", "EXDATE;TZID=America/Los_Angeles:20230327T090000"}, following)
+	})
+	t.Run("Until", func(t *testing.T) {
+		previous, following, err := splitGcalRecurrence([]string{"RRULE:FREQ=DAILY;UNTIL=20230401T000000Z;BYDAY=MO,WE"}, seriesStart, splitStart)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"RRULE:FREQ=DAILY;UNTIL=20230320T155959Z;BYDAY=MO,WE"}, previous)
+		assert.Equal(t, []string{"RRULE:FREQ=DAILY;UNTIL=20230401T000000Z;BYDAY=MO,WE"}, following)
+	})
+	t.Run("InvalidRule", func(t *testing.T) {
+		_, _, err := splitGcalRecurrence([]string{"RRULE:FREQ=HOURLY"}, seriesStart, splitStart)
+		assert.Error(t, err)
+	})
+}
+
+func TestModifyGcalSeries(t *testing.T) {
+	series := &calendar.Event{
+		Id:         "series",
+		Summary:    "Standup",
+		Start:      &calendar.EventDateTime{DateTime: "2023-03-06T09:00:00-08:00", TimeZone: "America/Los_Angeles"},
+		End:        &calendar.EventDateTime{DateTime: "2023-03-06T09:30:00-08:00", TimeZone: "America/Los_Angeles"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;COUNT=5"},
+	}
+	getInstance := func(originalStart string, originalEnd string) *calendar.Event {
+		return &calendar.Event{
+			Id:                "series_instance",
+			RecurringEventId:  "series",
+			OriginalStartTime: &calendar.EventDateTime{DateTime: originalStart, TimeZone: "America/Los_Angeles"},
+			Start:             &calendar.EventDateTime{DateTime: originalStart},
+			End:               &calendar.EventDateTime{DateTime: originalEnd},
+		}
+	}
+	// moves the instance an hour later and renames it
+	gcalEvent := &calendar.Event{
+		Summary: "Planning",
+		Start:   &calendar.EventDateTime{DateTime: "2023-03-20T10:00:00-07:00"},
+		End:     &calendar.EventDateTime{DateTime: "2023-03-20T10:30:00-07:00"},
+	}
+
+	t.Run("All", func(t *testing.T) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		instance := getInstance("2023-03-20T09:00:00-07:00", "2023-03-20T09:30:00-07:00")
+		server := getGcalSeriesServer(t, &requests, &bodies, []*calendar.Event{series, instance})
+		defer server.Close()
+		calendarService, err := createGcalService(&server.URL, primitive.NewObjectID(), "accountID", context.Background(), nil)
+		assert.NoError(t, err)
+
+		fetchedInstance, fetchedSeries, err := getGcalSeries(calendarService, "cal", "series_instance")
+		assert.NoError(t, err)
+		assert.Equal(t, "series", fetchedSeries.Id)
+		err = modifyGcalSeries(calendarService, "cal", fetchedInstance, fetchedSeries, gcalEvent, EventScopeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GET /calendars/cal/events/series_instance", "GET /calendars/cal/events/series", "PATCH /calendars/cal/events/series"}, requests)
+		assert.Equal(t, "Planning", bodies[0].Summary)
+		assert.Equal(t, &calendar.EventDateTime{DateTime: "2023-03-06T10:00:00-08:00", TimeZone: "America/Los_Angeles"}, bodies[0].Start)
+		assert.Equal(t, &calendar.EventDateTime{DateTime: "2023-03-06T10:30:00-08:00", TimeZone: "America/Los_Angeles"}, bodies[0].End)
+	})
+	t.Run("ThisAndFollowing", func(t *testing.T) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		server := getGcalSeriesServer(t, &requests, &bodies, nil)
+		defer server.Close()
+		calendarService, err := createGcalService(&server.URL, primitive.NewObjectID(), "accountID", context.Background(), nil)
+		assert.NoError(t, err)
+
+		instance := getInstance("2023-03-20T09:00:00-07:00", "2023-03-20T09:30:00-07:00")
+		err = modifyGcalSeries(calendarService, "cal", instance, series, gcalEvent, EventScopeThisAndFollowing)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"PATCH /calendars/cal/events/series", "POST /calendars/cal/events"}, requests)
+		assert.Equal(t, []string{"RRULE:FREQ=WEEKLY;UNTIL=20230320T155959Z"}, bodies[0].Recurrence)
+		assert.Equal(t, "Planning", bodies[1].Summary)
+		assert.Equal(t, []string{"RRULE:FREQ=WEEKLY;COUNT=3"}, bodies[1].Recurrence)
+		assert.Equal(t, &calendar.EventDateTime{DateTime: "2023-03-20T10:00:00-07:00", TimeZone: "America/Los_Angeles"}, bodies[1].Start)
+		assert.Equal(t, &calendar.EventDateTime{DateTime: "2023-03-20T10:30:00-07:00", TimeZone: "America/Los_Angeles"}, bodies[1].End)
+	})
+	t.Run("ThisAndFollowingFromFirstInstance", func(t *testing.T) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		server := getGcalSeriesServer(t, &requests, &bodies, nil)
+		defer server.Close()
+		calendarService, err := createGcalService(&server.URL, primitive.NewObjectID(), "accountID", context.Background(), nil)
+		assert.NoError(t, err)
+
+		instance := getInstance("2023-03-06T09:00:00-08:00", "2023-03-06T09:30:00-08:00")
+		err = modifyGcalSeries(calendarService, "cal", instance, series, &calendar.Event{Summary: "Planning"}, EventScopeThisAndFollowing)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"PATCH /calendars/cal/events/series"}, requests)
+		assert.Equal(t, "2023-03-06T09:00:00-08:00", bodies[0].Start.DateTime)
+	})
+}
+
+func TestDeleteGcalSeries(t *testing.T) {
+	series := &calendar.Event{
+		Id:         "series",
+		Start:      &calendar.EventDateTime{DateTime: "2023-03-06T09:00:00-08:00", TimeZone: "America/Los_Angeles"},
+		End:        &calendar.EventDateTime{DateTime: "2023-03-06T09:30:00-08:00", TimeZone: "America/Los_Angeles"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;COUNT=5"},
+	}
+	instance := &calendar.Event{
+		Id:                "series_instance",
+		RecurringEventId:  "series",
+		OriginalStartTime: &calendar.EventDateTime{DateTime: "2023-03-20T09:00:00-07:00"},
+	}
+	deleteSeries := func(scope string) ([]string, []*calendar.Event) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		server := getGcalSeriesServer(t, &requests, &bodies, nil)
+		defer server.Close()
+		calendarService, err := createGcalService(&server.URL, primitive.NewObjectID(), "accountID", context.Background(), nil)
+		assert.NoError(t, err)
+		err = deleteGcalSeries(calendarService, "cal", instance, series, scope)
+		assert.NoError(t, err)
+		return requests, bodies
+	}
+
+	t.Run("All", func(t *testing.T) {
+		requests, _ := deleteSeries(EventScopeAll)
+		assert.Equal(t, []string{"DELETE /calendars/cal/events/series"}, requests)
+	})
+	t.Run("ThisAndFollowing", func(t *testing.T) {
+		requests, bodies := deleteSeries(EventScopeThisAndFollowing)
+		assert.Equal(t, []string{"PATCH /calendars/cal/events/series"}, requests)
+		assert.Equal(t, []string{"RRULE:FREQ=WEEKLY;UNTIL=20230320T155959Z"}, bodies[0].Recurrence)
+	})
+}
+
+func TestGetFreeBusy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/freeBusy", r.URL.Path)
+		request := calendar.FreeBusyRequest{}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		assert.NoError(t, err)
+		assert.Equal(t, "2023-03-06T09:00:00Z", request.TimeMin)
+		assert.Equal(t, 3, len(request.Items))
+		json.NewEncoder(w).Encode(&calendar.FreeBusyResponse{Calendars: map[string]calendar.FreeBusyCalendar{
+			"user@example.com":     {Busy: []*calendar.TimePeriod{{Start: "2023-03-06T10:00:00Z", End: "2023-03-06T11:00:00Z"}}},
+			"teammate@example.com": {Busy: []*calendar.TimePeriod{}},
+			"outsider@example.com": {Errors: []*calendar.Error{{Domain: "global", Reason: "notFound"}}},
+		}})
+	}))
+	defer server.Close()
+	googleCalendar := GoogleCalendarSource{Google: GoogleService{OverrideURLs: GoogleURLOverrides{CalendarFetchURL: &server.URL}}}
+	startTime := time.Date(2023, time.March, 6, 9, 0, 0, 0, time.UTC)
+
+	calendarIDToBusy, unavailableCalendarIDs, err := googleCalendar.GetFreeBusy(nil, primitive.NewObjectID(), "user@example.com", []string{"user@example.com", "teammate@example.com", "outsider@example.com"}, startTime, startTime.Add(8*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]CalendarBusyRange{
+		"user@example.com":     {{DatetimeStart: startTime.Add(time.Hour), DatetimeEnd: startTime.Add(2 * time.Hour)}},
+		"teammate@example.com": {},
+	}, calendarIDToBusy)
+	assert.Equal(t, []string{"outsider@example.com"}, unavailableCalendarIDs)
+}
+
+func TestRespondToEvent(t *testing.T) {
+	invitation := &calendar.Event{
+		Id: "invitation",
+		Attendees: []*calendar.EventAttendee{
+			{Email: "organizer@example.com", Organizer: true, ResponseStatus: constants.EventResponseAccepted},
+			{Email: "user@example.com", Self: true, ResponseStatus: constants.EventResponseNeedsAction},
+		},
+	}
+	ownEvent := &calendar.Event{Id: "own_event"}
+
+	t.Run("Success", func(t *testing.T) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		server := getGcalSeriesServer(t, &requests, &bodies, []*calendar.Event{invitation, ownEvent})
+		defer server.Close()
+		googleCalendar := GoogleCalendarSource{Google: GoogleService{OverrideURLs: GoogleURLOverrides{CalendarModifyURL: &server.URL}}}
+
+		err := googleCalendar.RespondToEvent(nil, primitive.NewObjectID(), "user@example.com", "invitation", "cal", constants.EventResponseTentative)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GET /calendars/cal/events/invitation", "PATCH /calendars/cal/events/invitation"}, requests)
+		assert.Equal(t, 2, len(bodies[0].Attendees))
+		assert.Equal(t, constants.EventResponseAccepted, bodies[0].Attendees[0].ResponseStatus)
+		assert.Equal(t, constants.EventResponseTentative, bodies[0].Attendees[1].ResponseStatus)
+	})
+	t.Run("NotAttendee", func(t *testing.T) {
+		requests := []string{}
+		bodies := []*calendar.Event{}
+		server := getGcalSeriesServer(t, &requests, &bodies, []*calendar.Event{invitation, ownEvent})
+		defer server.Close()
+		googleCalendar := GoogleCalendarSource{Google: GoogleService{OverrideURLs: GoogleURLOverrides{CalendarModifyURL: &server.URL}}}
+
+		err := googleCalendar.RespondToEvent(nil, primitive.NewObjectID(), "user@example.com", "own_event", "cal", constants.EventResponseAccepted)
+		assert.EqualError(t, err, "not an attendee of the event")
+		assert.Equal(t, []string{"GET /calendars/cal/events/own_event"}, requests)
+	})
+}
+
 func TestCreateNewEvent(t *testing.T) {
 	db, dbCleanup, _ := database.GetDBConnection()
 	defer dbCleanup()
@@ -643,7 +1104,7 @@ func TestDeleteEvent(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarDeleteURL: &server.URL},
 			},
 		}
-		err := googleCalendar.DeleteEvent(db, userID, "exampleAccountID", gcalEventID, "")
+		err := googleCalendar.DeleteEvent(db, userID, "exampleAccountID", gcalEventID, "", EventScopeThis)
 		assert.Error(t, err)
 	})
 	t.Run("Success", func(t *testing.T) {
@@ -659,7 +1120,7 @@ func TestDeleteEvent(t *testing.T) {
 				OverrideURLs: GoogleURLOverrides{CalendarDeleteURL: &server.URL},
 			},
 		}
-		err := googleCalendar.DeleteEvent(db, userID, accountID, gcalEventID, "")
+		err := googleCalendar.DeleteEvent(db, userID, accountID, gcalEventID, "", EventScopeThis)
 		assert.NoError(t, err)
 	})
 }
@@ -895,3 +1356,56 @@ func getEventModifyGoogleCalendar(t *testing.T, expectedEvent *calendar.Event, c
 	}
 	return googleCalendar, server
 }
+
+// getGcalSyncServer serves the events response for each sync token, where the empty token is a full sync
+func getGcalSyncServer(t *testing.T, requests *[]string, syncTokenToEvents map[string]*calendar.Events) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+		query := r.URL.Query()
+		switch {
+		case r.URL.Path == "/colors":
+			json.NewEncoder(w).Encode(&calendar.Colors{})
+		case r.URL.Path == "/users/me/settings/timezone":
+			json.NewEncoder(w).Encode(&calendar.Setting{Value: "America/New_York"})
+		case query.Get("pageToken") == "second_page":
+			json.NewEncoder(w).Encode(&calendar.Events{Items: []*calendar.Event{{Id: "second_page"}}, NextSyncToken: "full_sync_token"})
+		default:
+			syncToken := query.Get("syncToken")
+			if syncToken == "" {
+				assert.NotEmpty(t, query.Get("timeMin"))
+			}
+			events, ok := syncTokenToEvents[syncToken]
+			if !ok {
+				w.WriteHeader(http.StatusGone)
+				w.Write([]byte(`{"error": {"code": 410, "message": "Sync token is no longer valid, a full sync is required."}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(events)
+		}
+	}))
+}
+
+// getGcalSeriesServer serves the given events by ID, and records every request along with the events sent in patches and inserts
+func getGcalSeriesServer(t *testing.T, requests *[]string, bodies *[]*calendar.Event, events []*calendar.Event) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case "GET":
+			for _, event := range events {
+				if r.URL.Path == "/calendars/cal/events/"+event.Id {
+					json.NewEncoder(w).Encode(event)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		case "PATCH", "POST":
+			body := &calendar.Event{}
+			err := json.NewDecoder(r.Body).Decode(body)
+			assert.NoError(t, err)
+			*bodies = append(*bodies, body)
+			json.NewEncoder(w).Encode(body)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
@@ -24,6 +24,7 @@ import (
 type GithubConfigValues struct {
 	FetchExternalAPIToken       *bool
 	CompareURL                  *string
+	GetPullRequestURL           *string
 	GetUserURL                  *string
 	ListPullRequestsURL         *string
 	ListPullRequestReviewURL    *string
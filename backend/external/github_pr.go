@@ -92,6 +92,8 @@ type GithubPRRequestData struct {
 	PullRequest *github.PullRequest
 	Token       *oauth2.Token
 	UserTeams   []*github.Team
+	// skips the If-Modified-Since check, as some changes (e.g. check runs) don't update the PR
+	IgnoreCache bool
 }
 
 type GithubUserResult struct {
@@ -259,6 +261,82 @@ func (gitPR GithubPRSource) GetPullRequests(db *mongo.Database, userID primitive
 	}
 }
 
+// RefreshPullRequest refetches a single pull request for a user and upserts it, used when Github notifies us of a change
+func (gitPR GithubPRSource) RefreshPullRequest(db *mongo.Database, userID primitive.ObjectID, accountID string, repository *github.Repository, number int) (*database.PullRequest, error) {
+	if repository == nil || repository.Owner == nil || repository.Owner.Login == nil {
+		return nil, errors.New("repository is nil")
+	}
+	var token *oauth2.Token
+	var githubClient *github.Client
+	extCtx, cancel := context.WithTimeout(context.Background(), constants.ExternalTimeout)
+	defer cancel()
+	if gitPR.Github.Config.ConfigValues.FetchExternalAPIToken != nil && *gitPR.Github.Config.ConfigValues.FetchExternalAPIToken {
+		var err error
+		token, err = GetGithubToken(database.GetExternalTokenCollection(db), userID, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, errors.New("failed to fetch Github API token")
+		}
+		githubClient = getGithubClientFromToken(extCtx, token)
+	} else {
+		githubClient = github.NewClient(nil)
+	}
+
+	userResultChan := make(chan GithubUserResult)
+	go getGithubUser(extCtx, githubClient, CurrentlyAuthedUserFilter, gitPR.Github.Config.ConfigValues.GetUserURL, userResultChan)
+	userResult := <-userResultChan
+	if userResult.Error != nil || userResult.User == nil {
+		return nil, errors.New("failed to fetch Github user")
+	}
+	userTeamsResultChan := make(chan GithubUserTeamsResult)
+	go getUserTeams(extCtx, githubClient, gitPR.Github.Config.ConfigValues.ListUserTeamsURL, userTeamsResultChan)
+	userTeamsResult := <-userTeamsResultChan
+	if userTeamsResult.Error != nil {
+		return nil, userTeamsResult.Error
+	}
+
+	err := setOverrideURL(githubClient, gitPR.Github.Config.ConfigValues.GetPullRequestURL)
+	if err != nil {
+		return nil, err
+	}
+	githubPullRequest, _, err := githubClient.PullRequests.Get(extCtx, repository.Owner.GetLogin(), repository.GetName(), number)
+	if err != nil {
+		return nil, err
+	}
+
+	requestTime := primitive.NewDateTimeFromTime(time.Now())
+	pullRequestChan := make(chan *database.PullRequest)
+	go gitPR.getPullRequestInfo(db, userID, accountID, GithubPRRequestData{
+		Client:      githubClient,
+		User:        userResult.User,
+		Repository:  repository,
+		PullRequest: githubPullRequest,
+		Token:       token,
+		UserTeams:   userTeamsResult.UserTeams,
+		IgnoreCache: true,
+	}, pullRequestChan)
+	pullRequest := <-pullRequestChan
+	if pullRequest == nil {
+		return nil, errors.New("failed to fetch Github PR info")
+	}
+
+	isCompleted := githubPullRequest.GetState() == "closed"
+	pullRequest.IsCompleted = &isCompleted
+	if isCompleted {
+		pullRequest.CompletedAt = primitive.NewDateTimeFromTime(githubPullRequest.GetClosedAt())
+	}
+	pullRequest.LastFetched = requestTime
+	return database.UpdateOrCreatePullRequest(
+		db,
+		userID,
+		pullRequest.IDExternal,
+		pullRequest.SourceID,
+		pullRequest,
+		nil)
+}
+
 func (gitPR GithubPRSource) processRepository(db *mongo.Database, userID primitive.ObjectID, accountID string, repository *github.Repository, githubClient *github.Client, token *oauth2.Token, githubUser *github.User, userTeams []*github.Team, result chan<- ProcessRepositoryResult) {
 	err := updateOrCreateRepository(db, repository, accountID, userID)
 	if err != nil {
@@ -310,10 +388,12 @@ func (gitPR GithubPRSource) getPullRequestInfo(db *mongo.Database, userID primit
 	// do the check
 	extCtx, cancel := context.WithTimeout(context.Background(), constants.ExternalTimeout)
 	defer cancel()
-	hasBeenModified, cachedPR := pullRequestHasBeenModified(db, extCtx, userID, requestData, gitPR.Github.Config.ConfigValues.PullRequestModifiedURL)
-	if !hasBeenModified {
-		result <- cachedPR
-		return
+	if !requestData.IgnoreCache {
+		hasBeenModified, cachedPR := pullRequestHasBeenModified(db, extCtx, userID, requestData, gitPR.Github.Config.ConfigValues.PullRequestModifiedURL)
+		if !hasBeenModified {
+			result <- cachedPR
+			return
+		}
 	}
 
 	err = setOverrideURL(githubClient, gitPR.Github.Config.ConfigValues.ListPullRequestReviewURL)
@@ -829,7 +909,11 @@ func (gitPR GithubPRSource) CreateNewEvent(db *mongo.Database, userID primitive.
 	return errors.New("has not been implemented yet")
 }
 
-func (gitPR GithubPRSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (gitPR GithubPRSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (gitPR GithubPRSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
 	return errors.New("has not been implemented yet")
 }
 
@@ -88,7 +88,11 @@ func (generalTask GeneralTaskTaskSource) CreateNewEvent(db *mongo.Database, user
 	return errors.New("has not been implemented yet")
 }
 
-func (generalTask GeneralTaskTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (generalTask GeneralTaskTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (generalTask GeneralTaskTaskSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
 	return errors.New("has not been implemented yet")
 }
 
@@ -0,0 +1,382 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ICalDateFormat         = "20060102"
+	ICalDateTimeFormat     = "20060102T150405"
+	ICalDateTimeUTCFormat  = "20060102T150405Z"
+	ICalLineLength         = 75
+	ICalComponentCalendar  = "VCALENDAR"
+	ICalComponentTodo      = "VTODO"
+	ICalComponentEvent     = "VEVENT"
+	ICalComponentTimezone  = "VTIMEZONE"
+	ICalParamValue         = "VALUE"
+	ICalParamTimezoneID    = "TZID"
+	ICalValueDate          = "DATE"
+	ICalProductID          = "-//General Task//Task Manager//EN"
+	ICalVersion            = "2.0"
+	ICalBegin              = "BEGIN"
+	ICalEnd                = "END"
+	ICalPropertyProductID  = "PRODID"
+	ICalPropertyVersion    = "VERSION"
+	ICalPropertyUID        = "UID"
+	ICalPropertySummary    = "SUMMARY"
+	ICalPropertyDesc       = "DESCRIPTION"
+	ICalPropertyDue        = "DUE"
+	ICalPropertyPriority   = "PRIORITY"
+	ICalPropertyStatus     = "STATUS"
+	ICalPropertyCompleted  = "COMPLETED"
+	ICalPropertyPercent    = "PERCENT-COMPLETE"
+	ICalPropertyCreated    = "CREATED"
+	ICalPropertyModified   = "LAST-MODIFIED"
+	ICalPropertyTimestamp  = "DTSTAMP"
+	ICalPropertySequence   = "SEQUENCE"
+	ICalPropertyStart      = "DTSTART"
+	ICalPropertyEnd        = "DTEND"
+	ICalPropertyRecurrence = "RRULE"
+	ICalPropertyLocation   = "LOCATION"
+	ICalPropertyURL        = "URL"
+	ICalPropertyDuration   = "DURATION"
+	ICalPropertyRecurID    = "RECURRENCE-ID"
+	ICalPropertyExDate     = "EXDATE"
+	ICalPropertyCalName    = "X-WR-CALNAME"
+	ICalPropertyMethod     = "METHOD"
+	ICalStatusCancelled    = "CANCELLED"
+	ICalMethodPublish      = "PUBLISH"
+)
+
+// ICalProperty is a single content line, e.g. DUE;VALUE=DATE:20230420
+type ICalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// ICalComponent is a BEGIN/END block, e.g. VCALENDAR or VTODO, along with its nested components
+type ICalComponent struct {
+	Name       string
+	Properties []*ICalProperty
+	Components []*ICalComponent
+}
+
+// ParseICal parses an iCalendar document (RFC 5545) into its top level component
+func ParseICal(data string) (*ICalComponent, error) {
+	var root *ICalComponent
+	stack := []*ICalComponent{}
+	for _, line := range unfoldICalLines(data) {
+		property, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch property.Name {
+		case ICalBegin:
+			component := &ICalComponent{Name: strings.ToUpper(property.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root != nil {
+				return nil, errors.New("ical data has more than one top level component")
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case ICalEnd:
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("unexpected end of ical component: %s", property.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, errors.New("ical property outside of a component")
+			}
+			component := stack[len(stack)-1]
+			component.Properties = append(component.Properties, property)
+		}
+	}
+	if root == nil {
+		return nil, errors.New("ical data is empty")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("ical component not closed: %s", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+func unfoldICalLines(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	lines := []string{}
+	for _, line := range strings.Split(data, "\n") {
+		// continuation lines start with a single space or tab
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func parseICalLine(line string) (*ICalProperty, error) {
+	// the value starts at the first colon that isn't inside a quoted parameter value
+	inQuotes := false
+	valueIndex := -1
+	for idx, char := range line {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == ':' && !inQuotes {
+			valueIndex = idx
+			break
+		}
+	}
+	if valueIndex == -1 {
+		return nil, fmt.Errorf("invalid ical line: %s", line)
+	}
+	property := &ICalProperty{Value: line[valueIndex+1:]}
+	nameAndParams := splitICalParams(line[:valueIndex])
+	property.Name = strings.ToUpper(nameAndParams[0])
+	for _, param := range nameAndParams[1:] {
+		paramName, paramValue, found := strings.Cut(param, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid ical parameter: %s", param)
+		}
+		if property.Params == nil {
+			property.Params = map[string]string{}
+		}
+		property.Params[strings.ToUpper(paramName)] = strings.Trim(paramValue, `"`)
+	}
+	return property, nil
+}
+
+func splitICalParams(nameAndParams string) []string {
+	parts := []string{}
+	inQuotes := false
+	start := 0
+	for idx, char := range nameAndParams {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == ';' && !inQuotes {
+			parts = append(parts, nameAndParams[start:idx])
+			start = idx + 1
+		}
+	}
+	return append(parts, nameAndParams[start:])
+}
+
+// Serialize writes the component back out with CRLF line endings and folded lines
+func (component *ICalComponent) Serialize() string {
+	var builder strings.Builder
+	component.serialize(&builder)
+	return builder.String()
+}
+
+func (component *ICalComponent) serialize(builder *strings.Builder) {
+	writeICalLine(builder, ICalBegin+":"+component.Name)
+	for _, property := range component.Properties {
+		writeICalLine(builder, property.String())
+	}
+	for _, child := range component.Components {
+		child.serialize(builder)
+	}
+	writeICalLine(builder, ICalEnd+":"+component.Name)
+}
+
+func writeICalLine(builder *strings.Builder, line string) {
+	// lines longer than 75 octets are folded, without splitting multi-byte characters
+	lineLength := 0
+	for _, char := range line {
+		charLength := len(string(char))
+		if lineLength+charLength > ICalLineLength {
+			builder.WriteString("\r\n ")
+			lineLength = 1
+		}
+		builder.WriteRune(char)
+		lineLength += charLength
+	}
+	builder.WriteString("\r\n")
+}
+
+func (property *ICalProperty) String() string {
+	var builder strings.Builder
+	builder.WriteString(property.Name)
+	paramNames := []string{}
+	for paramName := range property.Params {
+		paramNames = append(paramNames, paramName)
+	}
+	// sorted so that output is stable
+	sort.Strings(paramNames)
+	for _, paramName := range paramNames {
+		paramValue := property.Params[paramName]
+		if strings.ContainsAny(paramValue, ";:,") {
+			paramValue = `"` + paramValue + `"`
+		}
+		builder.WriteString(";" + paramName + "=" + paramValue)
+	}
+	builder.WriteString(":" + property.Value)
+	return builder.String()
+}
+
+func (component *ICalComponent) GetComponents(name string) []*ICalComponent {
+	components := []*ICalComponent{}
+	for _, child := range component.Components {
+		if child.Name == name {
+			components = append(components, child)
+		}
+	}
+	return components
+}
+
+func (component *ICalComponent) GetProperty(name string) *ICalProperty {
+	for _, property := range component.Properties {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+// GetText returns the unescaped value of a text property, or an empty string if it is missing
+func (component *ICalComponent) GetText(name string) string {
+	property := component.GetProperty(name)
+	if property == nil {
+		return ""
+	}
+	return UnescapeICalText(property.Value)
+}
+
+// SetProperty replaces all existing values of the property
+func (component *ICalComponent) SetProperty(name string, value string, params map[string]string) {
+	component.RemoveProperty(name)
+	component.Properties = append(component.Properties, &ICalProperty{Name: name, Value: value, Params: params})
+}
+
+func (component *ICalComponent) SetText(name string, value string) {
+	component.SetProperty(name, EscapeICalText(value), nil)
+}
+
+func (component *ICalComponent) RemoveProperty(name string) {
+	properties := []*ICalProperty{}
+	for _, property := range component.Properties {
+		if property.Name != name {
+			properties = append(properties, property)
+		}
+	}
+	component.Properties = properties
+}
+
+func EscapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+func UnescapeICalText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
+
+// ParseICalTime parses a DATE or DATE-TIME property, returning whether it was an all day date.
+// Floating times without a timezone are interpreted in the given location.
+func ParseICalTime(property *ICalProperty, location *time.Location) (time.Time, bool, error) {
+	if property == nil {
+		return time.Time{}, false, errors.New("ical property is missing")
+	}
+	value := property.Value
+	if property.Params[ICalParamValue] == ICalValueDate || len(value) == len(ICalDateFormat) {
+		parsedDate, err := time.Parse(ICalDateFormat, value)
+		return parsedDate, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		parsedTime, err := time.Parse(ICalDateTimeUTCFormat, value)
+		return parsedTime, false, err
+	}
+	if timezoneID, exists := property.Params[ICalParamTimezoneID]; exists {
+		timezone, err := time.LoadLocation(timezoneID)
+		if err == nil {
+			location = timezone
+		}
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	parsedTime, err := time.ParseInLocation(ICalDateTimeFormat, value, location)
+	return parsedTime, false, err
+}
+
+// ParseICalDuration parses a DURATION value, e.g. PT1H30M or P1D. Days and weeks are nominal, so they are
+// returned separately from the exact duration to keep the local time across daylight saving changes.
+func ParseICalDuration(value string) (int, time.Duration, error) {
+	invalidErr := fmt.Errorf("invalid ical duration: %s", value)
+	sign := 1
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+	}
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, 0, invalidErr
+	}
+	days := 0
+	var duration time.Duration
+	inTime := false
+	number := ""
+	for _, char := range value[1:] {
+		switch {
+		case char >= '0' && char <= '9':
+			number += string(char)
+			continue
+		case char == 'T' && number == "" && !inTime:
+			inTime = true
+			continue
+		}
+		amount, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, 0, invalidErr
+		}
+		number = ""
+		switch {
+		case char == 'W' && !inTime:
+			days += 7 * amount
+		case char == 'D' && !inTime:
+			days += amount
+		case char == 'H' && inTime:
+			duration += time.Duration(amount) * time.Hour
+		case char == 'M' && inTime:
+			duration += time.Duration(amount) * time.Minute
+		case char == 'S' && inTime:
+			duration += time.Duration(amount) * time.Second
+		default:
+			return 0, 0, invalidErr
+		}
+	}
+	if number != "" {
+		return 0, 0, invalidErr
+	}
+	return sign * days, time.Duration(sign) * duration, nil
+}
+
+func FormatICalDate(date time.Time) (string, map[string]string) {
+	return date.Format(ICalDateFormat), map[string]string{ICalParamValue: ICalValueDate}
+}
+
+func FormatICalDateTime(dateTime time.Time) string {
+	return dateTime.UTC().Format(ICalDateTimeUTCFormat)
+}
+
+// NewICalCalendar wraps components in a VCALENDAR with the required properties
+func NewICalCalendar(components ...*ICalComponent) *ICalComponent {
+	return &ICalComponent{
+		Name: ICalComponentCalendar,
+		Properties: []*ICalProperty{
+			{Name: ICalPropertyVersion, Value: ICalVersion},
+			{Name: ICalPropertyProductID, Value: ICalProductID},
+		},
+		Components: components,
+	}
+}
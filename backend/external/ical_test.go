@@ -0,0 +1,142 @@
+package external
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleICalTodo = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Example//EN\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:sample-uid\r\n" +
+	"SUMMARY:Buy milk\\, eggs\\; and bread\r\n" +
+	"DESCRIPTION:first line\\nsecond line which is long enough that it needs to be\r\n" +
+	"  folded\r\n" +
+	"DUE;TZID=America/Los_Angeles:20230420T090000\r\n" +
+	"X-CUSTOM;X-PARAM=\"quoted:value\":custom\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICal(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		calendar, err := ParseICal(sampleICalTodo)
+		assert.NoError(t, err)
+		assert.Equal(t, ICalComponentCalendar, calendar.Name)
+		todos := calendar.GetComponents(ICalComponentTodo)
+		assert.Equal(t, 1, len(todos))
+		assert.Equal(t, "Buy milk, eggs; and bread", todos[0].GetText(ICalPropertySummary))
+		assert.Equal(t, "first line\nsecond line which is long enough that it needs to be folded", todos[0].GetText(ICalPropertyDesc))
+		custom := todos[0].GetProperty("X-CUSTOM")
+		assert.Equal(t, "custom", custom.Value)
+		assert.Equal(t, "quoted:value", custom.Params["X-PARAM"])
+		assert.Nil(t, todos[0].GetProperty(ICalPropertyPriority))
+	})
+	t.Run("Unclosed", func(t *testing.T) {
+		_, err := ParseICal("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n")
+		assert.EqualError(t, err, "ical component not closed: VCALENDAR")
+	})
+	t.Run("MismatchedEnd", func(t *testing.T) {
+		_, err := ParseICal("BEGIN:VCALENDAR\r\nEND:VTODO\r\n")
+		assert.EqualError(t, err, "unexpected end of ical component: VTODO")
+	})
+	t.Run("InvalidLine", func(t *testing.T) {
+		_, err := ParseICal("BEGIN:VCALENDAR\r\nnot a property\r\nEND:VCALENDAR\r\n")
+		assert.EqualError(t, err, "invalid ical line: not a property")
+	})
+	t.Run("Empty", func(t *testing.T) {
+		_, err := ParseICal("")
+		assert.EqualError(t, err, "ical data is empty")
+	})
+}
+
+func TestSerializeICal(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		calendar, err := ParseICal(sampleICalTodo)
+		assert.NoError(t, err)
+		reparsed, err := ParseICal(calendar.Serialize())
+		assert.NoError(t, err)
+		assert.Equal(t, calendar, reparsed)
+	})
+	t.Run("FoldsLongLines", func(t *testing.T) {
+		todo := &ICalComponent{Name: ICalComponentTodo}
+		todo.SetText(ICalPropertySummary, strings.Repeat("é", 80))
+		serialized := todo.Serialize()
+		for _, line := range strings.Split(strings.TrimSuffix(serialized, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), ICalLineLength)
+		}
+		reparsed, err := ParseICal(serialized)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("é", 80), reparsed.GetText(ICalPropertySummary))
+	})
+	t.Run("NewCalendar", func(t *testing.T) {
+		todo := &ICalComponent{Name: ICalComponentTodo}
+		todo.SetProperty(ICalPropertyUID, "uid", nil)
+		value, params := FormatICalDate(time.Date(2023, time.April, 20, 0, 0, 0, 0, time.UTC))
+		todo.SetProperty(ICalPropertyDue, value, params)
+		assert.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//General Task//Task Manager//EN\r\nBEGIN:VTODO\r\nUID:uid\r\nDUE;VALUE=DATE:20230420\r\nEND:VTODO\r\nEND:VCALENDAR\r\n", NewICalCalendar(todo).Serialize())
+	})
+}
+
+func TestParseICalTime(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+
+	t.Run("Missing", func(t *testing.T) {
+		_, _, err := ParseICalTime(nil, time.UTC)
+		assert.EqualError(t, err, "ical property is missing")
+	})
+	t.Run("Date", func(t *testing.T) {
+		parsedTime, isDate, err := ParseICalTime(&ICalProperty{Value: "20230420", Params: map[string]string{ICalParamValue: ICalValueDate}}, time.UTC)
+		assert.NoError(t, err)
+		assert.True(t, isDate)
+		assert.Equal(t, time.Date(2023, time.April, 20, 0, 0, 0, 0, time.UTC), parsedTime)
+	})
+	t.Run("UTC", func(t *testing.T) {
+		parsedTime, isDate, err := ParseICalTime(&ICalProperty{Value: "20230420T160000Z"}, losAngeles)
+		assert.NoError(t, err)
+		assert.False(t, isDate)
+		assert.True(t, time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC).Equal(parsedTime))
+	})
+	t.Run("TimezoneID", func(t *testing.T) {
+		parsedTime, _, err := ParseICalTime(&ICalProperty{Value: "20230420T090000", Params: map[string]string{ICalParamTimezoneID: "America/Los_Angeles"}}, time.UTC)
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC).Equal(parsedTime))
+	})
+	t.Run("Floating", func(t *testing.T) {
+		parsedTime, _, err := ParseICalTime(&ICalProperty{Value: "20230420T090000"}, losAngeles)
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC).Equal(parsedTime))
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := ParseICalTime(&ICalProperty{Value: "not a time"}, time.UTC)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseICalDuration(t *testing.T) {
+	for value, expected := range map[string]struct {
+		days     int
+		duration time.Duration
+	}{
+		"PT1H30M":    {0, 90 * time.Minute},
+		"P1D":        {1, 0},
+		"P2W":        {14, 0},
+		"P1DT12H":    {1, 12 * time.Hour},
+		"-PT15M":     {0, -15 * time.Minute},
+		"+PT10S":     {0, 10 * time.Second},
+		"P0DT0H0M0S": {0, 0},
+	} {
+		days, duration, err := ParseICalDuration(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected.days, days, value)
+		assert.Equal(t, expected.duration, duration, value)
+	}
+	for _, value := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "PT1H2", "P1.5D"} {
+		_, _, err := ParseICalDuration(value)
+		assert.Error(t, err, value)
+	}
+}
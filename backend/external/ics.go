@@ -0,0 +1,425 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// feeds larger than this are rejected rather than loaded into memory
+const icsFeedMaxSize = 10 << 20
+
+// feed URLs are user-supplied, so requests are kept from reaching anything inside our network
+var icsHTTPClient = utils.NewPublicHTTPClient(constants.ExternalTimeout)
+
+type ICSService struct{}
+
+type ICSCalendarSource struct {
+	ICS ICSService
+}
+
+// ICSSubscription is stored as the token of the linked account, as public feeds don't need credentials
+type ICSSubscription struct {
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+func (ics ICSService) GetLinkURL(stateTokenID primitive.ObjectID, userID primitive.ObjectID) (*string, error) {
+	return nil, errors.New("ics calendars are linked with a feed url")
+}
+
+func (ics ICSService) GetSignupURL(stateTokenID primitive.ObjectID, forcePrompt bool) (*string, error) {
+	return nil, errors.New("ics does not support signup")
+}
+
+func (ics ICSService) HandleLinkCallback(db *mongo.Database, params CallbackParams, userID primitive.ObjectID) error {
+	logger := logging.GetSentryLogger()
+	if params.FeedURL == nil || params.FeedURL.URL == "" {
+		return errors.New("missing ics feed url")
+	}
+	feedURL, err := normalizeICSFeedURL(params.FeedURL.URL)
+	if err != nil {
+		return err
+	}
+
+	// check the feed can be loaded before saving it
+	calendar, err := fetchICSFeed(feedURL)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load ics feed")
+		return errors.New("unable to load ics feed")
+	}
+	subscription := ICSSubscription{URL: feedURL, Name: params.FeedURL.Name}
+	if subscription.Name == "" {
+		subscription.Name = calendar.GetText(ICalPropertyCalName)
+	}
+	if subscription.Name == "" {
+		parsedURL, _ := url.Parse(feedURL)
+		subscription.Name = parsedURL.Host
+	}
+
+	tokenString, err := json.Marshal(&subscription)
+	if err != nil {
+		logger.Error().Err(err).Msg("error parsing token")
+		return errors.New("internal server error")
+	}
+	_, err = database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"user_id": userID}, {"service_id": TASK_SERVICE_ID_ICS}, {"account_id": feedURL}}},
+		bson.M{"$set": &database.ExternalAPIToken{
+			UserID:         userID,
+			ServiceID:      TASK_SERVICE_ID_ICS,
+			Token:          string(tokenString),
+			AccountID:      feedURL,
+			DisplayID:      subscription.Name,
+			IsUnlinkable:   true,
+			IsPrimaryLogin: false,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("error saving token")
+		return errors.New("internal server error")
+	}
+	return nil
+}
+
+func (ics ICSService) HandleSignupCallback(db *mongo.Database, params CallbackParams) (primitive.ObjectID, *bool, *string, error) {
+	return primitive.NilObjectID, nil, nil, errors.New("ics does not support signup")
+}
+
+// normalizeICSFeedURL accepts http, https and webcal URLs, where webcal is fetched over https
+func normalizeICSFeedURL(rawURL string) (string, error) {
+	feedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", errors.New("invalid ics feed url")
+	}
+	if feedURL.Scheme == "webcal" {
+		feedURL.Scheme = "https"
+	}
+	if (feedURL.Scheme != "http" && feedURL.Scheme != "https") || feedURL.Host == "" {
+		return "", errors.New("invalid ics feed url")
+	}
+	return feedURL.String(), nil
+}
+
+func fetchICSFeed(feedURL string) (*ICalComponent, error) {
+	parsedURL, err := url.Parse(feedURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return nil, errors.New("invalid ics feed url")
+	}
+	response, err := icsHTTPClient.Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ics feed request failed with status %d", response.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, icsFeedMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > icsFeedMaxSize {
+		return nil, errors.New("ics feed is too large")
+	}
+	calendar, err := ParseICal(string(body))
+	if err != nil {
+		return nil, err
+	}
+	if calendar.Name != ICalComponentCalendar {
+		return nil, errors.New("ics feed is not a calendar")
+	}
+	return calendar, nil
+}
+
+func getICSSubscription(db *mongo.Database, userID primitive.ObjectID, accountID string) (*ICSSubscription, error) {
+	token, err := getExternalToken(db, userID, accountID, TASK_SERVICE_ID_ICS)
+	if err != nil {
+		return nil, err
+	}
+	var subscription ICSSubscription
+	err = json.Unmarshal([]byte(token.Token), &subscription)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetEvents loads the feed on every call, the account sync job keeps it refreshed for users without the app open
+func (icsCalendar ICSCalendarSource) GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult) {
+	logger := logging.GetSentryLogger()
+	subscription, err := getICSSubscription(db, userID, accountID)
+	if err != nil {
+		result <- emptyCalendarResult(err)
+		return
+	}
+	calendar, err := fetchICSFeed(subscription.URL)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to load ics feed")
+		result <- emptyCalendarResult(err)
+		return
+	}
+	// all day events, e.g. holidays, are shown from midnight in the user's timezone
+	location, err := database.GetUserLocation(db, userID)
+	if err != nil || location == nil {
+		location = time.UTC
+	}
+
+	events := []*database.CalendarEvent{}
+	for _, event := range getICSCalendarEvents(calendar, userID, accountID, startTime, endTime, location) {
+		dbEvent, err := database.UpdateOrCreateCalendarEvent(
+			db,
+			userID,
+			event.IDExternal,
+			event.SourceID,
+			event,
+			&[]bson.M{{"source_account_id": accountID}},
+		)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not store ics event in db %s", event.IDExternal)
+			continue
+		}
+		events = append(events, dbEvent)
+	}
+
+	calendarAccount := database.CalendarAccount{
+		UserID:     userID,
+		IDExternal: accountID,
+		SourceID:   TASK_SOURCE_ID_ICS,
+		Scopes:     scopes,
+		Calendars: []database.Calendar{{
+			AccessRole: constants.AccessControlReader,
+			CalendarID: accountID,
+			Title:      subscription.Name,
+		}},
+	}
+	_, err = database.UpdateOrCreateCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_ICS, calendarAccount, nil)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not create CalendarAccount: %+v", calendarAccount)
+	}
+	result <- CalendarResult{CalendarEvents: events, Error: nil}
+}
+
+// getICSCalendarEvents returns the events in the feed which overlap the time range, expanding recurring events.
+// Each occurrence of a recurring event is stored separately, keyed by its UID and start time.
+func getICSCalendarEvents(calendar *ICalComponent, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, location *time.Location) []*database.CalendarEvent {
+	vevents := calendar.GetComponents(ICalComponentEvent)
+	// modified occurrences of recurring events are separate VEVENTs with a RECURRENCE-ID
+	overriddenOccurrences := map[string]bool{}
+	for _, vevent := range vevents {
+		recurrenceID, _, err := parseICSTime(vevent.GetProperty(ICalPropertyRecurID), location)
+		if err == nil {
+			overriddenOccurrences[getICSOccurrenceID(vevent.GetText(ICalPropertyUID), recurrenceID)] = true
+		}
+	}
+
+	events := []*database.CalendarEvent{}
+	for _, vevent := range vevents {
+		uid := vevent.GetText(ICalPropertyUID)
+		if uid == "" || vevent.GetText(ICalPropertyStatus) == ICalStatusCancelled {
+			continue
+		}
+		dtstart, isAllDay, err := parseICSTime(vevent.GetProperty(ICalPropertyStart), location)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not parse start of ics event %s", uid)
+			continue
+		}
+		days, duration, err := getICSEventLength(vevent, dtstart, isAllDay, location)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not parse end of ics event %s", uid)
+			continue
+		}
+
+		occurrenceStarts := []time.Time{dtstart}
+		recurrenceProperty := vevent.GetProperty(ICalPropertyRecurrence)
+		isRecurring := recurrenceProperty != nil && vevent.GetProperty(ICalPropertyRecurID) == nil
+		if isRecurring {
+			rule, err := ParseRRule(recurrenceProperty.Value)
+			if err != nil {
+				log.Error().Err(err).Msgf("could not parse recurrence of ics event %s", uid)
+				continue
+			}
+			exdates, err := getICSExceptionDates(vevent, location)
+			if err != nil {
+				log.Error().Err(err).Msgf("could not parse exception dates of ics event %s", uid)
+				continue
+			}
+			// occurrences which start before the range can still overlap it
+			rangeStart := startTime.AddDate(0, 0, -days).Add(-duration)
+			occurrenceStarts = rule.Between(dtstart, rangeStart, endTime, exdates)
+		}
+
+		for _, occurrenceStart := range occurrenceStarts {
+			occurrenceEnd := occurrenceStart.AddDate(0, 0, days).Add(duration)
+			if !occurrenceStart.Before(endTime) || !occurrenceEnd.After(startTime) {
+				continue
+			}
+			idExternal := uid
+			event := icsEventToCalendarEvent(vevent, idExternal, userID, accountID, occurrenceStart, occurrenceEnd)
+			if isRecurring {
+				idExternal = getICSOccurrenceID(uid, occurrenceStart)
+				if overriddenOccurrences[idExternal] {
+					continue
+				}
+				event.RecurringEventID = uid
+				event.OriginalStartTime = primitive.NewDateTimeFromTime(occurrenceStart)
+			} else if recurrenceID, _, err := parseICSTime(vevent.GetProperty(ICalPropertyRecurID), location); err == nil {
+				idExternal = getICSOccurrenceID(uid, recurrenceID)
+				event.RecurringEventID = uid
+				event.OriginalStartTime = primitive.NewDateTimeFromTime(recurrenceID)
+			}
+			event.IDExternal = idExternal
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func icsEventToCalendarEvent(vevent *ICalComponent, idExternal string, userID primitive.ObjectID, accountID string, datetimeStart time.Time, datetimeEnd time.Time) *database.CalendarEvent {
+	description := vevent.GetText(ICalPropertyDesc)
+	location := vevent.GetText(ICalPropertyLocation)
+	conferenceCall := utils.GetConferenceUrlFromString(description)
+	if conferenceCall == nil {
+		conferenceCall = utils.GetConferenceUrlFromString(location)
+	}
+	if conferenceCall == nil {
+		conferenceCall = &utils.ConferenceCall{}
+	}
+	return &database.CalendarEvent{
+		UserID:          userID,
+		IDExternal:      idExternal,
+		SourceID:        TASK_SOURCE_ID_ICS,
+		SourceAccountID: accountID,
+		CalendarID:      accountID,
+		Deeplink:        vevent.GetText(ICalPropertyURL),
+		Title:           vevent.GetText(ICalPropertySummary),
+		Body:            description,
+		Location:        location,
+		TimeAllocation:  datetimeEnd.Sub(datetimeStart).Nanoseconds(),
+		DatetimeStart:   primitive.NewDateTimeFromTime(datetimeStart),
+		DatetimeEnd:     primitive.NewDateTimeFromTime(datetimeEnd),
+		CanModify:       false,
+		CallURL:         conferenceCall.URL,
+		CallLogo:        conferenceCall.Logo,
+		CallPlatform:    conferenceCall.Platform,
+	}
+}
+
+func getICSOccurrenceID(uid string, occurrenceStart time.Time) string {
+	return uid + "_" + FormatICalDateTime(occurrenceStart)
+}
+
+// parseICSTime is the same as ParseICalTime, except that all day dates start at midnight in the given location
+func parseICSTime(property *ICalProperty, location *time.Location) (time.Time, bool, error) {
+	parsedTime, isAllDay, err := ParseICalTime(property, location)
+	if err != nil || !isAllDay {
+		return parsedTime, isAllDay, err
+	}
+	return time.Date(parsedTime.Year(), parsedTime.Month(), parsedTime.Day(), 0, 0, 0, 0, location), true, nil
+}
+
+// getICSEventLength returns the length of the event as nominal days and an exact duration, from DTEND or DURATION.
+// Without either, all day events last one day and other events have no length (RFC 5545 3.6.1).
+func getICSEventLength(vevent *ICalComponent, dtstart time.Time, isAllDay bool, location *time.Location) (int, time.Duration, error) {
+	if endProperty := vevent.GetProperty(ICalPropertyEnd); endProperty != nil {
+		dtend, _, err := parseICSTime(endProperty, location)
+		if err != nil {
+			return 0, 0, err
+		}
+		if dtend.Before(dtstart) {
+			return 0, 0, errors.New("ics event ends before it starts")
+		}
+		if isAllDay {
+			startDate := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+			endDate := time.Date(dtend.Year(), dtend.Month(), dtend.Day(), 0, 0, 0, 0, time.UTC)
+			return int(endDate.Sub(startDate).Hours() / 24), 0, nil
+		}
+		return 0, dtend.Sub(dtstart), nil
+	}
+	if durationProperty := vevent.GetProperty(ICalPropertyDuration); durationProperty != nil {
+		days, duration, err := ParseICalDuration(durationProperty.Value)
+		if err == nil && (days < 0 || duration < 0) {
+			err = errors.New("ics event has a negative duration")
+		}
+		return days, duration, err
+	}
+	if isAllDay {
+		return 1, 0, nil
+	}
+	return 0, 0, nil
+}
+
+func getICSExceptionDates(vevent *ICalComponent, location *time.Location) ([]RecurrenceExceptionDate, error) {
+	exdates := []RecurrenceExceptionDate{}
+	for _, property := range vevent.Properties {
+		if property.Name != ICalPropertyExDate {
+			continue
+		}
+		propertyLocation := location
+		if timezoneID, exists := property.Params[ICalParamTimezoneID]; exists {
+			timezone, err := time.LoadLocation(timezoneID)
+			if err == nil {
+				propertyLocation = timezone
+			}
+		}
+		propertyExdates, err := ParseRecurrenceExceptionDates([]string{property.Value}, propertyLocation)
+		if err != nil {
+			return nil, err
+		}
+		exdates = append(exdates, propertyExdates...)
+	}
+	return exdates, nil
+}
+
+func (icsCalendar ICSCalendarSource) GetTasks(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- TaskResult) {
+	result <- emptyTaskResult(nil)
+}
+
+func (icsCalendar ICSCalendarSource) GetPullRequests(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- PullRequestResult) {
+	result <- emptyPullRequestResult(nil, false)
+}
+
+func (icsCalendar ICSCalendarSource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
+	return primitive.NilObjectID, errors.New("has not been implemented yet")
+}
+
+func (icsCalendar ICSCalendarSource) ModifyTask(db *mongo.Database, userID primitive.ObjectID, accountID string, issueID string, updateFields *database.Task, task *database.Task) error {
+	return nil
+}
+
+func (icsCalendar ICSCalendarSource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
+	return errors.New("ics calendars are read only")
+}
+
+func (icsCalendar ICSCalendarSource) ModifyEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, eventID string, updateFields *EventModifyObject) error {
+	return errors.New("ics calendars are read only")
+}
+
+func (icsCalendar ICSCalendarSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("ics calendars are read only")
+}
+
+func (icsCalendar ICSCalendarSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
+	return errors.New("ics calendars are read only")
+}
+
+func (icsCalendar ICSCalendarSource) AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error {
+	return errors.New("has not been implemented yet")
+}
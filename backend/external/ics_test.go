@@ -0,0 +1,233 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const icsTestFeed = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//Example//EN\r\nX-WR-CALNAME:Team On-call\r\n" +
+	"BEGIN:VEVENT\r\nUID:single\r\nSUMMARY:Incident review\r\nDESCRIPTION:join at https://zoom.us/j/123456\r\nLOCATION:Room 1\r\n" +
+	"URL:https://example.com/review\r\nDTSTART:20230306T170000Z\r\nDTEND:20230306T180000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:holiday\r\nSUMMARY:Holiday\r\nDTSTART;VALUE=DATE:20230307\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:on-call\r\nSUMMARY:On-call\r\nDTSTART;TZID=America/Los_Angeles:20230301T090000\r\nDURATION:PT2H\r\n" +
+	"RRULE:FREQ=DAILY\r\nEXDATE;TZID=America/Los_Angeles:20230307T090000\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:on-call\r\nSUMMARY:On-call (moved)\r\nRECURRENCE-ID;TZID=America/Los_Angeles:20230308T090000\r\n" +
+	"DTSTART;TZID=America/Los_Angeles:20230308T130000\r\nDTEND;TZID=America/Los_Angeles:20230308T150000\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:cancelled\r\nSTATUS:CANCELLED\r\nDTSTART:20230306T170000Z\r\nDTEND:20230306T180000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:last-week\r\nDTSTART:20230227T170000Z\r\nDTEND:20230227T180000Z\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func getICSTestServer(feed string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.ics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		w.Write([]byte(feed))
+	}))
+}
+
+func TestGetICSCalendarEvents(t *testing.T) {
+	calendar, err := ParseICal(icsTestFeed)
+	assert.NoError(t, err)
+	userID := primitive.NewObjectID()
+	location, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	startTime := time.Date(2023, time.March, 6, 0, 0, 0, 0, location)
+	endTime := time.Date(2023, time.March, 9, 0, 0, 0, 0, location)
+
+	events := getICSCalendarEvents(calendar, userID, "feed", startTime, endTime, location)
+	idToEvent := map[string]*database.CalendarEvent{}
+	for _, event := range events {
+		idToEvent[event.IDExternal] = event
+	}
+	assert.Equal(t, 4, len(events))
+
+	t.Run("Single", func(t *testing.T) {
+		assert.Equal(t, &database.CalendarEvent{
+			UserID:          userID,
+			IDExternal:      "single",
+			SourceID:        TASK_SOURCE_ID_ICS,
+			SourceAccountID: "feed",
+			CalendarID:      "feed",
+			Deeplink:        "https://example.com/review",
+			Title:           "Incident review",
+			Body:            "join at https://zoom.us/j/123456",
+			Location:        "Room 1",
+			TimeAllocation:  time.Hour.Nanoseconds(),
+			DatetimeStart:   primitive.NewDateTimeFromTime(time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)),
+			DatetimeEnd:     primitive.NewDateTimeFromTime(time.Date(2023, time.March, 6, 18, 0, 0, 0, time.UTC)),
+			CallURL:         "https://zoom.us/j/123456",
+			CallLogo:        "/images/zoom.svg",
+			CallPlatform:    "Zoom",
+		}, idToEvent["single"])
+	})
+	t.Run("AllDay", func(t *testing.T) {
+		holiday := idToEvent["holiday"]
+		assert.Equal(t, time.Date(2023, time.March, 7, 0, 0, 0, 0, location), holiday.DatetimeStart.Time().In(location))
+		assert.Equal(t, time.Date(2023, time.March, 8, 0, 0, 0, 0, location), holiday.DatetimeEnd.Time().In(location))
+	})
+	t.Run("Recurring", func(t *testing.T) {
+		monday := idToEvent["on-call_20230306T170000Z"]
+		assert.Equal(t, "On-call", monday.Title)
+		assert.Equal(t, (2 * time.Hour).Nanoseconds(), monday.TimeAllocation)
+		assert.Equal(t, "on-call", monday.RecurringEventID)
+		assert.Equal(t, time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC), monday.OriginalStartTime.Time().UTC())
+		// excluded by EXDATE
+		assert.Nil(t, idToEvent["on-call_20230307T170000Z"])
+		// replaced by the moved occurrence
+		moved := idToEvent["on-call_20230308T170000Z"]
+		assert.Equal(t, "On-call (moved)", moved.Title)
+		assert.Equal(t, time.Date(2023, time.March, 8, 21, 0, 0, 0, time.UTC), moved.DatetimeStart.Time().UTC())
+		assert.Equal(t, "on-call", moved.RecurringEventID)
+		assert.Equal(t, time.Date(2023, time.March, 8, 17, 0, 0, 0, time.UTC), moved.OriginalStartTime.Time().UTC())
+	})
+	t.Run("OccurrenceOverlappingRangeStart", func(t *testing.T) {
+		events := getICSCalendarEvents(calendar, userID, "feed", time.Date(2023, time.March, 6, 10, 0, 0, 0, location), endTime, location)
+		ids := []string{}
+		for _, event := range events {
+			ids = append(ids, event.IDExternal)
+		}
+		assert.Contains(t, ids, "on-call_20230306T170000Z")
+		// ends as the range starts
+		assert.NotContains(t, ids, "single")
+	})
+}
+
+func TestGetICSEventLength(t *testing.T) {
+	parseEvent := func(lines string) *ICalComponent {
+		calendar, err := ParseICal("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + lines + "END:VEVENT\r\nEND:VCALENDAR\r\n")
+		assert.NoError(t, err)
+		return calendar.GetComponents(ICalComponentEvent)[0]
+	}
+	dtstart := time.Date(2023, time.March, 6, 0, 0, 0, 0, time.UTC)
+
+	days, duration, err := getICSEventLength(parseEvent("DTEND;VALUE=DATE:20230309\r\n"), dtstart, true, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, days)
+	assert.Equal(t, time.Duration(0), duration)
+
+	days, duration, err = getICSEventLength(parseEvent("DURATION:P1DT2H\r\n"), dtstart, false, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, days)
+	assert.Equal(t, 2*time.Hour, duration)
+
+	days, _, err = getICSEventLength(parseEvent(""), dtstart, true, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, days)
+
+	_, _, err = getICSEventLength(parseEvent("DTEND:20230305T000000Z\r\n"), dtstart, false, time.UTC)
+	assert.EqualError(t, err, "ics event ends before it starts")
+}
+
+func TestNormalizeICSFeedURL(t *testing.T) {
+	feedURL, err := normalizeICSFeedURL(" webcal://example.com/feed.ics ")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/feed.ics", feedURL)
+	feedURL, err = normalizeICSFeedURL("http://example.com/feed.ics?token=abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/feed.ics?token=abc", feedURL)
+	for _, invalidURL := range []string{"ftp://example.com/feed.ics", "example.com/feed.ics", "https://"} {
+		_, err = normalizeICSFeedURL(invalidURL)
+		assert.EqualError(t, err, "invalid ics feed url", invalidURL)
+	}
+}
+
+func TestFetchICSFeed(t *testing.T) {
+	t.Run("InternalAddress", func(t *testing.T) {
+		server := getICSTestServer(icsTestFeed)
+		defer server.Close()
+		_, err := fetchICSFeed(server.URL + "/feed.ics")
+		assert.ErrorIs(t, err, utils.ErrNonPublicAddress)
+	})
+	t.Run("InvalidScheme", func(t *testing.T) {
+		_, err := fetchICSFeed("file:///etc/passwd")
+		assert.EqualError(t, err, "invalid ics feed url")
+	})
+	// the test servers are on localhost
+	publicClient := icsHTTPClient
+	icsHTTPClient = http.DefaultClient
+	defer func() { icsHTTPClient = publicClient }()
+
+	t.Run("Success", func(t *testing.T) {
+		server := getICSTestServer(icsTestFeed)
+		defer server.Close()
+		calendar, err := fetchICSFeed(server.URL + "/feed.ics")
+		assert.NoError(t, err)
+		assert.Equal(t, "Team On-call", calendar.GetText(ICalPropertyCalName))
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		server := getICSTestServer(icsTestFeed)
+		defer server.Close()
+		_, err := fetchICSFeed(server.URL + "/missing.ics")
+		assert.EqualError(t, err, "ics feed request failed with status 404")
+	})
+	t.Run("NotACalendar", func(t *testing.T) {
+		server := getICSTestServer("BEGIN:VEVENT\r\nUID:single\r\nEND:VEVENT\r\n")
+		defer server.Close()
+		_, err := fetchICSFeed(server.URL + "/feed.ics")
+		assert.EqualError(t, err, "ics feed is not a calendar")
+	})
+	t.Run("TooLarge", func(t *testing.T) {
+		server := getICSTestServer(icsTestFeed + strings.Repeat("X", icsFeedMaxSize))
+		defer server.Close()
+		_, err := fetchICSFeed(server.URL + "/feed.ics")
+		assert.EqualError(t, err, "ics feed is too large")
+	})
+}
+
+func TestICSCalendarSource(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	server := getICSTestServer(icsTestFeed)
+	defer server.Close()
+	feedURL := server.URL + "/feed.ics"
+	userID := primitive.NewObjectID()
+	publicClient := icsHTTPClient
+	icsHTTPClient = http.DefaultClient
+	defer func() { icsHTTPClient = publicClient }()
+
+	t.Run("LinkInvalidFeed", func(t *testing.T) {
+		err := ICSService{}.HandleLinkCallback(db, CallbackParams{FeedURL: &FeedURLParams{URL: server.URL + "/missing.ics"}}, userID)
+		assert.EqualError(t, err, "unable to load ics feed")
+	})
+	t.Run("Link", func(t *testing.T) {
+		err := ICSService{}.HandleLinkCallback(db, CallbackParams{FeedURL: &FeedURLParams{URL: feedURL}}, userID)
+		assert.NoError(t, err)
+		var token database.ExternalAPIToken
+		err = database.GetExternalTokenCollection(db).FindOne(context.Background(), bson.M{"user_id": userID, "service_id": TASK_SERVICE_ID_ICS}).Decode(&token)
+		assert.NoError(t, err)
+		assert.Equal(t, feedURL, token.AccountID)
+		// the name defaults to the calendar name in the feed
+		assert.Equal(t, "Team On-call", token.DisplayID)
+	})
+	t.Run("GetEvents", func(t *testing.T) {
+		result := make(chan CalendarResult)
+		go ICSCalendarSource{}.GetEvents(db, userID, feedURL, time.Date(2023, time.March, 6, 0, 0, 0, 0, time.UTC), time.Date(2023, time.March, 7, 0, 0, 0, 0, time.UTC), nil, result)
+		calendarResult := <-result
+		assert.NoError(t, calendarResult.Error)
+		assert.Equal(t, 2, len(calendarResult.CalendarEvents))
+
+		calendarAccounts, err := database.GetCalendarAccounts(db, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(*calendarAccounts))
+		assert.Equal(t, []database.Calendar{{AccessRole: constants.AccessControlReader, CalendarID: feedURL, Title: "Team On-call"}}, (*calendarAccounts)[0].Calendars)
+	})
+	t.Run("ReadOnly", func(t *testing.T) {
+		err := ICSCalendarSource{}.DeleteEvent(db, userID, feedURL, "single", feedURL, EventScopeThis)
+		assert.EqualError(t, err, "ics calendars are read only")
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jjPlusPlus/task-manager/backend/constants"
@@ -21,6 +22,8 @@ const (
 	JIRAPriorityKey = "priority"
 	JIRADueDateKey  = "duedate"
 	NoProject       = "noProject"
+	// Jira's default link type, with inward "is blocked by" and outward "blocks"
+	JIRABlocksLinkType = "Blocks"
 )
 
 type JIRASource struct {
@@ -72,6 +75,26 @@ type JIRATaskFields struct {
 	Project     JIRAProject     `json:"project"`
 	Status      JIRAStatus      `json:"status"`
 	Priority    JIRAPriority    `json:"priority"`
+	IssueLinks  []JIRAIssueLink `json:"issuelinks"`
+	Labels      []string        `json:"labels"`
+}
+
+// JIRAIssueLink is a relation to another issue, where InwardIssue is set when the other issue is on the inward side (i.e. "is blocked by")
+type JIRAIssueLink struct {
+	Type         JIRAIssueLinkType `json:"type"`
+	InwardIssue  *JIRALinkedIssue  `json:"inwardIssue,omitempty"`
+	OutwardIssue *JIRALinkedIssue  `json:"outwardIssue,omitempty"`
+}
+
+type JIRAIssueLinkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type JIRALinkedIssue struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
 }
 
 // JIRATask represents the API detail result for issues - only fields we need
@@ -79,6 +102,8 @@ type JIRATask struct {
 	Fields JIRATaskFields `json:"fields"`
 	ID     string         `json:"id"`
 	Key    string         `json:"key"`
+	// the issue's REST URL, which is on the site the issue belongs to
+	Self string `json:"self"`
 }
 
 // JIRATaskList represents the API list result for issues - only fields we need
@@ -176,10 +201,13 @@ func (jira JIRASource) GetTasks(db *mongo.Database, userID primitive.ObjectID, a
 	}
 
 	var tasks []*database.Task
+	blockingIssueIDs := map[string][]string{}
 	for idx, jiraTask := range jiraTasks.Issues {
 		titleString := jiraTask.Fields.Summary
 		bodyString := string(jiraTask.Fields.Description)
 
+		blockingIssueIDs[jiraTask.ID] = getJIRABlockingIssueIDs(jiraTask.Fields.IssueLinks)
+
 		task := &database.Task{
 			UserID:          userID,
 			IDExternal:      jiraTask.ID,
@@ -254,28 +282,25 @@ func (jira JIRASource) GetTasks(db *mongo.Database, userID primitive.ObjectID, a
 			task.UpdatedAt = primUpdatedAt
 		}
 		if jiraTask.Fields.Priority.ID != "" && len(priorityList) > 0 {
-			priorityLength := len(priorityList)
-
-			var allPriorities []*database.ExternalTaskPriority
-			for idx, priority := range priorityList {
-				priorityNormalized := getNormalizedPriority(idx, priorityLength)
-				priorityObject := database.ExternalTaskPriority{
-					ExternalID:         priority.ID,
-					Name:               priority.Name,
-					Color:              priority.Color,
-					PriorityNormalized: priorityNormalized,
-					IconURL:            priority.IconURL,
-				}
-				if priority.ID == jiraTask.Fields.Priority.ID {
-					task.ExternalPriority = &priorityObject
-					task.PriorityNormalized = &priorityNormalized
-				}
-				allPriorities = append(allPriorities, &priorityObject)
+			allPriorities := getJIRAExternalPriorities(priorityList)
+			task.ExternalPriority = GetJIRAExternalPriority(allPriorities, jiraTask.Fields.Priority.ID)
+			if task.ExternalPriority != nil {
+				task.PriorityNormalized = &task.ExternalPriority.PriorityNormalized
 			}
-
 			task.AllExternalPriorities = allPriorities
 		}
 
+		labels := []database.Label{}
+		for _, jiraLabel := range jiraTask.Fields.Labels {
+			labels = append(labels, database.Label{Name: jiraLabel, Color: constants.DefaultLabelColor})
+		}
+		externalLabelIDs, err := database.GetOrCreateLabels(db, userID, labels, time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to import jira labels")
+		} else {
+			task.ExternalLabelIDs = &externalLabelIDs
+		}
+
 		tasks = append(tasks, task)
 	}
 
@@ -294,6 +319,7 @@ func (jira JIRASource) GetTasks(db *mongo.Database, userID primitive.ObjectID, a
 			Comments:              task.Comments,
 			IsCompleted:           &isCompleted,
 			JIRATaskParams:        task.JIRATaskParams,
+			ExternalLabelIDs:      task.ExternalLabelIDs,
 		}
 
 		dbTask, err := database.UpdateOrCreateTask(
@@ -313,6 +339,11 @@ func (jira JIRASource) GetTasks(db *mongo.Database, userID primitive.ObjectID, a
 		task.ID = dbTask.ID
 		task.IDOrdering = dbTask.IDOrdering
 		task.IDTaskSection = dbTask.IDTaskSection
+
+		err = database.ReplaceImportedTaskDependencies(db, userID, dbTask.ID, TASK_SOURCE_ID_JIRA, blockingIssueIDs[task.IDExternal], time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to update jira issue links")
+		}
 	}
 
 	result <- TaskResult{
@@ -320,6 +351,16 @@ func (jira JIRASource) GetTasks(db *mongo.Database, userID primitive.ObjectID, a
 	}
 }
 
+func getJIRABlockingIssueIDs(issueLinks []JIRAIssueLink) []string {
+	blockingIssueIDs := []string{}
+	for _, issueLink := range issueLinks {
+		if issueLink.Type.Name == JIRABlocksLinkType && issueLink.InwardIssue != nil {
+			blockingIssueIDs = append(blockingIssueIDs, issueLink.InwardIssue.ID)
+		}
+	}
+	return blockingIssueIDs
+}
+
 func (JIRA JIRASource) GetPullRequests(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- PullRequestResult) {
 	result <- emptyPullRequestResult(nil, false)
 }
@@ -381,23 +422,7 @@ func (jira JIRASource) GetListOfComments(siteConfiguration *database.AtlassianSi
 	}
 
 	for _, comment := range commentList.Comments {
-		dbComment := database.Comment{
-			ExternalID: comment.ID,
-			Body:       string(comment.Body),
-			User: database.ExternalUser{
-				ExternalID:  comment.Author.AccountID,
-				Name:        comment.Author.DisplayName,
-				DisplayName: comment.Author.DisplayName,
-			},
-		}
-
-		createdAt, err := time.Parse("2006-01-02T15:04:05.999-0700", comment.CreatedAt)
-		if err == nil {
-			primCreatedAt := primitive.NewDateTimeFromTime(createdAt)
-			dbComment.CreatedAt = primCreatedAt
-		}
-
-		dbComments = append(dbComments, dbComment)
+		dbComments = append(dbComments, GetDatabaseCommentFromJIRAComment(comment))
 	}
 
 	result <- JIRACommentResult{
@@ -405,6 +430,25 @@ func (jira JIRASource) GetListOfComments(siteConfiguration *database.AtlassianSi
 	}
 }
 
+func GetDatabaseCommentFromJIRAComment(comment JIRAComment) database.Comment {
+	dbComment := database.Comment{
+		ExternalID: comment.ID,
+		Body:       string(comment.Body),
+		User: database.ExternalUser{
+			ExternalID:  comment.Author.AccountID,
+			Name:        comment.Author.DisplayName,
+			DisplayName: comment.Author.DisplayName,
+		},
+	}
+
+	createdAt, err := time.Parse("2006-01-02T15:04:05.999-0700", comment.CreatedAt)
+	if err == nil {
+		primCreatedAt := primitive.NewDateTimeFromTime(createdAt)
+		dbComment.CreatedAt = primCreatedAt
+	}
+	return dbComment
+}
+
 func (jira JIRASource) GetListOfStatuses(siteConfiguration *database.AtlassianSiteConfiguration, userID primitive.ObjectID, authToken string) (map[string][]*database.ExternalTaskStatus, error) {
 	statusMap := make(map[string][]*database.ExternalTaskStatus)
 	baseURL := jira.getJIRABaseURL(siteConfiguration, jira.Atlassian.Config.ConfigValues.StatusListURL)
@@ -478,6 +522,44 @@ func (jira JIRASource) GetListOfPriorities(siteConfiguration *database.Atlassian
 	return priorityIds, nil
 }
 
+// GetExternalPriorities fetches the site's priorities for a linked account, normalized the same way as in GetTasks
+func (jira JIRASource) GetExternalPriorities(userID primitive.ObjectID, accountID string) ([]*database.ExternalTaskPriority, error) {
+	authToken, _ := jira.Atlassian.getAndRefreshToken(userID, accountID)
+	siteConfiguration, _ := jira.Atlassian.getSiteConfiguration(userID)
+	if authToken == nil || siteConfiguration == nil {
+		return nil, errors.New("missing authToken or siteConfiguration")
+	}
+	priorityList, err := jira.GetListOfPriorities(siteConfiguration, userID, authToken.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return getJIRAExternalPriorities(priorityList), nil
+}
+
+func getJIRAExternalPriorities(priorityList []JIRAPriority) []*database.ExternalTaskPriority {
+	priorityLength := len(priorityList)
+	var allPriorities []*database.ExternalTaskPriority
+	for idx, priority := range priorityList {
+		allPriorities = append(allPriorities, &database.ExternalTaskPriority{
+			ExternalID:         priority.ID,
+			Name:               priority.Name,
+			Color:              priority.Color,
+			PriorityNormalized: getNormalizedPriority(idx, priorityLength),
+			IconURL:            priority.IconURL,
+		})
+	}
+	return allPriorities
+}
+
+func GetJIRAExternalPriority(allPriorities []*database.ExternalTaskPriority, priorityID string) *database.ExternalTaskPriority {
+	for _, priority := range allPriorities {
+		if priority.ExternalID == priorityID {
+			return priority
+		}
+	}
+	return nil
+}
+
 type JIRAFieldsResult struct {
 	JIRATaskParams database.JIRATaskParams
 	Error          error
@@ -546,15 +628,158 @@ func (jira JIRASource) GetListOfFields(siteConfiguration *database.AtlassianSite
 	}
 }
 
+type JIRATaskCreationParams struct {
+	ProjectID   string `json:"project_id"`
+	IssueTypeID string `json:"issue_type_id"`
+	PriorityID  string `json:"priority_id,omitempty"`
+}
+
+type JIRAIssueType struct {
+	ID string `json:"id"`
+}
+
+type JIRAAssignee struct {
+	ID string `json:"id"`
+}
+
+type JIRACreateIssueFields struct {
+	Project     JIRAProject      `json:"project"`
+	IssueType   JIRAIssueType    `json:"issuetype"`
+	Summary     string           `json:"summary"`
+	Description *json.RawMessage `json:"description,omitempty"`
+	Priority    *JIRAPriority    `json:"priority,omitempty"`
+	DueDate     *string          `json:"duedate,omitempty"`
+	Assignee    *JIRAAssignee    `json:"assignee,omitempty"`
+}
+
+type JIRACreateIssueRequest struct {
+	Fields JIRACreateIssueFields `json:"fields"`
+}
+
+type JIRACreateIssueResponse struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
 func (jira JIRASource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
-	return primitive.NilObjectID, errors.New("has not been implemented yet")
+	if task.JIRAParams == nil || task.JIRAParams.ProjectID == "" || task.JIRAParams.IssueTypeID == "" {
+		return primitive.NilObjectID, errors.New("project and issue type are required to create a JIRA issue")
+	}
+	authToken, _ := jira.Atlassian.getAndRefreshToken(userID, accountID)
+	siteConfiguration, _ := jira.Atlassian.getSiteConfiguration(userID)
+	if authToken == nil || siteConfiguration == nil {
+		return primitive.NilObjectID, errors.New("missing authToken or siteConfiguration")
+	}
+
+	// issues are assigned to the creator so that they come back in the assignee=currentuser() search
+	currentUser, err := jira.getCurrentUser(siteConfiguration, authToken.AccessToken)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	createRequest := JIRACreateIssueRequest{Fields: JIRACreateIssueFields{
+		Project:   JIRAProject{ID: task.JIRAParams.ProjectID},
+		IssueType: JIRAIssueType{ID: task.JIRAParams.IssueTypeID},
+		Summary:   task.Title,
+		Assignee:  &JIRAAssignee{ID: currentUser.AccountID},
+	}}
+	if task.Body != "" {
+		description := getJIRADescription(task.Body)
+		createRequest.Fields.Description = &description
+	}
+	if task.JIRAParams.PriorityID != "" {
+		createRequest.Fields.Priority = &JIRAPriority{ID: task.JIRAParams.PriorityID}
+	}
+	if task.DueDate != nil {
+		dueDateString := task.DueDate.Format(constants.YEAR_MONTH_DAY_FORMAT)
+		createRequest.Fields.DueDate = &dueDateString
+	}
+	createRequestBytes, err := json.Marshal(&createRequest)
+	if err != nil {
+		return primitive.NilObjectID, errors.New("unable to marshal fields for JIRA create request")
+	}
+
+	apiBaseURL := jira.getJIRABaseURL(siteConfiguration, jira.Atlassian.Config.ConfigValues.IssueCreateURL)
+	req, _ := http.NewRequest("POST", apiBaseURL+"/rest/api/3/issue", bytes.NewBuffer(createRequestBytes))
+	req = addJIRARequestHeaders(req, authToken.AccessToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		logging.GetSentryLogger().Error().Msgf("JIRA issue create failed: %s %v", responseBytes, resp.StatusCode)
+		return primitive.NilObjectID, errors.New("unable to successfully make issue create request")
+	}
+	var createdIssue JIRACreateIssueResponse
+	err = json.Unmarshal(responseBytes, &createdIssue)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	var priorityNormalized *float64
+	if task.JIRAParams.PriorityID != "" {
+		priorityList, err := jira.GetListOfPriorities(siteConfiguration, userID, authToken.AccessToken)
+		if err == nil {
+			priority := GetJIRAExternalPriority(getJIRAExternalPriorities(priorityList), task.JIRAParams.PriorityID)
+			if priority != nil {
+				priorityNormalized = &priority.PriorityNormalized
+			}
+		}
+	}
+	return insertCreatedExternalTask(db, userID, accountID, TASK_SOURCE_ID_JIRA, createdIssue.ID, siteConfiguration.SiteURL+"/browse/"+createdIssue.Key, task, priorityNormalized)
+}
+
+func (jira JIRASource) getCurrentUser(siteConfiguration *database.AtlassianSiteConfiguration, authToken string) (*JIRAUser, error) {
+	baseURL := jira.getJIRABaseURL(siteConfiguration, jira.Atlassian.Config.ConfigValues.CurrentUserURL)
+	req, _ := http.NewRequest("GET", baseURL+"/rest/api/3/myself", nil)
+	req = addJIRARequestHeaders(req, authToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	userString, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var user JIRAUser
+	err = json.Unmarshal(userString, &user)
+	if err != nil {
+		return nil, err
+	}
+	if user.AccountID == "" {
+		return nil, errors.New("unable to fetch current JIRA user")
+	}
+	return &user, nil
+}
+
+// descriptions are Atlassian Document Format, so plain text is wrapped in a single paragraph
+func getJIRADescription(body string) json.RawMessage {
+	if json.Valid([]byte(body)) && strings.HasPrefix(strings.TrimSpace(body), "{") {
+		return json.RawMessage(body)
+	}
+	description, _ := json.Marshal(map[string]interface{}{
+		"type":    "doc",
+		"version": 1,
+		"content": []map[string]interface{}{{
+			"type":    "paragraph",
+			"content": []map[string]interface{}{{"type": "text", "text": body}},
+		}},
+	})
+	return description
 }
 
 func (jira JIRASource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
 	return errors.New("has not been implemented yet")
 }
 
-func (jira JIRASource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (jira JIRASource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (jira JIRASource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
 	return errors.New("has not been implemented yet")
 }
 
@@ -524,6 +524,86 @@ func TestGetJIRAComments(t *testing.T) {
 	})
 }
 
+func TestCreateJIRATask(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	userID, accountID := setupJIRA(t, database.GetExternalTokenCollection(db), database.GetJiraSitesCollection(db))
+	tokenServer := getTokenServerForJIRA(t, http.StatusOK)
+	defer tokenServer.Close()
+	currentUserServer := testutils.GetMockAPIServer(t, 200, `{"accountId":"jira_user_id","displayName":"Jira User"}`)
+	defer currentUserServer.Close()
+	priorityServer := getJIRAPriorityServer(t, 200, []byte(`[{"id":"1","name":"Highest"},{"id":"2","name":"Medium"},{"id":"3","name":"Lowest"}]`))
+	defer priorityServer.Close()
+	var createRequest JIRACreateIssueRequest
+	createServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/3/issue", r.RequestURI)
+		assert.Equal(t, "POST", r.Method)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &createRequest))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"10042","key":"MOON-42"}`))
+	}))
+	defer createServer.Close()
+	JIRA := JIRASource{Atlassian: AtlassianService{Config: AtlassianConfig{ConfigValues: AtlassianConfigValues{
+		TokenURL:        &tokenServer.URL,
+		CurrentUserURL:  &currentUserServer.URL,
+		IssueCreateURL:  &createServer.URL,
+		PriorityListURL: &priorityServer.URL,
+	}}}}
+
+	t.Run("MissingParams", func(t *testing.T) {
+		_, err := JIRA.CreateNewTask(db, *userID, accountID, TaskCreationObject{Title: "to the moon"})
+		assert.Error(t, err)
+	})
+	t.Run("Success", func(t *testing.T) {
+		dueDate := time.Date(2023, time.April, 20, 0, 0, 0, 0, time.UTC)
+		taskID, err := JIRA.CreateNewTask(db, *userID, accountID, TaskCreationObject{
+			Title:      "to the moon",
+			Body:       "buy the dip",
+			DueDate:    &dueDate,
+			JIRAParams: &JIRATaskCreationParams{ProjectID: "10000", IssueTypeID: "10001", PriorityID: "2"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "10000", createRequest.Fields.Project.ID)
+		assert.Equal(t, "10001", createRequest.Fields.IssueType.ID)
+		assert.Equal(t, "to the moon", createRequest.Fields.Summary)
+		assert.Equal(t, "2", createRequest.Fields.Priority.ID)
+		assert.Equal(t, "2023-04-20", *createRequest.Fields.DueDate)
+		assert.Equal(t, "jira_user_id", createRequest.Fields.Assignee.ID)
+
+		task, err := database.GetTask(db, taskID, *userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "10042", task.IDExternal)
+		assert.Equal(t, TASK_SOURCE_ID_JIRA, task.SourceID)
+		assert.Equal(t, "https://dankmemes.com/browse/MOON-42", task.Deeplink)
+		assert.Equal(t, "to the moon", *task.Title)
+		assert.Equal(t, 2.5, *task.PriorityNormalized)
+		assert.False(t, *task.IsCompleted)
+	})
+}
+
+func TestGetJIRADescription(t *testing.T) {
+	assert.Equal(t, `{"content":[{"content":[{"text":"buy the dip","type":"text"}],"type":"paragraph"}],"type":"doc","version":1}`, string(getJIRADescription("buy the dip")))
+	adf := `{"type":"doc","version":1,"content":[]}`
+	assert.Equal(t, adf, string(getJIRADescription(adf)))
+}
+
+func TestGetJIRABlockingIssueIDs(t *testing.T) {
+	var fields JIRATaskFields
+	err := json.Unmarshal([]byte(`{"issuelinks": [
+		{"type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "inwardIssue": {"id": "10001", "key": "TEST-1"}},
+		{"type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "outwardIssue": {"id": "10002", "key": "TEST-2"}},
+		{"type": {"name": "Relates", "inward": "relates to", "outward": "relates to"}, "inwardIssue": {"id": "10003", "key": "TEST-3"}}
+	]}`), &fields)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10001"}, getJIRABlockingIssueIDs(fields.IssueLinks))
+	assert.Equal(t, []string{}, getJIRABlockingIssueIDs(nil))
+}
+
 func setupJIRA(t *testing.T, externalAPITokenCollection *mongo.Collection, AtlassianSiteCollection *mongo.Collection) (*primitive.ObjectID, string) {
 	userID, accountID := createJIRAToken(t, externalAPITokenCollection)
 	createAtlassianSiteConfiguration(t, userID, AtlassianSiteCollection)
@@ -27,6 +27,7 @@ const (
 	LinearTokenUrl        = "https://api.linear.app/oauth/token" //#nosec
 	LinearCompletedType   = "completed"
 	LinearCanceledType    = "canceled"
+	LinearBlocksRelation  = "blocks"
 )
 
 type LinearConfigValues struct {
@@ -307,6 +308,21 @@ type linearAssignedIssuesQuery struct {
 				StartsAt graphql.String
 				EndsAt   graphql.String
 			}
+			Labels struct {
+				Nodes []struct {
+					Name  graphql.String
+					Color graphql.String
+				}
+			}
+			// relations pointing at this issue, i.e. issues which block it
+			InverseRelations struct {
+				Nodes []struct {
+					Type  graphql.String
+					Issue struct {
+						Id graphql.ID
+					}
+				}
+			}
 		}
 	} `graphql:"issues(filter: {state: {type: {nin: [\"completed\", \"canceled\"]}}, assignee: {email: {eq: $email}}})"`
 	ActiveCycles   Cycles `graphql:" activeCycles: cycles (filter: {isActive: {eq: true}})"`
@@ -502,6 +518,88 @@ func handleMutateLinearIssue(client *graphqlBasic.Client, issueID string, update
 	return bool(query.IssueUpdate.Success), nil
 }
 
+const linearCreateIssueQueryStr = `
+		mutation IssueCreate (
+			$title: String!
+			, $description: String
+			, $teamId: String!
+			, $stateId: String
+			, $priority: Int
+			, $cycleId: String
+			, $dueDate: TimelessDate
+			, $assigneeId: String
+		) {
+		  issueCreate(
+			input: {
+			  title: $title
+			  , description: $description
+			  , teamId: $teamId
+			  , stateId: $stateId
+			  , priority: $priority
+			  , cycleId: $cycleId
+			  , dueDate: $dueDate
+			  , assigneeId: $assigneeId
+			}
+		  ) {
+			success
+			issue {
+			  id
+			  url
+			}
+		  }
+		}`
+
+type linearCreatedIssue struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+type linearCreateIssueQuery struct {
+	IssueCreate struct {
+		Success graphql.Boolean
+		Issue   linearCreatedIssue
+	} `graphql:"issueCreate(input: {title: $title, description: $description, teamId: $teamId, stateId: $stateId, priority: $priority, cycleId: $cycleId, dueDate: $dueDate, assigneeId: $assigneeId})"`
+}
+
+func createLinearIssue(client *graphqlBasic.Client, assigneeID string, task TaskCreationObject) (*linearCreatedIssue, error) {
+	if task.Title == "" {
+		return nil, errors.New("cannot create linear issue with empty title")
+	}
+	request := graphqlBasic.NewRequest(linearCreateIssueQueryStr)
+	request.Var("title", task.Title)
+	request.Var("teamId", task.LinearParams.TeamID)
+	if task.Body != "" {
+		request.Var("description", task.Body)
+	}
+	if task.LinearParams.StateID != "" {
+		request.Var("stateId", task.LinearParams.StateID)
+	}
+	if task.LinearParams.Priority != nil {
+		request.Var("priority", *task.LinearParams.Priority)
+	}
+	if task.LinearParams.CycleID != "" {
+		request.Var("cycleId", task.LinearParams.CycleID)
+	}
+	if task.DueDate != nil {
+		request.Var("dueDate", task.DueDate.Format(constants.YEAR_MONTH_DAY_FORMAT))
+	}
+	if assigneeID != "" {
+		request.Var("assigneeId", assigneeID)
+	}
+
+	log.Debug().Msgf("sending request to Linear: %+v", request)
+	var query linearCreateIssueQuery
+	if err := client.Run(context.Background(), request, &query); err != nil {
+		logger := logging.GetSentryLogger()
+		logger.Error().Err(err).Msg("failed to create linear issue")
+		return nil, err
+	}
+	if !query.IssueCreate.Success || query.IssueCreate.Issue.ID == "" {
+		return nil, errors.New("linear mutation failed to create issue")
+	}
+	return &query.IssueCreate.Issue, nil
+}
+
 func updateLinearIssue(client *graphqlBasic.Client, issueID string, updateFields *database.Task, task *database.Task) (bool, error) {
 	var success bool
 	var err error
@@ -156,6 +156,17 @@ func (linearTask LinearTaskSource) GetTasks(db *mongo.Database, userID primitive
 			LinearCycle:        task.LinearCycle,
 		}
 
+		labels := []database.Label{}
+		for _, linearLabel := range linearIssue.Labels.Nodes {
+			labels = append(labels, database.Label{Name: string(linearLabel.Name), Color: string(linearLabel.Color)})
+		}
+		externalLabelIDs, err := database.GetOrCreateLabels(db, userID, labels, time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to import linear labels")
+		} else {
+			updateFields.ExternalLabelIDs = &externalLabelIDs
+		}
+
 		if linearIssue.DueDate != "" {
 			dueDate, _ := time.Parse(constants.YEAR_MONTH_DAY_FORMAT, string(linearIssue.DueDate))
 			primitiveDueDate := primitive.NewDateTimeFromTime(dueDate)
@@ -189,6 +200,16 @@ func (linearTask LinearTaskSource) GetTasks(db *mongo.Database, userID primitive
 			result <- emptyTaskResultWithSource(err, TASK_SOURCE_ID_LINEAR)
 			return
 		}
+		blockingIssueIDs := []string{}
+		for _, relation := range linearIssue.InverseRelations.Nodes {
+			if relation.Type == LinearBlocksRelation && relation.Issue.Id != nil {
+				blockingIssueIDs = append(blockingIssueIDs, relation.Issue.Id.(string))
+			}
+		}
+		err = database.ReplaceImportedTaskDependencies(db, userID, dbTask.ID, TASK_SOURCE_ID_LINEAR, blockingIssueIDs, time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to update linear issue relations")
+		}
 		task.HasBeenReordered = dbTask.HasBeenReordered
 		task.ID = dbTask.ID
 		task.IDOrdering = dbTask.IDOrdering
@@ -224,15 +245,51 @@ func (linearTask LinearTaskSource) ModifyTask(db *mongo.Database, userID primiti
 	return nil
 }
 
+type LinearTaskCreationParams struct {
+	TeamID   string `json:"team_id"`
+	StateID  string `json:"state_id,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+	CycleID  string `json:"cycle_id,omitempty"`
+}
+
 func (linearTask LinearTaskSource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
-	return primitive.NilObjectID, errors.New("has not been implemented yet")
+	if task.LinearParams == nil || task.LinearParams.TeamID == "" {
+		return primitive.NilObjectID, errors.New("team is required to create a linear issue")
+	}
+	logger := logging.GetSentryLogger()
+	token, err := database.GetExternalToken(db, accountID, TASK_SERVICE_ID_LINEAR)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	client, err := GetBasicLinearClient(linearTask.Linear.Config.ConfigValues.TaskUpdateURL, db, userID, accountID)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to create linear client")
+		return primitive.NilObjectID, err
+	}
+	// issues are assigned to the creator so that they come back with the rest of the assigned issues
+	createdIssue, err := createLinearIssue(client, token.ExternalID, task)
+	if err != nil {
+		logger.Error().Err(err).Msg("unable to create linear issue")
+		return primitive.NilObjectID, err
+	}
+
+	var priorityNormalized *float64
+	if task.LinearParams.Priority != nil {
+		priority := float64(*task.LinearParams.Priority)
+		priorityNormalized = &priority
+	}
+	return insertCreatedExternalTask(db, userID, accountID, TASK_SOURCE_ID_LINEAR, createdIssue.ID, createdIssue.URL, task, priorityNormalized)
 }
 
 func (linearTask LinearTaskSource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
 	return errors.New("has not been implemented yet")
 }
 
-func (linearTask LinearTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error {
+func (linearTask LinearTaskSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	return errors.New("has not been implemented yet")
+}
+
+func (linearTask LinearTaskSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
 	return errors.New("has not been implemented yet")
 }
 
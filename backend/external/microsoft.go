@@ -0,0 +1,210 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/oauth2"
+)
+
+const (
+	MicrosoftGraphBaseURL = "https://graph.microsoft.com/v1.0"
+	// Graph returns its scopes prefixed with the resource, they are stored without it
+	microsoftGraphScopePrefix = "https://graph.microsoft.com/"
+)
+
+type MicrosoftConfig struct {
+	OauthConfig  OauthConfigWrapper
+	ConfigValues MicrosoftConfigValues
+}
+
+// GraphURL replaces the Graph API base URL in tests, requests are sent without authentication
+type MicrosoftConfigValues struct {
+	GraphURL *string
+}
+
+type MicrosoftService struct {
+	Config MicrosoftConfig
+}
+
+type MicrosoftUserInfo struct {
+	ID                string `json:"id"`
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+	DisplayName       string `json:"displayName"`
+}
+
+type microsoftGraphError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func getMicrosoftOauthConfig() OauthConfigWrapper {
+	return &OauthConfig{Config: &oauth2.Config{
+		ClientID:     config.GetConfigValue("MICROSOFT_OAUTH_CLIENT_ID"),
+		ClientSecret: config.GetConfigValue("MICROSOFT_OAUTH_CLIENT_SECRET"),
+		RedirectURL:  config.GetConfigValue("SERVER_URL") + "link/microsoft/callback/",
+		Scopes:       []string{"offline_access", "User.Read", database.MicrosoftCalendarScope},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://login.microsoftonline.com/common/oauth2/v2.0/authorize",
+			TokenURL: "https://login.microsoftonline.com/common/oauth2/v2.0/token",
+		},
+	}}
+}
+
+func (microsoft MicrosoftService) GetLinkURL(stateTokenID primitive.ObjectID, userID primitive.ObjectID) (*string, error) {
+	authURL := microsoft.Config.OauthConfig.AuthCodeURL(stateTokenID.Hex(), oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "select_account"))
+	return &authURL, nil
+}
+
+func (microsoft MicrosoftService) GetSignupURL(stateTokenID primitive.ObjectID, forcePrompt bool) (*string, error) {
+	return nil, errors.New("microsoft does not support signup")
+}
+
+func (microsoft MicrosoftService) HandleLinkCallback(db *mongo.Database, params CallbackParams, userID primitive.ObjectID) error {
+	parentCtx := context.Background()
+	extCtx, cancel := context.WithTimeout(parentCtx, constants.ExternalTimeout)
+	defer cancel()
+	token, err := microsoft.Config.OauthConfig.Exchange(extCtx, *params.Oauth2Code)
+	logger := logging.GetSentryLogger()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch token from Microsoft")
+		return errors.New("internal server error")
+	}
+
+	client, graphURL := http.DefaultClient, MicrosoftGraphBaseURL
+	if microsoft.Config.ConfigValues.GraphURL != nil {
+		graphURL = *microsoft.Config.ConfigValues.GraphURL
+	} else {
+		client = oauth2.NewClient(parentCtx, oauth2.StaticTokenSource(token))
+	}
+	var userInfo MicrosoftUserInfo
+	err = microsoftGraphRequest(client, "GET", graphURL+"/me", nil, &userInfo)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load Microsoft user info")
+		return errors.New("internal server error")
+	}
+	// work accounts may not have a mailbox address set, but always have a principal name
+	accountID := userInfo.Mail
+	if accountID == "" {
+		accountID = userInfo.UserPrincipalName
+	}
+	if accountID == "" {
+		logger.Error().Msg("missing account email from Microsoft user info")
+		return errors.New("internal server error")
+	}
+
+	tokenString, err := json.Marshal(&token)
+	if err != nil {
+		logger.Error().Err(err).Msg("error parsing token")
+		return errors.New("internal server error")
+	}
+	_, err = database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"user_id": userID}, {"service_id": TASK_SERVICE_ID_MICROSOFT}, {"account_id": accountID}}},
+		bson.M{"$set": &database.ExternalAPIToken{
+			UserID:         userID,
+			ServiceID:      TASK_SERVICE_ID_MICROSOFT,
+			Token:          string(tokenString),
+			AccountID:      accountID,
+			DisplayID:      accountID,
+			ExternalID:     userInfo.ID,
+			IsUnlinkable:   true,
+			IsPrimaryLogin: false,
+			Scopes:         getMicrosoftGrantedScopes(token),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("error saving token")
+		return errors.New("internal server error")
+	}
+
+	err = database.UpdateUserSetting(db, userID, constants.HasDismissedMulticalPrompt, constants.SettingFalse)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to set HasDismissedMulticalPrompt as false")
+		return err
+	}
+	return nil
+}
+
+func (microsoft MicrosoftService) HandleSignupCallback(db *mongo.Database, params CallbackParams) (primitive.ObjectID, *bool, *string, error) {
+	return primitive.NilObjectID, nil, nil, errors.New("microsoft does not support signup")
+}
+
+func getMicrosoftGrantedScopes(token *oauth2.Token) []string {
+	scopes := []string{}
+	scopeString, ok := token.Extra("scope").(string)
+	if !ok {
+		return scopes
+	}
+	for _, scope := range strings.Fields(scopeString) {
+		scopes = append(scopes, strings.TrimPrefix(scope, microsoftGraphScopePrefix))
+	}
+	return scopes
+}
+
+func getMicrosoftHttpClient(db *mongo.Database, userID primitive.ObjectID, accountID string) *http.Client {
+	return getExternalOauth2Client(db, userID, accountID, TASK_SERVICE_ID_MICROSOFT, getMicrosoftOauthConfig())
+}
+
+// returns the client and Graph base URL to use for a request, preferring the override set in tests
+func (microsoft MicrosoftService) getClientAndURL(db *mongo.Database, userID primitive.ObjectID, accountID string) (*http.Client, string, error) {
+	if microsoft.Config.ConfigValues.GraphURL != nil {
+		return http.DefaultClient, *microsoft.Config.ConfigValues.GraphURL, nil
+	}
+	client := getMicrosoftHttpClient(db, userID, accountID)
+	if client == nil {
+		return nil, "", errors.New("could not load microsoft token")
+	}
+	return client, MicrosoftGraphBaseURL, nil
+}
+
+// microsoftGraphRequest sends a JSON request to the Graph API, asking for times in UTC and plain text bodies
+func microsoftGraphRequest(client *http.Client, method string, url string, body interface{}, result interface{}) error {
+	var requestBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewBuffer(bodyBytes)
+	}
+	request, err := http.NewRequest(method, url, requestBody)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add("Prefer", `outlook.timezone="UTC"`)
+	request.Header.Add("Prefer", `outlook.body-content-type="text"`)
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		var graphError microsoftGraphError
+		json.NewDecoder(response.Body).Decode(&graphError)
+		return fmt.Errorf("graph request failed with status %d: %s %s", response.StatusCode, graphError.Error.Code, graphError.Error.Message)
+	}
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
@@ -0,0 +1,545 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// Graph assigns its own event IDs, so events created here keep our ID in an extended property
+	outlookEventIDPropertyID  = "String {00020329-0000-0000-C000-000000000046} Name GeneralTaskEventID"
+	outlookDateTimeLayout     = "2006-01-02T15:04:05.9999999"
+	outlookEventPageSize      = 250
+	outlookTeamsProvider      = "teamsForBusiness"
+	outlookShowAsOutOfOffice  = "oof"
+	outlookResponseDeclined   = "declined"
+	outlookResponseAccepted   = "accepted"
+	outlookResponseTentative  = "tentativelyAccepted"
+	outlookResponseOrganizer  = "organizer"
+	outlookSensitivityPrivate = "private"
+)
+
+// the Graph actions which send each response to an invitation
+var outlookResponseActions = map[string]string{
+	constants.EventResponseAccepted:  "accept",
+	constants.EventResponseDeclined:  "decline",
+	constants.EventResponseTentative: "tentativelyAccept",
+}
+
+type OutlookCalendarSource struct {
+	Microsoft MicrosoftService
+}
+
+type OutlookEmailAddress struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+type OutlookCalendar struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	HexColor          string              `json:"hexColor"`
+	CanEdit           bool                `json:"canEdit"`
+	IsDefaultCalendar bool                `json:"isDefaultCalendar"`
+	Owner             OutlookEmailAddress `json:"owner"`
+}
+
+type OutlookDateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type OutlookItemBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type OutlookLocation struct {
+	DisplayName string `json:"displayName"`
+}
+
+type OutlookAttendee struct {
+	EmailAddress OutlookEmailAddress    `json:"emailAddress"`
+	Type         string                 `json:"type,omitempty"`
+	Status       *OutlookResponseStatus `json:"status,omitempty"`
+}
+
+type OutlookOnlineMeeting struct {
+	JoinURL string `json:"joinUrl"`
+}
+
+type OutlookResponseStatus struct {
+	Response string `json:"response"`
+}
+
+type OutlookExtendedProperty struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// OutlookEvent is used both to read events and to send the fields being created or modified
+type OutlookEvent struct {
+	ID                            string                    `json:"id,omitempty"`
+	Subject                       *string                   `json:"subject,omitempty"`
+	Body                          *OutlookItemBody          `json:"body,omitempty"`
+	Start                         *OutlookDateTime          `json:"start,omitempty"`
+	End                           *OutlookDateTime          `json:"end,omitempty"`
+	Location                      *OutlookLocation          `json:"location,omitempty"`
+	Attendees                     *[]OutlookAttendee        `json:"attendees,omitempty"`
+	IsAllDay                      bool                      `json:"isAllDay,omitempty"`
+	IsCancelled                   bool                      `json:"isCancelled,omitempty"`
+	IsOrganizer                   bool                      `json:"isOrganizer,omitempty"`
+	ShowAs                        string                    `json:"showAs,omitempty"`
+	WebLink                       string                    `json:"webLink,omitempty"`
+	Sensitivity                   string                    `json:"sensitivity,omitempty"`
+	IsOnlineMeeting               *bool                     `json:"isOnlineMeeting,omitempty"`
+	OnlineMeetingProvider         string                    `json:"onlineMeetingProvider,omitempty"`
+	OnlineMeeting                 *OutlookOnlineMeeting     `json:"onlineMeeting,omitempty"`
+	OnlineMeetingURL              string                    `json:"onlineMeetingUrl,omitempty"`
+	ResponseStatus                *OutlookResponseStatus    `json:"responseStatus,omitempty"`
+	SingleValueExtendedProperties []OutlookExtendedProperty `json:"singleValueExtendedProperties,omitempty"`
+	SeriesMasterID                string                    `json:"seriesMasterId,omitempty"`
+	OriginalStart                 string                    `json:"originalStart,omitempty"`
+}
+
+type outlookListResponse[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"@odata.nextLink"`
+}
+
+func (outlookCalendar OutlookCalendarSource) GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult) {
+	logger := logging.GetSentryLogger()
+	client, graphURL, err := outlookCalendar.Microsoft.getClientAndURL(db, userID, accountID)
+	if err != nil {
+		result <- emptyCalendarResult(err)
+		return
+	}
+	outlookCalendars, err := getOutlookList[OutlookCalendar](client, graphURL+"/me/calendars")
+	if err != nil {
+		isBadToken := CheckAndHandleBadToken(err, db, userID, accountID, TASK_SERVICE_ID_MICROSOFT)
+		if !isBadToken {
+			logger.Error().Err(err).Msg("unable to load outlook calendars")
+		}
+		result <- emptyCalendarResult(err)
+		return
+	}
+
+	calendarAccount := database.CalendarAccount{
+		UserID:     userID,
+		IDExternal: accountID,
+		SourceID:   TASK_SOURCE_ID_OUTLOOK,
+		Scopes:     scopes,
+		Calendars:  []database.Calendar{},
+	}
+	events := []*database.CalendarEvent{}
+	for _, outlookCalendar := range outlookCalendars {
+		calendarAccount.Calendars = append(calendarAccount.Calendars, database.Calendar{
+			AccessRole:      getOutlookAccessRole(outlookCalendar, accountID),
+			CalendarID:      outlookCalendar.ID,
+			Title:           outlookCalendar.Name,
+			ColorBackground: outlookCalendar.HexColor,
+		})
+		eventsURL := fmt.Sprintf(
+			"%s/me/calendars/%s/calendarView?startDateTime=%s&endDateTime=%s&$top=%d&$expand=%s",
+			graphURL,
+			url.PathEscape(outlookCalendar.ID),
+			url.QueryEscape(startTime.UTC().Format(time.RFC3339)),
+			url.QueryEscape(endTime.UTC().Format(time.RFC3339)),
+			outlookEventPageSize,
+			url.QueryEscape(fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", outlookEventIDPropertyID)),
+		)
+		outlookEvents, err := getOutlookList[OutlookEvent](client, eventsURL)
+		if err != nil {
+			logger.Error().Err(err).Msgf("unable to load events for outlook calendar %s", outlookCalendar.ID)
+			// the stored events are returned so that callers don't treat them as deleted
+			storedEvents, err := database.GetCalendarEvents(db, userID, &[]bson.M{
+				{"source_id": TASK_SOURCE_ID_OUTLOOK},
+				{"source_account_id": accountID},
+				{"calendar_id": outlookCalendar.ID},
+				{"datetime_end": bson.M{"$gte": startTime}},
+				{"datetime_start": bson.M{"$lte": endTime}},
+			})
+			if err != nil {
+				result <- emptyCalendarResult(err)
+				return
+			}
+			for index := range *storedEvents {
+				events = append(events, &(*storedEvents)[index])
+			}
+			continue
+		}
+		for _, outlookEvent := range outlookEvents {
+			dbEvent := outlookEventToCalendarEvent(outlookEvent, userID, accountID, outlookCalendar.ID)
+			if dbEvent == nil {
+				continue
+			}
+			dbEvent, err = database.UpdateOrCreateCalendarEvent(
+				db,
+				userID,
+				dbEvent.IDExternal,
+				dbEvent.SourceID,
+				dbEvent,
+				&[]bson.M{
+					{"source_account_id": accountID},
+					{"calendar_id": outlookCalendar.ID},
+				},
+			)
+			if err != nil {
+				log.Error().Err(err).Msgf("could not store outlook event in db %s", outlookEvent.ID)
+				continue
+			}
+			events = append(events, dbEvent)
+		}
+	}
+
+	_, err = database.UpdateOrCreateCalendarAccount(db, userID, accountID, TASK_SOURCE_ID_OUTLOOK, calendarAccount, nil)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not create CalendarAccount: %+v", calendarAccount)
+	}
+	result <- CalendarResult{CalendarEvents: events, Error: nil}
+}
+
+// getOutlookList follows the next links of a Graph collection until all pages are loaded
+func getOutlookList[T any](client *http.Client, listURL string) ([]T, error) {
+	items := []T{}
+	for listURL != "" {
+		var response outlookListResponse[T]
+		err := microsoftGraphRequest(client, "GET", listURL, nil, &response)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, response.Value...)
+		listURL = response.NextLink
+	}
+	return items, nil
+}
+
+// getOutlookAccessRole maps Outlook calendar permissions onto the Google access roles used by calendar accounts
+func getOutlookAccessRole(outlookCalendar OutlookCalendar, accountID string) string {
+	if outlookCalendar.IsDefaultCalendar || strings.EqualFold(outlookCalendar.Owner.Address, accountID) {
+		return constants.AccessControlOwner
+	}
+	if outlookCalendar.CanEdit {
+		return "writer"
+	}
+	return constants.AccessControlReader
+}
+
+// outlookEventToCalendarEvent returns nil for events which aren't shown: all day, cancelled and declined events
+func outlookEventToCalendarEvent(outlookEvent OutlookEvent, userID primitive.ObjectID, accountID string, calendarID string) *database.CalendarEvent {
+	if outlookEvent.IsAllDay || outlookEvent.IsCancelled || outlookEvent.Start == nil || outlookEvent.End == nil {
+		return nil
+	}
+	if outlookEvent.ResponseStatus != nil && outlookEvent.ResponseStatus.Response == outlookResponseDeclined {
+		return nil
+	}
+	datetimeStart, err := parseOutlookDateTime(*outlookEvent.Start)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not parse start of outlook event %s", outlookEvent.ID)
+		return nil
+	}
+	datetimeEnd, err := parseOutlookDateTime(*outlookEvent.End)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not parse end of outlook event %s", outlookEvent.ID)
+		return nil
+	}
+
+	idExternal := outlookEvent.ID
+	for _, property := range outlookEvent.SingleValueExtendedProperties {
+		if strings.EqualFold(property.ID, outlookEventIDPropertyID) && property.Value != "" {
+			idExternal = property.Value
+		}
+	}
+	title := ""
+	if outlookEvent.Subject != nil {
+		title = *outlookEvent.Subject
+	}
+	body := ""
+	if outlookEvent.Body != nil {
+		body = outlookEvent.Body.Content
+	}
+	location := ""
+	if outlookEvent.Location != nil {
+		location = outlookEvent.Location.DisplayName
+	}
+	eventType := ""
+	if outlookEvent.ShowAs == outlookShowAsOutOfOffice {
+		eventType = "outOfOffice"
+	}
+	attendeeEmails := []string{}
+	attendees := []database.CalendarEventAttendee{}
+	if outlookEvent.Attendees != nil {
+		for _, attendee := range *outlookEvent.Attendees {
+			attendeeEmails = append(attendeeEmails, attendee.EmailAddress.Address)
+			responseStatus := constants.EventResponseNeedsAction
+			if attendee.Status != nil {
+				responseStatus = getOutlookEventResponse(attendee.Status.Response)
+			}
+			attendees = append(attendees, database.CalendarEventAttendee{
+				Name:           attendee.EmailAddress.Name,
+				Email:          attendee.EmailAddress.Address,
+				ResponseStatus: responseStatus,
+				IsSelf:         strings.EqualFold(attendee.EmailAddress.Address, accountID),
+			})
+		}
+	}
+	conferenceCall := getOutlookConferenceCall(outlookEvent)
+	originalStartTime := primitive.DateTime(0)
+	if outlookEvent.SeriesMasterID != "" {
+		originalStart, err := time.Parse(time.RFC3339, outlookEvent.OriginalStart)
+		if err == nil {
+			originalStartTime = primitive.NewDateTimeFromTime(originalStart)
+		}
+	}
+	return &database.CalendarEvent{
+		UserID:          userID,
+		IDExternal:      idExternal,
+		SourceID:        TASK_SOURCE_ID_OUTLOOK,
+		SourceAccountID: accountID,
+		CalendarID:      calendarID,
+		Deeplink:        outlookEvent.WebLink,
+		Title:           title,
+		Body:            body,
+		Location:        location,
+		EventType:       eventType,
+		TimeAllocation:  datetimeEnd.Sub(datetimeStart).Nanoseconds(),
+		DatetimeStart:   primitive.NewDateTimeFromTime(datetimeStart),
+		DatetimeEnd:     primitive.NewDateTimeFromTime(datetimeEnd),
+		CanModify:       outlookEvent.IsOrganizer,
+		CallURL:         conferenceCall.URL,
+		CallLogo:        conferenceCall.Logo,
+		CallPlatform:    conferenceCall.Platform,
+		AttendeeEmails:  attendeeEmails,
+		Attendees:       attendees,
+		// occurrences of a series are listed with their own IDs
+		RecurringEventID:  outlookEvent.SeriesMasterID,
+		OriginalStartTime: originalStartTime,
+	}
+}
+
+// getOutlookEventResponse converts a Graph response to the names google calendar uses
+func getOutlookEventResponse(response string) string {
+	switch response {
+	case outlookResponseAccepted, outlookResponseOrganizer:
+		return constants.EventResponseAccepted
+	case outlookResponseDeclined:
+		return constants.EventResponseDeclined
+	case outlookResponseTentative:
+		return constants.EventResponseTentative
+	}
+	return constants.EventResponseNeedsAction
+}
+
+// Graph times don't include an offset, they are in the time zone named next to them (UTC for our requests)
+func parseOutlookDateTime(outlookDateTime OutlookDateTime) (time.Time, error) {
+	location, err := time.LoadLocation(outlookDateTime.TimeZone)
+	if err != nil || outlookDateTime.TimeZone == "" {
+		location = time.UTC
+	}
+	return time.ParseInLocation(outlookDateTimeLayout, outlookDateTime.DateTime, location)
+}
+
+func formatOutlookDateTime(datetime time.Time) *OutlookDateTime {
+	return &OutlookDateTime{DateTime: datetime.UTC().Format(outlookDateTimeLayout), TimeZone: "UTC"}
+}
+
+func getOutlookConferenceCall(outlookEvent OutlookEvent) *utils.ConferenceCall {
+	joinURL := outlookEvent.OnlineMeetingURL
+	if outlookEvent.OnlineMeeting != nil && outlookEvent.OnlineMeeting.JoinURL != "" {
+		joinURL = outlookEvent.OnlineMeeting.JoinURL
+	}
+	if joinURL != "" {
+		conferenceCall := utils.GetConferenceUrlFromString(joinURL)
+		if conferenceCall != nil {
+			return conferenceCall
+		}
+		return &utils.ConferenceCall{URL: joinURL}
+	}
+	// then check the description for a conference URL
+	if outlookEvent.Body != nil && outlookEvent.Body.Content != "" {
+		conferenceCall := utils.GetConferenceUrlFromString(outlookEvent.Body.Content)
+		if conferenceCall != nil {
+			return conferenceCall
+		}
+	}
+	return &utils.ConferenceCall{}
+}
+
+func createOutlookAttendees(attendees []Attendee) *[]OutlookAttendee {
+	outlookAttendees := []OutlookAttendee{}
+	for _, attendee := range attendees {
+		outlookAttendees = append(outlookAttendees, OutlookAttendee{
+			EmailAddress: OutlookEmailAddress{Name: attendee.Name, Address: attendee.Email},
+			Type:         "required",
+		})
+	}
+	return &outlookAttendees
+}
+
+func (outlookCalendar OutlookCalendarSource) CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error {
+	client, graphURL, err := outlookCalendar.Microsoft.getClientAndURL(db, userID, accountID)
+	if err != nil {
+		return err
+	}
+	outlookEvent := OutlookEvent{
+		Subject:   &event.Summary,
+		Body:      &OutlookItemBody{ContentType: "text", Content: event.Description},
+		Start:     formatOutlookDateTime(*event.DatetimeStart),
+		End:       formatOutlookDateTime(*event.DatetimeEnd),
+		Location:  &OutlookLocation{DisplayName: event.Location},
+		Attendees: createOutlookAttendees(event.Attendees),
+	}
+	if event.ID != primitive.NilObjectID {
+		outlookEvent.SingleValueExtendedProperties = []OutlookExtendedProperty{{ID: outlookEventIDPropertyID, Value: event.ID.Hex()}}
+	}
+	if event.AddConferenceCall {
+		isOnlineMeeting := true
+		outlookEvent.IsOnlineMeeting = &isOnlineMeeting
+		outlookEvent.OnlineMeetingProvider = outlookTeamsProvider
+	}
+	if event.LinkedTaskID != primitive.NilObjectID || event.LinkedViewID != primitive.NilObjectID {
+		outlookEvent.Sensitivity = outlookSensitivityPrivate
+	}
+
+	createURL := graphURL + "/me/events"
+	if event.CalendarID != "" {
+		createURL = fmt.Sprintf("%s/me/calendars/%s/events", graphURL, url.PathEscape(event.CalendarID))
+	}
+	var createdEvent OutlookEvent
+	err = microsoftGraphRequest(client, "POST", createURL, outlookEvent, &createdEvent)
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("unable to create outlook event")
+		return err
+	}
+	log.Info().Msgf("Outlook event created: %s", createdEvent.WebLink)
+	return nil
+}
+
+func (outlookCalendar OutlookCalendarSource) ModifyEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, eventID string, updateFields *EventModifyObject) error {
+	if !IsSingleEventScope(updateFields.Scope) {
+		return errors.New("outlook events can only be modified one at a time")
+	}
+	client, graphURL, err := outlookCalendar.Microsoft.getClientAndURL(db, userID, accountID)
+	if err != nil {
+		return err
+	}
+	outlookEventID, err := resolveOutlookEventID(client, graphURL, eventID)
+	if err != nil {
+		return err
+	}
+
+	outlookEvent := OutlookEvent{Subject: updateFields.Summary}
+	if updateFields.Description != nil {
+		outlookEvent.Body = &OutlookItemBody{ContentType: "text", Content: *updateFields.Description}
+	}
+	if updateFields.Location != nil {
+		outlookEvent.Location = &OutlookLocation{DisplayName: *updateFields.Location}
+	}
+	if updateFields.DatetimeStart != nil {
+		outlookEvent.Start = formatOutlookDateTime(*updateFields.DatetimeStart)
+	}
+	if updateFields.DatetimeEnd != nil {
+		outlookEvent.End = formatOutlookDateTime(*updateFields.DatetimeEnd)
+	}
+	if updateFields.Attendees != nil {
+		outlookEvent.Attendees = createOutlookAttendees(*updateFields.Attendees)
+	}
+	if updateFields.AddConferenceCall != nil && *updateFields.AddConferenceCall {
+		outlookEvent.IsOnlineMeeting = updateFields.AddConferenceCall
+		outlookEvent.OnlineMeetingProvider = outlookTeamsProvider
+	}
+	return microsoftGraphRequest(client, "PATCH", fmt.Sprintf("%s/me/events/%s", graphURL, url.PathEscape(outlookEventID)), outlookEvent, nil)
+}
+
+func (outlookCalendar OutlookCalendarSource) RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error {
+	action, exists := outlookResponseActions[response]
+	if !exists {
+		return fmt.Errorf("unsupported outlook event response: %s", response)
+	}
+	client, graphURL, err := outlookCalendar.Microsoft.getClientAndURL(db, userID, accountID)
+	if err != nil {
+		return err
+	}
+	outlookEventID, err := resolveOutlookEventID(client, graphURL, externalID)
+	if err != nil {
+		return err
+	}
+	responseURL := fmt.Sprintf("%s/me/events/%s/%s", graphURL, url.PathEscape(outlookEventID), action)
+	err = microsoftGraphRequest(client, "POST", responseURL, map[string]bool{"sendResponse": true}, nil)
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("unable to respond to outlook event")
+		return err
+	}
+	return nil
+}
+
+func (outlookCalendar OutlookCalendarSource) DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error {
+	if !IsSingleEventScope(scope) {
+		return errors.New("outlook events can only be deleted one at a time")
+	}
+	client, graphURL, err := outlookCalendar.Microsoft.getClientAndURL(db, userID, accountID)
+	if err != nil {
+		return err
+	}
+	outlookEventID, err := resolveOutlookEventID(client, graphURL, externalID)
+	if err != nil {
+		return err
+	}
+	err = microsoftGraphRequest(client, "DELETE", fmt.Sprintf("%s/me/events/%s", graphURL, url.PathEscape(outlookEventID)), nil, nil)
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("unable to delete outlook event")
+		return err
+	}
+	log.Info().Msgf("outlook event successfully deleted externalID=%s", externalID)
+	return nil
+}
+
+// resolveOutlookEventID looks up the Graph ID of events created here, which are stored with our own ID
+func resolveOutlookEventID(client *http.Client, graphURL string, externalID string) (string, error) {
+	if !primitive.IsValidObjectID(externalID) {
+		return externalID, nil
+	}
+	filter := fmt.Sprintf("singleValueExtendedProperties/Any(ep: ep/id eq '%s' and ep/value eq '%s')", outlookEventIDPropertyID, externalID)
+	events, err := getOutlookList[OutlookEvent](client, fmt.Sprintf("%s/me/events?$filter=%s&$select=id", graphURL, url.QueryEscape(filter)))
+	if err != nil {
+		return "", err
+	}
+	if len(events) == 0 {
+		return "", errors.New("outlook event not found")
+	}
+	return events[0].ID, nil
+}
+
+func (outlookCalendar OutlookCalendarSource) GetTasks(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- TaskResult) {
+	result <- emptyTaskResult(nil)
+}
+
+func (outlookCalendar OutlookCalendarSource) GetPullRequests(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- PullRequestResult) {
+	result <- emptyPullRequestResult(nil, false)
+}
+
+func (outlookCalendar OutlookCalendarSource) CreateNewTask(db *mongo.Database, userID primitive.ObjectID, accountID string, task TaskCreationObject) (primitive.ObjectID, error) {
+	return primitive.NilObjectID, errors.New("has not been implemented yet")
+}
+
+func (outlookCalendar OutlookCalendarSource) ModifyTask(db *mongo.Database, userID primitive.ObjectID, accountID string, issueID string, updateFields *database.Task, task *database.Task) error {
+	return nil
+}
+
+func (outlookCalendar OutlookCalendarSource) AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error {
+	return errors.New("has not been implemented yet")
+}
@@ -0,0 +1,342 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const outlookTestTeamsURL = "https://teams.microsoft.com/l/meetup-join/19%3ameeting_abc%40thread.v2/0"
+
+type outlookTestRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// getOutlookTestServer serves a default and a shared calendar, each with one event, and accepts every event change
+func getOutlookTestServer(t *testing.T, requests *[]outlookTestRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		*requests = append(*requests, outlookTestRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/me/calendars":
+			w.Write([]byte(`{"value": [
+				{"id": "default", "name": "Calendar", "hexColor": "#ff0000", "canEdit": true, "isDefaultCalendar": true},
+				{"id": "shared", "name": "Team", "canEdit": false, "owner": {"address": "teammate@example.com"}}
+			]}`))
+		case r.Method == "GET" && r.URL.Path == "/me/calendars/default/calendarView":
+			w.Write([]byte(fmt.Sprintf(`{"value": [{
+				"id": "graph-event-1",
+				"subject": "Standup",
+				"start": {"dateTime": "2023-03-06T17:00:00.0000000", "timeZone": "UTC"},
+				"end": {"dateTime": "2023-03-06T17:30:00.0000000", "timeZone": "UTC"},
+				"isOrganizer": true,
+				"onlineMeeting": {"joinUrl": "%s"}
+			}]}`, outlookTestTeamsURL)))
+		case r.Method == "GET" && r.URL.Path == "/me/calendars/shared/calendarView":
+			w.Write([]byte(`{"value": [{
+				"id": "graph-event-2",
+				"subject": "Holiday",
+				"isAllDay": true,
+				"start": {"dateTime": "2023-03-06T00:00:00.0000000", "timeZone": "UTC"},
+				"end": {"dateTime": "2023-03-07T00:00:00.0000000", "timeZone": "UTC"}
+			}]}`))
+		case r.Method == "GET" && r.URL.Path == "/me/events":
+			w.Write([]byte(`{"value": [{"id": "graph-event-3"}]}`))
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "graph-event-4", "webLink": "https://outlook.office365.com/event"}`))
+		case r.Method == "PATCH":
+			w.Write([]byte(`{}`))
+		case r.Method == "DELETE" && r.URL.Path != "/me/events/missing":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "ErrorItemNotFound", "message": "not found"}}`))
+		}
+	}))
+}
+
+func getOutlookTestSource(serverURL string) OutlookCalendarSource {
+	return OutlookCalendarSource{Microsoft: MicrosoftService{Config: MicrosoftConfig{ConfigValues: MicrosoftConfigValues{GraphURL: &serverURL}}}}
+}
+
+func TestOutlookEventToCalendarEvent(t *testing.T) {
+	userID := primitive.NewObjectID()
+	subject := "Planning"
+	newOutlookEvent := func() OutlookEvent {
+		return OutlookEvent{
+			ID:       "graph-event-1",
+			Subject:  &subject,
+			Body:     &OutlookItemBody{ContentType: "text", Content: "agenda"},
+			Start:    &OutlookDateTime{DateTime: "2023-03-06T17:00:00.0000000", TimeZone: "UTC"},
+			End:      &OutlookDateTime{DateTime: "2023-03-06T18:30:00.0000000", TimeZone: "UTC"},
+			Location: &OutlookLocation{DisplayName: "Room 1"},
+			Attendees: &[]OutlookAttendee{
+				{EmailAddress: OutlookEmailAddress{Name: "Teammate", Address: "teammate@example.com"}, Status: &OutlookResponseStatus{Response: outlookResponseTentative}},
+				{EmailAddress: OutlookEmailAddress{Address: "User@example.com"}, Status: &OutlookResponseStatus{Response: "notResponded"}},
+			},
+			IsOrganizer: true,
+			WebLink:     "https://outlook.office365.com/event",
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		event := outlookEventToCalendarEvent(newOutlookEvent(), userID, "user@example.com", "default")
+		assert.Equal(t, &database.CalendarEvent{
+			UserID:          userID,
+			IDExternal:      "graph-event-1",
+			SourceID:        TASK_SOURCE_ID_OUTLOOK,
+			SourceAccountID: "user@example.com",
+			CalendarID:      "default",
+			Deeplink:        "https://outlook.office365.com/event",
+			Title:           "Planning",
+			Body:            "agenda",
+			Location:        "Room 1",
+			TimeAllocation:  (90 * time.Minute).Nanoseconds(),
+			DatetimeStart:   primitive.NewDateTimeFromTime(time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)),
+			DatetimeEnd:     primitive.NewDateTimeFromTime(time.Date(2023, time.March, 6, 18, 30, 0, 0, time.UTC)),
+			CanModify:       true,
+			AttendeeEmails:  []string{"teammate@example.com", "User@example.com"},
+			Attendees: []database.CalendarEventAttendee{
+				{Name: "Teammate", Email: "teammate@example.com", ResponseStatus: constants.EventResponseTentative},
+				{Email: "User@example.com", ResponseStatus: constants.EventResponseNeedsAction, IsSelf: true},
+			},
+		}, event)
+	})
+	t.Run("CreatedEventKeepsOurID", func(t *testing.T) {
+		eventID := primitive.NewObjectID()
+		outlookEvent := newOutlookEvent()
+		outlookEvent.SingleValueExtendedProperties = []OutlookExtendedProperty{{ID: outlookEventIDPropertyID, Value: eventID.Hex()}}
+		assert.Equal(t, eventID.Hex(), outlookEventToCalendarEvent(outlookEvent, userID, "user@example.com", "default").IDExternal)
+	})
+	t.Run("TeamsMeeting", func(t *testing.T) {
+		outlookEvent := newOutlookEvent()
+		outlookEvent.OnlineMeeting = &OutlookOnlineMeeting{JoinURL: outlookTestTeamsURL}
+		event := outlookEventToCalendarEvent(outlookEvent, userID, "user@example.com", "default")
+		assert.Equal(t, outlookTestTeamsURL, event.CallURL)
+		assert.Equal(t, "Microsoft Teams", event.CallPlatform)
+		assert.Equal(t, "/images/teams.svg", event.CallLogo)
+	})
+	t.Run("ConferenceURLInBody", func(t *testing.T) {
+		outlookEvent := newOutlookEvent()
+		outlookEvent.Body.Content = "join at https://zoom.us/j/123456"
+		event := outlookEventToCalendarEvent(outlookEvent, userID, "user@example.com", "default")
+		assert.Equal(t, "https://zoom.us/j/123456", event.CallURL)
+		assert.Equal(t, "Zoom", event.CallPlatform)
+	})
+	t.Run("OutOfOffice", func(t *testing.T) {
+		outlookEvent := newOutlookEvent()
+		outlookEvent.ShowAs = outlookShowAsOutOfOffice
+		assert.Equal(t, "outOfOffice", outlookEventToCalendarEvent(outlookEvent, userID, "user@example.com", "default").EventType)
+	})
+	t.Run("SkippedEvents", func(t *testing.T) {
+		allDay := newOutlookEvent()
+		allDay.IsAllDay = true
+		cancelled := newOutlookEvent()
+		cancelled.IsCancelled = true
+		declined := newOutlookEvent()
+		declined.ResponseStatus = &OutlookResponseStatus{Response: outlookResponseDeclined}
+		invalidStart := newOutlookEvent()
+		invalidStart.Start.DateTime = "tomorrow"
+		for _, outlookEvent := range []OutlookEvent{allDay, cancelled, declined, invalidStart} {
+			assert.Nil(t, outlookEventToCalendarEvent(outlookEvent, userID, "user@example.com", "default"))
+		}
+	})
+}
+
+func TestParseOutlookDateTime(t *testing.T) {
+	datetime, err := parseOutlookDateTime(OutlookDateTime{DateTime: "2023-03-06T17:00:00.5000000", TimeZone: "UTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.March, 6, 17, 0, 0, 500000000, time.UTC), datetime)
+
+	datetime, err = parseOutlookDateTime(OutlookDateTime{DateTime: "2023-03-06T09:00:00", TimeZone: "America/Los_Angeles"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC), datetime.UTC())
+
+	assert.Equal(t, &OutlookDateTime{DateTime: "2023-03-06T17:00:00", TimeZone: "UTC"}, formatOutlookDateTime(datetime))
+}
+
+func TestGetOutlookAccessRole(t *testing.T) {
+	assert.Equal(t, constants.AccessControlOwner, getOutlookAccessRole(OutlookCalendar{IsDefaultCalendar: true}, "user@example.com"))
+	assert.Equal(t, constants.AccessControlOwner, getOutlookAccessRole(OutlookCalendar{Owner: OutlookEmailAddress{Address: "User@Example.com"}}, "user@example.com"))
+	assert.Equal(t, "writer", getOutlookAccessRole(OutlookCalendar{CanEdit: true}, "user@example.com"))
+	assert.Equal(t, constants.AccessControlReader, getOutlookAccessRole(OutlookCalendar{}, "user@example.com"))
+}
+
+func TestOutlookGetEvents(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	userID := primitive.NewObjectID()
+	requests := []outlookTestRequest{}
+	server := getOutlookTestServer(t, &requests)
+	defer server.Close()
+
+	result := make(chan CalendarResult)
+	go getOutlookTestSource(server.URL).GetEvents(db, userID, "user@example.com", time.Now(), time.Now().Add(24*time.Hour), []string{database.MicrosoftCalendarScope}, result)
+	calendarResult := <-result
+	assert.NoError(t, calendarResult.Error)
+	assert.Equal(t, 1, len(calendarResult.CalendarEvents))
+	assert.Equal(t, "graph-event-1", calendarResult.CalendarEvents[0].IDExternal)
+	assert.Equal(t, "Microsoft Teams", calendarResult.CalendarEvents[0].CallPlatform)
+
+	calendarAccounts, err := database.GetCalendarAccounts(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*calendarAccounts))
+	assert.Equal(t, TASK_SOURCE_ID_OUTLOOK, (*calendarAccounts)[0].SourceID)
+	assert.Equal(t, []database.Calendar{
+		{AccessRole: constants.AccessControlOwner, CalendarID: "default", ColorBackground: "#ff0000", Title: "Calendar"},
+		{AccessRole: constants.AccessControlReader, CalendarID: "shared", Title: "Team"},
+	}, (*calendarAccounts)[0].Calendars)
+}
+
+func TestOutlookGetEventsCalendarFailure(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	userID := primitive.NewObjectID()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/me/calendars" {
+			w.Write([]byte(`{"value": [{"id": "default", "name": "Calendar", "canEdit": true, "isDefaultCalendar": true}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": {"code": "ErrorInternalServerError", "message": "oops"}}`))
+	}))
+	defer server.Close()
+	storedEvent, err := database.UpdateOrCreateCalendarEvent(db, userID, "graph-event-1", TASK_SOURCE_ID_OUTLOOK, database.CalendarEvent{
+		UserID:          userID,
+		IDExternal:      "graph-event-1",
+		SourceID:        TASK_SOURCE_ID_OUTLOOK,
+		SourceAccountID: "user@example.com",
+		CalendarID:      "default",
+		DatetimeStart:   primitive.NewDateTimeFromTime(time.Now().Add(time.Hour)),
+		DatetimeEnd:     primitive.NewDateTimeFromTime(time.Now().Add(2 * time.Hour)),
+	}, nil)
+	assert.NoError(t, err)
+
+	result := make(chan CalendarResult)
+	go getOutlookTestSource(server.URL).GetEvents(db, userID, "user@example.com", time.Now(), time.Now().Add(24*time.Hour), []string{database.MicrosoftCalendarScope}, result)
+	calendarResult := <-result
+	// the stored events of the calendar are kept, rather than treated as deleted
+	assert.NoError(t, calendarResult.Error)
+	assert.Equal(t, 1, len(calendarResult.CalendarEvents))
+	assert.Equal(t, storedEvent.ID, calendarResult.CalendarEvents[0].ID)
+}
+
+func TestOutlookCreateNewEvent(t *testing.T) {
+	requests := []outlookTestRequest{}
+	server := getOutlookTestServer(t, &requests)
+	defer server.Close()
+	datetimeStart := time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)
+	datetimeEnd := datetimeStart.Add(time.Hour)
+	eventID := primitive.NewObjectID()
+
+	err := getOutlookTestSource(server.URL).CreateNewEvent(nil, primitive.NewObjectID(), "user@example.com", EventCreateObject{
+		ID:                eventID,
+		CalendarID:        "default",
+		Summary:           "Focus time",
+		Description:       "write design doc",
+		DatetimeStart:     &datetimeStart,
+		DatetimeEnd:       &datetimeEnd,
+		Attendees:         []Attendee{{Name: "Teammate", Email: "teammate@example.com"}},
+		AddConferenceCall: true,
+		LinkedTaskID:      primitive.NewObjectID(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, "POST", requests[0].Method)
+	assert.Equal(t, "/me/calendars/default/events", requests[0].Path)
+
+	var createdEvent OutlookEvent
+	assert.NoError(t, json.Unmarshal([]byte(requests[0].Body), &createdEvent))
+	assert.Equal(t, "Focus time", *createdEvent.Subject)
+	assert.Equal(t, &OutlookDateTime{DateTime: "2023-03-06T17:00:00", TimeZone: "UTC"}, createdEvent.Start)
+	assert.Equal(t, &OutlookDateTime{DateTime: "2023-03-06T18:00:00", TimeZone: "UTC"}, createdEvent.End)
+	assert.Equal(t, []OutlookExtendedProperty{{ID: outlookEventIDPropertyID, Value: eventID.Hex()}}, createdEvent.SingleValueExtendedProperties)
+	assert.Equal(t, &[]OutlookAttendee{{EmailAddress: OutlookEmailAddress{Name: "Teammate", Address: "teammate@example.com"}, Type: "required"}}, createdEvent.Attendees)
+	assert.True(t, *createdEvent.IsOnlineMeeting)
+	assert.Equal(t, outlookTeamsProvider, createdEvent.OnlineMeetingProvider)
+	assert.Equal(t, outlookSensitivityPrivate, createdEvent.Sensitivity)
+}
+
+func TestOutlookModifyEvent(t *testing.T) {
+	requests := []outlookTestRequest{}
+	server := getOutlookTestServer(t, &requests)
+	defer server.Close()
+	source := getOutlookTestSource(server.URL)
+
+	t.Run("GraphEventID", func(t *testing.T) {
+		requests = []outlookTestRequest{}
+		summary := "Renamed"
+		err := source.ModifyEvent(nil, primitive.NewObjectID(), "user@example.com", "graph-event-1", &EventModifyObject{Summary: &summary})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(requests))
+		assert.Equal(t, "PATCH", requests[0].Method)
+		assert.Equal(t, "/me/events/graph-event-1", requests[0].Path)
+		assert.Equal(t, `{"subject":"Renamed"}`, requests[0].Body)
+	})
+	t.Run("CreatedEventID", func(t *testing.T) {
+		requests = []outlookTestRequest{}
+		datetimeStart := time.Date(2023, time.March, 6, 17, 0, 0, 0, time.UTC)
+		err := source.ModifyEvent(nil, primitive.NewObjectID(), "user@example.com", primitive.NewObjectID().Hex(), &EventModifyObject{DatetimeStart: &datetimeStart})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(requests))
+		assert.Equal(t, "/me/events", requests[0].Path)
+		assert.Contains(t, requests[0].Query, "filter=")
+		assert.Equal(t, "/me/events/graph-event-3", requests[1].Path)
+		assert.Equal(t, `{"start":{"dateTime":"2023-03-06T17:00:00","timeZone":"UTC"}}`, requests[1].Body)
+	})
+}
+
+func TestOutlookDeleteEvent(t *testing.T) {
+	requests := []outlookTestRequest{}
+	server := getOutlookTestServer(t, &requests)
+	defer server.Close()
+	source := getOutlookTestSource(server.URL)
+
+	t.Run("Success", func(t *testing.T) {
+		err := source.DeleteEvent(nil, primitive.NewObjectID(), "user@example.com", "graph-event-1", "default", EventScopeThis)
+		assert.NoError(t, err)
+		assert.Equal(t, "DELETE", requests[len(requests)-1].Method)
+		assert.Equal(t, "/me/events/graph-event-1", requests[len(requests)-1].Path)
+	})
+	t.Run("ExternalError", func(t *testing.T) {
+		err := source.DeleteEvent(nil, primitive.NewObjectID(), "user@example.com", "missing", "default", EventScopeThis)
+		assert.EqualError(t, err, "graph request failed with status 404: ErrorItemNotFound not found")
+	})
+}
+
+func TestOutlookRespondToEvent(t *testing.T) {
+	requests := []outlookTestRequest{}
+	server := getOutlookTestServer(t, &requests)
+	defer server.Close()
+	source := getOutlookTestSource(server.URL)
+
+	t.Run("Success", func(t *testing.T) {
+		err := source.RespondToEvent(nil, primitive.NewObjectID(), "user@example.com", "graph-event-1", "default", constants.EventResponseTentative)
+		assert.NoError(t, err)
+		assert.Equal(t, "POST", requests[len(requests)-1].Method)
+		assert.Equal(t, "/me/events/graph-event-1/tentativelyAccept", requests[len(requests)-1].Path)
+		assert.Equal(t, `{"sendResponse":true}`, requests[len(requests)-1].Body)
+	})
+	t.Run("UnsupportedResponse", func(t *testing.T) {
+		err := source.RespondToEvent(nil, primitive.NewObjectID(), "user@example.com", "graph-event-1", "default", constants.EventResponseNeedsAction)
+		assert.EqualError(t, err, "unsupported outlook event response: needsAction")
+	})
+}
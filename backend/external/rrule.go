@@ -0,0 +1,464 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RecurrenceFrequencyDaily   = "DAILY"
+	RecurrenceFrequencyWeekly  = "WEEKLY"
+	RecurrenceFrequencyMonthly = "MONTHLY"
+	RecurrenceFrequencyYearly  = "YEARLY"
+	// stops expansion of rules which can never match, e.g. BYMONTH=2;BYMONTHDAY=30
+	recurrenceMaxEmptyPeriods = 1000
+)
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceWeekday is a BYDAY value, e.g. TU, or -1FR for the last Friday of the period
+type RecurrenceWeekday struct {
+	Weekday time.Weekday
+	Ordinal int
+}
+
+// RecurrenceRule is a parsed RFC 5545 RRULE. Sub-daily frequencies and the
+// BYYEARDAY, BYWEEKNO, BYHOUR, BYMINUTE and BYSECOND parts are not supported.
+type RecurrenceRule struct {
+	Frequency  string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// ParseRRule parses the value of an RRULE property, with or without the "RRULE:" prefix
+func ParseRRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), ICalPropertyRecurrence+":")
+	if rule == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+	result := &RecurrenceRule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part: %s", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			result.Frequency = strings.ToUpper(value)
+			if result.Frequency != RecurrenceFrequencyDaily && result.Frequency != RecurrenceFrequencyWeekly && result.Frequency != RecurrenceFrequencyMonthly && result.Frequency != RecurrenceFrequencyYearly {
+				return nil, fmt.Errorf("unsupported recurrence frequency: %s", value)
+			}
+		case "INTERVAL":
+			result.Interval, err = strconv.Atoi(value)
+			if err != nil || result.Interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval: %s", value)
+			}
+		case "COUNT":
+			result.Count, err = strconv.Atoi(value)
+			if err != nil || result.Count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count: %s", value)
+			}
+		case "UNTIL":
+			until, _, err := ParseICalTime(&ICalProperty{Value: value}, time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recurrence until: %s", value)
+			}
+			result.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, err := parseRecurrenceWeekday(day)
+				if err != nil {
+					return nil, err
+				}
+				result.ByDay = append(result.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			result.ByMonthDay, err = parseRecurrenceInts(value, -31, 31)
+		case "BYMONTH":
+			result.ByMonth, err = parseRecurrenceInts(value, 1, 12)
+		case "BYSETPOS":
+			result.BySetPos, err = parseRecurrenceInts(value, -366, 366)
+		case "WKST":
+			weekStart, exists := recurrenceWeekdays[strings.ToUpper(value)]
+			if !exists {
+				return nil, fmt.Errorf("invalid recurrence week start: %s", value)
+			}
+			result.WeekStart = weekStart
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part: %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result.Frequency == "" {
+		return nil, errors.New("recurrence rule is missing FREQ")
+	}
+	if result.Count > 0 && result.Until != nil {
+		return nil, errors.New("recurrence rule cannot have both COUNT and UNTIL")
+	}
+	for _, weekday := range result.ByDay {
+		// ordinals only make sense when the period contains more than one of each weekday
+		if weekday.Ordinal != 0 && result.Frequency != RecurrenceFrequencyMonthly && result.Frequency != RecurrenceFrequencyYearly {
+			return nil, errors.New("recurrence BYDAY ordinals are only valid for monthly and yearly rules")
+		}
+	}
+	if len(result.ByMonthDay) > 0 && result.Frequency == RecurrenceFrequencyWeekly {
+		return nil, errors.New("recurrence BYMONTHDAY is not valid for weekly rules")
+	}
+	return result, nil
+}
+
+func parseRecurrenceWeekday(day string) (RecurrenceWeekday, error) {
+	day = strings.ToUpper(strings.TrimSpace(day))
+	if len(day) < 2 {
+		return RecurrenceWeekday{}, fmt.Errorf("invalid recurrence weekday: %s", day)
+	}
+	weekday, exists := recurrenceWeekdays[day[len(day)-2:]]
+	if !exists {
+		return RecurrenceWeekday{}, fmt.Errorf("invalid recurrence weekday: %s", day)
+	}
+	ordinal := 0
+	if len(day) > 2 {
+		var err error
+		ordinal, err = strconv.Atoi(day[:len(day)-2])
+		if err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+			return RecurrenceWeekday{}, fmt.Errorf("invalid recurrence weekday: %s", day)
+		}
+	}
+	return RecurrenceWeekday{Weekday: weekday, Ordinal: ordinal}, nil
+}
+
+func parseRecurrenceInts(value string, min int, max int) ([]int, error) {
+	result := []int{}
+	for _, part := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || number == 0 || number < min || number > max {
+			return nil, fmt.Errorf("invalid recurrence value: %s", part)
+		}
+		result = append(result, number)
+	}
+	return result, nil
+}
+
+func (rule *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + rule.Frequency}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	if rule.Until != nil {
+		parts = append(parts, "UNTIL="+FormatICalDateTime(*rule.Until))
+	}
+	if len(rule.ByDay) > 0 {
+		days := []string{}
+		for _, weekday := range rule.ByDay {
+			day := getRecurrenceWeekdayName(weekday.Weekday)
+			if weekday.Ordinal != 0 {
+				day = strconv.Itoa(weekday.Ordinal) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(rule.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinRecurrenceInts(rule.ByMonthDay))
+	}
+	if len(rule.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinRecurrenceInts(rule.ByMonth))
+	}
+	if len(rule.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinRecurrenceInts(rule.BySetPos))
+	}
+	if rule.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+getRecurrenceWeekdayName(rule.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func getRecurrenceWeekdayName(weekday time.Weekday) string {
+	for name, value := range recurrenceWeekdays {
+		if value == weekday {
+			return name
+		}
+	}
+	return ""
+}
+
+func joinRecurrenceInts(values []int) string {
+	parts := []string{}
+	for _, value := range values {
+		parts = append(parts, strconv.Itoa(value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// RecurrenceExceptionDate is an EXDATE value. All day dates exclude any occurrence on that local date.
+type RecurrenceExceptionDate struct {
+	Time   time.Time
+	IsDate bool
+}
+
+// ParseRecurrenceExceptionDates parses EXDATE values, which may be comma separated, e.g. "20230418,20230425T090000Z".
+// Floating times are interpreted in the given location.
+func ParseRecurrenceExceptionDates(values []string, location *time.Location) ([]RecurrenceExceptionDate, error) {
+	exdates := []RecurrenceExceptionDate{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			exdate, isDate, err := ParseICalTime(&ICalProperty{Value: strings.TrimSpace(part)}, location)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recurrence exception date: %s", part)
+			}
+			exdates = append(exdates, RecurrenceExceptionDate{Time: exdate, IsDate: isDate})
+		}
+	}
+	return exdates, nil
+}
+
+// Between returns the occurrences of the rule starting at dtstart which fall in [after, before), skipping exdates.
+// The time of day and location of each occurrence come from dtstart, so occurrences keep the same local
+// time across daylight saving changes.
+func (rule *RecurrenceRule) Between(dtstart time.Time, after time.Time, before time.Time, exdates []RecurrenceExceptionDate) []time.Time {
+	occurrences := []time.Time{}
+	count := 0
+	emptyPeriods := 0
+	for periodStart := rule.getPeriodStart(dtstart); ; periodStart = rule.getNextPeriodStart(periodStart) {
+		candidates := rule.getPeriodOccurrences(periodStart, dtstart)
+		if len(candidates) == 0 {
+			emptyPeriods += 1
+			if emptyPeriods > recurrenceMaxEmptyPeriods {
+				return occurrences
+			}
+			continue
+		}
+		emptyPeriods = 0
+		for _, candidate := range candidates {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			if rule.Until != nil && candidate.After(*rule.Until) {
+				return occurrences
+			}
+			if !candidate.Before(before) {
+				return occurrences
+			}
+			// excluded dates still count towards COUNT
+			count += 1
+			if !candidate.Before(after) && !isRecurrenceExcluded(candidate, exdates) {
+				occurrences = append(occurrences, candidate)
+			}
+			if rule.Count > 0 && count >= rule.Count {
+				return occurrences
+			}
+		}
+	}
+}
+
+func isRecurrenceExcluded(occurrence time.Time, exdates []RecurrenceExceptionDate) bool {
+	for _, exdate := range exdates {
+		if exdate.IsDate {
+			if exdate.Time.Year() == occurrence.Year() && exdate.Time.Month() == occurrence.Month() && exdate.Time.Day() == occurrence.Day() {
+				return true
+			}
+		} else if exdate.Time.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *RecurrenceRule) getPeriodStart(dtstart time.Time) time.Time {
+	date := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+	switch rule.Frequency {
+	case RecurrenceFrequencyWeekly:
+		offset := (int(date.Weekday()) - int(rule.WeekStart) + 7) % 7
+		return date.AddDate(0, 0, -offset)
+	case RecurrenceFrequencyMonthly:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case RecurrenceFrequencyYearly:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date
+}
+
+func (rule *RecurrenceRule) getNextPeriodStart(periodStart time.Time) time.Time {
+	switch rule.Frequency {
+	case RecurrenceFrequencyWeekly:
+		return periodStart.AddDate(0, 0, 7*rule.Interval)
+	case RecurrenceFrequencyMonthly:
+		return periodStart.AddDate(0, rule.Interval, 0)
+	case RecurrenceFrequencyYearly:
+		return periodStart.AddDate(rule.Interval, 0, 0)
+	}
+	return periodStart.AddDate(0, 0, rule.Interval)
+}
+
+// getPeriodOccurrences returns the sorted occurrences within the period, with BYSETPOS applied.
+// Dates are calculated in UTC and then moved to the time of day and location of dtstart.
+func (rule *RecurrenceRule) getPeriodOccurrences(periodStart time.Time, dtstart time.Time) []time.Time {
+	dates := []time.Time{}
+	switch rule.Frequency {
+	case RecurrenceFrequencyDaily:
+		dates = append(dates, periodStart)
+	case RecurrenceFrequencyWeekly:
+		for i := 0; i < 7; i++ {
+			date := periodStart.AddDate(0, 0, i)
+			if len(rule.ByDay) > 0 || date.Weekday() == dtstart.Weekday() {
+				dates = append(dates, date)
+			}
+		}
+	case RecurrenceFrequencyMonthly:
+		dates = rule.getMonthDates(periodStart.Year(), periodStart.Month(), dtstart)
+	case RecurrenceFrequencyYearly:
+		if len(rule.ByMonth) > 0 || len(rule.ByMonthDay) > 0 {
+			for month := time.January; month <= time.December; month++ {
+				dates = append(dates, rule.getMonthDates(periodStart.Year(), month, dtstart)...)
+			}
+		} else if len(rule.ByDay) > 0 {
+			dates = getRecurrenceWeekdayDates(periodStart, periodStart.AddDate(1, 0, 0), rule.ByDay)
+		} else {
+			date := time.Date(periodStart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+			// February 29 is skipped rather than rolled over in years without it
+			if date.Month() == dtstart.Month() {
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	filteredDates := []time.Time{}
+	for _, date := range dates {
+		if !rule.matchesFilters(date) {
+			continue
+		}
+		filteredDates = append(filteredDates, date)
+	}
+	sort.Slice(filteredDates, func(i, j int) bool { return filteredDates[i].Before(filteredDates[j]) })
+	filteredDates = applyRecurrenceSetPos(filteredDates, rule.BySetPos)
+
+	occurrences := []time.Time{}
+	for _, date := range filteredDates {
+		occurrences = append(occurrences, time.Date(date.Year(), date.Month(), date.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location()))
+	}
+	return occurrences
+}
+
+func (rule *RecurrenceRule) getMonthDates(year int, month time.Month, dtstart time.Time) []time.Time {
+	if len(rule.ByMonth) > 0 && !containsRecurrenceInt(rule.ByMonth, int(month)) {
+		return []time.Time{}
+	}
+	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	daysInMonth := monthEnd.AddDate(0, 0, -1).Day()
+
+	if len(rule.ByDay) > 0 {
+		// BYMONTHDAY further limits the matching weekdays in matchesFilters
+		return getRecurrenceWeekdayDates(monthStart, monthEnd, rule.ByDay)
+	}
+	monthDays := rule.ByMonthDay
+	if len(monthDays) == 0 {
+		monthDays = []int{dtstart.Day()}
+	}
+	dates := []time.Time{}
+	for _, monthDay := range monthDays {
+		// days which don't exist in the month (e.g. the 31st of April) are skipped rather than rolled over
+		if monthDay < 0 {
+			monthDay = daysInMonth + monthDay + 1
+		}
+		if monthDay < 1 || monthDay > daysInMonth {
+			continue
+		}
+		dates = append(dates, time.Date(year, month, monthDay, 0, 0, 0, 0, time.UTC))
+	}
+	return dates
+}
+
+// getRecurrenceWeekdayDates returns the dates in [start, end) matching the BYDAY values, where ordinals count within the range
+func getRecurrenceWeekdayDates(start time.Time, end time.Time, byDay []RecurrenceWeekday) []time.Time {
+	dates := []time.Time{}
+	for _, weekday := range byDay {
+		matchingDates := []time.Time{}
+		for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+			if date.Weekday() == weekday.Weekday {
+				matchingDates = append(matchingDates, date)
+			}
+		}
+		if weekday.Ordinal == 0 {
+			dates = append(dates, matchingDates...)
+		} else if weekday.Ordinal > 0 && weekday.Ordinal <= len(matchingDates) {
+			dates = append(dates, matchingDates[weekday.Ordinal-1])
+		} else if weekday.Ordinal < 0 && -weekday.Ordinal <= len(matchingDates) {
+			dates = append(dates, matchingDates[len(matchingDates)+weekday.Ordinal])
+		}
+	}
+	return dates
+}
+
+func (rule *RecurrenceRule) matchesFilters(date time.Time) bool {
+	if len(rule.ByMonth) > 0 && !containsRecurrenceInt(rule.ByMonth, int(date.Month())) {
+		return false
+	}
+	if len(rule.ByMonthDay) > 0 {
+		daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if !containsRecurrenceInt(rule.ByMonthDay, date.Day()) && !containsRecurrenceInt(rule.ByMonthDay, date.Day()-daysInMonth-1) {
+			return false
+		}
+	}
+	if len(rule.ByDay) > 0 {
+		matchesWeekday := false
+		for _, weekday := range rule.ByDay {
+			if weekday.Weekday == date.Weekday() {
+				matchesWeekday = true
+			}
+		}
+		if !matchesWeekday {
+			return false
+		}
+	}
+	return true
+}
+
+func applyRecurrenceSetPos(dates []time.Time, bySetPos []int) []time.Time {
+	if len(bySetPos) == 0 {
+		return dates
+	}
+	result := []time.Time{}
+	for idx, date := range dates {
+		for _, position := range bySetPos {
+			if position == idx+1 || position == idx-len(dates) {
+				result = append(result, date)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func containsRecurrenceInt(values []int, value int) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package external
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRRule(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		rule, err := ParseRRule("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=MO,-1FR;BYSETPOS=-1;WKST=SU")
+		assert.NoError(t, err)
+		assert.Equal(t, &RecurrenceRule{
+			Frequency: RecurrenceFrequencyMonthly,
+			Interval:  2,
+			ByDay:     []RecurrenceWeekday{{Weekday: time.Monday}, {Weekday: time.Friday, Ordinal: -1}},
+			BySetPos:  []int{-1},
+			WeekStart: time.Sunday,
+		}, rule)
+		assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;BYDAY=MO,-1FR;BYSETPOS=-1;WKST=SU", rule.String())
+	})
+	t.Run("Until", func(t *testing.T) {
+		rule, err := ParseRRule("FREQ=DAILY;UNTIL=20230420T090000Z")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, time.April, 20, 9, 0, 0, 0, time.UTC), *rule.Until)
+		assert.Equal(t, "FREQ=DAILY;UNTIL=20230420T090000Z", rule.String())
+	})
+	t.Run("Errors", func(t *testing.T) {
+		for rule, expectedError := range map[string]string{
+			"":                                  "recurrence rule is empty",
+			"INTERVAL=2":                        "recurrence rule is missing FREQ",
+			"FREQ=HOURLY":                       "unsupported recurrence frequency: HOURLY",
+			"FREQ=DAILY;BYHOUR=9":               "unsupported recurrence rule part: BYHOUR",
+			"FREQ=DAILY;INTERVAL=0":             "invalid recurrence interval: 0",
+			"FREQ=DAILY;COUNT":                  "invalid recurrence rule part: COUNT",
+			"FREQ=DAILY;COUNT=2;UNTIL=20230420": "recurrence rule cannot have both COUNT and UNTIL",
+			"FREQ=WEEKLY;BYDAY=XX":              "invalid recurrence weekday: XX",
+			"FREQ=WEEKLY;BYDAY=2TU":             "recurrence BYDAY ordinals are only valid for monthly and yearly rules",
+			"FREQ=WEEKLY;BYMONTHDAY=1":          "recurrence BYMONTHDAY is not valid for weekly rules",
+			"FREQ=MONTHLY;BYMONTHDAY=32":        "invalid recurrence value: 32",
+			"FREQ=YEARLY;BYMONTH=13":            "invalid recurrence value: 13",
+		} {
+			_, err := ParseRRule(rule)
+			assert.EqualError(t, err, expectedError, rule)
+		}
+	})
+}
+
+func TestRecurrenceRuleBetween(t *testing.T) {
+	getOccurrences := func(t *testing.T, rule string, dtstart time.Time, after time.Time, before time.Time) []time.Time {
+		recurrenceRule, err := ParseRRule(rule)
+		assert.NoError(t, err)
+		return recurrenceRule.Between(dtstart, after, before, nil)
+	}
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Daily", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=DAILY", date(2023, time.April, 1), date(2023, time.April, 3), date(2023, time.April, 6))
+		assert.Equal(t, []time.Time{date(2023, time.April, 3), date(2023, time.April, 4), date(2023, time.April, 5)}, occurrences)
+	})
+	t.Run("EveryOtherTuesday", func(t *testing.T) {
+		// Saturday April 1st, so the first week has no Tuesday after the start
+		occurrences := getOccurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.May, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 11), date(2023, time.April, 25)}, occurrences)
+	})
+	t.Run("WeeklyDefaultsToStartWeekday", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=WEEKLY;COUNT=3", date(2023, time.April, 5), date(2023, time.April, 1), date(2024, time.April, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 5), date(2023, time.April, 12), date(2023, time.April, 19)}, occurrences)
+	})
+	t.Run("LastBusinessDayOfMonth", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.July, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 28), date(2023, time.May, 31), date(2023, time.June, 30)}, occurrences)
+	})
+	t.Run("FirstAndFifteenth", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=MONTHLY;BYMONTHDAY=1,15", date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.June, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 1), date(2023, time.April, 15), date(2023, time.May, 1), date(2023, time.May, 15)}, occurrences)
+	})
+	t.Run("LastDayOfMonth", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=MONTHLY;BYMONTHDAY=-1", date(2023, time.January, 1), date(2023, time.January, 1), date(2023, time.April, 1))
+		assert.Equal(t, []time.Time{date(2023, time.January, 31), date(2023, time.February, 28), date(2023, time.March, 31)}, occurrences)
+	})
+	t.Run("MonthlySkipsMissingDays", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=MONTHLY", date(2023, time.January, 31), date(2023, time.January, 1), date(2023, time.May, 1))
+		assert.Equal(t, []time.Time{date(2023, time.January, 31), date(2023, time.March, 31)}, occurrences)
+	})
+	t.Run("SecondTuesdayOfMonth", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=MONTHLY;BYDAY=2TU", date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.June, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 11), date(2023, time.May, 9)}, occurrences)
+	})
+	t.Run("YearlyLeapDay", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=YEARLY", date(2020, time.February, 29), date(2020, time.January, 1), date(2025, time.January, 1))
+		assert.Equal(t, []time.Time{date(2020, time.February, 29), date(2024, time.February, 29)}, occurrences)
+	})
+	t.Run("YearlyByMonth", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", date(2022, time.January, 1), date(2022, time.January, 1), date(2024, time.January, 1))
+		assert.Equal(t, []time.Time{date(2022, time.November, 24), date(2023, time.November, 23)}, occurrences)
+	})
+	t.Run("Until", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=DAILY;UNTIL=20230403T090000Z", date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.May, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 1), date(2023, time.April, 2), date(2023, time.April, 3)}, occurrences)
+	})
+	t.Run("CountIncludesOccurrencesBeforeWindow", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=DAILY;COUNT=3", date(2023, time.April, 1), date(2023, time.April, 3), date(2023, time.May, 1))
+		assert.Equal(t, []time.Time{date(2023, time.April, 3)}, occurrences)
+	})
+	t.Run("NeverMatches", func(t *testing.T) {
+		occurrences := getOccurrences(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", date(2023, time.April, 1), date(2023, time.April, 1), date(2100, time.January, 1))
+		assert.Equal(t, []time.Time{}, occurrences)
+	})
+	t.Run("DaylightSavingTime", func(t *testing.T) {
+		losAngeles, err := time.LoadLocation("America/Los_Angeles")
+		assert.NoError(t, err)
+		dtstart := time.Date(2023, time.March, 11, 9, 0, 0, 0, losAngeles)
+		occurrences := getOccurrences(t, "FREQ=DAILY", dtstart, dtstart, dtstart.AddDate(0, 0, 2))
+		assert.Equal(t, 2, len(occurrences))
+		assert.Equal(t, 9, occurrences[1].Hour())
+		assert.Equal(t, 23*time.Hour, occurrences[1].Sub(occurrences[0]))
+	})
+	t.Run("ExceptionDates", func(t *testing.T) {
+		rule, err := ParseRRule("FREQ=DAILY")
+		assert.NoError(t, err)
+		exdates, err := ParseRecurrenceExceptionDates([]string{"20230402,20230404T090000Z"}, time.UTC)
+		assert.NoError(t, err)
+		occurrences := rule.Between(date(2023, time.April, 1), date(2023, time.April, 1), date(2023, time.April, 6), exdates)
+		assert.Equal(t, []time.Time{date(2023, time.April, 1), date(2023, time.April, 3), date(2023, time.April, 5)}, occurrences)
+
+		_, err = ParseRecurrenceExceptionDates([]string{"tomorrow"}, time.UTC)
+		assert.EqualError(t, err, "invalid recurrence exception date: tomorrow")
+	})
+}
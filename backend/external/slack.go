@@ -57,7 +57,7 @@ func GetSlackAppConfig() SlackConfig {
 			ClientID:     config.GetConfigValue("SLACK_OAUTH_CLIENT_ID"),
 			ClientSecret: config.GetConfigValue("SLACK_OAUTH_CLIENT_SECRET"),
 			RedirectURL:  config.GetConfigValue("SERVER_URL") + "link_app/slack/",
-			Scopes:       []string{"commands", "users:read"},
+			Scopes:       []string{"commands", "users:read", "chat:write"},
 			Endpoint: oauth2.Endpoint{
 				AuthURL:  "https://slack.com/oauth/authorize",
 				TokenURL: "https://slack.com/api/oauth.v2.access",
@@ -0,0 +1,93 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/oauth2"
+)
+
+var ErrSlackAppNotInstalled = errors.New("slack app is not installed for any of the user's slack accounts")
+
+// SaveSlackAppToken stores the app's bot token for the workspace it was installed in.
+// The app is installed per workspace, so the token isn't tied to a single user.
+func SaveSlackAppToken(db *mongo.Database, token *oauth2.Token) error {
+	team, _ := token.Extra("team").(map[string]interface{})
+	teamID, _ := team["id"].(string)
+	if teamID == "" {
+		return errors.New("slack app token is missing team")
+	}
+	teamName, _ := team["name"].(string)
+	tokenString, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	_, err = database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"service_id": TASK_SERVICE_ID_SLACK_APP}, {"account_id": teamID}}},
+		bson.M{"$set": &database.ExternalAPIToken{
+			ServiceID: TASK_SERVICE_ID_SLACK_APP,
+			Token:     string(tokenString),
+			AccountID: teamID,
+			DisplayID: teamName,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// SendDirectMessage messages the user from the Slack app in every workspace where they have linked Slack and the app is installed
+func (slackService SlackService) SendDirectMessage(db *mongo.Database, userID primitive.ObjectID, text string) error {
+	tokens, err := database.GetExternalTokens(db, userID, TASK_SERVICE_ID_SLACK)
+	if err != nil {
+		return err
+	}
+	sent := false
+	for _, token := range *tokens {
+		// account IDs are generated by GenerateSlackUserID
+		teamID, slackUserID, found := strings.Cut(token.AccountID, "-")
+		if !found {
+			continue
+		}
+		var appToken database.ExternalAPIToken
+		err := database.GetExternalTokenCollection(db).FindOne(
+			context.Background(),
+			bson.M{"$and": []bson.M{{"service_id": TASK_SERVICE_ID_SLACK_APP}, {"account_id": teamID}}},
+		).Decode(&appToken)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var oauthToken oauth2.Token
+		err = json.Unmarshal([]byte(appToken.Token), &oauthToken)
+		if err != nil {
+			return err
+		}
+
+		client := slack.New(oauthToken.AccessToken)
+		if slackService.Config.ConfigValues.OverrideURL != nil {
+			client = slack.New(oauthToken.AccessToken, slack.OptionAPIURL(*slackService.Config.ConfigValues.OverrideURL))
+		}
+		// posting to a user ID sends the message to the app's DM with that user
+		_, _, err = client.PostMessage(slackUserID, slack.MsgOptionText(text, false))
+		if err != nil {
+			return err
+		}
+		sent = true
+	}
+	if !sent {
+		return ErrSlackAppNotInstalled
+	}
+	return nil
+}
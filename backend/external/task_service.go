@@ -16,4 +16,17 @@ type CallbackParams struct {
 	Oauth1Token    *string
 	Oauth1Verifier *string
 	Oauth2Code     *string
+	BasicAuth      *BasicAuthParams
+	FeedURL        *FeedURLParams
+}
+
+type FeedURLParams struct {
+	URL  string
+	Name string
+}
+
+type BasicAuthParams struct {
+	ServerURL string
+	Username  string
+	Password  string
 }
@@ -5,10 +5,18 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/jjPlusPlus/task-manager/backend/constants"
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// which events of a recurring series a modify or delete applies to
+const (
+	EventScopeThis             = "this"
+	EventScopeThisAndFollowing = "this_and_following"
+	EventScopeAll              = "all"
+)
+
 type TaskSource interface {
 	GetEvents(db *mongo.Database, userID primitive.ObjectID, accountID string, startTime time.Time, endTime time.Time, scopes []string, result chan<- CalendarResult)
 	GetTasks(db *mongo.Database, userID primitive.ObjectID, accountID string, result chan<- TaskResult)
@@ -17,7 +25,8 @@ type TaskSource interface {
 	ModifyTask(db *mongo.Database, userID primitive.ObjectID, accountID string, issueID string, updateFields *database.Task, task *database.Task) error
 	CreateNewEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, event EventCreateObject) error
 	ModifyEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, eventID string, updateFields *EventModifyObject) error
-	DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string) error
+	DeleteEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, scope string) error
+	RespondToEvent(db *mongo.Database, userID primitive.ObjectID, accountID string, externalID string, calendarID string, response string) error
 	AddComment(db *mongo.Database, userID primitive.ObjectID, accountID string, comment database.Comment, task *database.Task) error
 }
 
@@ -29,6 +38,10 @@ type TaskCreationObject struct {
 	IDTaskSection      primitive.ObjectID
 	ParentTaskID       primitive.ObjectID
 	SlackMessageParams database.SlackMessageParams
+	JIRAParams         *JIRATaskCreationParams
+	LinearParams       *LinearTaskCreationParams
+	AsanaParams        *AsanaTaskCreationParams
+	CalDAVParams       *CalDAVTaskCreationParams
 }
 
 type Attendee struct {
@@ -51,6 +64,8 @@ type EventCreateObject struct {
 	LinkedTaskID        primitive.ObjectID `json:"task_id,omitempty"`
 	LinkedViewID        primitive.ObjectID `json:"view_id,omitempty"`
 	LinkedPullRequestID primitive.ObjectID `json:"pr_id,omitempty"`
+	// RRULE, RDATE and EXDATE lines, which make the event recurring
+	Recurrence []string `json:"recurrence,omitempty"`
 }
 
 type EventModifyObject struct {
@@ -64,4 +79,62 @@ type EventModifyObject struct {
 	DatetimeEnd       *time.Time  `json:"datetime_end"`
 	Attendees         *[]Attendee `json:"attendees"`
 	AddConferenceCall *bool       `json:"add_conference_call"`
+	// one of the EventScope values, this by default
+	Scope string `json:"scope"`
+}
+
+func IsValidEventScope(scope string) bool {
+	return scope == "" || scope == EventScopeThis || scope == EventScopeThisAndFollowing || scope == EventScopeAll
+}
+
+func IsSingleEventScope(scope string) bool {
+	return scope == "" || scope == EventScopeThis
+}
+
+// saves a task just created in an external source, so it shows up before the next refresh fills in the rest
+func insertCreatedExternalTask(db *mongo.Database, userID primitive.ObjectID, accountID string, sourceID string, idExternal string, deeplink string, task TaskCreationObject, priorityNormalized *float64) (primitive.ObjectID, error) {
+	taskSection := constants.IDTaskSectionDefault
+	if task.IDTaskSection != primitive.NilObjectID {
+		taskSection = task.IDTaskSection
+	}
+	completed := false
+	deleted := false
+	newTask := database.Task{
+		UserID:             userID,
+		IDExternal:         idExternal,
+		IDTaskSection:      taskSection,
+		Deeplink:           deeplink,
+		SourceID:           sourceID,
+		Title:              &task.Title,
+		Body:               &task.Body,
+		SourceAccountID:    accountID,
+		IsCompleted:        &completed,
+		IsDeleted:          &deleted,
+		PriorityNormalized: priorityNormalized,
+		CreatedAtExternal:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if task.DueDate != nil {
+		dueDate := primitive.NewDateTimeFromTime(*task.DueDate)
+		newTask.DueDate = &dueDate
+	}
+	if task.TimeAllocation != nil {
+		newTask.TimeAllocation = task.TimeAllocation
+	}
+	if task.ParentTaskID != primitive.NilObjectID {
+		newTask.ParentTaskID = task.ParentTaskID
+	}
+
+	dbTask, err := database.UpdateOrCreateTask(
+		db,
+		userID,
+		idExternal,
+		sourceID,
+		newTask,
+		database.Task{UpdatedAt: primitive.NewDateTimeFromTime(time.Now())},
+		nil,
+	)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return dbTask.ID, nil
 }
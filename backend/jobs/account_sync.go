@@ -0,0 +1,319 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	lock "github.com/square/mongo-lock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const ACCOUNT_SYNC_INTERVAL = 15 * time.Minute
+const ACCOUNT_SYNC_EVENT_LOOKAHEAD_DAYS = 7
+const BAD_TOKEN_BASE_BACKOFF = time.Hour
+const BAD_TOKEN_MAX_BACKOFF = 24 * time.Hour
+
+type accountSyncFetch struct {
+	token        database.ExternalAPIToken
+	sourceID     string
+	tasks        chan external.TaskResult
+	pullRequests chan external.PullRequestResult
+	events       chan external.CalendarResult
+}
+
+func accountSyncJob() {
+	err := syncAllLinkedAccounts(external.GetConfig(), time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to run account sync job")
+		return
+	}
+}
+
+func syncAllLinkedAccounts(externalConfig external.Config, timeNow time.Time) error {
+	logger := logging.GetSentryLogger()
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	userIDs, err := getAccountSyncUserIDs(db)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		_, err := EnsureUserJobOnlyRunsOncePerInterval(db, "account_sync", userID, ACCOUNT_SYNC_INTERVAL)
+		// already locked if another server has already synced this user during this interval
+		if err == lock.ErrAlreadyLocked {
+			continue
+		} else if err != nil {
+			logger.Error().Err(err).Str("userID", userID.Hex()).Msg("failed to lock account sync")
+			continue
+		}
+		err = syncLinkedAccountsForUser(db, externalConfig, userID, timeNow)
+		if err != nil {
+			logger.Error().Err(err).Str("userID", userID.Hex()).Msg("failed to sync linked accounts")
+		}
+	}
+	return nil
+}
+
+// getAccountSyncUserIDs skips Slack app installs, as they're stored per workspace without a user
+func getAccountSyncUserIDs(db *mongo.Database) ([]primitive.ObjectID, error) {
+	rawUserIDs, err := database.GetExternalTokenCollection(db).Distinct(
+		context.Background(),
+		"user_id",
+		bson.M{
+			"service_id": bson.M{"$nin": []string{external.TASK_SERVICE_ID_GT, external.TASK_SERVICE_ID_SLACK_APP}},
+			"user_id":    bson.M{"$ne": primitive.NilObjectID},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := []primitive.ObjectID{}
+	for _, rawUserID := range rawUserIDs {
+		userID, ok := rawUserID.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func syncLinkedAccountsForUser(db *mongo.Database, externalConfig external.Config, userID primitive.ObjectID, timeNow time.Time) error {
+	logger := logging.GetSentryLogger()
+	tokens, err := database.GetAllExternalTokens(db, userID)
+	if err != nil {
+		return err
+	}
+	currentTasks, err := database.GetActiveTasks(db, userID)
+	if err != nil {
+		return err
+	}
+	currentPullRequests, err := database.GetActivePRs(db, userID)
+	if err != nil {
+		return err
+	}
+
+	eventsStart := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, time.UTC)
+	eventsEnd := eventsStart.AddDate(0, 0, ACCOUNT_SYNC_EVENT_LOOKAHEAD_DAYS)
+
+	fetches := []accountSyncFetch{}
+	for _, token := range tokens {
+		if token.ServiceID == external.TASK_SERVICE_ID_GT || !shouldSyncToken(token, timeNow) {
+			continue
+		}
+		taskServiceResult, err := externalConfig.GetTaskServiceResult(token.ServiceID)
+		if err != nil {
+			logger.Error().Err(err).Msg("error loading task service")
+			continue
+		}
+		for _, taskSourceResult := range taskServiceResult.Sources {
+			fetch := accountSyncFetch{
+				token:        token,
+				sourceID:     taskSourceResult.Details.ID,
+				pullRequests: make(chan external.PullRequestResult),
+				events:       make(chan external.CalendarResult),
+			}
+			// DO NOT REMOVE: linear rate limits are hit quickly if we refresh more often than the client does
+			if token.ServiceID != external.TASK_SERVICE_ID_LINEAR || !isLinearRecentlyRefreshed(token, timeNow) {
+				fetch.tasks = make(chan external.TaskResult)
+				go taskSourceResult.Source.GetTasks(db, userID, token.AccountID, fetch.tasks)
+			}
+			go taskSourceResult.Source.GetPullRequests(db, userID, token.AccountID, fetch.pullRequests)
+			go taskSourceResult.Source.GetEvents(db, userID, token.AccountID, eventsStart, eventsEnd, token.Scopes, fetch.events)
+			fetches = append(fetches, fetch)
+		}
+	}
+
+	fetchedTaskIDs := make(map[primitive.ObjectID]bool)
+	fetchedPullRequestIDs := make(map[primitive.ObjectID]bool)
+	syncedTaskSources := make(map[string]bool)
+	syncedPullRequestSources := make(map[string]bool)
+	tokenErrors := make(map[primitive.ObjectID]error)
+	for _, fetch := range fetches {
+		sourceKey := getAccountSyncSourceKey(fetch.sourceID, fetch.token.AccountID)
+		if fetch.tasks != nil {
+			taskResult := <-fetch.tasks
+			if taskResult.Error != nil {
+				tokenErrors[fetch.token.ID] = taskResult.Error
+			} else {
+				syncedTaskSources[sourceKey] = true
+				for _, task := range taskResult.Tasks {
+					fetchedTaskIDs[task.ID] = true
+				}
+				if fetch.token.ServiceID == external.TASK_SERVICE_ID_LINEAR {
+					err = updateLastFullRefreshTime(db, fetch.token, timeNow)
+					if err != nil {
+						logger.Error().Err(err).Msg("error updating last refresh time")
+					}
+				}
+			}
+		}
+
+		pullRequestResult := <-fetch.pullRequests
+		if pullRequestResult.Error != nil {
+			tokenErrors[fetch.token.ID] = pullRequestResult.Error
+		} else {
+			syncedPullRequestSources[sourceKey] = true
+			for _, pullRequest := range pullRequestResult.PullRequests {
+				fetchedPullRequestIDs[pullRequest.ID] = true
+			}
+		}
+
+		calendarResult := <-fetch.events
+		if calendarResult.Error != nil {
+			tokenErrors[fetch.token.ID] = calendarResult.Error
+		} else {
+			err = adjustForDeletedEvents(db, userID, fetch.sourceID, fetch.token.AccountID, calendarResult.CalendarEvents, eventsStart, eventsEnd)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to adjust for deleted events")
+			}
+		}
+	}
+
+	err = adjustForCompletedTasks(db, currentTasks, fetchedTaskIDs, syncedTaskSources)
+	if err != nil {
+		return err
+	}
+	err = adjustForCompletedPullRequests(db, currentPullRequests, fetchedPullRequestIDs, syncedPullRequestSources)
+	if err != nil {
+		return err
+	}
+
+	updatedTokenIDs := make(map[primitive.ObjectID]bool)
+	for _, fetch := range fetches {
+		if updatedTokenIDs[fetch.token.ID] {
+			continue
+		}
+		updatedTokenIDs[fetch.token.ID] = true
+		fetchErr := tokenErrors[fetch.token.ID]
+		if fetchErr != nil {
+			isBadToken := external.CheckAndHandleBadToken(fetchErr, db, userID, fetch.token.AccountID, fetch.token.ServiceID)
+			if !isBadToken && !fetch.token.IsBadToken {
+				logger.Error().Err(fetchErr).Str("serviceID", fetch.token.ServiceID).Msg("failed to sync linked account")
+			}
+		}
+		err = recordBackgroundSync(db, fetch.token, timeNow, fetchErr == nil)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to record background sync")
+		}
+	}
+	return nil
+}
+
+// bad tokens are retried with exponential backoff in case the failure was transient
+func shouldSyncToken(token database.ExternalAPIToken, timeNow time.Time) bool {
+	if !token.IsBadToken {
+		return true
+	}
+	return timeNow.Sub(token.LastBackgroundSyncTime.Time()) >= getBadTokenBackoff(token.BackgroundSyncFailures)
+}
+
+func getBadTokenBackoff(failures int) time.Duration {
+	backoff := BAD_TOKEN_BASE_BACKOFF
+	for i := 0; i < failures && backoff < BAD_TOKEN_MAX_BACKOFF; i++ {
+		backoff *= 2
+	}
+	if backoff > BAD_TOKEN_MAX_BACKOFF {
+		return BAD_TOKEN_MAX_BACKOFF
+	}
+	return backoff
+}
+
+func isLinearRecentlyRefreshed(token database.ExternalAPIToken, timeNow time.Time) bool {
+	return timeNow.Sub(token.LastFullRefreshTime.Time()) < ACCOUNT_SYNC_INTERVAL
+}
+
+func getAccountSyncSourceKey(sourceID string, accountID string) string {
+	return sourceID + "_" + accountID
+}
+
+func adjustForCompletedTasks(db *mongo.Database, currentTasks *[]database.Task, fetchedTaskIDs map[primitive.ObjectID]bool, syncedTaskSources map[string]bool) error {
+	for _, currentTask := range *currentTasks {
+		if currentTask.SourceID == external.TASK_SOURCE_ID_GT_TASK || currentTask.IsMeetingPreparationTask {
+			continue
+		}
+		if !syncedTaskSources[getAccountSyncSourceKey(currentTask.SourceID, currentTask.SourceAccountID)] || fetchedTaskIDs[currentTask.ID] {
+			continue
+		}
+		err := database.MarkCompleteWithCollection(database.GetTaskCollection(db), currentTask.ID)
+		if err != nil {
+			return err
+		}
+		database.PublishChange(db, currentTask.UserID, database.ChangeTypeTask, currentTask.ID)
+	}
+	return nil
+}
+
+func adjustForCompletedPullRequests(db *mongo.Database, currentPullRequests *[]database.PullRequest, fetchedPullRequestIDs map[primitive.ObjectID]bool, syncedPullRequestSources map[string]bool) error {
+	for _, currentPullRequest := range *currentPullRequests {
+		if !syncedPullRequestSources[getAccountSyncSourceKey(currentPullRequest.SourceID, currentPullRequest.SourceAccountID)] || fetchedPullRequestIDs[currentPullRequest.ID] {
+			continue
+		}
+		err := database.MarkCompleteWithCollection(database.GetPullRequestCollection(db), currentPullRequest.ID)
+		if err != nil {
+			return err
+		}
+		database.PublishChange(db, currentPullRequest.UserID, database.ChangeTypePullRequest, currentPullRequest.ID)
+	}
+	return nil
+}
+
+func adjustForDeletedEvents(db *mongo.Database, userID primitive.ObjectID, sourceID string, accountID string, fetchedEvents []*database.CalendarEvent, datetimeStart time.Time, datetimeEnd time.Time) error {
+	// matches the events endpoint, which leaves existing events alone when nothing is returned
+	if len(fetchedEvents) == 0 {
+		return nil
+	}
+	fetchedEventIDs := []primitive.ObjectID{}
+	for _, event := range fetchedEvents {
+		fetchedEventIDs = append(fetchedEventIDs, event.ID)
+	}
+	_, err := database.GetCalendarEventCollection(db).DeleteMany(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"user_id": userID},
+			{"source_id": sourceID},
+			{"source_account_id": accountID},
+			{"datetime_end": bson.M{"$gte": datetimeStart}},
+			{"datetime_start": bson.M{"$lte": datetimeEnd}},
+			{"_id": bson.M{"$nin": fetchedEventIDs}},
+		}},
+	)
+	return err
+}
+
+func updateLastFullRefreshTime(db *mongo.Database, token database.ExternalAPIToken, timeNow time.Time) error {
+	_, err := database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"_id": token.ID},
+		bson.M{"$set": bson.M{"last_full_refresh_time": primitive.NewDateTimeFromTime(timeNow)}},
+	)
+	return err
+}
+
+func recordBackgroundSync(db *mongo.Database, token database.ExternalAPIToken, timeNow time.Time, success bool) error {
+	update := bson.M{"$set": bson.M{
+		"last_background_sync_time": primitive.NewDateTimeFromTime(timeNow),
+		"background_sync_failures":  0,
+	}}
+	if !success {
+		update = bson.M{
+			"$set": bson.M{"last_background_sync_time": primitive.NewDateTimeFromTime(timeNow)},
+			"$inc": bson.M{"background_sync_failures": 1},
+		}
+	}
+	_, err := database.GetExternalTokenCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"_id": token.ID},
+		update,
+	)
+	return err
+}
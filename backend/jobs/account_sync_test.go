@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShouldSyncToken(t *testing.T) {
+	nowTime, _ := time.Parse(time.RFC3339, "2023-04-20T19:01:12Z")
+	t.Run("GoodToken", func(t *testing.T) {
+		token := database.ExternalAPIToken{LastBackgroundSyncTime: primitive.NewDateTimeFromTime(nowTime)}
+		assert.True(t, shouldSyncToken(token, nowTime))
+	})
+	t.Run("BadTokenWithinBackoff", func(t *testing.T) {
+		token := database.ExternalAPIToken{
+			IsBadToken:             true,
+			BackgroundSyncFailures: 2,
+			LastBackgroundSyncTime: primitive.NewDateTimeFromTime(nowTime.Add(-3 * time.Hour)),
+		}
+		assert.False(t, shouldSyncToken(token, nowTime))
+	})
+	t.Run("BadTokenAfterBackoff", func(t *testing.T) {
+		token := database.ExternalAPIToken{
+			IsBadToken:             true,
+			BackgroundSyncFailures: 2,
+			LastBackgroundSyncTime: primitive.NewDateTimeFromTime(nowTime.Add(-4 * time.Hour)),
+		}
+		assert.True(t, shouldSyncToken(token, nowTime))
+	})
+	t.Run("BackoffIsCapped", func(t *testing.T) {
+		assert.Equal(t, time.Hour, getBadTokenBackoff(0))
+		assert.Equal(t, 16*time.Hour, getBadTokenBackoff(4))
+		assert.Equal(t, BAD_TOKEN_MAX_BACKOFF, getBadTokenBackoff(5))
+		assert.Equal(t, BAD_TOKEN_MAX_BACKOFF, getBadTokenBackoff(1000))
+	})
+}
+
+func TestGetAccountSyncUserIDs(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	linkedUserID := primitive.NewObjectID()
+	gtOnlyUserID := primitive.NewObjectID()
+	_, err = database.GetExternalTokenCollection(db).InsertMany(context.Background(), []interface{}{
+		database.ExternalAPIToken{UserID: linkedUserID, ServiceID: external.TASK_SERVICE_ID_LINEAR},
+		database.ExternalAPIToken{UserID: gtOnlyUserID, ServiceID: external.TASK_SERVICE_ID_GT},
+		database.ExternalAPIToken{ServiceID: external.TASK_SERVICE_ID_SLACK_APP, AccountID: "TACCOUNTSYNC"},
+	})
+	assert.NoError(t, err)
+
+	userIDs, err := getAccountSyncUserIDs(db)
+	assert.NoError(t, err)
+	assert.Contains(t, userIDs, linkedUserID)
+	assert.NotContains(t, userIDs, gtOnlyUserID)
+	assert.NotContains(t, userIDs, primitive.NilObjectID)
+}
+
+func TestAdjustForCompletedTasks(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	userID := primitive.NewObjectID()
+	notCompleted := false
+	createTask := func(sourceID string, accountID string, isMeetingPrep bool) primitive.ObjectID {
+		res, err := database.GetTaskCollection(db).InsertOne(context.Background(), database.Task{
+			UserID:                   userID,
+			SourceID:                 sourceID,
+			SourceAccountID:          accountID,
+			IsCompleted:              &notCompleted,
+			IsMeetingPreparationTask: isMeetingPrep,
+		})
+		assert.NoError(t, err)
+		return res.InsertedID.(primitive.ObjectID)
+	}
+	fetchedTaskID := createTask(external.TASK_SOURCE_ID_LINEAR, "synced", false)
+	missingTaskID := createTask(external.TASK_SOURCE_ID_LINEAR, "synced", false)
+	otherAccountTaskID := createTask(external.TASK_SOURCE_ID_LINEAR, "not_synced", false)
+	gtTaskID := createTask(external.TASK_SOURCE_ID_GT_TASK, external.GeneralTaskDefaultAccountID, false)
+	meetingPrepTaskID := createTask(external.TASK_SOURCE_ID_GCAL, "synced", true)
+
+	currentTasks, err := database.GetActiveTasks(db, userID)
+	assert.NoError(t, err)
+	err = adjustForCompletedTasks(
+		db,
+		currentTasks,
+		map[primitive.ObjectID]bool{fetchedTaskID: true},
+		map[string]bool{
+			getAccountSyncSourceKey(external.TASK_SOURCE_ID_LINEAR, "synced"): true,
+			getAccountSyncSourceKey(external.TASK_SOURCE_ID_GCAL, "synced"):   true,
+		},
+	)
+	assert.NoError(t, err)
+
+	for taskID, expectedCompleted := range map[primitive.ObjectID]bool{
+		fetchedTaskID:      false,
+		missingTaskID:      true,
+		otherAccountTaskID: false,
+		gtTaskID:           false,
+		meetingPrepTaskID:  false,
+	} {
+		var task database.Task
+		err = database.GetTaskCollection(db).FindOne(context.Background(), bson.M{"_id": taskID}).Decode(&task)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedCompleted, *task.IsCompleted)
+	}
+}
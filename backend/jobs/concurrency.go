@@ -7,6 +7,7 @@ import (
 	"github.com/jjPlusPlus/task-manager/backend/database"
 	lock "github.com/square/mongo-lock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func EnsureJobOnlyRunsOnceToday(jobName string) (primitive.ObjectID, error) {
@@ -47,3 +48,13 @@ func EnsureJobOnlyRunsOncePerHour(jobName string) (primitive.ObjectID, error) {
 	lockID := primitive.NewObjectID()
 	return lockID, lockClient.XLock(context.Background(), resourceName, lockID.Hex(), lock.LockDetails{})
 }
+
+// EnsureUserJobOnlyRunsOncePerInterval relies on the job lock indexes created in migrations 018 and 022, as it runs for every user on every tick
+func EnsureUserJobOnlyRunsOncePerInterval(db *mongo.Database, jobName string, userID primitive.ObjectID, interval time.Duration) (primitive.ObjectID, error) {
+	lockClient := lock.NewClient(database.GetJobLocksCollection(db))
+	// bucket by interval so each user is only processed once per interval, even with several servers running jobs
+	resourceName := jobName + "_" + userID.Hex() + "_" + time.Now().Truncate(interval).Format("01-02-2006 15:04")
+	lockID := primitive.NewObjectID()
+	// the lock outlives its bucket, after which the TTL index removes it rather than keeping a lock per user per interval forever
+	return lockID, lockClient.XLock(context.Background(), resourceName, lockID.Hex(), lock.LockDetails{TTL: uint(interval.Seconds())})
+}
@@ -1,9 +1,15 @@
 package jobs
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	lock "github.com/square/mongo-lock"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestGetSettingsOptions(t *testing.T) {
@@ -24,4 +30,33 @@ func TestGetSettingsOptions(t *testing.T) {
 		_, err = EnsureJobOnlyRunsOncePerHour("foobar2")
 		assert.NoError(t, err)
 	})
+
+	t.Run("SuccessPerUserInterval", func(t *testing.T) {
+		db, dbCleanup, err := database.GetDBConnection()
+		assert.NoError(t, err)
+		defer dbCleanup()
+		createJobLockIndexes(t, db)
+		userID := primitive.NewObjectID()
+		_, err = EnsureUserJobOnlyRunsOncePerInterval(db, "foobar", userID, 15*time.Minute)
+		assert.NoError(t, err)
+		_, err = EnsureUserJobOnlyRunsOncePerInterval(db, "foobar", userID, 15*time.Minute)
+		assert.Equal(t, lock.ErrAlreadyLocked, err)
+		_, err = EnsureUserJobOnlyRunsOncePerInterval(db, "foobar", primitive.NewObjectID(), 15*time.Minute)
+		assert.NoError(t, err)
+		lockID, err := EnsureUserJobOnlyRunsOncePerInterval(db, "foobar2", userID, 15*time.Minute)
+		assert.NoError(t, err)
+
+		// the lock expires once its interval is over, for the TTL index to remove it
+		lockStatuses, err := lock.NewClient(database.GetJobLocksCollection(db)).Status(context.Background(), lock.Filter{LockId: lockID.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(lockStatuses))
+		assert.Greater(t, lockStatuses[0].TTL, int64(0))
+		assert.LessOrEqual(t, lockStatuses[0].TTL, int64((15 * time.Minute).Seconds()))
+	})
+}
+
+// createJobLockIndexes stands in for migration 018, as migrations aren't run for tests
+func createJobLockIndexes(t *testing.T, db *mongo.Database) {
+	err := lock.NewClient(database.GetJobLocksCollection(db)).CreateIndexes(context.Background())
+	assert.NoError(t, err)
 }
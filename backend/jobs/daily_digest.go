@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/settings"
+	"github.com/jjPlusPlus/task-manager/backend/templating"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	lock "github.com/square/mongo-lock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// local hour of the day to send the digest at
+const DAILY_DIGEST_HOUR = 9
+
+// DailyDigestBuilder gathers the contents of the digest, which relies on the api package's overview logic
+type DailyDigestBuilder interface {
+	GetDailyDigest(userID primitive.ObjectID, location *time.Location) (*templating.DailyDigest, error)
+}
+
+func dailyDigestJob(digestBuilder DailyDigestBuilder) {
+	err := sendDailyDigests(external.GetConfig(), utils.GetMailer(), digestBuilder, time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to run daily digest job")
+		return
+	}
+}
+
+// sendDailyDigests runs hourly, and sends the digest to users for whom it's currently 9am
+func sendDailyDigests(externalConfig external.Config, mailer utils.Mailer, digestBuilder DailyDigestBuilder, timeNow time.Time) error {
+	logger := logging.GetSentryLogger()
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// users without a timezone don't have a 9am to send the digest at
+	var users []database.User
+	cursor, err := database.GetUserCollection(db).Find(
+		context.Background(),
+		bson.M{"timezone": bson.M{"$nin": []interface{}{nil, ""}}},
+	)
+	if err != nil {
+		return err
+	}
+	err = cursor.All(context.Background(), &users)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		location, err := time.LoadLocation(user.Timezone)
+		if err != nil || timeNow.In(location).Hour() != DAILY_DIGEST_HOUR {
+			continue
+		}
+		err = sendDailyDigest(db, externalConfig, mailer, digestBuilder, user, location)
+		if err != nil {
+			logger.Error().Err(err).Str("userID", user.ID.Hex()).Msg("failed to send daily digest")
+		}
+	}
+	return nil
+}
+
+func sendDailyDigest(db *mongo.Database, externalConfig external.Config, mailer utils.Mailer, digestBuilder DailyDigestBuilder, user database.User, location *time.Location) error {
+	var userSettings []database.UserSetting
+	cursor, err := database.GetUserSettingsCollection(db).Find(context.Background(), bson.M{"user_id": user.ID})
+	if err != nil {
+		return err
+	}
+	err = cursor.All(context.Background(), &userSettings)
+	if err != nil {
+		return err
+	}
+	if settings.GetSettingValue(userSettings, settings.DailyDigestEnabledSetting) == constants.SettingFalse {
+		return nil
+	}
+	_, err = EnsureUserJobOnlyRunsOncePerInterval(db, "daily_digest", user.ID, time.Hour)
+	// already locked if another server has already sent this user's digest
+	if err == lock.ErrAlreadyLocked {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	digest, err := digestBuilder.GetDailyDigest(user.ID, location)
+	if err != nil {
+		return err
+	}
+	// nothing worth interrupting the user's morning for
+	if digest.IsEmpty() {
+		return nil
+	}
+	text, err := templating.RenderDailyDigestText(*digest)
+	if err != nil {
+		return err
+	}
+
+	if settings.GetSettingValue(userSettings, settings.DailyDigestDeliveryMethodSetting) == constants.ChoiceKeyReminderDeliverySlack {
+		err = sendSlackDirectMessage(db, externalConfig, user.ID, text)
+		// users who haven't installed the Slack app still get their digest
+		if err != external.ErrSlackAppNotInstalled {
+			return err
+		}
+	}
+	html, err := templating.RenderDailyDigestHTML(*digest)
+	if err != nil {
+		return err
+	}
+	return mailer.SendHTMLEmail(user.Email, "Your day, "+digest.Date, html, text)
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/templating"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockDailyDigestBuilder struct {
+	digests map[primitive.ObjectID]*templating.DailyDigest
+}
+
+func (builder *mockDailyDigestBuilder) GetDailyDigest(userID primitive.ObjectID, location *time.Location) (*templating.DailyDigest, error) {
+	digest, exists := builder.digests[userID]
+	if !exists {
+		return &templating.DailyDigest{Date: "Thursday, April 20"}, nil
+	}
+	return digest, nil
+}
+
+func TestSendDailyDigests(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	createJobLockIndexes(t, db)
+
+	// 9am in Los Angeles, 12pm in New York
+	timeNow := time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC)
+	createUser := func(timezone string) (primitive.ObjectID, string) {
+		email := "digest_" + primitive.NewObjectID().Hex() + "@resonant-kelpie-404a42.netlify.app"
+		userResult, err := database.GetUserCollection(db).InsertOne(context.Background(), database.User{Email: email, Timezone: timezone})
+		assert.NoError(t, err)
+		return userResult.InsertedID.(primitive.ObjectID), email
+	}
+	losAngelesUserID, losAngelesEmail := createUser("America/Los_Angeles")
+	newYorkUserID, newYorkEmail := createUser("America/New_York")
+	unsubscribedUserID, unsubscribedEmail := createUser("America/Los_Angeles")
+	_, err = database.GetUserSettingsCollection(db).InsertOne(context.Background(), database.UserSetting{
+		UserID:     unsubscribedUserID,
+		FieldKey:   constants.SettingFieldDailyDigestEnabled,
+		FieldValue: constants.SettingFalse,
+	})
+	assert.NoError(t, err)
+	_, emptyDigestEmail := createUser("America/Los_Angeles")
+
+	digest := &templating.DailyDigest{
+		Date:     "Thursday, April 20",
+		DueTasks: []templating.DailyDigestItem{{Title: "write report"}},
+	}
+	builder := &mockDailyDigestBuilder{digests: map[primitive.ObjectID]*templating.DailyDigest{
+		losAngelesUserID:   digest,
+		newYorkUserID:      digest,
+		unsubscribedUserID: digest,
+	}}
+	mailer := &mockMailer{emails: map[string][]string{}}
+	err = sendDailyDigests(external.GetConfig(), mailer, builder, timeNow)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Your day, Thursday, April 20\n\nDue today\n• write report\n"}, mailer.emails[losAngelesEmail])
+	assert.Empty(t, mailer.emails[newYorkEmail])
+	assert.Empty(t, mailer.emails[unsubscribedEmail])
+	assert.Empty(t, mailer.emails[emptyDigestEmail])
+
+	// digest is only sent once per day, even if the job runs again
+	err = sendDailyDigests(external.GetConfig(), mailer, builder, timeNow)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(mailer.emails[losAngelesEmail]))
+}
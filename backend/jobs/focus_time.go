@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+)
+
+func focusTimeJob() {
+	_, err := EnsureJobOnlyRunsOnceToday("focus_time")
+	if err != nil {
+		return
+	}
+	err = updateFocusTimeData(time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to run focus time data job")
+		return
+	}
+}
+
+// updateFocusTimeData saves the minutes logged with timers on the previous day, for the industry, each dashboard team and each team member
+func updateFocusTimeData(now time.Time) error {
+	logger := logging.GetSentryLogger()
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// days start at the same time as the PR response time data points
+	dayEnd := time.Date(now.Year(), now.Month(), now.Day(), constants.UTC_OFFSET, 0, 0, 0, time.UTC)
+	if dayEnd.After(now) {
+		dayEnd = dayEnd.AddDate(0, 0, -1)
+	}
+	dayStart := dayEnd.AddDate(0, 0, -1)
+	userIDToMinutes, err := getFocusTimeMinutesByUser(db, dayStart, dayEnd, now)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch time entries")
+		return err
+	}
+	date := primitive.NewDateTimeFromTime(dayStart)
+
+	industryMinutes := []int{}
+	for _, minutes := range userIDToMinutes {
+		industryMinutes = append(industryMinutes, minutes)
+	}
+	if len(industryMinutes) > 0 {
+		err = saveFocusTimeDataPoint(db, date, getAverage(industryMinutes), primitive.NilObjectID, primitive.NilObjectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	cursor, err := database.GetDashboardTeamCollection(db).Find(context.Background(), bson.M{})
+	if err != nil {
+		return err
+	}
+	var teams []database.DashboardTeam
+	err = cursor.All(context.Background(), &teams)
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		teamMembers, err := database.GetDashboardTeamMembers(db, team.ID)
+		if err != nil || teamMembers == nil {
+			logger.Error().Err(err).Msgf("failed to get team %s members", team.ID)
+			continue
+		}
+		teamMinutes := []int{}
+		for _, teamMember := range *teamMembers {
+			if teamMember.Email == "" {
+				continue
+			}
+			// only members with a General Task account can log time
+			var user database.User
+			err = database.GetUserCollection(db).FindOne(context.Background(), bson.M{"email": teamMember.Email}).Decode(&user)
+			if err != nil {
+				continue
+			}
+			minutes := userIDToMinutes[user.ID]
+			teamMinutes = append(teamMinutes, minutes)
+			err = saveFocusTimeDataPoint(db, date, minutes, team.ID, teamMember.ID)
+			if err != nil {
+				logger.Error().Err(err).Msgf("failed to save team %s member %s data points", team.ID, teamMember.ID)
+				return err
+			}
+		}
+		if len(teamMinutes) > 0 {
+			err = saveFocusTimeDataPoint(db, date, getAverage(teamMinutes), team.ID, primitive.NilObjectID)
+			if err != nil {
+				logger.Error().Err(err).Msgf("failed to save team %s data points", team.ID)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func getFocusTimeMinutesByUser(db *mongo.Database, dayStart time.Time, dayEnd time.Time, now time.Time) (map[primitive.ObjectID]int, error) {
+	cursor, err := database.GetTimeEntryCollection(db).Find(context.Background(), database.GetTimeEntryRangeFilter(dayStart, dayEnd))
+	if err != nil {
+		return nil, err
+	}
+	var timeEntries []database.TimeEntry
+	err = cursor.All(context.Background(), &timeEntries)
+	if err != nil {
+		return nil, err
+	}
+	userIDToDuration := map[primitive.ObjectID]time.Duration{}
+	for _, timeEntry := range timeEntries {
+		userIDToDuration[timeEntry.UserID] += database.GetTimeEntryDurationInRange(timeEntry, dayStart, dayEnd, now)
+	}
+	userIDToMinutes := map[primitive.ObjectID]int{}
+	for userID, duration := range userIDToDuration {
+		userIDToMinutes[userID] = int(duration.Minutes())
+	}
+	return userIDToMinutes, nil
+}
+
+func saveFocusTimeDataPoint(db *mongo.Database, date primitive.DateTime, minutes int, teamID primitive.ObjectID, individualID primitive.ObjectID) error {
+	dashboardDataPoint := database.DashboardDataPoint{
+		GraphType: constants.DashboardGraphTypeFocusTime,
+		Value:     minutes,
+		Date:      date,
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	filters := []bson.M{
+		{"date": date},
+		{"graph_type": constants.DashboardGraphTypeFocusTime},
+	}
+	if teamID != primitive.NilObjectID {
+		dashboardDataPoint.TeamID = teamID
+		filters = append(filters, bson.M{"team_id": teamID})
+	} else {
+		filters = append(filters, bson.M{"team_id": bson.M{"$exists": false}})
+	}
+	if individualID != primitive.NilObjectID {
+		dashboardDataPoint.IndividualID = individualID
+		filters = append(filters, bson.M{"individual_id": individualID})
+	} else {
+		filters = append(filters, bson.M{"individual_id": bson.M{"$exists": false}})
+	}
+	_, err := database.GetDashboardDataPointCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": filters},
+		bson.M{"$set": dashboardDataPoint},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to update data point")
+	}
+	return err
+}
+
+func getAverage(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total / len(values)
+}
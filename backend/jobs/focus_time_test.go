@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdateFocusTimeData(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	now := time.Date(2023, time.April, 20, 8, 0, 0, 0, time.UTC)
+	dayStart := now.AddDate(0, 0, -1)
+	userInsertResult, err := database.GetUserCollection(db).InsertOne(context.Background(), database.User{Email: "focus_time@resonant-kelpie-404a42.netlify.app"})
+	assert.NoError(t, err)
+	userID := userInsertResult.InsertedID.(primitive.ObjectID)
+	otherUserID := primitive.NewObjectID()
+
+	_, err = database.GetTimeEntryCollection(db).InsertMany(context.Background(), []interface{}{
+		database.TimeEntry{UserID: userID, DatetimeStart: primitive.NewDateTimeFromTime(dayStart.Add(time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(dayStart.Add(3 * time.Hour))},
+		// only the part of the entry in the day counts
+		database.TimeEntry{UserID: userID, DatetimeStart: primitive.NewDateTimeFromTime(now.Add(-30 * time.Minute)), DatetimeEnd: primitive.NewDateTimeFromTime(now.Add(time.Hour))},
+		database.TimeEntry{UserID: otherUserID, DatetimeStart: primitive.NewDateTimeFromTime(dayStart.Add(time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(dayStart.Add(2 * time.Hour))},
+		database.TimeEntry{UserID: otherUserID, DatetimeStart: primitive.NewDateTimeFromTime(dayStart.Add(-2 * time.Hour)), DatetimeEnd: primitive.NewDateTimeFromTime(dayStart.Add(-time.Hour))},
+	})
+	assert.NoError(t, err)
+
+	team, err := database.GetOrCreateDashboardTeam(db, userID)
+	assert.NoError(t, err)
+	memberInsertResult, err := database.GetDashboardTeamMemberCollection(db).InsertOne(context.Background(), database.DashboardTeamMember{TeamID: team.ID, Email: "focus_time@resonant-kelpie-404a42.netlify.app"})
+	assert.NoError(t, err)
+	memberID := memberInsertResult.InsertedID.(primitive.ObjectID)
+	// members without an account don't count towards the team average
+	_, err = database.GetDashboardTeamMemberCollection(db).InsertOne(context.Background(), database.DashboardTeamMember{TeamID: team.ID, Email: "no_account@resonant-kelpie-404a42.netlify.app"})
+	assert.NoError(t, err)
+
+	// running the job twice updates the same data points
+	assert.NoError(t, updateFocusTimeData(now))
+	assert.NoError(t, updateFocusTimeData(now.Add(time.Hour)))
+
+	getValue := func(filters []bson.M) int {
+		filters = append(filters, bson.M{"graph_type": constants.DashboardGraphTypeFocusTime}, bson.M{"date": primitive.NewDateTimeFromTime(dayStart)})
+		count, err := database.GetDashboardDataPointCollection(db).CountDocuments(context.Background(), bson.M{"$and": filters})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		var dataPoint database.DashboardDataPoint
+		err = database.GetDashboardDataPointCollection(db).FindOne(context.Background(), bson.M{"$and": filters}).Decode(&dataPoint)
+		assert.NoError(t, err)
+		return dataPoint.Value
+	}
+	assert.Equal(t, 105, getValue([]bson.M{{"team_id": bson.M{"$exists": false}}, {"individual_id": bson.M{"$exists": false}}}))
+	assert.Equal(t, 150, getValue([]bson.M{{"team_id": team.ID}, {"individual_id": bson.M{"$exists": false}}}))
+	assert.Equal(t, 150, getValue([]bson.M{{"team_id": team.ID}, {"individual_id": memberID}}))
+}
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const RECURRING_TASK_TEMPLATE_INTERVAL = 5 * time.Minute
+
+// recurrence rate presets, which are translated to recurrence rules
+const (
+	Daily     int = 0
+	WeekDaily int = 1
+	Weekly    int = 2
+	Monthly   int = 3
+	Annually  int = 4
+)
+
+func recurringTaskTemplateJob() {
+	err := backfillAllRecurringTaskTemplates(time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to run recurring task template job")
+		return
+	}
+}
+
+// backfillAllRecurringTaskTemplates creates tasks for templates of users with a known timezone, so they
+// fire at their local time even when the user doesn't have the app open
+func backfillAllRecurringTaskTemplates(timeNow time.Time) error {
+	logger := logging.GetSentryLogger()
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var templates []database.RecurringTaskTemplate
+	cursor, err := database.GetRecurringTaskTemplateCollection(db).Find(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"is_deleted": false}, {"is_enabled": true}}},
+	)
+	if err != nil {
+		return err
+	}
+	err = cursor.All(context.Background(), &templates)
+	if err != nil {
+		return err
+	}
+
+	userLocations := map[primitive.ObjectID]*time.Location{}
+	for _, template := range templates {
+		location, exists := userLocations[template.UserID]
+		if !exists {
+			location, err = database.GetUserLocation(db, template.UserID)
+			if err != nil {
+				logger.Error().Err(err).Str("userID", template.UserID.Hex()).Msg("failed to load user timezone")
+			}
+			userLocations[template.UserID] = location
+		}
+		// templates for users without a timezone are still backfilled by the client
+		if location == nil {
+			continue
+		}
+		_, err = BackfillRecurringTaskTemplate(db, template, location, timeNow)
+		if err != nil {
+			logger.Error().Err(err).Str("templateID", template.ID.Hex()).Msg("failed to backfill recurring task template")
+		}
+	}
+	return nil
+}
+
+// BackfillRecurringTaskTemplate creates the tasks for occurrences of the template between its last backfill and timeNow.
+// Each occurrence has a unique key, so backfilling is idempotent even if it runs more than once at the same time.
+func BackfillRecurringTaskTemplate(db *mongo.Database, template database.RecurringTaskTemplate, location *time.Location, timeNow time.Time) ([]primitive.ObjectID, error) {
+	recurrenceRule, err := GetTemplateRecurrenceRule(template)
+	if err != nil {
+		return nil, err
+	}
+	exceptionDates := []external.RecurrenceExceptionDate{}
+	if template.ExceptionDates != nil {
+		exceptionDates, err = external.ParseRecurrenceExceptionDates(*template.ExceptionDates, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+	occurrences := recurrenceRule.Between(getTemplateRecurrenceStart(template, location), template.LastBackfillDatetime.Time(), timeNow, exceptionDates)
+
+	// templates which replace existing tasks only need the latest occurrence
+	if len(occurrences) > 0 && template.ReplaceExisting != nil && *template.ReplaceExisting {
+		occurrences = occurrences[len(occurrences)-1:]
+		_, err = database.GetTaskCollection(db).UpdateMany(
+			context.Background(),
+			bson.M{"$and": []bson.M{
+				{"recurring_task_template_id": template.ID},
+				{"user_id": template.UserID},
+				{"recurring_task_occurrence_key": bson.M{"$ne": getRecurringTaskOccurrenceKey(template, occurrences[0])}},
+			}},
+			bson.M{"$set": bson.M{"is_deleted": true}},
+		)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+
+	insertedIDs := []primitive.ObjectID{}
+	for _, occurrence := range occurrences {
+		task := createTaskFromTemplate(template, occurrence, timeNow)
+		result, err := database.GetTaskCollection(db).UpdateOne(
+			context.Background(),
+			bson.M{"recurring_task_occurrence_key": task.RecurringTaskOccurrenceKey},
+			bson.M{"$setOnInsert": task},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			// a concurrent backfill inserting the same occurrence violates the unique index
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return insertedIDs, err
+		}
+		if insertedID, ok := result.UpsertedID.(primitive.ObjectID); ok {
+			insertedIDs = append(insertedIDs, insertedID)
+			task.ID = insertedID
+			_, err = queueWebhookEventForRetryJob(db, template.UserID, constants.WebhookEventTaskCreated, GetWebhookTaskData(&task), timeNow)
+			if err != nil {
+				logging.GetSentryLogger().Error().Err(err).Msg("failed to queue webhook event")
+			}
+		}
+	}
+
+	// $max so that a slower concurrent backfill can't move the backfill time backwards
+	_, err = database.GetRecurringTaskTemplateCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"$and": []bson.M{{"_id": template.ID}, {"user_id": template.UserID}}},
+		bson.M{"$max": bson.M{"last_backfill_datetime": primitive.NewDateTimeFromTime(timeNow)}},
+	)
+	return insertedIDs, err
+}
+
+func getRecurringTaskOccurrenceKey(template database.RecurringTaskTemplate, occurrence time.Time) string {
+	return template.ID.Hex() + "_" + external.FormatICalDateTime(occurrence)
+}
+
+func createTaskFromTemplate(template database.RecurringTaskTemplate, occurrence time.Time, timeNow time.Time) database.Task {
+	completed := false
+	deleted := false
+	return database.Task{
+		UserID:                     template.UserID,
+		RecurringTaskTemplateID:    template.ID,
+		RecurringTaskOccurrenceKey: getRecurringTaskOccurrenceKey(template, occurrence),
+		SourceID:                   external.TASK_SOURCE_ID_GT_TASK,
+		Title:                      template.Title,
+		Body:                       template.Body,
+		IDTaskSection:              template.IDTaskSection,
+		PriorityNormalized:         template.PriorityNormalized,
+		IsCompleted:                &completed,
+		IsDeleted:                  &deleted,
+		CreatedAtExternal:          primitive.NewDateTimeFromTime(timeNow),
+		UpdatedAt:                  primitive.NewDateTimeFromTime(timeNow),
+	}
+}
+
+// GetTemplateRecurrenceRule returns the template's RRULE, or translates its recurrence rate preset into one
+func GetTemplateRecurrenceRule(template database.RecurringTaskTemplate) (*external.RecurrenceRule, error) {
+	if template.TimeOfDaySecondsToCreateTask == nil {
+		return nil, errors.New("invalid template value")
+	}
+	if template.RecurrenceRule != nil && *template.RecurrenceRule != "" {
+		return external.ParseRRule(*template.RecurrenceRule)
+	}
+	if template.RecurrenceRate == nil {
+		return nil, errors.New("invalid template value")
+	}
+	rule, err := getRecurrenceRuleForRate(*template.RecurrenceRate, template.DayToCreateTask, template.MonthToCreateTask)
+	if err != nil {
+		return nil, err
+	}
+	return external.ParseRRule(rule)
+}
+
+func getRecurrenceRuleForRate(recurrenceRate int, dayToCreateTask *int, monthToCreateTask *int) (string, error) {
+	// there are certain values that must be present depending on the recurrence type
+	if (recurrenceRate == Weekly || recurrenceRate == Monthly || recurrenceRate == Annually) && dayToCreateTask == nil {
+		return "", errors.New("invalid template value")
+	}
+	if recurrenceRate == Annually && monthToCreateTask == nil {
+		return "", errors.New("invalid template value")
+	}
+
+	switch recurrenceRate {
+	case Daily:
+		return "FREQ=DAILY", nil
+	case WeekDaily:
+		return "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", nil
+	case Weekly:
+		// both 0 and 7 are used for Sunday
+		weekdays := []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+		if *dayToCreateTask < 0 || *dayToCreateTask >= len(weekdays) {
+			return "", errors.New("invalid template value")
+		}
+		return "FREQ=WEEKLY;BYDAY=" + weekdays[*dayToCreateTask], nil
+	case Monthly:
+		return fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%d", *dayToCreateTask), nil
+	case Annually:
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYMONTHDAY=%d", *monthToCreateTask, *dayToCreateTask), nil
+	}
+	return "", errors.New("unrecognized recurrence rate for template backfill")
+}
+
+// getTemplateRecurrenceStart anchors the recurrence on the day the template was created, so that
+// intervals (e.g. every other Tuesday) don't shift as the template is backfilled
+func getTemplateRecurrenceStart(template database.RecurringTaskTemplate, location *time.Location) time.Time {
+	startDate := template.CreatedAt.Time().In(location)
+	if template.CreatedAt == 0 {
+		startDate = template.LastBackfillDatetime.Time().In(location)
+	}
+	timeOfDaySeconds := *template.TimeOfDaySecondsToCreateTask
+	return time.Date(startDate.Year(), startDate.Month(), startDate.Day(), timeOfDaySeconds/3600, (timeOfDaySeconds%3600)/60, timeOfDaySeconds%60, 0, location)
+}
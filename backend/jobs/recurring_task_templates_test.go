@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetRecurrenceRuleForRate(t *testing.T) {
+	day := 7
+	month := 11
+	for _, testCase := range []struct {
+		RecurrenceRate int
+		ExpectedRule   string
+	}{
+		{Daily, "FREQ=DAILY"},
+		{WeekDaily, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{Weekly, "FREQ=WEEKLY;BYDAY=SU"},
+		{Monthly, "FREQ=MONTHLY;BYMONTHDAY=7"},
+		{Annually, "FREQ=YEARLY;BYMONTH=11;BYMONTHDAY=7"},
+	} {
+		rule, err := getRecurrenceRuleForRate(testCase.RecurrenceRate, &day, &month)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.ExpectedRule, rule)
+	}
+
+	_, err := getRecurrenceRuleForRate(Weekly, nil, nil)
+	assert.EqualError(t, err, "invalid template value")
+	_, err = getRecurrenceRuleForRate(Annually, &day, nil)
+	assert.EqualError(t, err, "invalid template value")
+	_, err = getRecurrenceRuleForRate(10, &day, &month)
+	assert.EqualError(t, err, "unrecognized recurrence rate for template backfill")
+}
+
+func TestBackfillRecurringTaskTemplate(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+
+	createTemplate := func(t *testing.T, userID primitive.ObjectID, replaceExisting bool) database.RecurringTaskTemplate {
+		title := "daily standup"
+		enabled := true
+		deleted := false
+		recurrenceRate := Daily
+		// 9:00 local time
+		creationTimeSeconds := 60 * 60 * 9
+		template := database.RecurringTaskTemplate{
+			ID:                           primitive.NewObjectID(),
+			UserID:                       userID,
+			Title:                        &title,
+			IsEnabled:                    &enabled,
+			IsDeleted:                    &deleted,
+			ReplaceExisting:              &replaceExisting,
+			RecurrenceRate:               &recurrenceRate,
+			TimeOfDaySecondsToCreateTask: &creationTimeSeconds,
+			LastBackfillDatetime:         primitive.NewDateTimeFromTime(time.Date(2023, time.March, 10, 12, 0, 0, 0, losAngeles)),
+		}
+		_, err := database.GetRecurringTaskTemplateCollection(db).InsertOne(context.Background(), template)
+		assert.NoError(t, err)
+		return template
+	}
+	countTasks := func(t *testing.T, userID primitive.ObjectID) int64 {
+		count, err := database.GetTaskCollection(db).CountDocuments(context.Background(), bson.M{"user_id": userID, "is_deleted": false})
+		assert.NoError(t, err)
+		return count
+	}
+
+	t.Run("DaylightSavingTime", func(t *testing.T) {
+		userID := primitive.NewObjectID()
+		template := createTemplate(t, userID, false)
+
+		// DST starts on March 12th, so 9:00 local time moves from 17:00 to 16:00 UTC
+		timeNow := time.Date(2023, time.March, 13, 16, 30, 0, 0, time.UTC)
+		insertedIDs, err := BackfillRecurringTaskTemplate(db, template, losAngeles, timeNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(insertedIDs))
+		assert.Equal(t, int64(3), countTasks(t, userID))
+
+		var task database.Task
+		err = database.GetTaskCollection(db).FindOne(context.Background(), bson.M{"_id": insertedIDs[2]}).Decode(&task)
+		assert.NoError(t, err)
+		assert.Equal(t, template.ID.Hex()+"_20230313T160000Z", task.RecurringTaskOccurrenceKey)
+
+		var updatedTemplate database.RecurringTaskTemplate
+		err = database.GetRecurringTaskTemplateCollection(db).FindOne(context.Background(), bson.M{"_id": template.ID}).Decode(&updatedTemplate)
+		assert.NoError(t, err)
+		assert.Equal(t, timeNow, updatedTemplate.LastBackfillDatetime.Time().UTC())
+	})
+	t.Run("Idempotent", func(t *testing.T) {
+		userID := primitive.NewObjectID()
+		template := createTemplate(t, userID, false)
+
+		// a second backfill from the stale template doesn't create the same occurrences again
+		timeNow := time.Date(2023, time.March, 11, 18, 0, 0, 0, time.UTC)
+		insertedIDs, err := BackfillRecurringTaskTemplate(db, template, losAngeles, timeNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(insertedIDs))
+		insertedIDs, err = BackfillRecurringTaskTemplate(db, template, losAngeles, timeNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(insertedIDs))
+		assert.Equal(t, int64(1), countTasks(t, userID))
+	})
+	t.Run("ReplaceExisting", func(t *testing.T) {
+		userID := primitive.NewObjectID()
+		template := createTemplate(t, userID, true)
+
+		timeNow := time.Date(2023, time.March, 13, 16, 30, 0, 0, time.UTC)
+		insertedIDs, err := BackfillRecurringTaskTemplate(db, template, losAngeles, timeNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(insertedIDs))
+		assert.Equal(t, int64(1), countTasks(t, userID))
+	})
+}
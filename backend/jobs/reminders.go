@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/settings"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// reminders missed for longer than this (i.e. during downtime) are no longer useful, so they aren't sent
+const REMINDER_LOOKBACK = time.Hour
+const REMINDER_MAX_OFFSET = 7 * 24 * time.Hour
+
+type reminderPreferences struct {
+	user           *database.User
+	location       *time.Location
+	defaultOffsets []int
+	deliveryMethod string
+}
+
+func reminderJob() {
+	err := sendDueReminders(external.GetConfig(), utils.GetMailer(), time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to run reminder job")
+		return
+	}
+}
+
+func sendDueReminders(externalConfig external.Config, mailer utils.Mailer, timeNow time.Time) error {
+	logger := logging.GetSentryLogger()
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// date-only due dates shift by up to a day depending on the user's timezone
+	windowStart := primitive.NewDateTimeFromTime(timeNow.Add(-REMINDER_LOOKBACK - 24*time.Hour))
+	windowEnd := primitive.NewDateTimeFromTime(timeNow.Add(REMINDER_MAX_OFFSET + 24*time.Hour))
+	var tasks []database.Task
+	cursor, err := database.GetTaskCollection(db).Find(
+		context.Background(),
+		bson.M{"$and": []bson.M{
+			{"is_completed": bson.M{"$ne": true}},
+			{"is_deleted": bson.M{"$ne": true}},
+			{"$or": []bson.M{
+				{"due_date": bson.M{"$gte": windowStart, "$lte": windowEnd}},
+				{"meeting_preparation_params.datetime_start": bson.M{"$gte": windowStart, "$lte": windowEnd}},
+			}},
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	err = cursor.All(context.Background(), &tasks)
+	if err != nil {
+		return err
+	}
+
+	userPreferences := map[primitive.ObjectID]*reminderPreferences{}
+	for _, task := range tasks {
+		preferences, exists := userPreferences[task.UserID]
+		if !exists {
+			preferences, err = getReminderPreferences(db, task.UserID)
+			if err != nil {
+				logger.Error().Err(err).Str("userID", task.UserID.Hex()).Msg("failed to load reminder preferences")
+			}
+			userPreferences[task.UserID] = preferences
+		}
+		if preferences == nil {
+			continue
+		}
+		for _, remindAt := range getDueReminderTimes(&task, preferences, timeNow) {
+			err = sendReminder(db, externalConfig, mailer, &task, preferences, remindAt, timeNow)
+			if err != nil {
+				logger.Error().Err(err).Str("taskID", task.ID.Hex()).Msg("failed to send reminder")
+			}
+		}
+	}
+	return nil
+}
+
+func getReminderPreferences(db *mongo.Database, userID primitive.ObjectID) (*reminderPreferences, error) {
+	user, err := database.GetUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := database.GetUserLocation(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	var userSettings []database.UserSetting
+	cursor, err := database.GetUserSettingsCollection(db).Find(context.Background(), bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.Background(), &userSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultOffsets := []int{}
+	defaultOffset, err := strconv.Atoi(settings.GetSettingValue(userSettings, settings.ReminderDefaultOffsetSetting))
+	if err == nil {
+		defaultOffsets = append(defaultOffsets, defaultOffset)
+	}
+	return &reminderPreferences{
+		user:           user,
+		location:       location,
+		defaultOffsets: defaultOffsets,
+		deliveryMethod: settings.GetSettingValue(userSettings, settings.ReminderDeliveryMethodSetting),
+	}, nil
+}
+
+// getReminderDeadline returns the time the task's reminders count back from, whether it's only a date, and whether the task has one
+func getReminderDeadline(task *database.Task, location *time.Location) (time.Time, bool, bool) {
+	if task.MeetingPreparationParams != nil && task.MeetingPreparationParams.DatetimeStart != 0 {
+		return task.MeetingPreparationParams.DatetimeStart.Time().UTC(), false, true
+	}
+	// due dates before 1971 are treated as empty
+	if task.DueDate == nil || task.DueDate.Time().UTC().Year() <= 1971 {
+		return time.Time{}, false, false
+	}
+	dueDate := task.DueDate.Time().UTC()
+	// due dates without a time are stored at midnight UTC, and are due at the start of the day in the user's timezone
+	if dueDate.Hour() == 0 && dueDate.Minute() == 0 && dueDate.Second() == 0 {
+		return time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, location), true, true
+	}
+	return dueDate, false, true
+}
+
+func getDueReminderTimes(task *database.Task, preferences *reminderPreferences, timeNow time.Time) []time.Time {
+	deadline, _, exists := getReminderDeadline(task, preferences.location)
+	if !exists {
+		return []time.Time{}
+	}
+	offsets := preferences.defaultOffsets
+	if task.ReminderOffsets != nil {
+		offsets = *task.ReminderOffsets
+	}
+	remindAtTimes := []time.Time{}
+	for _, offset := range offsets {
+		remindAt := deadline.Add(-time.Duration(offset) * time.Minute)
+		if !remindAt.After(timeNow) && remindAt.After(timeNow.Add(-REMINDER_LOOKBACK)) {
+			remindAtTimes = append(remindAtTimes, remindAt)
+		}
+	}
+	return remindAtTimes
+}
+
+func getReminderMessage(task *database.Task, location *time.Location) string {
+	title := ""
+	if task.Title != nil {
+		title = *task.Title
+	}
+	deadline, isDateOnly, _ := getReminderDeadline(task, location)
+	deadline = deadline.In(location)
+	if task.MeetingPreparationParams != nil && task.MeetingPreparationParams.DatetimeStart != 0 {
+		return fmt.Sprintf("Reminder: %s starts at %s", title, deadline.Format("Mon Jan 2 3:04 PM MST"))
+	}
+	if isDateOnly {
+		return fmt.Sprintf("Reminder: %s is due %s", title, deadline.Format("Mon Jan 2"))
+	}
+	return fmt.Sprintf("Reminder: %s is due %s", title, deadline.Format("Mon Jan 2 3:04 PM MST"))
+}
+
+// sendReminder claims the reminder before delivering it, so it's only sent once even if multiple servers run the job
+func sendReminder(db *mongo.Database, externalConfig external.Config, mailer utils.Mailer, task *database.Task, preferences *reminderPreferences, remindAt time.Time, timeNow time.Time) error {
+	deliveryMethod := preferences.deliveryMethod
+	insertResult, err := database.GetReminderDeliveryCollection(db).InsertOne(context.Background(), database.ReminderDelivery{
+		UserID:         task.UserID,
+		TaskID:         task.ID,
+		Key:            task.ID.Hex() + "_" + strconv.FormatInt(remindAt.Unix(), 10),
+		DeliveryMethod: deliveryMethod,
+		RemindAt:       primitive.NewDateTimeFromTime(remindAt),
+		CreatedAt:      primitive.NewDateTimeFromTime(timeNow),
+		UpdatedAt:      primitive.NewDateTimeFromTime(timeNow),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	message := getReminderMessage(task, preferences.location)
+	var deliveryErr error
+	if deliveryMethod == constants.ChoiceKeyReminderDeliverySlack {
+		deliveryErr = sendSlackDirectMessage(db, externalConfig, task.UserID, message)
+		// users who haven't installed the Slack app still get their reminders
+		if deliveryErr == external.ErrSlackAppNotInstalled {
+			deliveryMethod = constants.ChoiceKeyReminderDeliveryEmail
+		}
+	}
+	if deliveryMethod == constants.ChoiceKeyReminderDeliveryEmail {
+		deliveryErr = mailer.SendEmail(preferences.user.Email, message, message)
+	}
+
+	update := bson.M{"is_sent": deliveryErr == nil, "delivery_method": deliveryMethod, "updated_at": primitive.NewDateTimeFromTime(timeNow)}
+	if deliveryErr != nil {
+		update["last_error"] = deliveryErr.Error()
+	}
+	_, err = database.GetReminderDeliveryCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"_id": insertResult.InsertedID},
+		bson.M{"$set": update},
+	)
+	if deliveryErr != nil {
+		return deliveryErr
+	}
+	return err
+}
+
+func sendSlackDirectMessage(db *mongo.Database, externalConfig external.Config, userID primitive.ObjectID, message string) error {
+	taskServiceResult, err := externalConfig.GetTaskServiceResult(external.TASK_SERVICE_ID_SLACK_APP)
+	if err != nil {
+		return err
+	}
+	slackService, ok := taskServiceResult.Service.(external.SlackService)
+	if !ok {
+		return external.ErrSlackAppNotInstalled
+	}
+	return slackService.SendDirectMessage(db, userID, message)
+}
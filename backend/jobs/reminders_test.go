@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/external"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockMailer struct {
+	emails map[string][]string
+}
+
+func (mailer *mockMailer) SendEmail(toEmail string, subject string, text string) error {
+	mailer.emails[toEmail] = append(mailer.emails[toEmail], text)
+	return nil
+}
+
+func (mailer *mockMailer) SendHTMLEmail(toEmail string, subject string, html string, text string) error {
+	return mailer.SendEmail(toEmail, subject, text)
+}
+
+func TestGetDueReminderTimes(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	timeNow := time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC)
+	title := "write report"
+	preferences := &reminderPreferences{location: losAngeles, defaultOffsets: []int{15}}
+
+	t.Run("DefaultOffset", func(t *testing.T) {
+		dueDate := primitive.NewDateTimeFromTime(timeNow.Add(10 * time.Minute))
+		task := &database.Task{Title: &title, DueDate: &dueDate}
+		assert.Equal(t, []time.Time{timeNow.Add(-5 * time.Minute)}, getDueReminderTimes(task, preferences, timeNow))
+		assert.Equal(t, "Reminder: write report is due Thu Apr 20 9:10 AM PDT", getReminderMessage(task, losAngeles))
+	})
+	t.Run("TaskOffsetsOverrideDefault", func(t *testing.T) {
+		dueDate := primitive.NewDateTimeFromTime(timeNow.Add(10 * time.Minute))
+		task := &database.Task{Title: &title, DueDate: &dueDate, ReminderOffsets: &[]int{5, 10, 60}}
+		assert.Equal(t, []time.Time{timeNow, timeNow.Add(-50 * time.Minute)}, getDueReminderTimes(task, preferences, timeNow))
+
+		task.ReminderOffsets = &[]int{}
+		assert.Equal(t, []time.Time{}, getDueReminderTimes(task, preferences, timeNow))
+	})
+	t.Run("StaleReminder", func(t *testing.T) {
+		dueDate := primitive.NewDateTimeFromTime(timeNow.Add(-2 * time.Hour))
+		task := &database.Task{Title: &title, DueDate: &dueDate}
+		assert.Equal(t, []time.Time{}, getDueReminderTimes(task, preferences, timeNow))
+	})
+	t.Run("DateOnlyUsesLocalMidnight", func(t *testing.T) {
+		dueDate := primitive.NewDateTimeFromTime(time.Date(2023, time.April, 21, 0, 0, 0, 0, time.UTC))
+		task := &database.Task{Title: &title, DueDate: &dueDate, ReminderOffsets: &[]int{60 * 24}}
+		// midnight April 21st in Los Angeles is 7:00 UTC
+		localNow := time.Date(2023, time.April, 20, 7, 30, 0, 0, time.UTC)
+		assert.Equal(t, []time.Time{time.Date(2023, time.April, 20, 0, 0, 0, 0, losAngeles)}, getDueReminderTimes(task, preferences, localNow))
+		assert.Equal(t, "Reminder: write report is due Fri Apr 21", getReminderMessage(task, losAngeles))
+	})
+	t.Run("MeetingPreparation", func(t *testing.T) {
+		task := &database.Task{Title: &title, MeetingPreparationParams: &database.MeetingPreparationParams{
+			DatetimeStart: primitive.NewDateTimeFromTime(timeNow.Add(15 * time.Minute)),
+		}}
+		assert.Equal(t, []time.Time{timeNow}, getDueReminderTimes(task, preferences, timeNow))
+		assert.Equal(t, "Reminder: write report starts at Thu Apr 20 9:15 AM PDT", getReminderMessage(task, losAngeles))
+	})
+	t.Run("NoDueDate", func(t *testing.T) {
+		task := &database.Task{Title: &title}
+		assert.Equal(t, []time.Time{}, getDueReminderTimes(task, preferences, timeNow))
+	})
+}
+
+func TestSendDueReminders(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	timeNow := time.Date(2023, time.April, 20, 16, 0, 0, 0, time.UTC)
+	email := "reminders_" + primitive.NewObjectID().Hex() + "@resonant-kelpie-404a42.netlify.app"
+	userResult, err := database.GetUserCollection(db).InsertOne(context.Background(), database.User{Email: email, Timezone: "America/Los_Angeles"})
+	assert.NoError(t, err)
+	userID := userResult.InsertedID.(primitive.ObjectID)
+	_, err = database.GetUserSettingsCollection(db).InsertOne(context.Background(), database.UserSetting{
+		UserID:     userID,
+		FieldKey:   constants.SettingFieldReminderDefaultOffset,
+		FieldValue: "15",
+	})
+	assert.NoError(t, err)
+
+	title := "write report"
+	completed := false
+	dueDate := primitive.NewDateTimeFromTime(timeNow.Add(10 * time.Minute))
+	taskResult, err := database.GetTaskCollection(db).InsertOne(context.Background(), database.Task{
+		UserID:      userID,
+		Title:       &title,
+		IsCompleted: &completed,
+		DueDate:     &dueDate,
+	})
+	assert.NoError(t, err)
+	// reminder isn't due yet
+	_, err = database.GetTaskCollection(db).InsertOne(context.Background(), database.Task{
+		UserID:          userID,
+		Title:           &title,
+		IsCompleted:     &completed,
+		DueDate:         &dueDate,
+		ReminderOffsets: &[]int{5},
+	})
+	assert.NoError(t, err)
+
+	// the Slack app isn't installed, so reminders fall back to email
+	_, err = database.GetUserSettingsCollection(db).InsertOne(context.Background(), database.UserSetting{
+		UserID:     userID,
+		FieldKey:   constants.SettingFieldReminderDeliveryMethod,
+		FieldValue: constants.ChoiceKeyReminderDeliverySlack,
+	})
+	assert.NoError(t, err)
+
+	mailer := &mockMailer{emails: map[string][]string{}}
+	err = sendDueReminders(external.GetConfig(), mailer, timeNow)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Reminder: write report is due Thu Apr 20 9:10 AM PDT"}, mailer.emails[email])
+
+	var delivery database.ReminderDelivery
+	err = database.GetReminderDeliveryCollection(db).FindOne(context.Background(), bson.M{"task_id": taskResult.InsertedID}).Decode(&delivery)
+	assert.NoError(t, err)
+	assert.True(t, delivery.IsSent)
+	assert.Equal(t, constants.ChoiceKeyReminderDeliveryEmail, delivery.DeliveryMethod)
+
+	// reminders are only sent once
+	err = sendDueReminders(external.GetConfig(), mailer, timeNow.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(mailer.emails[email]))
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/go-co-op/gocron"
 )
 
-func GetScheduler() (*gocron.Scheduler, error) {
+func GetScheduler(digestBuilder DailyDigestBuilder) (*gocron.Scheduler, error) {
 	s := gocron.NewScheduler(time.UTC)
 
 	// job schedules
@@ -15,7 +15,36 @@ func GetScheduler() (*gocron.Scheduler, error) {
 		return nil, err
 	}
 
-	_, err = s.Every(1).Day().At("08:00").Do(dripCampaignJob)
+	// focus time for the previous day, logged with timers
+	_, err = s.Every(1).Day().At("08:00").Do(focusTimeJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// runs hourly to send the digest at 9am in each user's timezone
+	_, err = s.Every(1).Hour().SingletonMode().Do(dailyDigestJob, digestBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	// refresh linked accounts for users who don't have the app open
+	_, err = s.Every(ACCOUNT_SYNC_INTERVAL).SingletonMode().Do(accountSyncJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// create tasks from recurring task templates for users who don't have the app open
+	_, err = s.Every(RECURRING_TASK_TEMPLATE_INTERVAL).SingletonMode().Do(recurringTaskTemplateJob)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Every(1).Minute().SingletonMode().Do(webhookRetryJob)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Every(1).Minute().SingletonMode().Do(reminderJob)
 	if err != nil {
 		return nil, err
 	}
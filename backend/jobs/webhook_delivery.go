@@ -0,0 +1,253 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/jjPlusPlus/task-manager/backend/logging"
+	"github.com/jjPlusPlus/task-manager/backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const WEBHOOK_MAX_ATTEMPTS = 5
+const WEBHOOK_BASE_RETRY_DELAY = time.Minute
+const WEBHOOK_REQUEST_TIMEOUT = 10 * time.Second
+
+// deliveries are leased while being attempted so other servers don't send them at the same time
+const WEBHOOK_DELIVERY_LEASE = 5 * time.Minute
+
+// webhook URLs are user-supplied, so requests are kept from reaching anything inside our network
+var webhookHTTPClient = utils.NewPublicHTTPClient(WEBHOOK_REQUEST_TIMEOUT)
+
+// failures are recorded without details from the receiver, which could otherwise be used to probe hosts the URL points at
+var errWebhookUnreachable = errors.New("receiver could not be reached")
+var errWebhookUnsuccessfulStatus = errors.New("receiver responded with a non-2xx status")
+
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookTaskData struct {
+	ID            primitive.ObjectID `json:"id"`
+	Title         string             `json:"title"`
+	SourceID      string             `json:"source_id"`
+	IDTaskSection primitive.ObjectID `json:"id_task_section"`
+}
+
+func GetWebhookTaskData(task *database.Task) WebhookTaskData {
+	title := ""
+	if task.Title != nil {
+		title = *task.Title
+	}
+	return WebhookTaskData{
+		ID:            task.ID,
+		Title:         title,
+		SourceID:      task.SourceID,
+		IDTaskSection: task.IDTaskSection,
+	}
+}
+
+// QueueWebhookEvent records a delivery for each of the user's subscriptions to eventType and attempts them in the background.
+// Failed attempts are picked up again by webhookRetryJob.
+func QueueWebhookEvent(db *mongo.Database, userID primitive.ObjectID, eventType string, data interface{}, timeNow time.Time) ([]database.WebhookDelivery, error) {
+	// leased so the retry job doesn't send the delivery while the first attempt is in flight
+	deliveries, subscriptions, err := insertWebhookDeliveries(db, userID, eventType, data, timeNow.Add(WEBHOOK_DELIVERY_LEASE), timeNow)
+	if err != nil {
+		return nil, err
+	}
+	for index, delivery := range deliveries {
+		go func(delivery database.WebhookDelivery, subscription database.WebhookSubscription) {
+			err := deliverWebhook(db, webhookHTTPClient, delivery, subscription, time.Now())
+			if err != nil {
+				logging.GetSentryLogger().Error().Err(err).Msg("failed to record webhook delivery")
+			}
+		}(delivery, subscriptions[index])
+	}
+	return deliveries, nil
+}
+
+// queueWebhookEventForRetryJob records the deliveries without attempting them, leaving them to webhookRetryJob.
+// Jobs use this as their db connection is closed once they return, which would cut background attempts short.
+func queueWebhookEventForRetryJob(db *mongo.Database, userID primitive.ObjectID, eventType string, data interface{}, timeNow time.Time) ([]database.WebhookDelivery, error) {
+	deliveries, _, err := insertWebhookDeliveries(db, userID, eventType, data, timeNow, timeNow)
+	return deliveries, err
+}
+
+func insertWebhookDeliveries(db *mongo.Database, userID primitive.ObjectID, eventType string, data interface{}, nextAttemptAt time.Time, timeNow time.Time) ([]database.WebhookDelivery, []database.WebhookSubscription, error) {
+	var subscriptions []database.WebhookSubscription
+	err := database.FindWithCollection(database.GetWebhookSubscriptionCollection(db), userID, &[]bson.M{
+		{"is_deleted": false},
+		{"event_types": eventType},
+	}, &subscriptions, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries := []database.WebhookDelivery{}
+	for _, subscription := range subscriptions {
+		deliveryID := primitive.NewObjectID()
+		payload, err := json.Marshal(WebhookEvent{
+			ID:        deliveryID.Hex(),
+			Type:      eventType,
+			CreatedAt: timeNow.UTC().Format(time.RFC3339),
+			Data:      data,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		delivery := database.WebhookDelivery{
+			ID:             deliveryID,
+			SubscriptionID: subscription.ID,
+			UserID:         userID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			NextAttemptAt:  primitive.NewDateTimeFromTime(nextAttemptAt),
+			CreatedAt:      primitive.NewDateTimeFromTime(timeNow),
+			UpdatedAt:      primitive.NewDateTimeFromTime(timeNow),
+		}
+		_, err = database.GetWebhookDeliveryCollection(db).InsertOne(context.Background(), delivery)
+		if err != nil {
+			return nil, nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, subscriptions, nil
+}
+
+func webhookRetryJob() {
+	db, cleanup, err := database.GetDBConnection()
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to connect to db for webhook retries")
+		return
+	}
+	defer cleanup()
+	err = retryPendingWebhookDeliveries(db, webhookHTTPClient, time.Now())
+	if err != nil {
+		logging.GetSentryLogger().Error().Err(err).Msg("failed to retry webhook deliveries")
+	}
+}
+
+func retryPendingWebhookDeliveries(db *mongo.Database, client *http.Client, timeNow time.Time) error {
+	for {
+		var delivery database.WebhookDelivery
+		err := database.GetWebhookDeliveryCollection(db).FindOneAndUpdate(
+			context.Background(),
+			bson.M{"$and": []bson.M{
+				{"is_delivered": false},
+				{"is_abandoned": false},
+				{"next_attempt_at": bson.M{"$lte": primitive.NewDateTimeFromTime(timeNow)}},
+			}},
+			bson.M{"$set": bson.M{"next_attempt_at": primitive.NewDateTimeFromTime(timeNow.Add(WEBHOOK_DELIVERY_LEASE))}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&delivery)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var subscription database.WebhookSubscription
+		err = database.GetWebhookSubscriptionCollection(db).FindOne(
+			context.Background(),
+			bson.M{"$and": []bson.M{
+				{"_id": delivery.SubscriptionID},
+				{"is_deleted": false},
+			}},
+		).Decode(&subscription)
+		if err != nil {
+			// the subscription was deleted since the event was queued
+			_, err = database.GetWebhookDeliveryCollection(db).UpdateOne(
+				context.Background(),
+				bson.M{"_id": delivery.ID},
+				bson.M{"$set": bson.M{"is_abandoned": true, "updated_at": primitive.NewDateTimeFromTime(timeNow)}},
+			)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = deliverWebhook(db, client, delivery, subscription, timeNow)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sends a single delivery attempt and records the outcome, only returning an error if the outcome could not be saved
+func deliverWebhook(db *mongo.Database, client *http.Client, delivery database.WebhookDelivery, subscription database.WebhookSubscription, timeNow time.Time) error {
+	sendErr := sendWebhookRequest(client, delivery, subscription, timeNow)
+
+	attempts := delivery.Attempts + 1
+	fields := bson.M{
+		"attempts":   attempts,
+		"updated_at": primitive.NewDateTimeFromTime(timeNow),
+	}
+	if sendErr == nil {
+		fields["is_delivered"] = true
+		fields["last_error"] = ""
+	} else {
+		fields["last_error"] = sendErr.Error()
+		if attempts >= WEBHOOK_MAX_ATTEMPTS {
+			fields["is_abandoned"] = true
+		} else {
+			fields["next_attempt_at"] = primitive.NewDateTimeFromTime(timeNow.Add(getWebhookRetryDelay(attempts)))
+		}
+	}
+	_, err := database.GetWebhookDeliveryCollection(db).UpdateOne(
+		context.Background(),
+		bson.M{"_id": delivery.ID},
+		bson.M{"$set": fields},
+	)
+	return err
+}
+
+func sendWebhookRequest(client *http.Client, delivery database.WebhookDelivery, subscription database.WebhookSubscription, timeNow time.Time) error {
+	request, err := http.NewRequest("POST", subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return errWebhookUnreachable
+	}
+	timestamp := strconv.FormatInt(timeNow.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-GeneralTask-Event", delivery.EventType)
+	request.Header.Set("X-GeneralTask-Delivery", delivery.ID.Hex())
+	request.Header.Set("X-GeneralTask-Request-Timestamp", timestamp)
+	request.Header.Set("X-GeneralTask-Signature", SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return errWebhookUnreachable
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errWebhookUnsuccessfulStatus
+	}
+	return nil
+}
+
+// signed the same way Slack signs requests to us, so receivers can verify with the same approach
+func SignWebhookPayload(secret string, timestamp string, payload string) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte("v0:" + timestamp + ":" + payload))
+	return "v0=" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func getWebhookRetryDelay(attempts int) time.Duration {
+	return WEBHOOK_BASE_RETRY_DELAY * time.Duration(1<<(attempts-1))
+}
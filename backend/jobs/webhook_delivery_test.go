@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjPlusPlus/task-manager/backend/constants"
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	assert.Equal(t, "v0=180adfbc373c5cba178f4900061acf5c8a708ac41bd420e32bcdaa9d9158bf3c", SignWebhookPayload("secret", "1650481272", `{"type":"task.created"}`))
+}
+
+func TestRetryPendingWebhookDeliveries(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	nowTime, _ := time.Parse(time.RFC3339, "2023-04-20T19:01:12Z")
+	responseCode := http.StatusInternalServerError
+	var receivedRequest *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedRequest = r
+		receivedBody = string(body)
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+
+	userID := primitive.NewObjectID()
+	subscriptionResult, err := database.GetWebhookSubscriptionCollection(db).InsertOne(context.Background(), database.WebhookSubscription{
+		UserID:     userID,
+		URL:        server.URL,
+		Secret:     "secret",
+		EventTypes: []string{constants.WebhookEventTaskCompleted},
+	})
+	assert.NoError(t, err)
+	deliveryID := primitive.NewObjectID()
+	_, err = database.GetWebhookDeliveryCollection(db).InsertOne(context.Background(), database.WebhookDelivery{
+		ID:             deliveryID,
+		SubscriptionID: subscriptionResult.InsertedID.(primitive.ObjectID),
+		UserID:         userID,
+		EventType:      constants.WebhookEventTaskCompleted,
+		Payload:        `{"type":"task.completed"}`,
+		NextAttemptAt:  primitive.NewDateTimeFromTime(nowTime),
+	})
+	assert.NoError(t, err)
+	getDelivery := func() database.WebhookDelivery {
+		var delivery database.WebhookDelivery
+		err := database.GetWebhookDeliveryCollection(db).FindOne(context.Background(), bson.M{"_id": deliveryID}).Decode(&delivery)
+		assert.NoError(t, err)
+		return delivery
+	}
+
+	t.Run("FailureSchedulesRetry", func(t *testing.T) {
+		err := retryPendingWebhookDeliveries(db, server.Client(), nowTime)
+		assert.NoError(t, err)
+		delivery := getDelivery()
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.False(t, delivery.IsDelivered)
+		assert.Equal(t, errWebhookUnsuccessfulStatus.Error(), delivery.LastError)
+		assert.Equal(t, primitive.NewDateTimeFromTime(nowTime.Add(WEBHOOK_BASE_RETRY_DELAY)), delivery.NextAttemptAt)
+
+		// not due yet
+		err = retryPendingWebhookDeliveries(db, server.Client(), nowTime.Add(time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, getDelivery().Attempts)
+	})
+	t.Run("SuccessIsSigned", func(t *testing.T) {
+		responseCode = http.StatusOK
+		retryTime := nowTime.Add(WEBHOOK_BASE_RETRY_DELAY)
+		err := retryPendingWebhookDeliveries(db, server.Client(), retryTime)
+		assert.NoError(t, err)
+		delivery := getDelivery()
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.True(t, delivery.IsDelivered)
+
+		assert.Equal(t, `{"type":"task.completed"}`, receivedBody)
+		assert.Equal(t, constants.WebhookEventTaskCompleted, receivedRequest.Header.Get("X-GeneralTask-Event"))
+		assert.Equal(t, deliveryID.Hex(), receivedRequest.Header.Get("X-GeneralTask-Delivery"))
+		timestamp := receivedRequest.Header.Get("X-GeneralTask-Request-Timestamp")
+		assert.Equal(t, SignWebhookPayload("secret", timestamp, receivedBody), receivedRequest.Header.Get("X-GeneralTask-Signature"))
+	})
+	t.Run("AbandonedAfterMaxAttempts", func(t *testing.T) {
+		responseCode = http.StatusBadGateway
+		_, err := database.GetWebhookDeliveryCollection(db).UpdateOne(
+			context.Background(),
+			bson.M{"_id": deliveryID},
+			bson.M{"$set": bson.M{"is_delivered": false, "attempts": WEBHOOK_MAX_ATTEMPTS - 1, "next_attempt_at": primitive.NewDateTimeFromTime(nowTime)}},
+		)
+		assert.NoError(t, err)
+		err = retryPendingWebhookDeliveries(db, server.Client(), nowTime)
+		assert.NoError(t, err)
+		delivery := getDelivery()
+		assert.Equal(t, WEBHOOK_MAX_ATTEMPTS, delivery.Attempts)
+		assert.True(t, delivery.IsAbandoned)
+	})
+}
+
+func TestQueueWebhookEventForRetryJob(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+
+	nowTime, _ := time.Parse(time.RFC3339, "2023-04-20T19:01:12Z")
+	userID := primitive.NewObjectID()
+	_, err = database.GetWebhookSubscriptionCollection(db).InsertOne(context.Background(), database.WebhookSubscription{
+		UserID:     userID,
+		URL:        "https://localhost:1/hook",
+		Secret:     "secret",
+		EventTypes: []string{constants.WebhookEventTaskCreated},
+	})
+	assert.NoError(t, err)
+
+	deliveries, err := queueWebhookEventForRetryJob(db, userID, constants.WebhookEventTaskCreated, WebhookTaskData{}, nowTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deliveries))
+	// left for the retry job to send right away rather than attempted in the background
+	var delivery database.WebhookDelivery
+	err = database.GetWebhookDeliveryCollection(db).FindOne(context.Background(), bson.M{"_id": deliveries[0].ID}).Decode(&delivery)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivery.Attempts)
+	assert.Equal(t, primitive.NewDateTimeFromTime(nowTime), delivery.NextAttemptAt)
+}
@@ -33,7 +33,7 @@ func main() {
 	}
 	apiStruct, dbCleanup := api.GetAPIWithDBCleanup()
 	defer dbCleanup()
-	scheduler, err := jobs.GetScheduler()
+	scheduler, err := jobs.GetScheduler(apiStruct)
 	if err != nil {
 		logger.Error().Err(err).Msg("error getting job scheduler")
 	} else {
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate012(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	taskCollection := database.GetTaskCollection(db)
+	occurrenceKey := primitive.NewObjectID().Hex() + "_20221115T100010Z"
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := taskCollection.InsertOne(context.Background(), database.Task{RecurringTaskOccurrenceKey: occurrenceKey})
+		assert.NoError(t, err)
+		_, err = taskCollection.InsertOne(context.Background(), database.Task{RecurringTaskOccurrenceKey: occurrenceKey})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+
+		// tasks not created from a template don't have a key
+		_, err = taskCollection.InsertOne(context.Background(), database.Task{})
+		assert.NoError(t, err)
+		_, err = taskCollection.InsertOne(context.Background(), database.Task{})
+		assert.NoError(t, err)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = taskCollection.InsertOne(context.Background(), database.Task{RecurringTaskOccurrenceKey: occurrenceKey})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate013(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	reminderDeliveryCollection := database.GetReminderDeliveryCollection(db)
+	key := primitive.NewObjectID().Hex() + "_1682017272"
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := reminderDeliveryCollection.InsertOne(context.Background(), database.ReminderDelivery{Key: key})
+		assert.NoError(t, err)
+		_, err = reminderDeliveryCollection.InsertOne(context.Background(), database.ReminderDelivery{Key: key})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = reminderDeliveryCollection.InsertOne(context.Background(), database.ReminderDelivery{Key: key})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate014(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	taskDependencyCollection := database.GetTaskDependencyCollection(db)
+	dependency := database.TaskDependency{TaskID: primitive.NewObjectID(), BlockingTaskID: primitive.NewObjectID()}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := taskDependencyCollection.InsertOne(context.Background(), dependency)
+		assert.NoError(t, err)
+		_, err = taskDependencyCollection.InsertOne(context.Background(), dependency)
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+		// imported links don't have a blocking task ID
+		importedDependency := database.TaskDependency{TaskID: dependency.TaskID, BlockingTaskIDExternal: "example-issue-id"}
+		_, err = taskDependencyCollection.InsertOne(context.Background(), importedDependency)
+		assert.NoError(t, err)
+		_, err = taskDependencyCollection.InsertOne(context.Background(), importedDependency)
+		assert.NoError(t, err)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = taskDependencyCollection.InsertOne(context.Background(), dependency)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate015(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	labelCollection := database.GetLabelCollection(db)
+	label := database.Label{UserID: primitive.NewObjectID(), Name: "on-call"}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := labelCollection.InsertOne(context.Background(), label)
+		assert.NoError(t, err)
+		_, err = labelCollection.InsertOne(context.Background(), label)
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+		// names only need to be unique per user
+		_, err = labelCollection.InsertOne(context.Background(), database.Label{UserID: primitive.NewObjectID(), Name: label.Name})
+		assert.NoError(t, err)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = labelCollection.InsertOne(context.Background(), label)
+		assert.NoError(t, err)
+	})
+}
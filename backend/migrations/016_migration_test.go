@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigrate016(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	userID := primitive.NewObjectID()
+	title := "Quarterly planning notes"
+	_, err = database.GetNoteCollection(db).InsertOne(context.Background(), database.Note{UserID: userID, Title: &title})
+	assert.NoError(t, err)
+	searchFilter := bson.M{"$and": []bson.M{{"user_id": userID}, {"$text": bson.M{"$search": "planning"}}}}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		count, err := database.GetNoteCollection(db).CountDocuments(context.Background(), searchFilter)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		// text search requires a text index
+		_, err := database.GetNoteCollection(db).CountDocuments(context.Background(), searchFilter)
+		assert.Error(t, err)
+	})
+}
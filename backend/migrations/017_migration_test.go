@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate017(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	timeEntryCollection := database.GetTimeEntryCollection(db)
+	runningTimeEntry := database.TimeEntry{UserID: primitive.NewObjectID(), IsRunning: true}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := timeEntryCollection.InsertOne(context.Background(), runningTimeEntry)
+		assert.NoError(t, err)
+		_, err = timeEntryCollection.InsertOne(context.Background(), runningTimeEntry)
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+		// only one timer can be running, but any number can be stopped
+		stoppedTimeEntry := database.TimeEntry{UserID: runningTimeEntry.UserID}
+		_, err = timeEntryCollection.InsertOne(context.Background(), stoppedTimeEntry)
+		assert.NoError(t, err)
+		_, err = timeEntryCollection.InsertOne(context.Background(), stoppedTimeEntry)
+		assert.NoError(t, err)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = timeEntryCollection.InsertOne(context.Background(), runningTimeEntry)
+		assert.NoError(t, err)
+	})
+}
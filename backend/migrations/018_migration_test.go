@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate018(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	jobLocksCollection := database.GetJobLocksCollection(db)
+	jobLock := bson.M{"resource": "account_sync_user_01-02-2023 15:00"}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := jobLocksCollection.InsertOne(context.Background(), jobLock)
+		assert.NoError(t, err)
+		_, err = jobLocksCollection.InsertOne(context.Background(), bson.M{"resource": jobLock["resource"]})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = jobLocksCollection.InsertOne(context.Background(), bson.M{"resource": jobLock["resource"]})
+		assert.NoError(t, err)
+	})
+}
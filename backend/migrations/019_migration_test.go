@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate019(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	personalAccessTokenCollection := database.GetPersonalAccessTokenCollection(db)
+	personalAccessToken := database.PersonalAccessToken{UserID: primitive.NewObjectID(), TokenHash: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := personalAccessTokenCollection.InsertOne(context.Background(), personalAccessToken)
+		assert.NoError(t, err)
+		// the hash alone identifies the token, whoever it belongs to
+		_, err = personalAccessTokenCollection.InsertOne(context.Background(), database.PersonalAccessToken{UserID: primitive.NewObjectID(), TokenHash: personalAccessToken.TokenHash})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = personalAccessTokenCollection.InsertOne(context.Background(), personalAccessToken)
+		assert.NoError(t, err)
+	})
+}
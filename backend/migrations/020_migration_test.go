@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrate020(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	userCollection := database.GetUserCollection(db)
+	user := database.User{Email: "calendar_feed_1@generaltask.com", CalendarFeedToken: "feed_token"}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+
+		_, err := userCollection.InsertOne(context.Background(), user)
+		assert.NoError(t, err)
+		_, err = userCollection.InsertOne(context.Background(), database.User{Email: "calendar_feed_2@generaltask.com", CalendarFeedToken: user.CalendarFeedToken})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+		// users without a feed aren't indexed
+		_, err = userCollection.InsertOne(context.Background(), database.User{Email: "calendar_feed_3@generaltask.com"})
+		assert.NoError(t, err)
+		_, err = userCollection.InsertOne(context.Background(), database.User{Email: "calendar_feed_4@generaltask.com"})
+		assert.NoError(t, err)
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+
+		_, err = userCollection.InsertOne(context.Background(), database.User{Email: "calendar_feed_2@generaltask.com", CalendarFeedToken: user.CalendarFeedToken})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjPlusPlus/task-manager/backend/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate022(t *testing.T) {
+	db, dbCleanup, err := database.GetDBConnection()
+	assert.NoError(t, err)
+	defer dbCleanup()
+	migrate, err := getMigrate("")
+	assert.NoError(t, err)
+	err = migrate.Steps(1)
+	assert.NoError(t, err)
+
+	getIndexNames := func() []string {
+		specifications, err := database.GetJobLocksCollection(db).Indexes().ListSpecifications(context.Background())
+		assert.NoError(t, err)
+		indexNames := []string{}
+		for _, specification := range specifications {
+			indexNames = append(indexNames, specification.Name)
+		}
+		return indexNames
+	}
+
+	t.Run("MigrateUp", func(t *testing.T) {
+		err = migrate.Steps(1)
+		assert.NoError(t, err)
+		assert.Contains(t, getIndexNames(), "exclusive.expiresAt_1")
+	})
+	t.Run("MigrateDown", func(t *testing.T) {
+		err = migrate.Steps(-1)
+		assert.NoError(t, err)
+		assert.NotContains(t, getIndexNames(), "exclusive.expiresAt_1")
+	})
+}
@@ -3,6 +3,7 @@ package settings
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/jjPlusPlus/task-manager/backend/external"
 	"github.com/jjPlusPlus/task-manager/backend/logging"
@@ -191,6 +192,68 @@ var HasDismissedMulticalPromptSetting = SettingDefinition{
 	},
 }
 
+// offsets are in minutes before the due date (or meeting start)
+var ReminderDefaultOffsetSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldReminderDefaultOffset,
+	DefaultChoice: constants.ChoiceKeyReminderNone,
+	Choices: []SettingChoice{
+		{Key: constants.ChoiceKeyReminderNone},
+		{Key: "0"},
+		{Key: "5"},
+		{Key: "15"},
+		{Key: "30"},
+		{Key: "60"},
+		{Key: "1440"},
+	},
+}
+
+var ReminderDeliveryMethodSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldReminderDeliveryMethod,
+	DefaultChoice: constants.ChoiceKeyReminderDeliveryEmail,
+	Choices: []SettingChoice{
+		{Key: constants.ChoiceKeyReminderDeliveryEmail},
+		{Key: constants.ChoiceKeyReminderDeliverySlack},
+	},
+}
+
+// turning this off unsubscribes the user from the daily digest
+var DailyDigestEnabledSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldDailyDigestEnabled,
+	DefaultChoice: "true",
+	Choices: []SettingChoice{
+		{Key: "true"},
+		{Key: "false"},
+	},
+}
+
+var DailyDigestDeliveryMethodSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldDailyDigestDeliveryMethod,
+	DefaultChoice: constants.ChoiceKeyReminderDeliveryEmail,
+	Choices:       ReminderDeliveryMethodSetting.Choices,
+}
+
+// working hours are hours of the day in the user's timezone
+var PlannerWorkingHoursStartSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldPlannerWorkingHoursStart,
+	DefaultChoice: "9",
+	Choices:       getHourChoices(0, 23),
+}
+
+var PlannerWorkingHoursEndSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldPlannerWorkingHoursEnd,
+	DefaultChoice: "17",
+	Choices:       getHourChoices(1, 24),
+}
+
+var PlannerIncludeWeekendsSetting = SettingDefinition{
+	FieldKey:      constants.SettingFieldPlannerIncludeWeekends,
+	DefaultChoice: "false",
+	Choices: []SettingChoice{
+		{Key: "true"},
+		{Key: "false"},
+	},
+}
+
 var LinearTaskFilteringSetting = SettingDefinition{
 	DefaultChoice: "all_cycles",
 	Choices: []SettingChoice{
@@ -227,6 +290,16 @@ var hardcodedSettings = []SettingDefinition{
 	LabSmartPrioritizeEnabledSetting,
 	// multical settings
 	HasDismissedMulticalPromptSetting,
+	// reminder settings
+	ReminderDefaultOffsetSetting,
+	ReminderDeliveryMethodSetting,
+	// daily digest settings
+	DailyDigestEnabledSetting,
+	DailyDigestDeliveryMethodSetting,
+	// planner settings
+	PlannerWorkingHoursStartSetting,
+	PlannerWorkingHoursEndSetting,
+	PlannerIncludeWeekendsSetting,
 }
 
 func GetSettingsOptions(db *mongo.Database, userID primitive.ObjectID) (*[]SettingDefinition, error) {
@@ -340,7 +413,15 @@ func GetSettingsOptions(db *mongo.Database, userID primitive.ObjectID) (*[]Setti
 // this helper can't live in the db package because its use of the external package would cause an import cycle
 func getCalendarTokens(db *mongo.Database, userID primitive.ObjectID) (*[]database.ExternalAPIToken, error) {
 	// in the future, make sure we add other services here with calendars
-	return database.GetExternalTokens(db, userID, external.TASK_SERVICE_ID_GOOGLE)
+	calendarTokens := []database.ExternalAPIToken{}
+	for _, serviceID := range []string{external.TASK_SERVICE_ID_GOOGLE, external.TASK_SERVICE_ID_MICROSOFT} {
+		tokens, err := database.GetExternalTokens(db, userID, serviceID)
+		if err != nil {
+			return nil, err
+		}
+		calendarTokens = append(calendarTokens, *tokens...)
+	}
+	return &calendarTokens, nil
 }
 
 func getGithubViews(db *mongo.Database, userID primitive.ObjectID) (*[]database.View, error) {
@@ -354,6 +435,14 @@ func getGithubViews(db *mongo.Database, userID primitive.ObjectID) (*[]database.
 	return &views, nil
 }
 
+func getHourChoices(firstHour int, lastHour int) []SettingChoice {
+	choices := []SettingChoice{}
+	for hour := firstHour; hour <= lastHour; hour++ {
+		choices = append(choices, SettingChoice{Key: strconv.Itoa(hour)})
+	}
+	return choices
+}
+
 func getGithubFieldKey(githubView database.View, suffix string) string {
 	return githubView.ID.Hex() + "_" + suffix
 }
@@ -76,7 +76,7 @@ func TestGetSettingsOptions(t *testing.T) {
 		&database.CalendarAccount{
 			UserID:     userID,
 			IDExternal: "b",
-			Calendars:  []database.Calendar{{"", "cal1", "", "title1", "", ""}, {"", "cal2", "", "title2", "", ""}},
+			Calendars:  []database.Calendar{{CalendarID: "cal1", Title: "title1"}, {CalendarID: "cal2", Title: "title2"}},
 		},
 	)
 	assert.NoError(t, err)
@@ -102,7 +102,7 @@ func TestGetSettingsOptions(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		settings, err := GetSettingsOptions(db, userID)
 		assert.NoError(t, err)
-		assert.Equal(t, 30, len(*settings))
+		assert.Equal(t, 37, len(*settings))
 		assert.Equal(t, "sidebar_linear_preference", (*settings)[3].FieldKey)
 		assert.Equal(t, "sidebar_jira_preference", (*settings)[4].FieldKey)
 		assert.Equal(t, "sidebar_github_preference", (*settings)[5].FieldKey)
@@ -115,18 +115,25 @@ func TestGetSettingsOptions(t *testing.T) {
 		assert.Equal(t, "move_empty_lists_to_bottom", (*settings)[12].FieldKey)
 		assert.Equal(t, "lab_smart_prioritize_enabled", (*settings)[13].FieldKey)
 		assert.Equal(t, "has_dismissed_multical_prompt", (*settings)[14].FieldKey)
-		assert.Equal(t, insertedViewID+"_github_filtering_preference", (*settings)[15].FieldKey)
-		assert.Equal(t, insertedViewID+"_github_sorting_preference", (*settings)[16].FieldKey)
-		assert.Equal(t, insertedViewID+"_github_sorting_direction", (*settings)[17].FieldKey)
-		assert.Equal(t, insertedSectionID+"_task_sorting_preference_main", (*settings)[18].FieldKey)
-		assert.Equal(t, insertedSectionID+"_task_sorting_direction_main", (*settings)[19].FieldKey)
-		assert.Equal(t, insertedSectionID+"_task_sorting_preference_overview", (*settings)[20].FieldKey)
-		assert.Equal(t, insertedSectionID+"_task_sorting_direction_overview", (*settings)[21].FieldKey)
-		assert.Equal(t, "000000000000000000000001_task_sorting_preference_main", (*settings)[22].FieldKey)
-		assert.Equal(t, "000000000000000000000001_task_sorting_direction_main", (*settings)[23].FieldKey)
-		assert.Equal(t, "000000000000000000000001_task_sorting_preference_overview", (*settings)[24].FieldKey)
-		assert.Equal(t, "000000000000000000000001_task_sorting_direction_overview", (*settings)[25].FieldKey)
-		calendarSetting := (*settings)[26]
+		assert.Equal(t, "reminder_default_offset", (*settings)[15].FieldKey)
+		assert.Equal(t, "reminder_delivery_method", (*settings)[16].FieldKey)
+		assert.Equal(t, "daily_digest_enabled", (*settings)[17].FieldKey)
+		assert.Equal(t, "daily_digest_delivery_method", (*settings)[18].FieldKey)
+		assert.Equal(t, "planner_working_hours_start", (*settings)[19].FieldKey)
+		assert.Equal(t, "planner_working_hours_end", (*settings)[20].FieldKey)
+		assert.Equal(t, "planner_include_weekends", (*settings)[21].FieldKey)
+		assert.Equal(t, insertedViewID+"_github_filtering_preference", (*settings)[22].FieldKey)
+		assert.Equal(t, insertedViewID+"_github_sorting_preference", (*settings)[23].FieldKey)
+		assert.Equal(t, insertedViewID+"_github_sorting_direction", (*settings)[24].FieldKey)
+		assert.Equal(t, insertedSectionID+"_task_sorting_preference_main", (*settings)[25].FieldKey)
+		assert.Equal(t, insertedSectionID+"_task_sorting_direction_main", (*settings)[26].FieldKey)
+		assert.Equal(t, insertedSectionID+"_task_sorting_preference_overview", (*settings)[27].FieldKey)
+		assert.Equal(t, insertedSectionID+"_task_sorting_direction_overview", (*settings)[28].FieldKey)
+		assert.Equal(t, "000000000000000000000001_task_sorting_preference_main", (*settings)[29].FieldKey)
+		assert.Equal(t, "000000000000000000000001_task_sorting_direction_main", (*settings)[30].FieldKey)
+		assert.Equal(t, "000000000000000000000001_task_sorting_preference_overview", (*settings)[31].FieldKey)
+		assert.Equal(t, "000000000000000000000001_task_sorting_direction_overview", (*settings)[32].FieldKey)
+		calendarSetting := (*settings)[33]
 		assert.Equal(t, constants.SettingFieldCalendarForNewTasks, calendarSetting.FieldKey)
 		assert.Equal(t, "a", calendarSetting.DefaultChoice)
 		assert.Equal(t, []SettingChoice{
@@ -134,7 +141,7 @@ func TestGetSettingsOptions(t *testing.T) {
 			{Key: "b", Name: "oof 2"},
 			{Key: "", Name: ""},
 		}, calendarSetting.Choices)
-		calendarIDSetting := (*settings)[27]
+		calendarIDSetting := (*settings)[34]
 		assert.Equal(t, constants.SettingFieldCalendarIDForNewTasks, calendarIDSetting.FieldKey)
 		assert.Equal(t, []SettingChoice{
 			{Key: "cal1", Name: "title1"},
@@ -0,0 +1,95 @@
+package templating
+
+import (
+	"bytes"
+	htmlTemplate "html/template"
+	textTemplate "text/template"
+)
+
+type DailyDigestItem struct {
+	Title    string
+	Subtitle string
+	Link     string
+}
+
+type DailyDigestCompletion struct {
+	Source string
+	Count  int
+}
+
+type DailyDigest struct {
+	Date               string
+	Events             []DailyDigestItem
+	DueTasks           []DailyDigestItem
+	PullRequests       []DailyDigestItem
+	CompletedYesterday []DailyDigestCompletion
+	HomeURL            string
+}
+
+func (digest DailyDigest) IsEmpty() bool {
+	return len(digest.Events) == 0 && len(digest.DueTasks) == 0 && len(digest.PullRequests) == 0 && len(digest.CompletedYesterday) == 0
+}
+
+func (digest DailyDigest) CompletedYesterdayCount() int {
+	count := 0
+	for _, completion := range digest.CompletedYesterday {
+		count += completion.Count
+	}
+	return count
+}
+
+const dailyDigestHTML = `{{ define "Item" }}<li>{{ if .Link }}<a href="{{ .Link }}">{{ .Title }}</a>{{ else }}{{ .Title }}{{ end }}{{ if .Subtitle }} <span style="color: #767676;">{{ .Subtitle }}</span>{{ end }}</li>{{ end }}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        html, body {
+            font-size: 16px;
+            font-family: "Gothic A1", sans-serif;
+        }
+    </style>
+</head>
+<body>
+<h2>Your day, {{ .Date }}</h2>
+{{ if .Events }}<h3>Meetings</h3>
+<ul>{{ range .Events }}{{ template "Item" . }}{{ end }}</ul>
+{{ end }}{{ if .DueTasks }}<h3>Due today</h3>
+<ul>{{ range .DueTasks }}{{ template "Item" . }}{{ end }}</ul>
+{{ end }}{{ if .PullRequests }}<h3>Pull requests to review</h3>
+<ul>{{ range .PullRequests }}{{ template "Item" . }}{{ end }}</ul>
+{{ end }}{{ if .CompletedYesterday }}<h3>Completed yesterday: {{ .CompletedYesterdayCount }}</h3>
+<ul>{{ range .CompletedYesterday }}<li>{{ .Source }}: {{ .Count }}</li>{{ end }}</ul>
+{{ end }}<p style="color: #767676;">You can unsubscribe from the daily digest in your <a href="{{ .HomeURL }}">General Task</a> settings.</p>
+</body>
+</html>
+`
+
+const dailyDigestText = `{{ define "Item" }}• {{ .Title }}{{ if .Subtitle }} ({{ .Subtitle }}){{ end }}{{ if .Link }} {{ .Link }}{{ end }}
+{{ end }}Your day, {{ .Date }}
+{{ if .Events }}
+Meetings
+{{ range .Events }}{{ template "Item" . }}{{ end }}{{ end }}{{ if .DueTasks }}
+Due today
+{{ range .DueTasks }}{{ template "Item" . }}{{ end }}{{ end }}{{ if .PullRequests }}
+Pull requests to review
+{{ range .PullRequests }}{{ template "Item" . }}{{ end }}{{ end }}{{ if .CompletedYesterday }}
+Completed yesterday: {{ .CompletedYesterdayCount }}
+{{ range .CompletedYesterday }}• {{ .Source }}: {{ .Count }}
+{{ end }}{{ end }}`
+
+var dailyDigestHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("daily_digest_html").Parse(dailyDigestHTML))
+var dailyDigestTextTemplate = textTemplate.Must(textTemplate.New("daily_digest_text").Parse(dailyDigestText))
+
+func RenderDailyDigestHTML(digest DailyDigest) (string, error) {
+	buffer := new(bytes.Buffer)
+	err := dailyDigestHTMLTemplate.Execute(buffer, digest)
+	return buffer.String(), err
+}
+
+// RenderDailyDigestText is used for Slack messages and as the plain text version of the email
+func RenderDailyDigestText(digest DailyDigest) (string, error) {
+	buffer := new(bytes.Buffer)
+	err := dailyDigestTextTemplate.Execute(buffer, digest)
+	return buffer.String(), err
+}
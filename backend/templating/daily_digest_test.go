@@ -0,0 +1,48 @@
+package templating
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderDailyDigest(t *testing.T) {
+	digest := DailyDigest{
+		Date:               "Thursday, April 20",
+		Events:             []DailyDigestItem{{Title: "Standup", Subtitle: "9:30 AM", Link: "https://calendar.google.com/event"}},
+		DueTasks:           []DailyDigestItem{{Title: "Write <report>", Link: "http://localhost:3000/tasks/1/2"}},
+		PullRequests:       []DailyDigestItem{{Title: "Fix login", Subtitle: "frontend", Link: "https://github.com/pr/1"}},
+		CompletedYesterday: []DailyDigestCompletion{{Source: "General Task", Count: 2}, {Source: "Linear", Count: 1}},
+		HomeURL:            "http://localhost:3000/",
+	}
+
+	t.Run("HTML", func(t *testing.T) {
+		result, err := RenderDailyDigestHTML(digest)
+		assert.NoError(t, err)
+		assert.Contains(t, result, "<h2>Your day, Thursday, April 20</h2>")
+		assert.Contains(t, result, `<li><a href="https://calendar.google.com/event">Standup</a> <span style="color: #767676;">9:30 AM</span></li>`)
+		// titles are escaped
+		assert.Contains(t, result, `<li><a href="http://localhost:3000/tasks/1/2">Write &lt;report&gt;</a></li>`)
+		assert.Contains(t, result, "<h3>Completed yesterday: 3</h3>")
+		assert.Contains(t, result, `<a href="http://localhost:3000/">General Task</a> settings`)
+	})
+	t.Run("Text", func(t *testing.T) {
+		result, err := RenderDailyDigestText(digest)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(
+			"Your day, Thursday, April 20\n",
+			"\nMeetings\n• Standup (9:30 AM) https://calendar.google.com/event\n",
+			"\nDue today\n• Write <report> http://localhost:3000/tasks/1/2\n",
+			"\nPull requests to review\n• Fix login (frontend) https://github.com/pr/1\n",
+			"\nCompleted yesterday: 3\n• General Task: 2\n• Linear: 1\n",
+		), result)
+	})
+	t.Run("OnlySomeSections", func(t *testing.T) {
+		result, err := RenderDailyDigestText(DailyDigest{Date: "Friday, April 21", DueTasks: []DailyDigestItem{{Title: "Taxes"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, "Your day, Friday, April 21\n\nDue today\n• Taxes\n", result)
+		assert.False(t, DailyDigest{DueTasks: []DailyDigestItem{{Title: "Taxes"}}}.IsEmpty())
+		assert.True(t, DailyDigest{Date: "Friday, April 21"}.IsEmpty())
+	})
+}
@@ -21,6 +21,10 @@ var ConferencePatterns = map[string]ConferenceCall{
 		Platform: "Zoom",
 		Logo:     "/images/zoom.svg",
 	},
+	"teams.microsoft.com": {
+		Platform: "Microsoft Teams",
+		Logo:     "/images/teams.svg",
+	},
 }
 
 // only return the first conference url - in the future we may want to return all of them
@@ -1,12 +1,8 @@
 package utils
 
 import (
-	"bytes"
-	"errors"
-	"net/http"
 	"regexp"
 
-	"github.com/jjPlusPlus/task-manager/backend/config"
 	"github.com/jjPlusPlus/task-manager/backend/constants"
 )
 
@@ -44,19 +40,3 @@ func IsEmailValid(e string) bool {
 	}
 	return emailRegex.MatchString(e)
 }
-
-const MANDRILL_SEND_URL = "https://mandrillapp.com/api/1.0/messages/send"
-
-func TestMailchimpEmail() error {
-	testMessage := `{"key": "` + config.GetConfigValue("MANDRILL_CLIENT_SECRET") + `", "message": {"from_email": "julian@resonant-kelpie-404a42.netlify.app", "subject": "General Task Test", "text": "Testing emails from General Task!", "to": [{ "email": "julian@resonant-kelpie-404a42.netlify.app", "type": "to" }]}}`
-	req, _ := http.NewRequest("POST", MANDRILL_SEND_URL, bytes.NewBuffer([]byte(testMessage)))
-	req.Header.Add("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("email send failed")
-	}
-	return nil
-}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jjPlusPlus/task-manager/backend/config"
+)
+
+const MANDRILL_SEND_URL = "https://mandrillapp.com/api/1.0/messages/send"
+const EMAIL_FROM_ADDRESS = "julian@resonant-kelpie-404a42.netlify.app"
+
+// Mailer sends emails, so that delivery can be swapped out (i.e. in tests)
+type Mailer interface {
+	SendEmail(toEmail string, subject string, text string) error
+	// text is the fallback for email clients which don't display HTML
+	SendHTMLEmail(toEmail string, subject string, html string, text string) error
+}
+
+type MandrillMailer struct {
+	APIKey    string
+	FromEmail string
+	SendURL   string
+}
+
+type mandrillRecipient struct {
+	Email string `json:"email"`
+	Type  string `json:"type"`
+}
+
+type mandrillMessage struct {
+	FromEmail string              `json:"from_email"`
+	Subject   string              `json:"subject"`
+	Text      string              `json:"text"`
+	HTML      string              `json:"html,omitempty"`
+	To        []mandrillRecipient `json:"to"`
+}
+
+type mandrillSendParams struct {
+	Key     string          `json:"key"`
+	Message mandrillMessage `json:"message"`
+}
+
+func GetMailer() Mailer {
+	return MandrillMailer{
+		APIKey:    config.GetConfigValue("MANDRILL_CLIENT_SECRET"),
+		FromEmail: EMAIL_FROM_ADDRESS,
+		SendURL:   MANDRILL_SEND_URL,
+	}
+}
+
+func (mailer MandrillMailer) SendEmail(toEmail string, subject string, text string) error {
+	return mailer.SendHTMLEmail(toEmail, subject, "", text)
+}
+
+func (mailer MandrillMailer) SendHTMLEmail(toEmail string, subject string, html string, text string) error {
+	body, err := json.Marshal(mandrillSendParams{
+		Key: mailer.APIKey,
+		Message: mandrillMessage{
+			FromEmail: mailer.FromEmail,
+			Subject:   subject,
+			Text:      text,
+			HTML:      html,
+			To:        []mandrillRecipient{{Email: toEmail, Type: "to"}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", mailer.SendURL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("email send failed")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMandrillMailer(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var receivedBody map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &receivedBody)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		mailer := MandrillMailer{APIKey: "key", FromEmail: "from@example.com", SendURL: server.URL}
+		err := mailer.SendEmail("to@example.com", "Reminder", "Task \"hello\" is due")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"key": "key",
+			"message": map[string]interface{}{
+				"from_email": "from@example.com",
+				"subject":    "Reminder",
+				"text":       "Task \"hello\" is due",
+				"to":         []interface{}{map[string]interface{}{"email": "to@example.com", "type": "to"}},
+			},
+		}, receivedBody)
+	})
+	t.Run("SuccessHTML", func(t *testing.T) {
+		var receivedBody map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &receivedBody)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		mailer := MandrillMailer{APIKey: "key", FromEmail: "from@example.com", SendURL: server.URL}
+		err := mailer.SendHTMLEmail("to@example.com", "Your day", "<h2>Your day</h2>", "Your day")
+		assert.NoError(t, err)
+		message := receivedBody["message"].(map[string]interface{})
+		assert.Equal(t, "<h2>Your day</h2>", message["html"])
+		assert.Equal(t, "Your day", message["text"])
+	})
+	t.Run("BadStatusCode", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		mailer := MandrillMailer{APIKey: "key", FromEmail: "from@example.com", SendURL: server.URL}
+		err := mailer.SendEmail("to@example.com", "Reminder", "text")
+		assert.EqualError(t, err, "email send failed")
+	})
+}
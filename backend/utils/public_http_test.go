@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, address := range []string{"8.8.8.8", "2606:4700:4700::1111", "100.128.0.1"} {
+		assert.True(t, IsPublicIP(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{
+		"127.0.0.1",
+		"::1",
+		"10.0.0.5",
+		"172.16.0.1",
+		"192.168.1.1",
+		"169.254.169.254",
+		"fe80::1",
+		"fd00::1",
+		"0.0.0.0",
+		"::",
+		"100.64.0.1",
+		"::ffff:127.0.0.1",
+		"::ffff:169.254.169.254",
+	} {
+		assert.False(t, IsPublicIP(net.ParseIP(address)), address)
+	}
+	assert.False(t, IsPublicIP(nil))
+}
+
+func TestNewPublicHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewPublicHTTPClient(time.Second).Get(server.URL)
+	assert.True(t, errors.Is(err, ErrNonPublicAddress))
+}